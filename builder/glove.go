@@ -16,14 +16,16 @@ package builder
 
 import (
 	"os"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 
 	"github.com/ynqa/wego/config"
+	"github.com/ynqa/wego/corpus"
 	"github.com/ynqa/wego/model"
 	"github.com/ynqa/wego/model/glove"
-	"github.com/ynqa/wego/validate"
+	"github.com/ynqa/wego/vectorio"
 )
 
 // GloveBuilder manages the members to build Model interface.
@@ -31,6 +33,14 @@ type GloveBuilder struct {
 	// input file path.
 	inputFile string
 
+	// corpora with their weights to read instead of inputFile, and the mode to weight them.
+	inputWeights map[string]float64
+	weightMode   string
+
+	// output file path, and whether its directory is validated to be writable before training.
+	outputFile  string
+	checkOutput bool
+
 	// common configs.
 	dimension  int
 	iteration  int
@@ -41,16 +51,83 @@ type GloveBuilder struct {
 	toLower    bool
 	verbose    bool
 
+	// fraction of token occurrences for vocabulary to cover.
+	minCoverage float64
+
+	// hook to decide whether the word is kept in vocabulary per word.
+	minCountFunc func(word string, freq int) bool
+
+	// fixed ids of words for vocabulary to honor.
+	wordIDs map[string]int
+
+	// master seed of the run, which is generated if it is 0.
+	seed uint64
+
+	// output format of word vectors.
+	outputFormat string
+
+	// way to treat invalid UTF-8 sequences in corpus.
+	sanitizeUTF8 string
+
+	// limits of tokens to train on and to build vocabulary.
+	maxTokens      int64
+	maxVocabTokens int64
+
+	// scripts to keep tokens predominantly in them, and fraction of runes in them to keep tokens.
+	scripts         []string
+	scriptThreshold float64
+
+	// whether to split tokens on hyphens and on apostrophes.
+	splitHyphens     bool
+	splitApostrophes bool
+
+	// special tokens with the reserved ids in vocabulary.
+	specialTokens []string
+
+	// times to retry reading corpus on transient errors, and the first delay of backoff.
+	readRetries    int
+	readRetryDelay time.Duration
+
+	// style and precision to format values of word vectors in text format.
+	saveFormat    string
+	savePrecision int
+
+	// words to track their vectors per iteration, and file path to append them.
+	trackWords []string
+	trackPath  string
+
+	// callback of progress of training.
+	onProgress model.ProgressFunc
+
+	// hooks called before and after each iteration.
+	hooks model.Hooks
+
 	// glove configs.
 	solver string
 	xmax   int
 	alpha  float64
+
+	// threshold for subsampling on counting co-occurrences, no subsampling if it is not positive.
+	subsampleThreshold float64
+
+	// co-occurrence file to train on instead of corpus, its format, and vocabulary file for its word ids.
+	cooccurrenceFile   string
+	cooccurrenceFormat string
+	cooccurrenceVocab  string
+
+	// directory and interval of iterations to save checkpoint, and checkpoint file to resume from.
+	checkpointDir   string
+	checkpointEvery int
+	resumeFrom      string
 }
 
 // NewGloveBuilder creates *GloveBuilder
 func NewGloveBuilder() *GloveBuilder {
 	return &GloveBuilder{
-		inputFile: config.DefaultInputFile,
+		inputFile:  config.DefaultInputFile,
+		weightMode: WeightScale,
+
+		checkOutput: config.DefaultCheckOutput,
 
 		dimension:  config.DefaultDimension,
 		iteration:  config.DefaultIteration,
@@ -61,29 +138,99 @@ func NewGloveBuilder() *GloveBuilder {
 		toLower:    config.DefaultToLower,
 		verbose:    config.DefaultVerbose,
 
+		minCoverage: config.DefaultMinCoverage,
+
+		outputFormat: config.DefaultOutputFormat,
+		sanitizeUTF8: config.DefaultSanitizeUTF8,
+
+		maxTokens:      config.DefaultMaxTokens,
+		maxVocabTokens: config.DefaultMaxVocabTokens,
+
+		scriptThreshold: config.DefaultScriptThreshold,
+
+		splitHyphens:     config.DefaultSplitHyphens,
+		splitApostrophes: config.DefaultSplitApostrophes,
+
+		seed: config.DefaultSeed,
+
+		readRetries:    config.DefaultReadRetries,
+		readRetryDelay: config.DefaultReadRetryDelay,
+
+		saveFormat:    config.DefaultSaveFormat,
+		savePrecision: config.DefaultSavePrecision,
+
 		solver: config.DefaultSolver,
 		xmax:   config.DefaultXmax,
 		alpha:  config.DefaultAlpha,
+
+		subsampleThreshold: config.DefaultGloveSubsampleThreshold,
+
+		cooccurrenceFormat: config.DefaultCooccurrenceFormat,
+
+		checkpointEvery: config.DefaultCheckpointEvery,
 	}
 }
 
-// NewGloveBuilderFromViper creates *GloveBuilder from viper.
+// NewGloveBuilderFromViper creates *GloveBuilder from the global viper.
 func NewGloveBuilderFromViper() *GloveBuilder {
+	return NewGloveBuilderWithViper(viper.GetViper())
+}
+
+// NewGloveBuilderWithViper creates *GloveBuilder from v, e.g. bound to flags of a command.
+func NewGloveBuilderWithViper(v *viper.Viper) *GloveBuilder {
 	return &GloveBuilder{
-		inputFile: viper.GetString(config.InputFile.String()),
+		inputFile:  v.GetString(config.InputFile.String()),
+		weightMode: WeightScale,
+
+		outputFile:  v.GetString(config.OutputFile.String()),
+		checkOutput: v.GetBool(config.CheckOutput.String()),
+
+		dimension:  v.GetInt(config.Dimension.String()),
+		iteration:  v.GetInt(config.Iteration.String()),
+		minCount:   v.GetInt(config.MinCount.String()),
+		threadSize: v.GetInt(config.ThreadSize.String()),
+		window:     v.GetInt(config.Window.String()),
+		initlr:     v.GetFloat64(config.Initlr.String()),
+		toLower:    v.GetBool(config.ToLower.String()),
+		verbose:    v.GetBool(config.Verbose.String()),
+
+		minCoverage: v.GetFloat64(config.MinCoverage.String()),
+
+		outputFormat: v.GetString(config.OutputFormat.String()),
+		sanitizeUTF8: v.GetString(config.SanitizeUTF8.String()),
+
+		maxTokens:      v.GetInt64(config.MaxTokens.String()),
+		maxVocabTokens: v.GetInt64(config.MaxVocabTokens.String()),
+
+		scripts:         v.GetStringSlice(config.Scripts.String()),
+		scriptThreshold: v.GetFloat64(config.ScriptThreshold.String()),
+
+		splitHyphens:     v.GetBool(config.SplitHyphens.String()),
+		splitApostrophes: v.GetBool(config.SplitApostrophes.String()),
+
+		specialTokens: v.GetStringSlice(config.SpecialTokens.String()),
+
+		seed: v.GetUint64(config.Seed.String()),
+
+		readRetries:    v.GetInt(config.ReadRetries.String()),
+		readRetryDelay: v.GetDuration(config.ReadRetryDelay.String()),
+
+		saveFormat:    v.GetString(config.SaveFormat.String()),
+		savePrecision: v.GetInt(config.SavePrecision.String()),
+
+		solver: v.GetString(config.Solver.String()),
+		xmax:   v.GetInt(config.Xmax.String()),
+		alpha:  v.GetFloat64(config.Alpha.String()),
+
+		subsampleThreshold: v.GetFloat64(config.SubsampleThreshold.String()),
 
-		dimension:  viper.GetInt(config.Dimension.String()),
-		iteration:  viper.GetInt(config.Iteration.String()),
-		minCount:   viper.GetInt(config.MinCount.String()),
-		threadSize: viper.GetInt(config.ThreadSize.String()),
-		window:     viper.GetInt(config.Window.String()),
-		initlr:     viper.GetFloat64(config.Initlr.String()),
-		toLower:    viper.GetBool(config.ToLower.String()),
-		verbose:    viper.GetBool(config.Verbose.String()),
+		cooccurrenceFile:   v.GetString(config.CooccurrenceFile.String()),
+		cooccurrenceFormat: v.GetString(config.CooccurrenceFormat.String()),
+		cooccurrenceVocab:  v.GetString(config.CooccurrenceVocab.String()),
 
-		solver: viper.GetString(config.Solver.String()),
-		xmax:   viper.GetInt(config.Xmax.String()),
-		alpha:  viper.GetFloat64(config.Alpha.String()),
+		checkpointDir:   v.GetString(config.CheckpointDir.String()),
+		checkpointEvery: v.GetInt(config.CheckpointEvery.String()),
+		resumeFrom:      v.GetString(config.ResumeFrom.String()),
 	}
 }
 
@@ -93,6 +240,33 @@ func (gb *GloveBuilder) InputFile(inputFile string) *GloveBuilder {
 	return gb
 }
 
+// InputWeighted sets corpora with their weights, i.e. path -> weight, to read as one corpus instead of inputFile,
+// e.g. a large generic corpus with weight 1 and a small in-domain corpus with weight 5 as if each sentence of it
+// appeared 5 times. How to weight them is set by WeightMode. The corpora are read in order of paths.
+func (gb *GloveBuilder) InputWeighted(inputs map[string]float64) *GloveBuilder {
+	gb.inputWeights = inputs
+	return gb
+}
+
+// WeightMode sets the mode to weight corpora given by InputWeighted. One of: scale|sample
+func (gb *GloveBuilder) WeightMode(mode string) *GloveBuilder {
+	gb.weightMode = mode
+	return gb
+}
+
+// OutputFile sets output file path to save word vectors, whose directory is validated to be writable on Build,
+// so that it fails before training.
+func (gb *GloveBuilder) OutputFile(outputFile string) *GloveBuilder {
+	gb.outputFile = outputFile
+	return gb
+}
+
+// CheckOutput sets whether the directory of OutputFile is validated to be writable on Build.
+func (gb *GloveBuilder) CheckOutput(check bool) *GloveBuilder {
+	gb.checkOutput = check
+	return gb
+}
+
 // Dimension sets dimension of word vector.
 func (gb *GloveBuilder) Dimension(dimension int) *GloveBuilder {
 	gb.dimension = dimension
@@ -111,7 +285,38 @@ func (gb *GloveBuilder) MinCount(minCount int) *GloveBuilder {
 	return gb
 }
 
-// ThreadSize sets number of goroutine.
+// MinCoverage sets fraction of token occurrences for vocabulary to cover, e.g. 0.95, instead of min count.
+// The effective min count is computed from the frequencies of words, which keeps the most frequent words until
+// they cover the fraction of tokens. It overrides MinCount if it is positive.
+func (gb *GloveBuilder) MinCoverage(coverage float64) *GloveBuilder {
+	gb.minCoverage = coverage
+	return gb
+}
+
+// MinCountFunc sets the hook which decides whether the word of freq is kept in vocabulary,
+// e.g. to require a higher count for numeric tokens than words. The words more frequent than min count
+// are kept if it is nil, and it overrides MinCount and MinCoverage otherwise.
+func (gb *GloveBuilder) MinCountFunc(fn func(word string, freq int) bool) *GloveBuilder {
+	gb.minCountFunc = fn
+	return gb
+}
+
+// Seed sets master seed of the run, which the seeds of threads are derived from. It is generated if it is 0,
+// and both are reported by Summary of the model, e.g. to repeat the run under a single thread for debugging.
+func (gb *GloveBuilder) Seed(seed uint64) *GloveBuilder {
+	gb.seed = seed
+	return gb
+}
+
+// WithWordIDs sets fixed ids of words for vocabulary to honor instead of ranking words by frequency,
+// e.g. to line up word vectors with an external embedding layer. The ids must be 0 to len-1 without duplication,
+// and the words must be the ones of corpus, otherwise Build fails.
+func (gb *GloveBuilder) WithWordIDs(ids map[string]int) *GloveBuilder {
+	gb.wordIDs = ids
+	return gb
+}
+
+// ThreadSize sets number of goroutine. If it is 0, the number of CPUs is used.
 func (gb *GloveBuilder) ThreadSize(threadSize int) *GloveBuilder {
 	gb.threadSize = threadSize
 	return gb
@@ -141,6 +346,115 @@ func (gb *GloveBuilder) Verbose() *GloveBuilder {
 	return gb
 }
 
+// SanitizeUTF8 sets how to treat invalid UTF-8 sequences in corpus. One of: none|replace|skip
+// They are replaced with U+FFFD by replace, or removed by skip.
+func (gb *GloveBuilder) SanitizeUTF8(sanitize string) *GloveBuilder {
+	gb.sanitizeUTF8 = sanitize
+	return gb
+}
+
+// MaxTokens sets limit of tokens to count co-occurrences on, e.g. for smoke tests.
+// Vocabulary is still built from the full corpus unless MaxVocabTokens is set.
+func (gb *GloveBuilder) MaxTokens(n int64) *GloveBuilder {
+	gb.maxTokens = n
+	return gb
+}
+
+// MaxVocabTokens sets limit of tokens to read from corpus to build vocabulary.
+// Since training runs on the read tokens, it also caps training.
+func (gb *GloveBuilder) MaxVocabTokens(n int64) *GloveBuilder {
+	gb.maxVocabTokens = n
+	return gb
+}
+
+// ScriptFilter adds scripts to keep tokens predominantly in them before counting, e.g. latin, cyrillic, han.
+// The names are of unicode.Scripts and case-insensitive.
+func (gb *GloveBuilder) ScriptFilter(scripts ...string) *GloveBuilder {
+	gb.scripts = append(gb.scripts, scripts...)
+	return gb
+}
+
+// ScriptThreshold sets fraction of runes in the scripts of ScriptFilter for mixed-script tokens to keep.
+func (gb *GloveBuilder) ScriptThreshold(threshold float64) *GloveBuilder {
+	gb.scriptThreshold = threshold
+	return gb
+}
+
+// SplitHyphens sets whether to split tokens on hyphens, e.g. "state-of-the-art" into "state", "of", "the" and "art".
+func (gb *GloveBuilder) SplitHyphens(split bool) *GloveBuilder {
+	gb.splitHyphens = split
+	return gb
+}
+
+// SplitApostrophes sets whether to split tokens on apostrophes, e.g. "don't" into "don" and "t".
+func (gb *GloveBuilder) SplitApostrophes(split bool) *GloveBuilder {
+	gb.splitApostrophes = split
+	return gb
+}
+
+// SpecialTokens sets special tokens, e.g. <pad>, <bos>, <eos>, which are always in vocabulary with the reserved ids
+// 0 to len-1 in order regardless of frequency, e.g. for downstream models relying on fixed ids of them.
+// The tokens not in corpus are counted once, and they are not available with WithWordIDs.
+func (gb *GloveBuilder) SpecialTokens(tokens []string) *GloveBuilder {
+	gb.specialTokens = tokens
+	return gb
+}
+
+// ReadRetry sets times to retry reading corpus on transient errors, and the delay before the first retry,
+// which doubles on each consecutive retry. The error is returned after all retries fail.
+func (gb *GloveBuilder) ReadRetry(retries int, delay time.Duration) *GloveBuilder {
+	gb.readRetries = retries
+	gb.readRetryDelay = delay
+	return gb
+}
+
+// SaveFormat sets style to format values of word vectors in text format. One of: fixed|scientific|shortest
+func (gb *GloveBuilder) SaveFormat(style string) *GloveBuilder {
+	gb.saveFormat = style
+	return gb
+}
+
+// SavePrecision sets digits to format values of word vectors in text format, e.g. 4 for %.4f with fixed style.
+// It is the number of significant digits for shortest style, and -1 means the minimal digits to represent them exactly.
+func (gb *GloveBuilder) SavePrecision(digits int) *GloveBuilder {
+	gb.savePrecision = digits
+	return gb
+}
+
+// TrackWords sets words to append their vectors into JSONL file on path after each iteration.
+// The words not in vocabulary are skipped, and printed with verbose.
+func (gb *GloveBuilder) TrackWords(words []string, path string) *GloveBuilder {
+	gb.trackWords = words
+	gb.trackPath = path
+	return gb
+}
+
+// OnProgress sets fn to be called with progress of training, which is common to word2vec and GloVe.
+func (gb *GloveBuilder) OnProgress(fn model.ProgressFunc) *GloveBuilder {
+	gb.onProgress = fn
+	return gb
+}
+
+// BeforeIteration registers fn to be called before each iteration of training with the iteration from 1,
+// e.g. to change hyperparameters between passes.
+func (gb *GloveBuilder) BeforeIteration(fn func(iteration int)) *GloveBuilder {
+	gb.hooks.BeforeIteration(fn)
+	return gb
+}
+
+// AfterIteration registers fn to be called after each iteration of training with the iteration and the model,
+// e.g. to evaluate custom metrics.
+func (gb *GloveBuilder) AfterIteration(fn func(iteration int, m model.Model)) *GloveBuilder {
+	gb.hooks.AfterIteration(fn)
+	return gb
+}
+
+// OutputFormat sets format to save word vectors. One of: text|binary|json|npy|fasttext-vec
+func (gb *GloveBuilder) OutputFormat(format string) *GloveBuilder {
+	gb.outputFormat = format
+	return gb
+}
+
 // Solver sets solver.
 func (gb *GloveBuilder) Solver(solver string) *GloveBuilder {
 	gb.solver = solver
@@ -159,19 +473,111 @@ func (gb *GloveBuilder) Alpha(alpha float64) *GloveBuilder {
 	return gb
 }
 
-// Build creates model.Model interface.
-func (gb *GloveBuilder) Build() (model.Model, error) {
-	if !validate.FileExists(gb.inputFile) {
-		return nil, errors.Errorf("Not such a file %s", gb.inputFile)
+// SubSampleThreshold sets threshold for subsampling frequent words on counting co-occurrences.
+// It is disabled by default, and the pairs dominated by stopwords decrease with it, e.g. 1.0e-3
+func (gb *GloveBuilder) SubSampleThreshold(threshold float64) *GloveBuilder {
+	gb.subsampleThreshold = threshold
+	return gb
+}
+
+// CooccurrenceFile sets co-occurrence file to train on instead of counting on corpus, e.g. cooccur.bin of
+// Stanford GloVe, and its format. One of: stanford|text
+// The word ids of stanford format refer to the vocabulary file set by CooccurrenceVocab.
+func (gb *GloveBuilder) CooccurrenceFile(path, format string) *GloveBuilder {
+	gb.cooccurrenceFile = path
+	gb.cooccurrenceFormat = format
+	return gb
+}
+
+// CooccurrenceVocab sets vocabulary file of the co-occurrence file, whose lines are "word frequency".
+func (gb *GloveBuilder) CooccurrenceVocab(path string) *GloveBuilder {
+	gb.cooccurrenceVocab = path
+	return gb
+}
+
+// CheckpointDir sets directory to save checkpoint of training into, which is disabled by default.
+// The checkpoint holds vectors, biases and the state of the solver after the iteration, and refers to
+// co-occurrences by the checksum of the co-occurrence file, or of the input file if it is not set.
+func (gb *GloveBuilder) CheckpointDir(dir string) *GloveBuilder {
+	gb.checkpointDir = dir
+	return gb
+}
+
+// CheckpointEvery sets interval of iterations to save checkpoint.
+func (gb *GloveBuilder) CheckpointEvery(every int) *GloveBuilder {
+	gb.checkpointEvery = every
+	return gb
+}
+
+// ResumeFrom sets checkpoint file to resume training from, which fails on Build if co-occurrences have changed.
+func (gb *GloveBuilder) ResumeFrom(path string) *GloveBuilder {
+	gb.resumeFrom = path
+	return gb
+}
+
+func (gb *GloveBuilder) config() *model.Config {
+	cnf := model.NewConfig(gb.dimension, gb.iteration, gb.minCount, gb.threadSize, gb.window,
+		gb.initlr, gb.toLower, gb.verbose)
+	cnf.OutputFormat = gb.outputFormat
+	cnf.SanitizeUTF8 = gb.sanitizeUTF8
+	cnf.MaxTokens = gb.maxTokens
+	cnf.MaxVocabTokens = gb.maxVocabTokens
+	cnf.Scripts = gb.scripts
+	cnf.ScriptThreshold = gb.scriptThreshold
+	cnf.ReadRetries = gb.readRetries
+	cnf.ReadRetryDelay = gb.readRetryDelay
+	cnf.SaveFormat = gb.saveFormat
+	cnf.SavePrecision = gb.savePrecision
+	cnf.MinCoverage = gb.minCoverage
+	cnf.MinCountFunc = gb.minCountFunc
+	cnf.FixedWordIDs = gb.wordIDs
+	cnf.Seed = gb.seed
+	cnf.SplitHyphens = gb.splitHyphens
+	cnf.SplitApostrophes = gb.splitApostrophes
+	cnf.SpecialTokens = gb.specialTokens
+	if gb.inputWeights != nil && gb.weightMode == WeightScale {
+		cnf.SentenceWeights, cnf.WeightedCounts = true, true
 	}
+	return cnf
+}
 
-	input, err := os.Open(gb.inputFile)
+// BuildCorpus parses corpus and counts co-occurrences in the same way as Build, whose subsampling is reproduced
+// by the same seed.
+func (gb *GloveBuilder) BuildCorpus() (*corpus.GloveCorpus, error) {
+	input, err := openInput(gb.inputFile, gb.inputWeights, gb.weightMode, gb.seed, gb.verbose)
 	if err != nil {
 		return nil, err
 	}
+	defer input.Close()
 
-	cnf := model.NewConfig(gb.dimension, gb.iteration, gb.minCount, gb.threadSize, gb.window,
-		gb.initlr, gb.toLower, gb.verbose)
+	cnf := gb.config()
+	seed := cnf.Seed
+	if seed == 0 {
+		seed = model.NewSeed()
+	}
+	return corpus.NewGloveCorpus(input, cnf.ParseConfig(), cnf.MinCount, cnf.Window, cnf.MaxTokens,
+		gb.subsampleThreshold, model.NewRandom(model.DeriveSeed(seed, glove.SubsampleSeedKey)))
+}
+
+// Build creates model.Model interface.
+func (gb *GloveBuilder) Build() (model.Model, error) {
+	if err := validateOutput(gb.outputFile, gb.checkOutput); err != nil {
+		return nil, err
+	}
+	if err := validateDimension(gb.dimension); err != nil {
+		return nil, err
+	}
+	cnf := gb.config()
+	if err := vectorio.ValidateFormat(gb.outputFormat); err != nil {
+		return nil, err
+	}
+	if err := cnf.FloatFormat().Validate(); err != nil {
+		return nil, err
+	}
+
+	if gb.inputWeights != nil && (gb.checkpointDir != "" || gb.resumeFrom != "") {
+		return nil, errors.New("Checkpoint is not available with weighted inputs, which have no single source")
+	}
 
 	var solver glove.Solver
 	switch gb.solver {
@@ -183,5 +589,79 @@ func (gb *GloveBuilder) Build() (model.Model, error) {
 		return nil, errors.Errorf("Invalid solver: %s not in sgd|adagrad", gb.solver)
 	}
 
-	return glove.NewGlove(input, cnf, solver, gb.xmax, gb.alpha)
+	gl, err := gb.build(cnf, solver)
+	if err != nil {
+		return nil, err
+	}
+	if gb.onProgress != nil {
+		gl.OnProgress(gb.onProgress)
+	}
+	gl.Hooks = gb.hooks
+	if gb.trackPath != "" {
+		missing, err := gl.TrackWords(gb.trackWords, gb.trackPath)
+		if err != nil {
+			return nil, err
+		}
+		warnUntracked(missing, gb.verbose)
+	}
+	source := gb.cooccurrenceFile
+	if source == "" {
+		source = gb.inputFile
+	}
+	if gb.checkpointDir != "" {
+		if err := gl.Checkpoint(gb.checkpointDir, gb.checkpointEvery, source); err != nil {
+			return nil, err
+		}
+	}
+	if gb.resumeFrom != "" {
+		if err := gl.Resume(gb.resumeFrom, source); err != nil {
+			return nil, err
+		}
+	}
+	return gl, nil
+}
+
+func (gb *GloveBuilder) build(cnf *model.Config, solver glove.Solver) (*glove.Glove, error) {
+	if gb.cooccurrenceFile == "" {
+		input, err := openInput(gb.inputFile, gb.inputWeights, gb.weightMode, gb.seed, gb.verbose)
+		if err != nil {
+			return nil, err
+		}
+		return glove.NewGlove(input, cnf, solver, gb.xmax, gb.alpha, gb.subsampleThreshold)
+	}
+
+	if gb.inputWeights != nil {
+		return nil, errors.New("Weighted inputs are not available with co-occurrence file")
+	}
+
+	if gb.wordIDs != nil {
+		return nil, errors.New("Word ids are defined by vocabulary file for co-occurrence file, not by WithWordIDs")
+	}
+	if gb.cooccurrenceVocab == "" {
+		return nil, errors.New("Vocabulary file is required for co-occurrence file")
+	}
+	cooccurrence, err := os.Open(gb.cooccurrenceFile)
+	if err != nil {
+		return nil, err
+	}
+	defer cooccurrence.Close()
+	vocab, err := os.Open(gb.cooccurrenceVocab)
+	if err != nil {
+		return nil, err
+	}
+	defer vocab.Close()
+	return glove.NewGloveFromCooccurrence(vocab, cooccurrence, gb.cooccurrenceFormat, cnf,
+		solver, gb.xmax, gb.alpha)
+}
+
+// BuildAndTrain creates model.Model interface and trains it on corpus.
+func (gb *GloveBuilder) BuildAndTrain() (model.Model, error) {
+	mod, err := gb.Build()
+	if err != nil {
+		return nil, err
+	}
+	if err := mod.Train(); err != nil {
+		return nil, errors.Wrap(err, "Unable to train GloVe")
+	}
+	return mod, nil
 }
@@ -15,7 +15,14 @@
 package builder
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/ynqa/wego/model"
 )
 
 func TestGloveInputFile(t *testing.T) {
@@ -62,6 +69,17 @@ func TestGloveMinCount(t *testing.T) {
 	}
 }
 
+func TestGloveMinCoverage(t *testing.T) {
+	b := &GloveBuilder{}
+
+	expectedMinCoverage := 0.95
+	b.MinCoverage(expectedMinCoverage)
+
+	if b.minCoverage != expectedMinCoverage {
+		t.Errorf("Expected builder.minCoverage=%v: %v", expectedMinCoverage, b.minCoverage)
+	}
+}
+
 func TestGloveThreadSize(t *testing.T) {
 	b := &GloveBuilder{}
 
@@ -115,6 +133,109 @@ func TestGloveVerbose(t *testing.T) {
 	}
 }
 
+func TestGloveSanitizeUTF8(t *testing.T) {
+	b := &GloveBuilder{}
+
+	expectedSanitizeUTF8 := "skip"
+	b.SanitizeUTF8(expectedSanitizeUTF8)
+
+	if b.sanitizeUTF8 != expectedSanitizeUTF8 {
+		t.Errorf("Expected builder.sanitizeUTF8=%v: %v", expectedSanitizeUTF8, b.sanitizeUTF8)
+	}
+}
+
+func TestGloveMaxTokens(t *testing.T) {
+	b := &GloveBuilder{}
+
+	var expectedMaxTokens int64 = 1000000
+	b.MaxTokens(expectedMaxTokens).MaxVocabTokens(expectedMaxTokens)
+
+	if b.maxTokens != expectedMaxTokens || b.maxVocabTokens != expectedMaxTokens {
+		t.Errorf("Expected builder.maxTokens=builder.maxVocabTokens=%v: %v, %v",
+			expectedMaxTokens, b.maxTokens, b.maxVocabTokens)
+	}
+}
+
+func TestGloveScriptFilter(t *testing.T) {
+	b := &GloveBuilder{}
+
+	b.ScriptFilter("latin").ScriptFilter("cyrillic", "han").ScriptThreshold(0.8)
+
+	if !reflect.DeepEqual(b.scripts, []string{"latin", "cyrillic", "han"}) {
+		t.Errorf("Expected builder.scripts=[latin cyrillic han]: %v", b.scripts)
+	}
+	if b.scriptThreshold != 0.8 {
+		t.Errorf("Expected builder.scriptThreshold=0.8: %v", b.scriptThreshold)
+	}
+}
+
+func TestGloveSplit(t *testing.T) {
+	b := &GloveBuilder{}
+
+	b.SplitHyphens(true).SplitApostrophes(true)
+
+	if !b.splitHyphens || !b.splitApostrophes {
+		t.Errorf("Expected builder.splitHyphens=true and builder.splitApostrophes=true: %v, %v",
+			b.splitHyphens, b.splitApostrophes)
+	}
+}
+
+func TestGloveReadRetry(t *testing.T) {
+	b := &GloveBuilder{}
+
+	expectedRetries := 3
+	expectedDelay := 10 * time.Millisecond
+	b.ReadRetry(expectedRetries, expectedDelay)
+
+	if b.readRetries != expectedRetries || b.readRetryDelay != expectedDelay {
+		t.Errorf("Expected builder.readRetries=%v, builder.readRetryDelay=%v: %v, %v",
+			expectedRetries, expectedDelay, b.readRetries, b.readRetryDelay)
+	}
+}
+
+func TestGloveSaveFormat(t *testing.T) {
+	b := &GloveBuilder{}
+
+	b.SaveFormat("scientific").SavePrecision(4)
+
+	if b.saveFormat != "scientific" || b.savePrecision != 4 {
+		t.Errorf("Expected builder.saveFormat=scientific, builder.savePrecision=4: %v, %v", b.saveFormat, b.savePrecision)
+	}
+}
+
+func TestGloveInvalidSaveFormatBuild(t *testing.T) {
+	inputFile := writeTestCorpus(t)
+	defer os.Remove(inputFile)
+
+	b := NewGloveBuilder()
+	b.InputFile(inputFile).SaveFormat("fake")
+
+	if _, err := b.Build(); err == nil {
+		t.Error("Expected to fail building with invalid save format except for fixed|scientific|shortest")
+	}
+}
+
+func TestGloveTrackWords(t *testing.T) {
+	b := &GloveBuilder{}
+
+	b.TrackWords([]string{"a", "b"}, "track.jsonl")
+
+	if len(b.trackWords) != 2 || b.trackPath != "track.jsonl" {
+		t.Errorf("Expected builder.trackWords=[a b], trackPath=track.jsonl: %v, %v", b.trackWords, b.trackPath)
+	}
+}
+
+func TestGloveOutputFormat(t *testing.T) {
+	b := &GloveBuilder{}
+
+	expectedOutputFormat := "npy"
+	b.OutputFormat(expectedOutputFormat)
+
+	if b.outputFormat != expectedOutputFormat {
+		t.Errorf("Expected builder.outputFormat=%v: %v", expectedOutputFormat, b.outputFormat)
+	}
+}
+
 func TestGloveSolver(t *testing.T) {
 	b := &GloveBuilder{}
 
@@ -126,6 +247,17 @@ func TestGloveSolver(t *testing.T) {
 	}
 }
 
+func TestGloveCheckpoint(t *testing.T) {
+	b := &GloveBuilder{}
+
+	b.CheckpointDir("ckpt").CheckpointEvery(5).ResumeFrom("ckpt/glove.checkpoint")
+
+	if b.checkpointDir != "ckpt" || b.checkpointEvery != 5 || b.resumeFrom != "ckpt/glove.checkpoint" {
+		t.Errorf("Expected builder.checkpointDir=ckpt, checkpointEvery=5, resumeFrom=ckpt/glove.checkpoint: "+
+			"%v, %v, %v", b.checkpointDir, b.checkpointEvery, b.resumeFrom)
+	}
+}
+
 func TestGloveXmax(t *testing.T) {
 	b := &GloveBuilder{}
 
@@ -148,6 +280,81 @@ func TestGloveAlpha(t *testing.T) {
 	}
 }
 
+func TestGloveSubSampleThreshold(t *testing.T) {
+	b := &GloveBuilder{}
+
+	expectedThreshold := 1.0e-3
+	b.SubSampleThreshold(expectedThreshold)
+
+	if b.subsampleThreshold != expectedThreshold {
+		t.Errorf("Expected builder.subsampleThreshold=%v: %v", expectedThreshold, b.subsampleThreshold)
+	}
+}
+
+func TestGloveCooccurrenceFile(t *testing.T) {
+	b := &GloveBuilder{}
+
+	b.CooccurrenceFile("cooccur.bin", "stanford").CooccurrenceVocab("vocab.txt")
+
+	if b.cooccurrenceFile != "cooccur.bin" || b.cooccurrenceFormat != "stanford" || b.cooccurrenceVocab != "vocab.txt" {
+		t.Errorf("Expected builder.cooccurrenceFile=cooccur.bin, cooccurrenceFormat=stanford, cooccurrenceVocab=vocab.txt: "+
+			"%v, %v, %v", b.cooccurrenceFile, b.cooccurrenceFormat, b.cooccurrenceVocab)
+	}
+}
+
+func TestGloveBuildFromCooccurrence(t *testing.T) {
+	inputFile := writeTestCorpus(t)
+	defer os.Remove(inputFile)
+
+	b := NewGloveBuilder()
+	b.InputFile(inputFile).
+		Dimension(5).
+		Iteration(1).
+		MinCount(0).
+		ThreadSize(1)
+	cps, err := b.BuildCorpus()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cooccurrence, err := ioutil.TempFile("", "cooccur")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(cooccurrence.Name())
+	vocab, err := ioutil.TempFile("", "vocab")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(vocab.Name())
+	if err := cps.WriteCooccurrence(cooccurrence, "stanford"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cps.WriteVocab(vocab); err != nil {
+		t.Fatal(err)
+	}
+	cooccurrence.Close()
+	vocab.Close()
+
+	b.InputFile("").CooccurrenceFile(cooccurrence.Name(), "stanford")
+	if _, err := b.Build(); err == nil {
+		t.Error("Expected to fail building without vocabulary file for co-occurrence file")
+	}
+	b.CooccurrenceVocab(vocab.Name())
+	if _, err := b.BuildAndTrain(); err != nil {
+		t.Errorf("Expected to build and train GloVe from co-occurrence file: %v", err)
+	}
+}
+
+func TestGloveUnwritableOutputBuild(t *testing.T) {
+	b := NewGloveBuilder()
+	b.OutputFile(filepath.Join(os.TempDir(), "fake_dir", "vectors.txt"))
+
+	if _, err := b.Build(); err == nil {
+		t.Error("Expected to fail building with non-existent directory of output file")
+	}
+}
+
 func TestGloveInvalidSolverBuild(t *testing.T) {
 	b := &GloveBuilder{}
 
@@ -157,3 +364,33 @@ func TestGloveInvalidSolverBuild(t *testing.T) {
 		t.Errorf("Expected to fail building with invalid solver except for sgd|adagrad: %v", b.solver)
 	}
 }
+
+func TestGloveBuildAndTrain(t *testing.T) {
+	inputFile := writeTestCorpus(t)
+	defer os.Remove(inputFile)
+
+	b := NewGloveBuilder()
+	b.InputFile(inputFile).
+		Dimension(5).
+		Iteration(1).
+		MinCount(0).
+		ThreadSize(1)
+
+	mod, err := b.BuildAndTrain()
+	if err != nil {
+		t.Fatalf("Expected to build and train GloVe: %v", err)
+	}
+	if mod == nil {
+		t.Error("Expected BuildAndTrain returns trained model")
+	}
+}
+
+func TestGloveOnProgress(t *testing.T) {
+	b := &GloveBuilder{}
+
+	b.OnProgress(func(model.Progress) {})
+
+	if b.onProgress == nil {
+		t.Error("Expected builder.onProgress to be set")
+	}
+}
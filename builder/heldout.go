@@ -0,0 +1,84 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/model"
+)
+
+// heldoutSeedKey derives the seed to split held-out sentences from the master seed,
+// apart from the one to sample weighted inputs.
+const heldoutSeedKey = 1
+
+// heldoutReader reads corpus r except a random fraction of its sentences, i.e. lines, which are held out
+// to evaluate the model trained on the rest.
+type heldoutReader struct {
+	src      io.ReadCloser
+	r        *bufio.Reader
+	fraction float64
+	rnd      *model.Random
+
+	buf     bytes.Buffer
+	heldout bytes.Buffer
+}
+
+func newHeldoutReader(r io.ReadCloser, fraction float64, seed uint64) *heldoutReader {
+	return &heldoutReader{
+		src:      r,
+		r:        bufio.NewReader(r),
+		fraction: fraction,
+		rnd:      model.NewRandom(model.DeriveSeed(seed, heldoutSeedKey)),
+	}
+}
+
+func (h *heldoutReader) Read(p []byte) (int, error) {
+	for h.buf.Len() == 0 {
+		line, err := h.r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		if strings.TrimSpace(line) != "" && h.rnd.Float64() < h.fraction {
+			h.heldout.WriteString(line)
+		} else {
+			h.buf.WriteString(line)
+		}
+		if err == io.EOF {
+			if h.buf.Len() == 0 {
+				return 0, io.EOF
+			}
+			break
+		}
+	}
+	return h.buf.Read(p)
+}
+
+func (h *heldoutReader) Close() error {
+	return h.src.Close()
+}
+
+// validateHeldout validates the fraction of sentences held out from corpus.
+func validateHeldout(fraction float64) error {
+	if fraction < 0 || fraction >= 1 {
+		return errors.Errorf("Invalid heldoutFraction: %v must be in [0, 1)", fraction)
+	}
+	return nil
+}
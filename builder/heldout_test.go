@@ -0,0 +1,172 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ynqa/wego/model"
+	"github.com/ynqa/wego/model/word2vec"
+)
+
+func heldoutCorpus() string {
+	var lines []string
+	for i := 0; i < 100; i++ {
+		lines = append(lines, fmt.Sprintf("common w%d", i))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestHeldoutReader(t *testing.T) {
+	text := heldoutCorpus()
+	split := func(seed uint64) (string, string) {
+		r := newHeldoutReader(ioutil.NopCloser(strings.NewReader(text)), 0.3, seed)
+		train, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(train), r.heldout.String()
+	}
+
+	train, heldout := split(1)
+	trainLines, heldoutLines := strings.Fields(train), strings.Fields(heldout)
+	if n := len(heldoutLines) / 2; n < 10 || n > 50 {
+		t.Errorf("Expected about 30 of 100 sentences to be held out: %d", n)
+	}
+	if len(trainLines)+len(heldoutLines) != 200 {
+		t.Errorf("Expected every sentence to be either trained on or held out: %d + %d tokens",
+			len(trainLines), len(heldoutLines))
+	}
+	if again, _ := split(1); again != train {
+		t.Error("Expected the same sentences to be held out with the same seed")
+	}
+	if other, _ := split(2); other == train {
+		t.Error("Expected other sentences to be held out with another seed")
+	}
+
+	for _, fraction := range []float64{-0.1, 1} {
+		if err := validateHeldout(fraction); err == nil {
+			t.Errorf("Expected to fail validating heldoutFraction=%v", fraction)
+		}
+	}
+}
+
+func TestWord2vecHeldoutFraction(t *testing.T) {
+	dir, paths := writeCorpora(t, map[string]string{"corpus": heldoutCorpus()})
+	defer os.RemoveAll(dir)
+
+	var perplexities []float64
+	b := NewWord2vecBuilder()
+	b.InputFile(paths["corpus"]).Dimension(5).MinCount(0).ThreadSize(1).Iteration(2).Seed(1).
+		HeldoutFraction(0.3).OnProgress(func(p model.Progress) {
+		if p.Has(model.ProgressPerplexity) {
+			perplexities = append(perplexities, p.Perplexity)
+		}
+	})
+	mod, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w2v := mod.(*word2vec.Word2vec)
+
+	f, err := os.Open(paths["corpus"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newHeldoutReader(f, 0.3, 1)
+	train, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	for _, line := range strings.Split(r.heldout.String(), "\n") {
+		if word := strings.TrimPrefix(line, "common "); word != "" {
+			if _, ok := w2v.Lookup(word); ok {
+				t.Errorf("Expected held-out word %s to be excluded from vocabulary", word)
+			}
+		}
+	}
+	sentences := strings.Count(string(train), "common")
+	if freq := w2v.WordFreq("common"); freq != sentences {
+		t.Errorf("Expected common to be counted only in %d sentences trained on: %d", sentences, freq)
+	}
+
+	if err := w2v.Train(); err != nil {
+		t.Fatal(err)
+	}
+	if len(perplexities) != 2 || perplexities[0] < 1 {
+		t.Errorf("Expected held-out perplexity to be reported after each of 2 iterations: %v", perplexities)
+	}
+
+	if _, err := b.Clone().HeldoutFraction(1).Build(); err == nil {
+		t.Error("Expected to fail building with heldoutFraction=1")
+	}
+	if _, err := b.Clone().InputFormat("pairs").Optimizer("ns").Build(); err == nil {
+		t.Error("Expected to fail building pairs input format with heldoutFraction")
+	}
+	if _, err := b.Clone().InputWeighted(map[string]float64{paths["corpus"]: 2}).WeightMode(WeightSample).
+		Build(); err == nil {
+		t.Error("Expected to fail building weighted inputs in sample mode with heldoutFraction")
+	}
+	if _, err := b.Clone().InputWeighted(map[string]float64{paths["corpus"]: 2}).Build(); err != nil {
+		t.Errorf("Expected to build weighted inputs in scale mode with heldoutFraction: %v", err)
+	}
+}
+
+func TestWord2vecHeldoutSeed(t *testing.T) {
+	dir, paths := writeCorpora(t, map[string]string{"corpus": heldoutCorpus()})
+	defer os.RemoveAll(dir)
+
+	// the master seed is generated, and the held-out sentences are split by the one in the summary.
+	mod, err := NewWord2vecBuilder().InputFile(paths["corpus"]).Dimension(5).MinCount(0).ThreadSize(1).
+		HeldoutFraction(0.3).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w2v := mod.(*word2vec.Word2vec)
+	seed := w2v.Summary().Seed
+	if seed == 0 {
+		t.Fatal("Expected the master seed to be generated")
+	}
+
+	f, err := os.Open(paths["corpus"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newHeldoutReader(f, 0.3, seed)
+	train, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Close()
+	for _, line := range strings.Split(string(train), "\n") {
+		if word := strings.TrimPrefix(line, "common "); word != "" {
+			if _, ok := w2v.Lookup(word); !ok {
+				t.Errorf("Expected %s to be trained on by the master seed %d", word, seed)
+			}
+		}
+	}
+	for _, line := range strings.Split(r.heldout.String(), "\n") {
+		if word := strings.TrimPrefix(line, "common "); word != "" {
+			if _, ok := w2v.Lookup(word); ok {
+				t.Errorf("Expected %s to be held out by the master seed %d", word, seed)
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/validate"
+)
+
+// validateOutput validates the directory of outputFile is writable, unless outputFile is empty or not check.
+func validateOutput(outputFile string, check bool) error {
+	if outputFile == "" || !check {
+		return nil
+	}
+	return validate.DirWritable(outputFile)
+}
+
+// validateDimension validates dimension of word vector is positive. Small ones, even 1, are valid, e.g. to plot.
+func validateDimension(dimension int) error {
+	if dimension <= 0 {
+		return errors.Errorf("Invalid dimension: %d must be positive", dimension)
+	}
+	return nil
+}
+
+// warnUntracked prints the words not in vocabulary given to TrackWords with verbose.
+func warnUntracked(missing []string, verbose bool) {
+	if verbose && len(missing) > 0 {
+		fmt.Printf("Warning: not in vocabulary and not tracked: %s\n", strings.Join(missing, ", "))
+	}
+}
+
+// warnUnmasked prints the words not in vocabulary given to Mask with verbose.
+func warnUnmasked(missing []string, verbose bool) {
+	if verbose && len(missing) > 0 {
+		fmt.Printf("Warning: not in vocabulary and not masked: %s\n", strings.Join(missing, ", "))
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/model"
+	"github.com/ynqa/wego/validate"
+)
+
+// The modes to weight corpora given by InputWeighted of the builders.
+const (
+	// WeightScale gives each sentence the weight of its corpus, which scales learning rate for word2vec, and
+	// co-occurrences for GloVe. Words are counted by the weights for vocabulary.
+	WeightScale = "scale"
+	// WeightSample repeats each sentence as many times as the weight of its corpus, where the fractional part is
+	// the probability of one more time.
+	WeightSample = "sample"
+)
+
+// validateWeighted validates the corpora and their weights, and the mode to weight them.
+func validateWeighted(inputs map[string]float64, mode string) error {
+	switch mode {
+	case WeightScale, WeightSample:
+	default:
+		return errors.Errorf("Invalid weightMode: %s not in %s|%s", mode, WeightScale, WeightSample)
+	}
+	if len(inputs) == 0 {
+		return errors.New("No corpus for weighted inputs")
+	}
+	for path, weight := range inputs {
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return errors.Errorf("Invalid weight of %s: %v must be a non-negative number", path, weight)
+		}
+		if !validate.FileExists(path) {
+			return errors.Errorf("Not such a file %s", path)
+		}
+	}
+	return nil
+}
+
+// weightedReader reads the corpora in order of paths as one corpus whose sentences are weighted by mode.
+type weightedReader struct {
+	paths   []string
+	weights []float64
+	mode    string
+	rnd     *model.Random
+	verbose bool
+
+	next   int
+	file   *os.File
+	r      *bufio.Reader
+	buf    bytes.Buffer
+	first  bool
+	tokens int
+	// tokens counted as if the weighted sentences appeared on their own.
+	effective float64
+}
+
+func newWeightedReader(inputs map[string]float64, mode string, seed uint64, verbose bool) *weightedReader {
+	paths := make([]string, 0, len(inputs))
+	for path := range inputs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	weights := make([]float64, len(paths))
+	for i, path := range paths {
+		weights[i] = inputs[path]
+	}
+	return &weightedReader{
+		paths:   paths,
+		weights: weights,
+		mode:    mode,
+		rnd:     model.NewRandom(model.DeriveSeed(seed)),
+		verbose: verbose,
+	}
+}
+
+func (w *weightedReader) Read(p []byte) (int, error) {
+	for w.buf.Len() == 0 {
+		if err := w.readLine(); err != nil {
+			return 0, err
+		}
+	}
+	return w.buf.Read(p)
+}
+
+// readLine writes the next line of the current corpus into buf as weighted sentences,
+// moving on to the next corpus at the end of it.
+func (w *weightedReader) readLine() error {
+	if w.file == nil {
+		if w.next >= len(w.paths) {
+			return io.EOF
+		}
+		file, err := os.Open(w.paths[w.next])
+		if err != nil {
+			return err
+		}
+		w.file, w.r, w.first = file, bufio.NewReader(file), true
+		w.tokens, w.effective = 0, 0
+	}
+
+	line, err := w.r.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return errors.Wrapf(err, "Unable to read %s", w.paths[w.next])
+	}
+	if w.first {
+		line, w.first = strings.TrimPrefix(line, "\uFEFF"), false
+	}
+	w.write(strings.TrimRight(line, "\r\n"), w.weights[w.next])
+	if err == io.EOF {
+		if w.verbose {
+			fmt.Printf("%s: %d tokens, weight %v, %v effective tokens\n",
+				w.paths[w.next], w.tokens, w.weights[w.next], w.effective)
+		}
+		w.next++
+		file := w.file
+		w.file, w.r = nil, nil
+		return file.Close()
+	}
+	return nil
+}
+
+func (w *weightedReader) write(line string, weight float64) {
+	tokens := len(strings.Fields(line))
+	if tokens == 0 {
+		return
+	}
+	w.tokens += tokens
+	switch w.mode {
+	case WeightScale:
+		w.buf.WriteString(strconv.FormatFloat(weight, 'g', -1, 64))
+		w.buf.WriteString(" ")
+		w.buf.WriteString(line)
+		w.buf.WriteString("\n")
+		w.effective += weight * float64(tokens)
+	case WeightSample:
+		times := int(weight)
+		if w.rnd.Float64() < weight-float64(times) {
+			times++
+		}
+		for i := 0; i < times; i++ {
+			w.buf.WriteString(line)
+			w.buf.WriteString("\n")
+		}
+		w.effective += float64(times * tokens)
+	}
+}
+
+func (w *weightedReader) Close() error {
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Close()
+}
+
+// openInput opens inputFile, or the corpora of inputs as one corpus weighted by mode if inputs is set.
+func openInput(inputFile string, inputs map[string]float64, mode string, seed uint64,
+	verbose bool) (io.ReadCloser, error) {
+	if inputs == nil {
+		if !validate.FileExists(inputFile) {
+			return nil, errors.Errorf("Not such a file %s", inputFile)
+		}
+		return os.Open(inputFile)
+	}
+	if err := validateWeighted(inputs, mode); err != nil {
+		return nil, err
+	}
+	return newWeightedReader(inputs, mode, seed, verbose), nil
+}
@@ -0,0 +1,137 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ynqa/wego/model/glove"
+	"github.com/ynqa/wego/model/word2vec"
+)
+
+func writeCorpora(t *testing.T, corpora map[string]string) (string, map[string]string) {
+	dir, err := ioutil.TempDir("", "weighted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := make(map[string]string, len(corpora))
+	for name, text := range corpora {
+		paths[name] = filepath.Join(dir, name)
+		if err := ioutil.WriteFile(paths[name], []byte(text), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir, paths
+}
+
+func TestWeightedReader(t *testing.T) {
+	dir, paths := writeCorpora(t, map[string]string{
+		"generic": "a b\n\nc\n",
+		"domain":  "\uFEFFx y\r\nz",
+	})
+	defer os.RemoveAll(dir)
+	inputs := map[string]float64{paths["generic"]: 1, paths["domain"]: 2}
+
+	testCases := []struct {
+		mode     string
+		expected string
+	}{
+		{WeightScale, "2 x y\n2 z\n1 a b\n1 c\n"},
+		{WeightSample, "x y\nx y\nz\nz\na b\nc\n"},
+	}
+	for _, testCase := range testCases {
+		r := newWeightedReader(inputs, testCase.mode, 1, false)
+		actual, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Close()
+		if string(actual) != testCase.expected {
+			t.Errorf("Expected %s mode to read %q: %q", testCase.mode, testCase.expected, actual)
+		}
+	}
+
+	for _, invalid := range []struct {
+		inputs map[string]float64
+		mode   string
+	}{
+		{inputs, "fake"},
+		{map[string]float64{}, WeightScale},
+		{map[string]float64{paths["generic"]: -1}, WeightScale},
+		{map[string]float64{filepath.Join(dir, "none"): 1}, WeightScale},
+	} {
+		if err := validateWeighted(invalid.inputs, invalid.mode); err == nil {
+			t.Errorf("Expected to fail validating weighted inputs %v in %s mode", invalid.inputs, invalid.mode)
+		}
+	}
+}
+
+func TestWord2vecInputWeighted(t *testing.T) {
+	dir, paths := writeCorpora(t, map[string]string{
+		"generic": "a b b c c c c\n",
+		"domain":  "d e\n",
+	})
+	defer os.RemoveAll(dir)
+
+	b := NewWord2vecBuilder()
+	b.InputFile(filepath.Join(dir, "none")).Dimension(5).MinCount(0).ThreadSize(1).Iteration(1).
+		InputWeighted(map[string]float64{paths["generic"]: 1, paths["domain"]: 5})
+	for _, mode := range []string{WeightScale, WeightSample} {
+		mod, err := b.Clone().WeightMode(mode).Build()
+		if err != nil {
+			t.Fatalf("Expected to build on weighted inputs in %s mode: %v", mode, err)
+		}
+		cps := mod.(*word2vec.Word2vec).Word2vecCorpus
+		if cps.WordFreq("d") != 5 || cps.WordFreq("c") != 4 {
+			t.Errorf("Expected frequencies multiplied by weights in %s mode: d=%d, c=%d",
+				mode, cps.WordFreq("d"), cps.WordFreq("c"))
+		}
+	}
+
+	if _, err := b.Clone().SentenceWeights(true).Build(); err == nil {
+		t.Error("Expected to fail building weighted inputs in scale mode with sentence weights")
+	}
+	if _, err := b.Clone().WeightMode("fake").Build(); err == nil {
+		t.Error("Expected to fail building with invalid weight mode")
+	}
+}
+
+func TestGloveInputWeighted(t *testing.T) {
+	dir, paths := writeCorpora(t, map[string]string{
+		"generic": "a b b c c c c\n",
+		"domain":  "d e\n",
+	})
+	defer os.RemoveAll(dir)
+
+	b := NewGloveBuilder()
+	b.InputFile(filepath.Join(dir, "none")).Dimension(5).MinCount(0).ThreadSize(1).Iteration(1).
+		InputWeighted(map[string]float64{paths["generic"]: 1, paths["domain"]: 5})
+	for _, mode := range []string{WeightScale, WeightSample} {
+		mod, err := b.WeightMode(mode).Build()
+		if err != nil {
+			t.Fatalf("Expected to build on weighted inputs in %s mode: %v", mode, err)
+		}
+		if freq := mod.(*glove.Glove).WordFreq("d"); freq != 5 {
+			t.Errorf("Expected frequency of d multiplied by weight in %s mode: %d", mode, freq)
+		}
+	}
+
+	if _, err := b.CheckpointDir(dir).Build(); err == nil {
+		t.Error("Expected to fail building weighted inputs with checkpoint")
+	}
+}
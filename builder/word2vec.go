@@ -15,15 +15,19 @@
 package builder
 
 import (
+	"io"
 	"os"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 
 	"github.com/ynqa/wego/config"
+	"github.com/ynqa/wego/corpus"
 	"github.com/ynqa/wego/model"
 	"github.com/ynqa/wego/model/word2vec"
 	"github.com/ynqa/wego/validate"
+	"github.com/ynqa/wego/vectorio"
 )
 
 // Word2vecBuilder manages the members to build Model interface.
@@ -31,6 +35,14 @@ type Word2vecBuilder struct {
 	// input file path.
 	inputFile string
 
+	// corpora with their weights to read instead of inputFile, and the mode to weight them.
+	inputWeights map[string]float64
+	weightMode   string
+
+	// output file path, and whether its directory is validated to be writable before training.
+	outputFile  string
+	checkOutput bool
+
 	// common configs.
 	dimension  int
 	iteration  int
@@ -41,6 +53,60 @@ type Word2vecBuilder struct {
 	toLower    bool
 	verbose    bool
 
+	// fraction of token occurrences for vocabulary to cover.
+	minCoverage float64
+
+	// hook to decide whether the word is kept in vocabulary per word.
+	minCountFunc func(word string, freq int) bool
+
+	// fixed ids of words for vocabulary to honor.
+	wordIDs map[string]int
+
+	// master seed of the run, which is generated if it is 0.
+	seed uint64
+
+	// output format of word vectors.
+	outputFormat string
+
+	// way to treat invalid UTF-8 sequences in corpus.
+	sanitizeUTF8 string
+
+	// limits of tokens to train on and to build vocabulary.
+	maxTokens      int64
+	maxVocabTokens int64
+
+	// scripts to keep tokens predominantly in them, and fraction of runes in them to keep tokens.
+	scripts         []string
+	scriptThreshold float64
+
+	// whether to split tokens on hyphens and on apostrophes.
+	splitHyphens     bool
+	splitApostrophes bool
+
+	// special tokens with the reserved ids in vocabulary.
+	specialTokens []string
+
+	// times to retry reading corpus on transient errors, and the first delay of backoff.
+	readRetries    int
+	readRetryDelay time.Duration
+
+	// style and precision to format values of word vectors in text format.
+	saveFormat    string
+	savePrecision int
+
+	// words to track their vectors per iteration, and file path to append them.
+	trackWords []string
+	trackPath  string
+
+	// callback of progress of training.
+	onProgress model.ProgressFunc
+
+	// hooks called before and after each iteration.
+	hooks model.Hooks
+
+	// words to keep their vectors on training.
+	mask []string
+
 	// word2vec configs.
 	model              string
 	optimizer          string
@@ -49,12 +115,48 @@ type Word2vecBuilder struct {
 	negativeSampleSize int
 	subsampleThreshold float64
 	theta              float64
+	excludeSelfContext bool
+	cbowMean           bool
+	swapRoles          bool
+	windowStride       int
+	pretrainedVectors  string
+	trainOnly          string
+	treeFile           string
+
+	// file to map words' vector into memory instead of allocating them, or empty to allocate them.
+	storageFile string
+
+	// how threads update the shared vectors, and interval of sentences to merge them in periodic mode.
+	syncMode     string
+	syncInterval int
+
+	// whether sentences are shuffled every iteration.
+	shuffleSentences bool
+
+	// whether each line of corpus begins with the weight of the sentence.
+	sentenceWeights bool
+
+	// format of corpus, one of: text|pairs, and whether contexts' vector is saved instead for pairs.
+	inputFormat  string
+	saveContexts bool
+
+	// limit of tokens to train on across all iterations.
+	maxTotalTokens int64
+
+	// fraction of sentences held out from corpus to evaluate perplexity.
+	heldoutFraction float64
+
+	// pairs of model and optimizer trained along with the main one.
+	alsoTrain [][2]string
 }
 
 // NewWord2vecBuilder creates *Word2vecBuilder.
 func NewWord2vecBuilder() *Word2vecBuilder {
 	return &Word2vecBuilder{
-		inputFile: config.DefaultInputFile,
+		inputFile:  config.DefaultInputFile,
+		weightMode: WeightScale,
+
+		checkOutput: config.DefaultCheckOutput,
 
 		dimension:  config.DefaultDimension,
 		iteration:  config.DefaultIteration,
@@ -65,6 +167,27 @@ func NewWord2vecBuilder() *Word2vecBuilder {
 		toLower:    config.DefaultToLower,
 		verbose:    config.DefaultVerbose,
 
+		minCoverage: config.DefaultMinCoverage,
+
+		outputFormat: config.DefaultOutputFormat,
+		sanitizeUTF8: config.DefaultSanitizeUTF8,
+
+		maxTokens:      config.DefaultMaxTokens,
+		maxVocabTokens: config.DefaultMaxVocabTokens,
+
+		scriptThreshold: config.DefaultScriptThreshold,
+
+		splitHyphens:     config.DefaultSplitHyphens,
+		splitApostrophes: config.DefaultSplitApostrophes,
+
+		seed: config.DefaultSeed,
+
+		readRetries:    config.DefaultReadRetries,
+		readRetryDelay: config.DefaultReadRetryDelay,
+
+		saveFormat:    config.DefaultSaveFormat,
+		savePrecision: config.DefaultSavePrecision,
+
 		model:              config.DefaultModel,
 		optimizer:          config.DefaultOptimizer,
 		batchSize:          config.DefaultBatchSize,
@@ -72,30 +195,107 @@ func NewWord2vecBuilder() *Word2vecBuilder {
 		negativeSampleSize: config.DefaultNegativeSampleSize,
 		subsampleThreshold: config.DefaultSubsampleThreshold,
 		theta:              config.DefaultTheta,
+		excludeSelfContext: config.DefaultExcludeSelfContext,
+		cbowMean:           config.DefaultCbowMean,
+		swapRoles:          config.DefaultSwapRoles,
+		windowStride:       config.DefaultWindowStride,
+		pretrainedVectors:  config.DefaultPretrainedVectors,
+		trainOnly:          config.DefaultTrainOnly,
+		treeFile:           config.DefaultTreeFile,
+
+		storageFile: config.DefaultStorageFile,
+
+		syncMode:     config.DefaultSyncMode,
+		syncInterval: config.DefaultSyncInterval,
+
+		shuffleSentences: config.DefaultShuffleSentences,
+		sentenceWeights:  config.DefaultSentenceWeights,
+
+		inputFormat:  config.DefaultCorpusFormat,
+		saveContexts: config.DefaultSaveContexts,
+
+		maxTotalTokens: config.DefaultMaxTotalTokens,
+
+		heldoutFraction: config.DefaultHeldoutFraction,
 	}
 }
 
-// NewWord2vecBuilderFromViper creates *Word2vecBuilder from viper.
+// NewWord2vecBuilderFromViper creates *Word2vecBuilder from the global viper.
 func NewWord2vecBuilderFromViper() *Word2vecBuilder {
+	return NewWord2vecBuilderWithViper(viper.GetViper())
+}
+
+// NewWord2vecBuilderWithViper creates *Word2vecBuilder from v, e.g. bound to flags of a command.
+func NewWord2vecBuilderWithViper(v *viper.Viper) *Word2vecBuilder {
 	return &Word2vecBuilder{
-		inputFile: viper.GetString(config.InputFile.String()),
+		inputFile:  v.GetString(config.InputFile.String()),
+		weightMode: WeightScale,
+
+		outputFile:  v.GetString(config.OutputFile.String()),
+		checkOutput: v.GetBool(config.CheckOutput.String()),
+
+		dimension:  v.GetInt(config.Dimension.String()),
+		iteration:  v.GetInt(config.Iteration.String()),
+		minCount:   v.GetInt(config.MinCount.String()),
+		threadSize: v.GetInt(config.ThreadSize.String()),
+		window:     v.GetInt(config.Window.String()),
+		initlr:     v.GetFloat64(config.Initlr.String()),
+		toLower:    v.GetBool(config.ToLower.String()),
+		verbose:    v.GetBool(config.Verbose.String()),
+
+		minCoverage: v.GetFloat64(config.MinCoverage.String()),
+
+		outputFormat: v.GetString(config.OutputFormat.String()),
+		sanitizeUTF8: v.GetString(config.SanitizeUTF8.String()),
+
+		maxTokens:      v.GetInt64(config.MaxTokens.String()),
+		maxVocabTokens: v.GetInt64(config.MaxVocabTokens.String()),
+
+		scripts:         v.GetStringSlice(config.Scripts.String()),
+		scriptThreshold: v.GetFloat64(config.ScriptThreshold.String()),
+
+		splitHyphens:     v.GetBool(config.SplitHyphens.String()),
+		splitApostrophes: v.GetBool(config.SplitApostrophes.String()),
+
+		specialTokens: v.GetStringSlice(config.SpecialTokens.String()),
 
-		dimension:  viper.GetInt(config.Dimension.String()),
-		iteration:  viper.GetInt(config.Iteration.String()),
-		minCount:   viper.GetInt(config.MinCount.String()),
-		threadSize: viper.GetInt(config.ThreadSize.String()),
-		window:     viper.GetInt(config.Window.String()),
-		initlr:     viper.GetFloat64(config.Initlr.String()),
-		toLower:    viper.GetBool(config.ToLower.String()),
-		verbose:    viper.GetBool(config.Verbose.String()),
+		seed: v.GetUint64(config.Seed.String()),
 
-		model:              viper.GetString(config.Model.String()),
-		optimizer:          viper.GetString(config.Optimizer.String()),
-		batchSize:          viper.GetInt(config.BatchSize.String()),
-		maxDepth:           viper.GetInt(config.MaxDepth.String()),
-		negativeSampleSize: viper.GetInt(config.NegativeSampleSize.String()),
-		subsampleThreshold: viper.GetFloat64(config.SubsampleThreshold.String()),
-		theta:              viper.GetFloat64(config.Theta.String()),
+		readRetries:    v.GetInt(config.ReadRetries.String()),
+		readRetryDelay: v.GetDuration(config.ReadRetryDelay.String()),
+
+		saveFormat:    v.GetString(config.SaveFormat.String()),
+		savePrecision: v.GetInt(config.SavePrecision.String()),
+
+		model:              v.GetString(config.Model.String()),
+		optimizer:          v.GetString(config.Optimizer.String()),
+		batchSize:          v.GetInt(config.BatchSize.String()),
+		maxDepth:           v.GetInt(config.MaxDepth.String()),
+		negativeSampleSize: v.GetInt(config.NegativeSampleSize.String()),
+		subsampleThreshold: v.GetFloat64(config.SubsampleThreshold.String()),
+		theta:              v.GetFloat64(config.Theta.String()),
+		excludeSelfContext: v.GetBool(config.ExcludeSelfContext.String()),
+		cbowMean:           v.GetBool(config.CbowMean.String()),
+		swapRoles:          v.GetBool(config.SwapRoles.String()),
+		windowStride:       v.GetInt(config.WindowStride.String()),
+		pretrainedVectors:  v.GetString(config.PretrainedVectors.String()),
+		trainOnly:          v.GetString(config.TrainOnly.String()),
+		treeFile:           v.GetString(config.TreeFile.String()),
+
+		storageFile: v.GetString(config.StorageFile.String()),
+
+		syncMode:     v.GetString(config.SyncMode.String()),
+		syncInterval: v.GetInt(config.SyncInterval.String()),
+
+		shuffleSentences: v.GetBool(config.ShuffleSentences.String()),
+		sentenceWeights:  v.GetBool(config.SentenceWeights.String()),
+
+		inputFormat:  v.GetString(config.CorpusFormat.String()),
+		saveContexts: v.GetBool(config.SaveContexts.String()),
+
+		maxTotalTokens: v.GetInt64(config.MaxTotalTokens.String()),
+
+		heldoutFraction: v.GetFloat64(config.HeldoutFraction.String()),
 	}
 }
 
@@ -105,6 +305,33 @@ func (wb *Word2vecBuilder) InputFile(inputFile string) *Word2vecBuilder {
 	return wb
 }
 
+// InputWeighted sets corpora with their weights, i.e. path -> weight, to read as one corpus instead of inputFile,
+// e.g. a large generic corpus with weight 1 and a small in-domain corpus with weight 5 as if each sentence of it
+// appeared 5 times. How to weight them is set by WeightMode. The corpora are read in order of paths.
+func (wb *Word2vecBuilder) InputWeighted(inputs map[string]float64) *Word2vecBuilder {
+	wb.inputWeights = inputs
+	return wb
+}
+
+// WeightMode sets the mode to weight corpora given by InputWeighted. One of: scale|sample
+func (wb *Word2vecBuilder) WeightMode(mode string) *Word2vecBuilder {
+	wb.weightMode = mode
+	return wb
+}
+
+// OutputFile sets output file path to save word vectors, whose directory is validated to be writable on Build,
+// so that it fails before training.
+func (wb *Word2vecBuilder) OutputFile(outputFile string) *Word2vecBuilder {
+	wb.outputFile = outputFile
+	return wb
+}
+
+// CheckOutput sets whether the directory of OutputFile is validated to be writable on Build.
+func (wb *Word2vecBuilder) CheckOutput(check bool) *Word2vecBuilder {
+	wb.checkOutput = check
+	return wb
+}
+
 // Dimension sets dimension of word vector.
 func (wb *Word2vecBuilder) Dimension(dimension int) *Word2vecBuilder {
 	wb.dimension = dimension
@@ -123,7 +350,39 @@ func (wb *Word2vecBuilder) MinCount(minCount int) *Word2vecBuilder {
 	return wb
 }
 
-// ThreadSize sets number of goroutine.
+// MinCoverage sets fraction of token occurrences for vocabulary to cover, e.g. 0.95, instead of min count.
+// The effective min count is computed from the frequencies of words, which keeps the most frequent words until
+// they cover the fraction of tokens. It overrides MinCount if it is positive.
+func (wb *Word2vecBuilder) MinCoverage(coverage float64) *Word2vecBuilder {
+	wb.minCoverage = coverage
+	return wb
+}
+
+// MinCountFunc sets the hook which decides whether the word of freq is kept in vocabulary,
+// e.g. to require a higher count for numeric tokens than words. The words more frequent than min count
+// are kept if it is nil, and it overrides MinCount and MinCoverage otherwise.
+func (wb *Word2vecBuilder) MinCountFunc(fn func(word string, freq int) bool) *Word2vecBuilder {
+	wb.minCountFunc = fn
+	return wb
+}
+
+// Seed sets master seed of the run, which the seeds of threads are derived from. It is generated if it is 0,
+// and both are reported by Summary of the model, e.g. to repeat the run under a single thread for debugging.
+func (wb *Word2vecBuilder) Seed(seed uint64) *Word2vecBuilder {
+	wb.seed = seed
+	return wb
+}
+
+// WithWordIDs sets fixed ids of words for vocabulary to honor instead of ranking words by frequency,
+// e.g. to line up word vectors with an external embedding layer. The ids must be 0 to len-1 without duplication,
+// and the words must be the ones of corpus, otherwise Build fails.
+func (wb *Word2vecBuilder) WithWordIDs(ids map[string]int) *Word2vecBuilder {
+	wb.wordIDs = ids
+	return wb
+}
+
+// ThreadSize sets number of goroutine. If it is 0, threads are spawned one by one in the first iteration
+// up to the number of CPUs, while words/sec gains 5% or more by each thread, and the chosen number is used after that.
 func (wb *Word2vecBuilder) ThreadSize(threadSize int) *Word2vecBuilder {
 	wb.threadSize = threadSize
 	return wb
@@ -153,6 +412,122 @@ func (wb *Word2vecBuilder) Verbose() *Word2vecBuilder {
 	return wb
 }
 
+// SanitizeUTF8 sets how to treat invalid UTF-8 sequences in corpus. One of: none|replace|skip
+// They are replaced with U+FFFD by replace, or removed by skip.
+func (wb *Word2vecBuilder) SanitizeUTF8(sanitize string) *Word2vecBuilder {
+	wb.sanitizeUTF8 = sanitize
+	return wb
+}
+
+// MaxTokens sets limit of tokens to train on per iteration, e.g. for smoke tests.
+// Vocabulary is still built from the full corpus unless MaxVocabTokens is set.
+func (wb *Word2vecBuilder) MaxTokens(n int64) *Word2vecBuilder {
+	wb.maxTokens = n
+	return wb
+}
+
+// MaxVocabTokens sets limit of tokens to read from corpus to build vocabulary.
+// Since training runs on the read tokens, it also caps training.
+func (wb *Word2vecBuilder) MaxVocabTokens(n int64) *Word2vecBuilder {
+	wb.maxVocabTokens = n
+	return wb
+}
+
+// ScriptFilter adds scripts to keep tokens predominantly in them before counting, e.g. latin, cyrillic, han.
+// The names are of unicode.Scripts and case-insensitive.
+func (wb *Word2vecBuilder) ScriptFilter(scripts ...string) *Word2vecBuilder {
+	wb.scripts = append(wb.scripts, scripts...)
+	return wb
+}
+
+// ScriptThreshold sets fraction of runes in the scripts of ScriptFilter for mixed-script tokens to keep.
+func (wb *Word2vecBuilder) ScriptThreshold(threshold float64) *Word2vecBuilder {
+	wb.scriptThreshold = threshold
+	return wb
+}
+
+// SplitHyphens sets whether to split tokens on hyphens, e.g. "state-of-the-art" into "state", "of", "the" and "art".
+func (wb *Word2vecBuilder) SplitHyphens(split bool) *Word2vecBuilder {
+	wb.splitHyphens = split
+	return wb
+}
+
+// SplitApostrophes sets whether to split tokens on apostrophes, e.g. "don't" into "don" and "t".
+func (wb *Word2vecBuilder) SplitApostrophes(split bool) *Word2vecBuilder {
+	wb.splitApostrophes = split
+	return wb
+}
+
+// SpecialTokens sets special tokens, e.g. <pad>, <bos>, <eos>, which are always in vocabulary with the reserved ids
+// 0 to len-1 in order regardless of frequency, e.g. for downstream models relying on fixed ids of them.
+// The tokens not in corpus are counted once, and they are not available with WithWordIDs.
+func (wb *Word2vecBuilder) SpecialTokens(tokens []string) *Word2vecBuilder {
+	wb.specialTokens = tokens
+	return wb
+}
+
+// ReadRetry sets times to retry reading corpus on transient errors, and the delay before the first retry,
+// which doubles on each consecutive retry. The error is returned after all retries fail.
+func (wb *Word2vecBuilder) ReadRetry(retries int, delay time.Duration) *Word2vecBuilder {
+	wb.readRetries = retries
+	wb.readRetryDelay = delay
+	return wb
+}
+
+// SaveFormat sets style to format values of word vectors in text format. One of: fixed|scientific|shortest
+func (wb *Word2vecBuilder) SaveFormat(style string) *Word2vecBuilder {
+	wb.saveFormat = style
+	return wb
+}
+
+// SavePrecision sets digits to format values of word vectors in text format, e.g. 4 for %.4f with fixed style.
+// It is the number of significant digits for shortest style, and -1 means the minimal digits to represent them exactly.
+func (wb *Word2vecBuilder) SavePrecision(digits int) *Word2vecBuilder {
+	wb.savePrecision = digits
+	return wb
+}
+
+// TrackWords sets words to append their vectors into JSONL file on path after each iteration.
+// The words not in vocabulary are skipped, and printed with verbose.
+func (wb *Word2vecBuilder) TrackWords(words []string, path string) *Word2vecBuilder {
+	wb.trackWords = words
+	wb.trackPath = path
+	return wb
+}
+
+// Mask sets words to keep their vectors on training, e.g. curated embeddings given by PretrainedVectors.
+// They still serve as context for the other words. The words not in vocabulary are skipped, and printed with verbose.
+func (wb *Word2vecBuilder) Mask(words []string) *Word2vecBuilder {
+	wb.mask = words
+	return wb
+}
+
+// OnProgress sets fn to be called with progress of training, which is common to word2vec and GloVe.
+func (wb *Word2vecBuilder) OnProgress(fn model.ProgressFunc) *Word2vecBuilder {
+	wb.onProgress = fn
+	return wb
+}
+
+// BeforeIteration registers fn to be called before each iteration of training with the iteration from 1,
+// e.g. to change hyperparameters between passes.
+func (wb *Word2vecBuilder) BeforeIteration(fn func(iteration int)) *Word2vecBuilder {
+	wb.hooks.BeforeIteration(fn)
+	return wb
+}
+
+// AfterIteration registers fn to be called after each iteration of training with the iteration and the model,
+// e.g. to evaluate custom metrics.
+func (wb *Word2vecBuilder) AfterIteration(fn func(iteration int, m model.Model)) *Word2vecBuilder {
+	wb.hooks.AfterIteration(fn)
+	return wb
+}
+
+// OutputFormat sets format to save word vectors. One of: text|binary|json|npy|fasttext-vec
+func (wb *Word2vecBuilder) OutputFormat(format string) *Word2vecBuilder {
+	wb.outputFormat = format
+	return wb
+}
+
 // Model sets model of Word2vec. One of: cbow|skip-gram
 func (wb *Word2vecBuilder) Model(model string) *Word2vecBuilder {
 	wb.model = model
@@ -165,7 +540,8 @@ func (wb *Word2vecBuilder) Optimizer(optimizer string) *Word2vecBuilder {
 	return wb
 }
 
-// BatchSize sets batch size to update learning rate.
+// BatchSize sets batch size to update learning rate. It is capped to the tokens of corpus, e.g. for tiny corpora,
+// so that learning rate is updated at least once per iteration.
 func (wb *Word2vecBuilder) BatchSize(batchSize int) *Word2vecBuilder {
 	wb.batchSize = batchSize
 	return wb
@@ -183,7 +559,9 @@ func (wb *Word2vecBuilder) NegativeSampleSize(size int) *Word2vecBuilder {
 	return wb
 }
 
-// SubSampleThreshold sets threshold for subsampling.
+// SubSampleThreshold sets threshold for subsampling, which discards frequent words at random on training,
+// e.g. 1.0e-3 by default. Words more frequent than the threshold relative to all tokens are discarded more often.
+// 0 disables subsampling, so that all tokens are trained on.
 func (wb *Word2vecBuilder) SubSampleThreshold(threshold float64) *Word2vecBuilder {
 	wb.subsampleThreshold = threshold
 	return wb
@@ -195,40 +573,449 @@ func (wb *Word2vecBuilder) Theta(theta float64) *Word2vecBuilder {
 	return wb
 }
 
+// ExcludeSelfContext is whether the other occurrences of the target word in the window are excluded from context.
+// By default, they count as context like the other words.
+func (wb *Word2vecBuilder) ExcludeSelfContext() *Word2vecBuilder {
+	wb.excludeSelfContext = true
+	return wb
+}
+
+// CbowMean sets whether the hidden layer of cbow is the average of context vectors or their sum.
+// It is the average by default, like cbow_mean=1 of the original word2vec.
+func (wb *Word2vecBuilder) CbowMean(mean bool) *Word2vecBuilder {
+	wb.cbowMean = mean
+	return wb
+}
+
+// SwapRoles sets whether skip-gram trains with the target word as input predicting its context words,
+// instead of the context words as inputs predicting the target word like the original word2vec.
+// Saved vectors are the input side either way.
+func (wb *Word2vecBuilder) SwapRoles(swap bool) *Word2vecBuilder {
+	wb.swapRoles = swap
+	return wb
+}
+
+// WindowStride sets the stride to sample context words within the window, e.g. 2 samples every other word,
+// i.e. the words at distance 2, 4, ... from the target word. 1 samples all of them, which is the default.
+func (wb *Word2vecBuilder) WindowStride(stride int) *Word2vecBuilder {
+	wb.windowStride = stride
+	return wb
+}
+
+// PretrainedVectors sets file path of pretrained word vectors in text format to initialize words' vector.
+func (wb *Word2vecBuilder) PretrainedVectors(path string) *Word2vecBuilder {
+	wb.pretrainedVectors = path
+	return wb
+}
+
+// TrainOnly sets which vectors are trained, and the other is frozen. One of: input|context
+// context is valid only together with PretrainedVectors.
+func (wb *Word2vecBuilder) TrainOnly(target string) *Word2vecBuilder {
+	wb.trainOnly = target
+	return wb
+}
+
+// TreeFile sets file path of binary tree, e.g. Brown clusters or WordNet, whose lines are "word code",
+// and hierarchical softmax uses the codes of words on it instead of huffman tree.
+// The codes are 0|1 and must be prefix-free, and all the words in vocabulary must be on the tree.
+func (wb *Word2vecBuilder) TreeFile(path string) *Word2vecBuilder {
+	wb.treeFile = path
+	return wb
+}
+
+// StorageFile sets file path to map words' vector into memory by model.MmapStorage (on unix), whose pages are loaded
+// on access and written back by OS, instead of allocating them, e.g. for the vectors of large vocabulary.
+// The vectors are initialized on it, and the file keeps them after training. The context vectors stay in memory.
+func (wb *Word2vecBuilder) StorageFile(path string) *Word2vecBuilder {
+	wb.storageFile = path
+	return wb
+}
+
+// SyncMode sets how threads update the shared vectors. One of: hogwild|periodic
+// periodic merges the updates of each thread every SyncInterval sentences, which is not available with AlsoTrain.
+func (wb *Word2vecBuilder) SyncMode(mode string) *Word2vecBuilder {
+	wb.syncMode = mode
+	return wb
+}
+
+// SyncInterval sets interval of sentences for each thread to merge its updates in periodic sync mode.
+func (wb *Word2vecBuilder) SyncInterval(interval int) *Word2vecBuilder {
+	wb.syncInterval = interval
+	return wb
+}
+
+// ShuffleSentences sets whether sentences, i.e. lines of corpus, are shuffled every iteration.
+func (wb *Word2vecBuilder) ShuffleSentences(shuffle bool) *Word2vecBuilder {
+	wb.shuffleSentences = shuffle
+	return wb
+}
+
+// SentenceWeights sets whether each line of corpus begins with the weight of the sentence, a non-negative number,
+// which scales learning rate for its words, e.g. "2.5 the quick brown fox".
+func (wb *Word2vecBuilder) SentenceWeights(weighted bool) *Word2vecBuilder {
+	wb.sentenceWeights = weighted
+	return wb
+}
+
+// MaxTotalTokens sets limit of tokens to train on across all iterations, e.g. for benchmarks independent of the size
+// of corpus, while MaxTokens limits each iteration.
+func (wb *Word2vecBuilder) MaxTotalTokens(n int64) *Word2vecBuilder {
+	wb.maxTotalTokens = n
+	return wb
+}
+
+// HeldoutFraction sets fraction of sentences, i.e. lines, held out from corpus at random by the seed.
+// The model is trained on the rest, and its perplexity on the held-out sentences is evaluated after each iteration,
+// which is printed with verbose and reported to OnProgress. 0 means no evaluation.
+func (wb *Word2vecBuilder) HeldoutFraction(fraction float64) *Word2vecBuilder {
+	wb.heldoutFraction = fraction
+	return wb
+}
+
+// InputFormat sets format of corpus. One of: text|pairs
+// With pairs, each line of corpus is "target context weight", e.g. (query, clicked item, weight) of clickstream,
+// and skip-gram with negative sampling is trained directly on the pairs without windows, which requires ns optimizer.
+// The other options of words' vector and sentences are not used.
+func (wb *Word2vecBuilder) InputFormat(format string) *Word2vecBuilder {
+	wb.inputFormat = format
+	return wb
+}
+
+// SaveContexts sets whether contexts' vector is saved instead of targets' vector with pairs input format.
+func (wb *Word2vecBuilder) SaveContexts(contexts bool) *Word2vecBuilder {
+	wb.saveContexts = contexts
+	return wb
+}
+
+// AlsoTrain adds a pair of model and optimizer trained on the same pass of corpus.
+// Its word vector is saved by (*word2vec.Word2vec).SaveAs with the name "model-optimizer", e.g. skip-gram-ns.
+func (wb *Word2vecBuilder) AlsoTrain(model, optimizer string) *Word2vecBuilder {
+	wb.alsoTrain = append(wb.alsoTrain, [2]string{model, optimizer})
+	return wb
+}
+
+// Clone returns a deep copy of the builder, e.g. for each point of hyperparameter sweeps,
+// so that setters on the copy never affect the original. Hooks such as MinCountFunc and OnProgress are shared,
+// and so are the ones of iterations registered before cloning.
+func (wb *Word2vecBuilder) Clone() *Word2vecBuilder {
+	clone := *wb
+	clone.scripts = append([]string(nil), wb.scripts...)
+	clone.specialTokens = append([]string(nil), wb.specialTokens...)
+	clone.trackWords = append([]string(nil), wb.trackWords...)
+	clone.mask = append([]string(nil), wb.mask...)
+	clone.alsoTrain = append([][2]string(nil), wb.alsoTrain...)
+	if wb.inputWeights != nil {
+		clone.inputWeights = make(map[string]float64, len(wb.inputWeights))
+		for path, weight := range wb.inputWeights {
+			clone.inputWeights[path] = weight
+		}
+	}
+	if wb.wordIDs != nil {
+		clone.wordIDs = make(map[string]int, len(wb.wordIDs))
+		for word, id := range wb.wordIDs {
+			clone.wordIDs[word] = id
+		}
+	}
+	return &clone
+}
+
 // Build creates model.Model interface.
 func (wb *Word2vecBuilder) Build() (model.Model, error) {
-	if !validate.FileExists(wb.inputFile) {
+	if wb.inputWeights == nil && !validate.FileExists(wb.inputFile) {
 		return nil, errors.Errorf("Not such a file %s", wb.inputFile)
 	}
+	if err := validateOutput(wb.outputFile, wb.checkOutput); err != nil {
+		return nil, err
+	}
+	if err := validateDimension(wb.dimension); err != nil {
+		return nil, err
+	}
+	if wb.inputWeights != nil {
+		if wb.inputFormat == "pairs" {
+			return nil, errors.New("Weighted inputs are not available with pairs inputFormat")
+		}
+		if wb.sentenceWeights && wb.weightMode == WeightScale {
+			return nil, errors.New("Weighted inputs in scale mode are not available with sentenceWeights")
+		}
+	}
 
-	input, err := os.Open(wb.inputFile)
+	if err := validateHeldout(wb.heldoutFraction); err != nil {
+		return nil, err
+	}
+	if wb.heldoutFraction > 0 && wb.inputFormat == "pairs" {
+		return nil, errors.New("heldoutFraction is not available with pairs inputFormat")
+	}
+	if wb.heldoutFraction > 0 && wb.inputWeights != nil && wb.weightMode == WeightSample {
+		// the copies of a sentence would be split independently, and leak into the held-out ones.
+		return nil, errors.New("heldoutFraction is not available with weighted inputs in sample mode")
+	}
+
+	// resolve the master seed before reading corpus, so that the sampled inputs and the held-out sentences
+	// are attributed to the same seed as the model.
+	seed := wb.seed
+	if seed == 0 {
+		seed = model.NewSeed()
+	}
+	input, err := openInput(wb.inputFile, wb.inputWeights, wb.weightMode, seed, wb.verbose)
 	if err != nil {
 		return nil, err
 	}
+	var heldout *heldoutReader
+	if wb.heldoutFraction > 0 {
+		heldout = newHeldoutReader(input, wb.heldoutFraction, seed)
+		input = heldout
+	}
 
 	cnf := model.NewConfig(wb.dimension, wb.iteration, wb.minCount, wb.threadSize, wb.window,
 		wb.initlr, wb.toLower, wb.verbose)
+	cnf.OutputFormat = wb.outputFormat
+	cnf.SanitizeUTF8 = wb.sanitizeUTF8
+	cnf.MaxTokens = wb.maxTokens
+	cnf.MaxVocabTokens = wb.maxVocabTokens
+	cnf.Scripts = wb.scripts
+	cnf.ScriptThreshold = wb.scriptThreshold
+	cnf.ReadRetries = wb.readRetries
+	cnf.ReadRetryDelay = wb.readRetryDelay
+	cnf.SaveFormat = wb.saveFormat
+	cnf.SavePrecision = wb.savePrecision
+	cnf.MinCoverage = wb.minCoverage
+	cnf.MinCountFunc = wb.minCountFunc
+	cnf.FixedWordIDs = wb.wordIDs
+	cnf.Seed = seed
+	cnf.SplitHyphens = wb.splitHyphens
+	cnf.SplitApostrophes = wb.splitApostrophes
+	cnf.SpecialTokens = wb.specialTokens
+	cnf.SentenceWeights = wb.sentenceWeights
+	if wb.inputWeights != nil && wb.weightMode == WeightScale {
+		cnf.SentenceWeights, cnf.WeightedCounts = true, true
+	}
+	if wb.storageFile != "" {
+		path := wb.storageFile
+		cnf.NewStorage = func(size, dimension int) (model.Storage, error) {
+			s, err := model.NewMmapStorage(path, size, dimension)
+			if err != nil {
+				return nil, err
+			}
+			return s, nil
+		}
+	}
+	if err := vectorio.ValidateFormat(wb.outputFormat); err != nil {
+		return nil, err
+	}
+	if err := cnf.FloatFormat().Validate(); err != nil {
+		return nil, err
+	}
+	if err := wb.validateTrainOnly(); err != nil {
+		return nil, err
+	}
+
+	switch wb.inputFormat {
+	case "", "text":
+	case "pairs":
+		return wb.buildPairs(input, cnf)
+	default:
+		return nil, errors.Errorf("Invalid inputFormat: %s not in text|pairs", wb.inputFormat)
+	}
+
+	mod, opt, err := wb.newModel(wb.model, wb.optimizer)
+	if err != nil {
+		return nil, err
+	}
+
+	w2v, err := word2vec.NewWord2vec(input, cnf, mod, opt,
+		wb.batchSize, wb.subsampleThreshold, wb.theta)
+	if err != nil {
+		return nil, err
+	}
 
+	for _, also := range wb.alsoTrain {
+		mod, opt, err := wb.newModel(also[0], also[1])
+		if err != nil {
+			return nil, err
+		}
+		if err := w2v.AlsoTrain(also[0]+"-"+also[1], mod, opt); err != nil {
+			return nil, err
+		}
+	}
+	if wb.syncMode != "" {
+		if err := w2v.SyncMode(wb.syncMode); err != nil {
+			return nil, err
+		}
+	}
+	if wb.syncMode == "periodic" {
+		if err := w2v.SyncInterval(wb.syncInterval); err != nil {
+			return nil, err
+		}
+	}
+	w2v.ShuffleSentences(wb.shuffleSentences)
+	if heldout != nil {
+		if err := w2v.Heldout(&heldout.heldout); err != nil {
+			return nil, errors.Wrap(err, "Unable to evaluate held-out sentences")
+		}
+	}
+	w2v.MaxTotalTokens(wb.maxTotalTokens)
+
+	if wb.pretrainedVectors != "" {
+		if err := wb.loadPretrained(w2v); err != nil {
+			return nil, err
+		}
+	}
+	if wb.trainOnly != "" {
+		if err := w2v.TrainOnly(wb.trainOnly); err != nil {
+			return nil, err
+		}
+	}
+	if len(wb.mask) > 0 {
+		warnUnmasked(w2v.Mask(wb.mask), wb.verbose)
+	}
+	if wb.onProgress != nil {
+		w2v.OnProgress(wb.onProgress)
+	}
+	w2v.Hooks = wb.hooks
+	if wb.trackPath != "" {
+		missing, err := w2v.TrackWords(wb.trackWords, wb.trackPath)
+		if err != nil {
+			return nil, err
+		}
+		warnUntracked(missing, wb.verbose)
+	}
+	return w2v, nil
+}
+
+func (wb *Word2vecBuilder) buildPairs(input io.ReadCloser, cnf *model.Config) (model.Model, error) {
+	if wb.model != "skip-gram" {
+		return nil, errors.Errorf("Invalid model: %s for pairs input format, which requires skip-gram", wb.model)
+	}
+	if wb.optimizer != "ns" {
+		return nil, errors.Errorf("Invalid optimizer: %s for pairs input format, which requires ns", wb.optimizer)
+	}
+	if err := wb.validatePairs(); err != nil {
+		return nil, err
+	}
+	pairs, err := word2vec.NewPairs(input, cnf, word2vec.NewNegativeSampling(wb.negativeSampleSize),
+		wb.batchSize, wb.theta)
+	if err != nil {
+		return nil, err
+	}
+	pairs.SaveContexts(wb.saveContexts)
+	pairs.Hooks = wb.hooks
+	return pairs, nil
+}
+
+// validatePairs rejects the options which pairs input format doesn't support, rather than ignoring them.
+func (wb *Word2vecBuilder) validatePairs() error {
+	unsupported := []struct {
+		name string
+		set  bool
+	}{
+		{"pretrainedVectors", wb.pretrainedVectors != ""},
+		{"trainOnly", wb.trainOnly != ""},
+		{"storageFile", wb.storageFile != ""},
+		{"mask", len(wb.mask) > 0},
+		{"trackWords", len(wb.trackWords) > 0 || wb.trackPath != ""},
+		{"syncMode", wb.syncMode != "" && wb.syncMode != "hogwild"},
+		{"alsoTrain", len(wb.alsoTrain) > 0},
+		{"maxTotalTokens", wb.maxTotalTokens > 0},
+		{"onProgress", wb.onProgress != nil},
+		{"shuffleSentences", wb.shuffleSentences},
+	}
+	for _, option := range unsupported {
+		if option.set {
+			return errors.Errorf("%s is not available with pairs inputFormat", option.name)
+		}
+	}
+	return nil
+}
+
+func (wb *Word2vecBuilder) validateTrainOnly() error {
+	switch wb.trainOnly {
+	case "", "input":
+	case "context":
+		if wb.pretrainedVectors == "" {
+			return errors.New("trainOnly=context requires pretrained vectors, otherwise words' vector is frozen at random")
+		}
+		if len(wb.alsoTrain) > 0 {
+			return errors.New("trainOnly=context is not available with alsoTrain, whose words' vector is not pretrained")
+		}
+	default:
+		return errors.Errorf("Invalid trainOnly: %s not in input|context", wb.trainOnly)
+	}
+	return nil
+}
+
+func (wb *Word2vecBuilder) loadPretrained(w2v *word2vec.Word2vec) error {
+	f, err := os.Open(wb.pretrainedVectors)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	vectors, err := vectorio.ReadText(f)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to read pretrained vectors %s", wb.pretrainedVectors)
+	}
+	if _, err := w2v.LoadPretrained(vectors); err != nil {
+		return errors.Wrapf(err, "Unable to load pretrained vectors %s", wb.pretrainedVectors)
+	}
+	return nil
+}
+
+func (wb *Word2vecBuilder) newModel(modelName, optimizerName string) (word2vec.Model, word2vec.Optimizer, error) {
 	var opt word2vec.Optimizer
-	switch wb.optimizer {
+	switch optimizerName {
 	case "hs":
-		opt = word2vec.NewHierarchicalSoftmax(wb.maxDepth)
+		if wb.treeFile == "" {
+			opt = word2vec.NewHierarchicalSoftmax(wb.maxDepth)
+			break
+		}
+		tree, err := wb.readTree()
+		if err != nil {
+			return nil, nil, err
+		}
+		opt = word2vec.NewHierarchicalSoftmaxWithTree(wb.maxDepth, tree)
 	case "ns":
 		opt = word2vec.NewNegativeSampling(wb.negativeSampleSize)
 	default:
-		return nil, errors.Errorf("Invalid optimizer: %s not in hs|ns", wb.optimizer)
+		return nil, nil, errors.Errorf("Invalid optimizer: %s not in hs|ns", optimizerName)
 	}
 
+	if wb.windowStride < 1 {
+		return nil, nil, errors.Errorf("Invalid windowStride: %d must be positive", wb.windowStride)
+	}
 	var mod word2vec.Model
-	switch wb.model {
+	switch modelName {
 	case "cbow":
-		mod = word2vec.NewCbow(wb.dimension, wb.window, wb.threadSize)
+		mod = word2vec.NewCbow(wb.dimension, wb.window, wb.threadSize).
+			Mean(wb.cbowMean).ExcludeSelf(wb.excludeSelfContext).WindowStride(wb.windowStride)
 	case "skip-gram":
-		mod = word2vec.NewSkipGram(wb.dimension, wb.window, wb.threadSize)
+		mod = word2vec.NewSkipGram(wb.dimension, wb.window, wb.threadSize).
+			ExcludeSelf(wb.excludeSelfContext).SwapRoles(wb.swapRoles).WindowStride(wb.windowStride)
 	default:
-		return nil, errors.Errorf("Invalid model: %s not in cbow|skip-gram", wb.model)
+		return nil, nil, errors.Errorf("Invalid model: %s not in cbow|skip-gram", modelName)
 	}
+	return mod, opt, nil
+}
 
-	return word2vec.NewWord2vec(input, cnf, mod, opt,
-		wb.batchSize, wb.subsampleThreshold, wb.theta)
+func (wb *Word2vecBuilder) readTree() (map[string]string, error) {
+	f, err := os.Open(wb.treeFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	tree, err := corpus.ReadTree(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to read tree %s", wb.treeFile)
+	}
+	return tree, nil
+}
+
+// BuildAndTrain creates model.Model interface and trains it on corpus.
+func (wb *Word2vecBuilder) BuildAndTrain() (model.Model, error) {
+	mod, err := wb.Build()
+	if err != nil {
+		return nil, err
+	}
+	if err := mod.Train(); err != nil {
+		return nil, errors.Wrap(err, "Unable to train word2vec")
+	}
+	return mod, nil
 }
@@ -15,9 +15,32 @@
 package builder
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/ynqa/wego/model"
+	"github.com/ynqa/wego/model/word2vec"
 )
 
+const testCorpus = "a b b c c c c"
+
+func writeTestCorpus(t *testing.T) string {
+	f, err := ioutil.TempFile("", "corpus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(testCorpus); err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
 func TestWord2vecInputFile(t *testing.T) {
 	b := &Word2vecBuilder{}
 
@@ -62,6 +85,17 @@ func TestWord2vecMinCount(t *testing.T) {
 	}
 }
 
+func TestWord2vecMinCoverage(t *testing.T) {
+	b := &Word2vecBuilder{}
+
+	expectedMinCoverage := 0.95
+	b.MinCoverage(expectedMinCoverage)
+
+	if b.minCoverage != expectedMinCoverage {
+		t.Errorf("Expected builder.minCoverage=%v: %v", expectedMinCoverage, b.minCoverage)
+	}
+}
+
 func TestWord2vecThreadSize(t *testing.T) {
 	b := &Word2vecBuilder{}
 
@@ -115,6 +149,158 @@ func TestWord2vecVerbose(t *testing.T) {
 	}
 }
 
+func TestWord2vecSanitizeUTF8(t *testing.T) {
+	b := &Word2vecBuilder{}
+
+	expectedSanitizeUTF8 := "skip"
+	b.SanitizeUTF8(expectedSanitizeUTF8)
+
+	if b.sanitizeUTF8 != expectedSanitizeUTF8 {
+		t.Errorf("Expected builder.sanitizeUTF8=%v: %v", expectedSanitizeUTF8, b.sanitizeUTF8)
+	}
+}
+
+func TestWord2vecMaxTokens(t *testing.T) {
+	b := &Word2vecBuilder{}
+
+	var expectedMaxTokens int64 = 1000000
+	b.MaxTokens(expectedMaxTokens).MaxVocabTokens(expectedMaxTokens).MaxTotalTokens(expectedMaxTokens)
+
+	if b.maxTokens != expectedMaxTokens || b.maxVocabTokens != expectedMaxTokens || b.maxTotalTokens != expectedMaxTokens {
+		t.Errorf("Expected builder.maxTokens=builder.maxVocabTokens=builder.maxTotalTokens=%v: %v, %v, %v",
+			expectedMaxTokens, b.maxTokens, b.maxVocabTokens, b.maxTotalTokens)
+	}
+}
+
+func TestWord2vecScriptFilter(t *testing.T) {
+	b := &Word2vecBuilder{}
+
+	b.ScriptFilter("latin").ScriptFilter("cyrillic", "han").ScriptThreshold(0.8)
+
+	if !reflect.DeepEqual(b.scripts, []string{"latin", "cyrillic", "han"}) {
+		t.Errorf("Expected builder.scripts=[latin cyrillic han]: %v", b.scripts)
+	}
+	if b.scriptThreshold != 0.8 {
+		t.Errorf("Expected builder.scriptThreshold=0.8: %v", b.scriptThreshold)
+	}
+}
+
+func TestWord2vecSeed(t *testing.T) {
+	b := &Word2vecBuilder{}
+
+	b.Seed(7)
+
+	if b.seed != 7 {
+		t.Errorf("Expected builder.seed=7: %v", b.seed)
+	}
+}
+
+func TestWord2vecSplit(t *testing.T) {
+	b := &Word2vecBuilder{}
+
+	b.SplitHyphens(true).SplitApostrophes(true)
+
+	if !b.splitHyphens || !b.splitApostrophes {
+		t.Errorf("Expected builder.splitHyphens=true and builder.splitApostrophes=true: %v, %v",
+			b.splitHyphens, b.splitApostrophes)
+	}
+}
+
+func TestWord2vecReadRetry(t *testing.T) {
+	b := &Word2vecBuilder{}
+
+	expectedRetries := 3
+	expectedDelay := 10 * time.Millisecond
+	b.ReadRetry(expectedRetries, expectedDelay)
+
+	if b.readRetries != expectedRetries || b.readRetryDelay != expectedDelay {
+		t.Errorf("Expected builder.readRetries=%v, builder.readRetryDelay=%v: %v, %v",
+			expectedRetries, expectedDelay, b.readRetries, b.readRetryDelay)
+	}
+}
+
+func TestWord2vecCbowMean(t *testing.T) {
+	b := NewWord2vecBuilder()
+
+	if !b.cbowMean {
+		t.Error("Expected builder.cbowMean=true by default")
+	}
+	b.CbowMean(false)
+	if b.cbowMean {
+		t.Error("Expected builder.cbowMean=false")
+	}
+}
+
+func TestWord2vecSwapRoles(t *testing.T) {
+	b := NewWord2vecBuilder()
+
+	if b.swapRoles {
+		t.Error("Expected builder.swapRoles=false by default")
+	}
+	b.SwapRoles(true)
+	if !b.swapRoles {
+		t.Error("Expected builder.swapRoles=true")
+	}
+}
+
+func TestWord2vecWindowStride(t *testing.T) {
+	b := NewWord2vecBuilder()
+
+	if b.windowStride != 1 {
+		t.Errorf("Expected builder.windowStride=1 by default: %v", b.windowStride)
+	}
+	b.WindowStride(2)
+	if b.windowStride != 2 {
+		t.Errorf("Expected builder.windowStride=2: %v", b.windowStride)
+	}
+	if _, _, err := b.WindowStride(0).newModel("skip-gram", "ns"); err == nil {
+		t.Error("Expected to fail with windowStride=0")
+	}
+}
+
+func TestWord2vecSaveFormat(t *testing.T) {
+	b := &Word2vecBuilder{}
+
+	b.SaveFormat("scientific").SavePrecision(4)
+
+	if b.saveFormat != "scientific" || b.savePrecision != 4 {
+		t.Errorf("Expected builder.saveFormat=scientific, builder.savePrecision=4: %v, %v", b.saveFormat, b.savePrecision)
+	}
+}
+
+func TestWord2vecInvalidSaveFormatBuild(t *testing.T) {
+	inputFile := writeTestCorpus(t)
+	defer os.Remove(inputFile)
+
+	b := NewWord2vecBuilder()
+	b.InputFile(inputFile).SaveFormat("fake")
+
+	if _, err := b.Build(); err == nil {
+		t.Error("Expected to fail building with invalid save format except for fixed|scientific|shortest")
+	}
+}
+
+func TestWord2vecTrackWords(t *testing.T) {
+	b := &Word2vecBuilder{}
+
+	b.TrackWords([]string{"a", "b"}, "track.jsonl")
+
+	if len(b.trackWords) != 2 || b.trackPath != "track.jsonl" {
+		t.Errorf("Expected builder.trackWords=[a b], trackPath=track.jsonl: %v, %v", b.trackWords, b.trackPath)
+	}
+}
+
+func TestWord2vecOutputFormat(t *testing.T) {
+	b := &Word2vecBuilder{}
+
+	expectedOutputFormat := "binary"
+	b.OutputFormat(expectedOutputFormat)
+
+	if b.outputFormat != expectedOutputFormat {
+		t.Errorf("Expected builder.outputFormat=%v: %v", expectedOutputFormat, b.outputFormat)
+	}
+}
+
 func TestWord2vecModel(t *testing.T) {
 	b := &Word2vecBuilder{}
 
@@ -192,6 +378,165 @@ func TestWord2vecTheta(t *testing.T) {
 	}
 }
 
+func TestWord2vecExcludeSelfContext(t *testing.T) {
+	b := &Word2vecBuilder{}
+
+	b.ExcludeSelfContext()
+
+	if !b.excludeSelfContext {
+		t.Errorf("Expected builder.excludeSelfContext=true: %v", b.excludeSelfContext)
+	}
+}
+
+func TestWord2vecPretrainedVectors(t *testing.T) {
+	b := &Word2vecBuilder{}
+
+	expectedPretrainedVectors := "pretrained.txt"
+	b.PretrainedVectors(expectedPretrainedVectors)
+
+	if b.pretrainedVectors != expectedPretrainedVectors {
+		t.Errorf("Expected builder.pretrainedVectors=%v: %v", expectedPretrainedVectors, b.pretrainedVectors)
+	}
+}
+
+func TestWord2vecTrainOnly(t *testing.T) {
+	b := &Word2vecBuilder{}
+
+	expectedTrainOnly := "context"
+	b.TrainOnly(expectedTrainOnly)
+
+	if b.trainOnly != expectedTrainOnly {
+		t.Errorf("Expected builder.trainOnly=%v: %v", expectedTrainOnly, b.trainOnly)
+	}
+}
+
+func TestWord2vecAlsoTrain(t *testing.T) {
+	b := &Word2vecBuilder{}
+
+	b.AlsoTrain("skip-gram", "ns").AlsoTrain("cbow", "ns")
+
+	if len(b.alsoTrain) != 2 {
+		t.Errorf("Expected builder.alsoTrain len=2: %v", b.alsoTrain)
+	}
+}
+
+func TestWord2vecSyncMode(t *testing.T) {
+	b := &Word2vecBuilder{}
+
+	expectedSyncMode, expectedSyncInterval := "periodic", 100
+	b.SyncMode(expectedSyncMode).SyncInterval(expectedSyncInterval)
+
+	if b.syncMode != expectedSyncMode || b.syncInterval != expectedSyncInterval {
+		t.Errorf("Expected builder.syncMode=%v, builder.syncInterval=%v: %v, %v",
+			expectedSyncMode, expectedSyncInterval, b.syncMode, b.syncInterval)
+	}
+}
+
+func TestWord2vecTreeBuild(t *testing.T) {
+	inputFile := writeTestCorpus(t)
+	defer os.Remove(inputFile)
+	treeFile, err := ioutil.TempFile("", "tree")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(treeFile.Name())
+	if _, err := treeFile.WriteString("a 00\nb 1\n"); err != nil {
+		t.Fatal(err)
+	}
+	treeFile.Close()
+
+	b := NewWord2vecBuilder()
+	b.InputFile(inputFile).
+		MinCount(0).
+		TreeFile(treeFile.Name())
+
+	if _, err := b.Build(); err == nil || !strings.Contains(err.Error(), `"c"`) {
+		t.Errorf("Expected to fail building with the word c not on tree: %v", err)
+	}
+}
+
+func TestWord2vecStorageFile(t *testing.T) {
+	b := &Word2vecBuilder{}
+
+	expectedStorageFile := "vectors.bin"
+	b.StorageFile(expectedStorageFile)
+
+	if b.storageFile != expectedStorageFile {
+		t.Errorf("Expected builder.storageFile=%v: %v", expectedStorageFile, b.storageFile)
+	}
+}
+
+func TestWord2vecShuffleSentences(t *testing.T) {
+	b := &Word2vecBuilder{}
+
+	b.ShuffleSentences(true)
+
+	if !b.shuffleSentences {
+		t.Errorf("Expected builder.shuffleSentences=true: %v", b.shuffleSentences)
+	}
+}
+
+func TestWord2vecSentenceWeights(t *testing.T) {
+	b := &Word2vecBuilder{}
+
+	b.SentenceWeights(true)
+
+	if !b.sentenceWeights {
+		t.Errorf("Expected builder.sentenceWeights=true: %v", b.sentenceWeights)
+	}
+}
+
+func TestWord2vecPairsBuild(t *testing.T) {
+	f, err := ioutil.TempFile("", "pairs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString("q1 item1 2.0\nq1 item2 0.5\nq2 item1 1\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	b := NewWord2vecBuilder()
+	b.InputFile(f.Name()).InputFormat("pairs").Dimension(5).MinCount(0).ThreadSize(1).Optimizer("ns")
+	if _, err := b.Build(); err == nil {
+		t.Error("Expected to fail building pairs input format with cbow model")
+	}
+	b.Model("skip-gram")
+	if _, err := b.Clone().Optimizer("hs").Build(); err == nil {
+		t.Error("Expected to fail building pairs input format with hs optimizer")
+	}
+	if _, err := b.Clone().InputFormat("fake").Build(); err == nil {
+		t.Error("Expected to fail building with invalid input format except for text|pairs")
+	}
+
+	unsupported := map[string]*Word2vecBuilder{
+		"pretrainedVectors": b.Clone().PretrainedVectors(f.Name()),
+		"trainOnly":         b.Clone().TrainOnly("input"),
+		"storageFile":       b.Clone().StorageFile(f.Name() + ".vectors"),
+		"mask":              b.Clone().Mask([]string{"q1"}),
+		"trackWords":        b.Clone().TrackWords([]string{"q1"}, f.Name()+".track"),
+		"syncMode":          b.Clone().SyncMode("periodic"),
+		"alsoTrain":         b.Clone().AlsoTrain("skip-gram", "ns"),
+		"maxTotalTokens":    b.Clone().MaxTotalTokens(1),
+		"onProgress":        b.Clone().OnProgress(func(model.Progress) {}),
+		"shuffleSentences":  b.Clone().ShuffleSentences(true),
+	}
+	for option, unsupportedBuilder := range unsupported {
+		if _, err := unsupportedBuilder.Build(); err == nil || !strings.Contains(err.Error(), option) {
+			t.Errorf("Expected to fail building pairs input format with %s: %v", option, err)
+		}
+	}
+
+	mod, err := b.SaveContexts(true).BuildAndTrain()
+	if err != nil {
+		t.Fatalf("Expected to build and train pairs: %v", err)
+	}
+	if _, ok := mod.(*word2vec.Pairs); !ok {
+		t.Errorf("Expected *word2vec.Pairs for pairs input format: %T", mod)
+	}
+}
+
 func TestWord2vecInvalidModelBuild(t *testing.T) {
 	b := &Word2vecBuilder{}
 
@@ -211,3 +556,218 @@ func TestWord2vecInvalidOptimizerBuild(t *testing.T) {
 		t.Errorf("Expected to fail building with invalid optimizer except for ns|hs: %v", b.optimizer)
 	}
 }
+
+func TestWord2vecBuildAndTrain(t *testing.T) {
+	inputFile := writeTestCorpus(t)
+	defer os.Remove(inputFile)
+
+	b := NewWord2vecBuilder()
+	b.InputFile(inputFile).
+		Dimension(5).
+		Iteration(1).
+		MinCount(0).
+		ThreadSize(1)
+
+	mod, err := b.BuildAndTrain()
+	if err != nil {
+		t.Fatalf("Expected to build and train word2vec: %v", err)
+	}
+	if mod == nil {
+		t.Error("Expected BuildAndTrain returns trained model")
+	}
+}
+
+func TestWord2vecWithWordIDs(t *testing.T) {
+	inputFile := writeTestCorpus(t)
+	defer os.Remove(inputFile)
+
+	wordIDs := map[string]int{"a": 0, "b": 1, "c": 2}
+	b := NewWord2vecBuilder()
+	b.InputFile(inputFile).
+		Dimension(5).
+		MinCount(0).
+		WithWordIDs(wordIDs)
+
+	mod, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w2v := mod.(*word2vec.Word2vec)
+	for word, expected := range wordIDs {
+		if actual, _ := w2v.Lookup(word); actual != expected {
+			t.Errorf("Expected id of %v=%v: %v", word, expected, actual)
+		}
+	}
+
+	b.WithWordIDs(map[string]int{"a": 0, "b": 2, "c": 3})
+	if _, err := b.Build(); err == nil {
+		t.Error("Expected to fail building with gaps of word ids")
+	}
+}
+
+func TestWord2vecBuildAndTrainInvalidOptimizer(t *testing.T) {
+	b := &Word2vecBuilder{}
+
+	b.Optimizer("fake_optimizer")
+
+	if _, err := b.BuildAndTrain(); err == nil {
+		t.Errorf("Expected to fail building and training with invalid optimizer: %v", b.optimizer)
+	}
+}
+
+func TestWord2vecInvalidAlsoTrainBuild(t *testing.T) {
+	inputFile := writeTestCorpus(t)
+	defer os.Remove(inputFile)
+
+	b := NewWord2vecBuilder()
+	b.InputFile(inputFile).
+		MinCount(0).
+		AlsoTrain("fake_model", "ns")
+
+	if _, err := b.Build(); err == nil {
+		t.Errorf("Expected to fail building with invalid model to train along with: %v", b.alsoTrain)
+	}
+}
+
+func TestWord2vecInvalidSyncModeBuild(t *testing.T) {
+	inputFile := writeTestCorpus(t)
+	defer os.Remove(inputFile)
+
+	b := NewWord2vecBuilder()
+	b.InputFile(inputFile).
+		MinCount(0).
+		SyncMode("periodic").
+		AlsoTrain("skip-gram", "ns")
+
+	if _, err := b.Build(); err == nil {
+		t.Error("Expected to fail building with periodic sync mode and the model to train along with")
+	}
+}
+
+func TestWord2vecUnwritableOutputBuild(t *testing.T) {
+	inputFile := writeTestCorpus(t)
+	defer os.Remove(inputFile)
+
+	outputFile := filepath.Join(os.TempDir(), "fake_dir", "vectors.txt")
+	b := NewWord2vecBuilder()
+	b.InputFile(inputFile).
+		MinCount(0).
+		OutputFile(outputFile)
+
+	if _, err := b.BuildAndTrain(); err == nil {
+		t.Errorf("Expected to fail building before training with non-existent directory: %v", outputFile)
+	}
+	b.CheckOutput(false)
+	if _, err := b.Build(); err != nil {
+		t.Errorf("Expected to build without checking output: %v", err)
+	}
+}
+
+func TestWord2vecInvalidOutputFormatBuild(t *testing.T) {
+	inputFile := writeTestCorpus(t)
+	defer os.Remove(inputFile)
+
+	b := NewWord2vecBuilder()
+	b.InputFile(inputFile).
+		MinCount(0).
+		OutputFormat("txt")
+
+	if _, err := b.Build(); err == nil {
+		t.Errorf("Expected to fail building with invalid output format except for text|binary|json|npy|fasttext-vec: %v", b.outputFormat)
+	}
+}
+
+func TestWord2vecInvalidTrainOnlyBuild(t *testing.T) {
+	inputFile := writeTestCorpus(t)
+	defer os.Remove(inputFile)
+
+	testCases := []struct {
+		name    string
+		builder *Word2vecBuilder
+	}{
+		{
+			name:    "unknown target",
+			builder: NewWord2vecBuilder().TrainOnly("output"),
+		},
+		{
+			name:    "context without pretrained vectors",
+			builder: NewWord2vecBuilder().TrainOnly("context"),
+		},
+		{
+			name:    "context with alsoTrain",
+			builder: NewWord2vecBuilder().TrainOnly("context").PretrainedVectors(inputFile).AlsoTrain("skip-gram", "ns"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase.builder.InputFile(inputFile).MinCount(0)
+		if _, err := testCase.builder.Build(); err == nil {
+			t.Errorf("Expected to fail building with %v", testCase.name)
+		}
+	}
+}
+
+func TestWord2vecTrainOnlyContextBuild(t *testing.T) {
+	inputFile := writeTestCorpus(t)
+	defer os.Remove(inputFile)
+
+	pretrained, err := ioutil.TempFile("", "pretrained")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(pretrained.Name())
+	pretrained.WriteString("a 1 1\nc 2 2\n")
+	pretrained.Close()
+
+	b := NewWord2vecBuilder()
+	b.InputFile(inputFile).
+		Dimension(2).
+		Iteration(1).
+		MinCount(0).
+		ThreadSize(1).
+		PretrainedVectors(pretrained.Name()).
+		TrainOnly("context")
+
+	if _, err := b.BuildAndTrain(); err != nil {
+		t.Errorf("Expected to build and train only context with pretrained vectors: %v", err)
+	}
+}
+
+func TestWord2vecOnProgress(t *testing.T) {
+	b := &Word2vecBuilder{}
+
+	b.OnProgress(func(model.Progress) {})
+
+	if b.onProgress == nil {
+		t.Error("Expected builder.onProgress to be set")
+	}
+}
+
+func TestWord2vecClone(t *testing.T) {
+	b := NewWord2vecBuilder()
+	b.Dimension(10).ScriptFilter("latin", "han").Mask([]string{"a"}).AlsoTrain("skip-gram", "ns")
+
+	c1, c2 := b.Clone(), b.Clone()
+	c1.Dimension(20).ScriptFilter("cyrillic").Mask([]string{"b"}).AlsoTrain("cbow", "hs")
+	c1.scripts[0] = "greek"
+	c2.ScriptFilter("arabic")
+
+	if b.dimension != 10 {
+		t.Errorf("Expected builder.dimension=10 of the original: %v", b.dimension)
+	}
+	if !reflect.DeepEqual(b.scripts, []string{"latin", "han"}) {
+		t.Errorf("Expected builder.scripts=[latin han] of the original: %v", b.scripts)
+	}
+	if !reflect.DeepEqual(b.mask, []string{"a"}) {
+		t.Errorf("Expected builder.mask=[a] of the original: %v", b.mask)
+	}
+	if !reflect.DeepEqual(b.alsoTrain, [][2]string{{"skip-gram", "ns"}}) {
+		t.Errorf("Expected builder.alsoTrain=[[skip-gram ns]] of the original: %v", b.alsoTrain)
+	}
+	if !reflect.DeepEqual(c1.scripts, []string{"greek", "han", "cyrillic"}) {
+		t.Errorf("Expected builder.scripts=[greek han cyrillic] of the clone: %v", c1.scripts)
+	}
+	if !reflect.DeepEqual(c2.scripts, []string{"latin", "han", "arabic"}) {
+		t.Errorf("Expected builder.scripts=[latin han arabic] of another clone: %v", c2.scripts)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ynqa/wego/config"
+	"github.com/ynqa/wego/export"
+	"github.com/ynqa/wego/validate"
+	"github.com/ynqa/wego/vectorio"
+)
+
+// NewAverageCmd creates the subcommand to average word vectors of several training runs.
+func NewAverageCmd(v *viper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "average",
+		Short:   "Average word vectors of several training runs",
+		Long:    "Average word vectors of several training runs, e.g. with different seeds, to reduce their variance",
+		Example: "  wego average -i run1.txt -i run2.txt -i run3.txt --normalize --align -o avg.txt",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			averageBind(v, cmd)
+			return envBind(v, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeAverage(v, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringSliceP(config.InputFile.String(), "i", nil,
+		"input file paths for trained word vectors of each run")
+	cmd.Flags().StringP(config.OutputFile.String(), "o", config.DefaultOutputFile,
+		"output file path to save averaged word vectors")
+	cmd.Flags().Bool(config.Normalize.String(), config.DefaultNormalize,
+		"whether to scale vectors to unit L2 norm per run before averaging")
+	cmd.Flags().Bool(config.Align.String(), config.DefaultAlign,
+		"whether to rotate each run onto the first run by orthogonal Procrustes before averaging")
+	cmd.Flags().Bool(config.Intersect.String(), config.DefaultIntersect,
+		"whether to average the words shared by all runs instead of failing on different vocabularies")
+	return cmd
+}
+
+func averageBind(v *viper.Viper, cmd *cobra.Command) {
+	v.BindPFlag(config.InputFile.String(), cmd.Flags().Lookup(config.InputFile.String()))
+	v.BindPFlag(config.OutputFile.String(), cmd.Flags().Lookup(config.OutputFile.String()))
+	v.BindPFlag(config.Normalize.String(), cmd.Flags().Lookup(config.Normalize.String()))
+	v.BindPFlag(config.Align.String(), cmd.Flags().Lookup(config.Align.String()))
+	v.BindPFlag(config.Intersect.String(), cmd.Flags().Lookup(config.Intersect.String()))
+}
+
+func executeAverage(v *viper.Viper, out io.Writer) error {
+	inputFiles := v.GetStringSlice(config.InputFile.String())
+	outputFile := v.GetString(config.OutputFile.String())
+
+	if len(inputFiles) < 2 {
+		return errors.Errorf("At least 2 input files are required to average: %v", inputFiles)
+	}
+	if validate.FileExists(outputFile) {
+		return errors.Errorf("%s is already existed", outputFile)
+	}
+
+	runs := make([]*vectorio.Vectors, len(inputFiles))
+	for i, inputFile := range inputFiles {
+		input, err := os.Open(inputFile)
+		if err != nil {
+			return err
+		}
+		runs[i], err = vectorio.ReadText(input)
+		input.Close()
+		if err != nil {
+			return errors.Wrapf(err, "Unable to read %s", inputFile)
+		}
+	}
+
+	averaged, err := export.Average(runs, export.AverageOptions{
+		Normalize: v.GetBool(config.Normalize.String()),
+		Align:     v.GetBool(config.Align.String()),
+		Intersect: v.GetBool(config.Intersect.String()),
+	})
+	if err != nil {
+		return err
+	}
+
+	output, err := os.Create(outputFile)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+	if err := vectorio.WriteText(output, averaged); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Averaged: %d runs of %d words\n", len(runs), len(averaged.Words))
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ynqa/wego/config"
+	"github.com/ynqa/wego/export"
+	"github.com/ynqa/wego/validate"
+	"github.com/ynqa/wego/vectorio"
+)
+
+// NewCanonicalizeCmd creates the subcommand to rotate trained word vectors into a canonical orientation.
+func NewCanonicalizeCmd(v *viper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "canonicalize",
+		Short: "Rotate trained word vectors into a canonical orientation",
+		Long: "Rotate trained word vectors onto their principal axes with fixed signs, so that the runs differing " +
+			"only by rotation and reflection become comparable. Cosine similarities between words are preserved",
+		Example: "  wego canonicalize -i example/word_vectors.txt -o canonical.txt",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			canonicalizeBind(v, cmd)
+			return envBind(v, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeCanonicalize(v, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringP(config.InputFile.String(), "i", config.DefaultInputFile,
+		"input file path for trained word vector")
+	cmd.Flags().StringP(config.OutputFile.String(), "o", config.DefaultOutputFile,
+		"output file path to save canonicalized word vectors")
+	return cmd
+}
+
+func canonicalizeBind(v *viper.Viper, cmd *cobra.Command) {
+	v.BindPFlag(config.InputFile.String(), cmd.Flags().Lookup(config.InputFile.String()))
+	v.BindPFlag(config.OutputFile.String(), cmd.Flags().Lookup(config.OutputFile.String()))
+}
+
+func executeCanonicalize(v *viper.Viper, out io.Writer) error {
+	inputFile := v.GetString(config.InputFile.String())
+	outputFile := v.GetString(config.OutputFile.String())
+	if validate.FileExists(outputFile) {
+		return errors.Errorf("%s is already existed", outputFile)
+	}
+
+	input, err := os.Open(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	vectors, err := vectorio.ReadText(input)
+	if err != nil {
+		return err
+	}
+
+	canonical, err := export.Canonicalize(vectors)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to canonicalize %s", inputFile)
+	}
+	if err := saveTo(outputFile, func(w io.Writer) error {
+		return vectorio.WriteText(w, canonical)
+	}); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Canonicalized: %d words, %d dimensions\n", len(canonical.Words), canonical.Dimension())
+	return nil
+}
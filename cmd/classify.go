@@ -0,0 +1,236 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ynqa/wego/config"
+	"github.com/ynqa/wego/model"
+	"github.com/ynqa/wego/model/classifier"
+	"github.com/ynqa/wego/validate"
+	"github.com/ynqa/wego/vectorio"
+)
+
+// splitSeed is the fixed seed to split held-out lines, so that the accuracy is comparable across runs.
+const splitSeed = 1
+
+// NewClassifyTrainCmd creates the subcommand to train a classifier of labeled lines on pretrained word vectors.
+func NewClassifyTrainCmd(v *viper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "classify-train",
+		Short: "Train a classifier of labeled lines on pretrained word vectors",
+		Long: "Train softmax over labels on the average of pretrained word vectors of each labeled line, " +
+			"like supervised fastText, and evaluate it on held-out lines",
+		Example: "  wego classify-train -i labeled.txt --label-prefix __label__ --pretrained vectors.txt -o classifier.json",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			classifyTrainBind(v, cmd)
+			return envBind(v, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeClassifyTrain(v, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringP(config.InputFile.String(), "i", config.DefaultInputFile,
+		"input file path for labeled corpus, whose lines are labels and words, e.g. \"__label__sports the match ended\"")
+	cmd.Flags().StringP(config.OutputFile.String(), "o", config.DefaultClassifier,
+		"output file path to save classifier")
+	cmd.Flags().String(config.Pretrained.String(), config.DefaultPretrained,
+		"file path of pretrained word vectors to classify on")
+	cmd.Flags().String(config.LabelPrefix.String(), config.DefaultLabelPrefix,
+		"prefix of tokens to be labels")
+	cmd.Flags().Int(config.Iteration.String(), config.DefaultClassifyIteration,
+		"number of iteration")
+	cmd.Flags().Float64(config.Initlr.String(), config.DefaultClassifyInitlr,
+		"initial learning rate")
+	cmd.Flags().Float64(config.FinetuneLr.String(), config.DefaultFinetuneLr,
+		"initial learning rate to fine-tune word vectors, finetune-lr=0 means to freeze them")
+	cmd.Flags().Float64(config.Holdout.String(), config.DefaultHoldout,
+		"fraction of lines held out to evaluate classifier, holdout=0 means no evaluation")
+	cmd.Flags().Bool(config.ToLower.String(), config.DefaultToLower,
+		"whether the words on corpus convert to lowercase or not")
+	return cmd
+}
+
+// NewClassifyPredictCmd creates the subcommand to predict labels of lines by a trained classifier.
+func NewClassifyPredictCmd(v *viper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "classify-predict",
+		Short:   "Predict labels of lines by a trained classifier",
+		Long:    "Predict the top labels of each line with their probabilities by a classifier trained by classify-train",
+		Example: "  wego classify-predict -i lines.txt --classifier classifier.json --top 3",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			classifyPredictBind(v, cmd)
+			return envBind(v, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeClassifyPredict(v, cmd.InOrStdin(), cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringP(config.InputFile.String(), "i", "",
+		"input file path for lines to predict, reading from stdin if it's empty")
+	cmd.Flags().String(config.Classifier.String(), config.DefaultClassifier,
+		"file path of classifier saved by classify-train")
+	cmd.Flags().Int(config.Top.String(), config.DefaultClassifyPredictTop,
+		"number of the top labels to predict, top=0 means all labels")
+	cmd.Flags().String(config.LabelPrefix.String(), config.DefaultLabelPrefix,
+		"prefix of tokens to be labels, which are ignored on prediction")
+	cmd.Flags().Bool(config.ToLower.String(), config.DefaultToLower,
+		"whether the words on lines convert to lowercase or not")
+	return cmd
+}
+
+func classifyTrainBind(v *viper.Viper, cmd *cobra.Command) {
+	v.BindPFlag(config.InputFile.String(), cmd.Flags().Lookup(config.InputFile.String()))
+	v.BindPFlag(config.OutputFile.String(), cmd.Flags().Lookup(config.OutputFile.String()))
+	v.BindPFlag(config.Pretrained.String(), cmd.Flags().Lookup(config.Pretrained.String()))
+	v.BindPFlag(config.LabelPrefix.String(), cmd.Flags().Lookup(config.LabelPrefix.String()))
+	v.BindPFlag(config.Iteration.String(), cmd.Flags().Lookup(config.Iteration.String()))
+	v.BindPFlag(config.Initlr.String(), cmd.Flags().Lookup(config.Initlr.String()))
+	v.BindPFlag(config.FinetuneLr.String(), cmd.Flags().Lookup(config.FinetuneLr.String()))
+	v.BindPFlag(config.Holdout.String(), cmd.Flags().Lookup(config.Holdout.String()))
+	v.BindPFlag(config.ToLower.String(), cmd.Flags().Lookup(config.ToLower.String()))
+}
+
+func classifyPredictBind(v *viper.Viper, cmd *cobra.Command) {
+	v.BindPFlag(config.InputFile.String(), cmd.Flags().Lookup(config.InputFile.String()))
+	v.BindPFlag(config.Classifier.String(), cmd.Flags().Lookup(config.Classifier.String()))
+	v.BindPFlag(config.Top.String(), cmd.Flags().Lookup(config.Top.String()))
+	v.BindPFlag(config.LabelPrefix.String(), cmd.Flags().Lookup(config.LabelPrefix.String()))
+	v.BindPFlag(config.ToLower.String(), cmd.Flags().Lookup(config.ToLower.String()))
+}
+
+func executeClassifyTrain(v *viper.Viper, out io.Writer) error {
+	inputFile := v.GetString(config.InputFile.String())
+	outputFile := v.GetString(config.OutputFile.String())
+	pretrainedFile := v.GetString(config.Pretrained.String())
+	holdout := v.GetFloat64(config.Holdout.String())
+
+	if holdout < 0 || holdout >= 1 {
+		return errors.Errorf("Invalid holdout: %v must be in [0, 1)", holdout)
+	}
+	if validate.FileExists(outputFile) {
+		return errors.Errorf("%s is already existed", outputFile)
+	}
+
+	pretrained, err := os.Open(pretrainedFile)
+	if err != nil {
+		return err
+	}
+	defer pretrained.Close()
+	vectors, err := vectorio.ReadText(pretrained)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to read %s", pretrainedFile)
+	}
+
+	input, err := os.Open(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	examples, err := classifier.ReadExamples(input, v.GetString(config.LabelPrefix.String()),
+		v.GetBool(config.ToLower.String()))
+	if err != nil {
+		return err
+	}
+
+	train, test := classifier.Split(examples, holdout, model.NewRandom(splitSeed))
+	c, err := classifier.New(vectors, classifier.Labels(train))
+	if err != nil {
+		return err
+	}
+	if err := c.Train(train, classifier.Options{
+		Iteration:  v.GetInt(config.Iteration.String()),
+		Initlr:     v.GetFloat64(config.Initlr.String()),
+		FinetuneLr: v.GetFloat64(config.FinetuneLr.String()),
+	}); err != nil {
+		return err
+	}
+
+	output, err := os.Create(outputFile)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+	if err := c.Save(output); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Trained: %d lines, %d labels\n", len(train), len(c.Labels))
+	if len(test) > 0 {
+		ev := c.Evaluate(test)
+		fmt.Fprintf(out, "N: %d, P@1: %.3f, R@1: %.3f\n", ev.Examples, ev.Precision, ev.Recall)
+	}
+	return nil
+}
+
+func executeClassifyPredict(v *viper.Viper, in io.Reader, out io.Writer) error {
+	inputFile := v.GetString(config.InputFile.String())
+	classifierFile := v.GetString(config.Classifier.String())
+
+	f, err := os.Open(classifierFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	c, err := classifier.Load(f)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to read %s", classifierFile)
+	}
+
+	input := in
+	if inputFile != "" {
+		f, err := os.Open(inputFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		input = f
+	}
+	return predictLines(input, out, c, v.GetInt(config.Top.String()),
+		v.GetString(config.LabelPrefix.String()), v.GetBool(config.ToLower.String()))
+}
+
+// predictLines writes the top labels with their probabilities for each line, e.g. "__label__a 0.9 __label__b 0.1".
+func predictLines(r io.Reader, w io.Writer, c *classifier.Classifier, top int, labelPrefix string, toLower bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024*1024)
+	out := bufio.NewWriter(w)
+	for scanner.Scan() {
+		e := classifier.ParseExample(scanner.Text(), labelPrefix, toLower)
+		predictions := c.Predict(e.Words, top)
+		fields := make([]string, 0, 2*len(predictions))
+		for _, p := range predictions {
+			fields = append(fields, p.Label, fmt.Sprintf("%.5f", p.Probability))
+		}
+		if _, err := fmt.Fprintln(out, strings.Join(fields, " ")); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.Wrap(err, "Unable to complete scanning")
+	}
+	return out.Flush()
+}
@@ -0,0 +1,73 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/ynqa/wego/model/classifier"
+	"github.com/ynqa/wego/vectorio"
+)
+
+const (
+	classifyTrainFlagSize   = 9
+	classifyPredictFlagSize = 5
+)
+
+func TestClassifyTrainBind(t *testing.T) {
+	v := viper.New()
+
+	classifyTrainBind(v, NewClassifyTrainCmd(v))
+
+	if len(v.AllKeys()) != classifyTrainFlagSize {
+		t.Errorf("Expected classifyTrainBind maps %v keys: %v",
+			classifyTrainFlagSize, v.AllKeys())
+	}
+}
+
+func TestClassifyPredictBind(t *testing.T) {
+	v := viper.New()
+
+	classifyPredictBind(v, NewClassifyPredictCmd(v))
+
+	if len(v.AllKeys()) != classifyPredictFlagSize {
+		t.Errorf("Expected classifyPredictBind maps %v keys: %v",
+			classifyPredictFlagSize, v.AllKeys())
+	}
+}
+
+func TestPredictLines(t *testing.T) {
+	vectors, err := vectorio.ReadText(strings.NewReader("a 1 0\nb 0 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := classifier.New(vectors, []string{"__label__x", "__label__y"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := predictLines(strings.NewReader("__label__x a\nb\n"), &buf, c, 1, "__label__", false); err != nil {
+		t.Fatal(err)
+	}
+	expected := "__label__x 0.50000\n__label__x 0.50000\n"
+	if buf.String() != expected {
+		t.Errorf("Expected a label per line with its probability %q: %q", expected, buf.String())
+	}
+}
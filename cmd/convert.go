@@ -0,0 +1,119 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ynqa/wego/config"
+	"github.com/ynqa/wego/validate"
+	"github.com/ynqa/wego/vectorio"
+)
+
+// NewConvertCmd creates the subcommand to convert trained word vectors between formats.
+func NewConvertCmd(v *viper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "convert",
+		Short:   "Convert trained word vectors between formats",
+		Long:    "Convert trained word vectors from one format into another without retraining",
+		Example: "  wego convert -i example/word_vectors.txt --from text --to binary -o word_vectors.bin",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			convertBind(v, cmd)
+			return envBind(v, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeConvert(v, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringP(config.InputFile.String(), "i", config.DefaultInputFile,
+		"input file path for trained word vector")
+	cmd.Flags().StringP(config.OutputFile.String(), "o", config.DefaultOutputFile,
+		"output file path to save converted word vectors")
+	cmd.Flags().String(config.From.String(), config.DefaultFrom,
+		"format of input file. One of: "+strings.Join(readableFormats(), "|"))
+	cmd.Flags().String(config.To.String(), config.DefaultTo,
+		"format of output file. One of: "+strings.Join(vectorio.Formats, "|"))
+	return cmd
+}
+
+func convertBind(v *viper.Viper, cmd *cobra.Command) {
+	v.BindPFlag(config.InputFile.String(), cmd.Flags().Lookup(config.InputFile.String()))
+	v.BindPFlag(config.OutputFile.String(), cmd.Flags().Lookup(config.OutputFile.String()))
+	v.BindPFlag(config.From.String(), cmd.Flags().Lookup(config.From.String()))
+	v.BindPFlag(config.To.String(), cmd.Flags().Lookup(config.To.String()))
+}
+
+// readableFormats returns the formats to read words from, i.e. except for npy.
+func readableFormats() []string {
+	formats := make([]string, 0, len(vectorio.Formats))
+	for _, format := range vectorio.Formats {
+		if format != vectorio.FormatNpy {
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
+
+func executeConvert(v *viper.Viper, out io.Writer) error {
+	inputFile := v.GetString(config.InputFile.String())
+	outputFile := v.GetString(config.OutputFile.String())
+	from := v.GetString(config.From.String())
+	to := v.GetString(config.To.String())
+
+	if from == vectorio.FormatNpy {
+		return errors.Errorf("Unable to convert from npy without words. One of: %s",
+			strings.Join(readableFormats(), "|"))
+	}
+	for _, format := range []string{from, to} {
+		if err := vectorio.ValidateFormat(format); err != nil {
+			return err
+		}
+	}
+	if validate.FileExists(outputFile) {
+		return errors.Errorf("%s is already existed", outputFile)
+	}
+
+	input, err := os.Open(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	output, err := os.Create(outputFile)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+	w := bufio.NewWriter(output)
+	size, dim, err := vectorio.Convert(bufio.NewReader(input), from, w, to)
+	if err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Converted: %d words of dimension %d from %s to %s\n", size, dim, from, to)
+	return nil
+}
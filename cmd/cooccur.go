@@ -0,0 +1,111 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ynqa/wego/builder"
+	"github.com/ynqa/wego/config"
+	"github.com/ynqa/wego/validate"
+)
+
+// DefaultCooccurOutputFile is the default output file path of co-occurrences.
+const DefaultCooccurOutputFile = "example/cooccur.bin"
+
+// NewCooccurCmd creates the subcommand to count co-occurrences for GloVe.
+func NewCooccurCmd(v *viper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cooccur",
+		Short: "Count co-occurrences of words for GloVe",
+		Long: "Count co-occurrences of words on corpus in the same way as glove, " +
+			"and save them with vocabulary, e.g. to train with Stanford GloVe",
+		Example: "  wego cooccur -i example/input.txt -o cooccur.bin --save-vocab vocab.txt",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			configBind(v, cmd)
+			cooccurBind(v, cmd)
+			return envBind(v, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeCooccur(v, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().AddFlagSet(ConfigFlagSet())
+	output := cmd.Flags().Lookup(config.OutputFile.String())
+	output.Usage = "output file path to save co-occurrences"
+	output.DefValue = DefaultCooccurOutputFile
+	output.Value.Set(DefaultCooccurOutputFile)
+	cmd.Flags().String(config.SaveVocab.String(), config.DefaultSaveVocab,
+		"file path to save vocabulary whose lines are \"word frequency\", the word ids of co-occurrences refer to")
+	cmd.Flags().String(config.CooccurrenceFormat.String(), config.DefaultCooccurrenceFormat,
+		"format to save co-occurrences. One of: stanford|text")
+	cmd.Flags().Float64(config.SubsampleThreshold.String(), config.DefaultGloveSubsampleThreshold,
+		"threshold for subsampling on counting co-occurrences, threshold=0 means no subsampling")
+	return cmd
+}
+
+func cooccurBind(v *viper.Viper, cmd *cobra.Command) {
+	v.BindPFlag(config.SaveVocab.String(), cmd.Flags().Lookup(config.SaveVocab.String()))
+	v.BindPFlag(config.CooccurrenceFormat.String(), cmd.Flags().Lookup(config.CooccurrenceFormat.String()))
+	v.BindPFlag(config.SubsampleThreshold.String(), cmd.Flags().Lookup(config.SubsampleThreshold.String()))
+}
+
+func executeCooccur(v *viper.Viper, out io.Writer) error {
+	outputFile := v.GetString(config.OutputFile.String())
+	vocabFile := v.GetString(config.SaveVocab.String())
+	for _, path := range []string{outputFile, vocabFile} {
+		if validate.FileExists(path) {
+			return errors.Errorf("%s is already existed", path)
+		}
+	}
+
+	cps, err := builder.NewGloveBuilderWithViper(v).BuildCorpus()
+	if err != nil {
+		return err
+	}
+	format := v.GetString(config.CooccurrenceFormat.String())
+	if err := saveTo(outputFile, func(w io.Writer) error {
+		return cps.WriteCooccurrence(w, format)
+	}); err != nil {
+		return err
+	}
+	if err := saveTo(vocabFile, cps.WriteVocab); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Counted: %d words, %d pairs\n", cps.Size(), len(cps.Cooccurrence()))
+	return nil
+}
+
+// saveTo creates the file on path, and writes it by write.
+func saveTo(path string, write func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+	if err := write(w); err != nil {
+		return err
+	}
+	return w.Flush()
+}
@@ -0,0 +1,54 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/ynqa/wego/config"
+)
+
+const cooccurFlagSize = 3
+
+func TestCooccurBind(t *testing.T) {
+	v := viper.New()
+
+	cooccurBind(v, NewCooccurCmd(v))
+
+	if len(v.AllKeys()) != cooccurFlagSize {
+		t.Errorf("Expected cooccurBind maps %v keys: %v",
+			cooccurFlagSize, v.AllKeys())
+	}
+}
+
+func TestCooccurCmdPreRun(t *testing.T) {
+	v := viper.New()
+
+	var empty []string
+	cmd := NewCooccurCmd(v)
+	if err := cmd.PreRunE(cmd, empty); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(v.AllKeys()) != cooccurFlagSize+configFlagSize {
+		t.Errorf("Expected PreRun of CooccurCmd maps %v keys: %v",
+			cooccurFlagSize+configFlagSize, v.AllKeys())
+	}
+	if v.GetString(config.OutputFile.String()) != DefaultCooccurOutputFile {
+		t.Errorf("Expected default outputFile=%v: %v", DefaultCooccurOutputFile, v.GetString(config.OutputFile.String()))
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ynqa/wego/config"
+	"github.com/ynqa/wego/corpus"
+	"github.com/ynqa/wego/export"
+	"github.com/ynqa/wego/vectorio"
+)
+
+// NewCoverageCmd creates the subcommand to report coverage of corpus by vocabulary of trained word vectors.
+func NewCoverageCmd(v *viper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "coverage",
+		Short: "Report coverage of corpus by vocabulary of trained word vectors",
+		Long: "Report how much of the tokens of corpus are covered by vocabulary of trained word vectors, " +
+			"tokenized in the same way as training, e.g. before embedding a new dataset",
+		Example: "  wego coverage -i example/word_vectors.txt --corpus new_data.txt --lower --format json",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			coverageBind(v, cmd)
+			return envBind(v, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeCoverage(v, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringP(config.InputFile.String(), "i", config.DefaultInputFile,
+		"input file path for trained word vector")
+	cmd.Flags().String(config.Corpus.String(), config.DefaultCorpus,
+		"file path for corpus to check coverage of")
+	cmd.Flags().String(config.Format.String(), config.DefaultCoverageFormat,
+		"format to report coverage. One of: text|json")
+	cmd.Flags().Int(config.Uncovered.String(), config.DefaultUncovered,
+		"number of the most frequent uncovered words to report")
+	cmd.Flags().Bool(config.ToLower.String(), config.DefaultToLower,
+		"whether the words on corpus convert to lowercase or not")
+	cmd.Flags().String(config.SanitizeUTF8.String(), config.DefaultSanitizeUTF8,
+		"how to treat invalid UTF-8 sequences in corpus. One of: none|replace|skip")
+	cmd.Flags().StringSlice(config.Scripts.String(), nil,
+		"scripts to keep tokens predominantly in them, e.g. latin,cyrillic,han (default all tokens)")
+	cmd.Flags().Float64(config.ScriptThreshold.String(), config.DefaultScriptThreshold,
+		"fraction of runes in the scripts for tokens to keep")
+	cmd.Flags().Bool(config.SplitHyphens.String(), config.DefaultSplitHyphens,
+		"whether to split tokens on hyphens, e.g. state-of-the-art into state, of, the and art")
+	cmd.Flags().Bool(config.SplitApostrophes.String(), config.DefaultSplitApostrophes,
+		"whether to split tokens on apostrophes, e.g. don't into don and t")
+	return cmd
+}
+
+func coverageBind(v *viper.Viper, cmd *cobra.Command) {
+	v.BindPFlag(config.InputFile.String(), cmd.Flags().Lookup(config.InputFile.String()))
+	v.BindPFlag(config.Corpus.String(), cmd.Flags().Lookup(config.Corpus.String()))
+	v.BindPFlag(config.Format.String(), cmd.Flags().Lookup(config.Format.String()))
+	v.BindPFlag(config.Uncovered.String(), cmd.Flags().Lookup(config.Uncovered.String()))
+	v.BindPFlag(config.ToLower.String(), cmd.Flags().Lookup(config.ToLower.String()))
+	v.BindPFlag(config.SanitizeUTF8.String(), cmd.Flags().Lookup(config.SanitizeUTF8.String()))
+	v.BindPFlag(config.Scripts.String(), cmd.Flags().Lookup(config.Scripts.String()))
+	v.BindPFlag(config.ScriptThreshold.String(), cmd.Flags().Lookup(config.ScriptThreshold.String()))
+	v.BindPFlag(config.SplitHyphens.String(), cmd.Flags().Lookup(config.SplitHyphens.String()))
+	v.BindPFlag(config.SplitApostrophes.String(), cmd.Flags().Lookup(config.SplitApostrophes.String()))
+}
+
+func executeCoverage(v *viper.Viper, out io.Writer) error {
+	inputFile := v.GetString(config.InputFile.String())
+	corpusFile := v.GetString(config.Corpus.String())
+	format := v.GetString(config.Format.String())
+
+	if corpusFile == "" {
+		return errors.Errorf("--%s is required to check coverage", config.Corpus.String())
+	}
+	switch format {
+	case "text", "json":
+	default:
+		return errors.Errorf("Invalid format: %s not in text|json", format)
+	}
+
+	input, err := os.Open(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	vectors, err := vectorio.ReadText(input)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(corpusFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	freqs := make(map[string]int)
+	if err := corpus.ScanTokens(f, corpus.ParseConfig{
+		ToLower:         v.GetBool(config.ToLower.String()),
+		Sanitize:        v.GetString(config.SanitizeUTF8.String()),
+		Scripts:         v.GetStringSlice(config.Scripts.String()),
+		ScriptThreshold: v.GetFloat64(config.ScriptThreshold.String()),
+
+		SplitHyphens:     v.GetBool(config.SplitHyphens.String()),
+		SplitApostrophes: v.GetBool(config.SplitApostrophes.String()),
+	}, func(word string) {
+		freqs[word]++
+	}); err != nil {
+		return err
+	}
+
+	report := export.CorpusCoverage(vectors, freqs, v.GetInt(config.Uncovered.String()))
+	return export.WriteCoverageReport(out, report, format)
+}
@@ -0,0 +1,125 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ynqa/wego/config"
+	"github.com/ynqa/wego/export"
+	"github.com/ynqa/wego/validate"
+	"github.com/ynqa/wego/vectorio"
+)
+
+// NewDedupeCmd creates the subcommand to group near-duplicate words of trained word vectors.
+func NewDedupeCmd(v *viper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dedupe",
+		Short: "Group near-duplicate words of trained word vectors",
+		Long: "Group words whose vectors have cosine similarity above threshold, e.g. singular and plural forms or " +
+			"typos, and propose the most frequent word of each group as its canonical form",
+		Example: "  wego dedupe -i example/word_vectors.txt --vocab vocab.txt --threshold 0.97 -o groups.tsv --canonicals reduced.txt",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			dedupeBind(v, cmd)
+			return envBind(v, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeDedupe(v, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringP(config.InputFile.String(), "i", config.DefaultInputFile,
+		"input file path for trained word vector")
+	cmd.Flags().StringP(config.OutputFile.String(), "o", config.DefaultDedupeOutput,
+		"output file path to save groups whose lines are \"canonical<TAB>duplicate<TAB>...\"")
+	cmd.Flags().String(config.Vocab.String(), config.DefaultVocab,
+		"vocabulary file path whose lines are \"word frequency\"")
+	cmd.Flags().Float64(config.DedupeThreshold.String(), config.DefaultDedupeThreshold,
+		"lower limit of cosine similarity for words to be duplicates")
+	cmd.Flags().String(config.Canonicals.String(), config.DefaultCanonicals,
+		"output file path to save word vectors only for canonical forms and the words not in groups additionally")
+	return cmd
+}
+
+func dedupeBind(v *viper.Viper, cmd *cobra.Command) {
+	v.BindPFlag(config.InputFile.String(), cmd.Flags().Lookup(config.InputFile.String()))
+	v.BindPFlag(config.OutputFile.String(), cmd.Flags().Lookup(config.OutputFile.String()))
+	v.BindPFlag(config.Vocab.String(), cmd.Flags().Lookup(config.Vocab.String()))
+	v.BindPFlag(config.DedupeThreshold.String(), cmd.Flags().Lookup(config.DedupeThreshold.String()))
+	v.BindPFlag(config.Canonicals.String(), cmd.Flags().Lookup(config.Canonicals.String()))
+}
+
+func executeDedupe(v *viper.Viper, out io.Writer) error {
+	inputFile := v.GetString(config.InputFile.String())
+	outputFile := v.GetString(config.OutputFile.String())
+	vocabFile := v.GetString(config.Vocab.String())
+	canonicalsFile := v.GetString(config.Canonicals.String())
+
+	if vocabFile == "" {
+		return errors.Errorf("--%s is required for frequency of words", config.Vocab.String())
+	}
+	for _, path := range []string{outputFile, canonicalsFile} {
+		if validate.FileExists(path) {
+			return errors.Errorf("%s is already existed", path)
+		}
+	}
+
+	input, err := os.Open(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	vectors, err := vectorio.ReadText(input)
+	if err != nil {
+		return err
+	}
+
+	vocab, err := os.Open(vocabFile)
+	if err != nil {
+		return err
+	}
+	defer vocab.Close()
+	freqs, err := export.ReadVocab(vocab)
+	if err != nil {
+		return err
+	}
+
+	groups := export.Dedupe(vectors, freqs, v.GetFloat64(config.DedupeThreshold.String()))
+	if err := saveTo(outputFile, func(w io.Writer) error {
+		return export.WriteDedupeGroups(w, groups)
+	}); err != nil {
+		return err
+	}
+	if canonicalsFile != "" {
+		if err := saveTo(canonicalsFile, func(w io.Writer) error {
+			return vectorio.WriteText(w, export.Canonicals(vectors, groups))
+		}); err != nil {
+			return err
+		}
+	}
+
+	var duplicates int
+	for _, g := range groups {
+		duplicates += len(g.Duplicates)
+	}
+	fmt.Fprintf(out, "Grouped: %d groups, %d duplicates of %d words\n", len(groups), duplicates, len(vectors.Words))
+	return nil
+}
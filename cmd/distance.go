@@ -15,6 +15,7 @@
 package cmd
 
 import (
+	"io"
 	"os"
 
 	"github.com/pkg/errors"
@@ -23,42 +24,93 @@ import (
 
 	"github.com/ynqa/wego/config"
 	"github.com/ynqa/wego/distance"
+	"github.com/ynqa/wego/export"
 )
 
-// DistanceCmd is the subcommand to estimate similarity.
-var DistanceCmd = &cobra.Command{
-	Use:     "distance",
-	Short:   "Estimate the distance between words",
-	Long:    "Estimate the distance between words",
-	Example: "  wego distance -i example/word_vectors.txt microsoft",
-	PreRun: func(cmd *cobra.Command, args []string) {
-		distanceBind(cmd)
-	},
-	RunE: func(cmd *cobra.Command, args []string) error {
-		if len(args) == 1 {
-			return executeDistance(args[0])
-		}
-		return errors.New("Input a single word")
-	},
-}
+// NewDistanceCmd creates the subcommand to estimate similarity.
+func NewDistanceCmd(v *viper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "distance",
+		Short: "Estimate the distance between words",
+		Long:  "Estimate the distance between words",
+		Example: `  wego distance -i example/word_vectors.txt microsoft
+  wego distance -i example/word_vectors.txt --expr "0.5*paris + 0.5*berlin - france"`,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			distanceBind(v, cmd)
+			return envBind(v, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if v.GetString(config.Expr.String()) != "" {
+				if len(args) != 0 {
+					return errors.New("Input either of a single word or expression")
+				}
+				return executeDistance(v, cmd.OutOrStdout(), "")
+			}
+			if len(args) == 1 {
+				return executeDistance(v, cmd.OutOrStdout(), args[0])
+			}
+			return errors.New("Input a single word")
+		},
+	}
 
-func init() {
-	DistanceCmd.Flags().StringP(config.InputFile.String(), "i", config.DefaultInputFile,
+	cmd.Flags().StringP(config.InputFile.String(), "i", config.DefaultInputFile,
 		"input file path for trained word vector")
-	DistanceCmd.Flags().IntP(config.Rank.String(), "r", config.DefaultRank,
+	cmd.Flags().IntP(config.Rank.String(), "r", config.DefaultRank,
 		"how many the most similar words will be displayed")
+	cmd.Flags().String(config.Vocab.String(), config.DefaultVocab,
+		"vocabulary file path whose lines are \"word frequency\" to show frequency of similar words")
+	cmd.Flags().Int(config.MinFreq.String(), config.DefaultMinFreq,
+		"lower limit of frequency for similar words (with vocab only)")
+	cmd.Flags().String(config.Metadata.String(), config.DefaultMetadata,
+		"metadata file path whose lines are \"word<TAB>key=value<TAB>...\" to filter similar words")
+	cmd.Flags().StringSlice(config.Filter.String(), nil,
+		"tags similar words must have, e.g. category=brand (with metadata only)")
+	cmd.Flags().Float64(config.MMR.String(), config.DefaultMMR,
+		"lambda of MMR to re-rank similar words for diversity, mmr=1 means no re-ranking")
+	cmd.Flags().String(config.Expr.String(), config.DefaultExpr,
+		"expression of words, numbers, +, -, * and parentheses to search instead of a word, e.g. \"0.5*paris + 0.5*berlin - france\"")
+	cmd.Flags().String(config.SenseDelimiter.String(), config.DefaultSenseDelimiter,
+		"delimiter of sense tags in words, e.g. @ of bank@1, to search a word not in vocabulary as all of its senses")
+	cmd.Flags().String(config.SenseMerge.String(), config.DefaultSenseMerge,
+		"how to merge similar words of senses. One of: union|average (with sense-delimiter only)")
+	return cmd
 }
 
-func distanceBind(cmd *cobra.Command) {
-	viper.BindPFlag(config.Rank.String(), cmd.Flags().Lookup(config.Rank.String()))
-	viper.BindPFlag(config.InputFile.String(), cmd.Flags().Lookup(config.InputFile.String()))
+func distanceBind(v *viper.Viper, cmd *cobra.Command) {
+	v.BindPFlag(config.Rank.String(), cmd.Flags().Lookup(config.Rank.String()))
+	v.BindPFlag(config.InputFile.String(), cmd.Flags().Lookup(config.InputFile.String()))
+	v.BindPFlag(config.Vocab.String(), cmd.Flags().Lookup(config.Vocab.String()))
+	v.BindPFlag(config.MinFreq.String(), cmd.Flags().Lookup(config.MinFreq.String()))
+	v.BindPFlag(config.Metadata.String(), cmd.Flags().Lookup(config.Metadata.String()))
+	v.BindPFlag(config.Filter.String(), cmd.Flags().Lookup(config.Filter.String()))
+	v.BindPFlag(config.MMR.String(), cmd.Flags().Lookup(config.MMR.String()))
+	v.BindPFlag(config.Expr.String(), cmd.Flags().Lookup(config.Expr.String()))
+	v.BindPFlag(config.SenseDelimiter.String(), cmd.Flags().Lookup(config.SenseDelimiter.String()))
+	v.BindPFlag(config.SenseMerge.String(), cmd.Flags().Lookup(config.SenseMerge.String()))
 }
 
-func executeDistance(target string) error {
-	inputFile := viper.GetString(config.InputFile.String())
-	rank := viper.GetInt(config.Rank.String())
+func executeDistance(v *viper.Viper, out io.Writer, target string) error {
+	inputFile := v.GetString(config.InputFile.String())
+	rank := v.GetInt(config.Rank.String())
 
-	est := distance.NewEstimator(target, rank)
+	est := distance.NewEstimator(target, rank).WithMMR(v.GetFloat64(config.MMR.String())).
+		WithExpression(v.GetString(config.Expr.String())).
+		WithSenseDelimiter(v.GetString(config.SenseDelimiter.String()), v.GetString(config.SenseMerge.String()))
+	if vocabFile := v.GetString(config.Vocab.String()); vocabFile != "" {
+		vocab, err := os.Open(vocabFile)
+		if err != nil {
+			return err
+		}
+		defer vocab.Close()
+		freqs, err := export.ReadVocab(vocab)
+		if err != nil {
+			return err
+		}
+		est.WithFrequency(freqs, v.GetInt(config.MinFreq.String()))
+	}
+	if err := withMetadata(v, est); err != nil {
+		return err
+	}
 
 	f, err := os.Open(inputFile)
 	if err != nil {
@@ -69,5 +121,30 @@ func executeDistance(target string) error {
 		return err
 	}
 
-	return est.Describe()
+	return est.DescribeTo(out)
+}
+
+func withMetadata(v *viper.Viper, est *distance.Estimator) error {
+	filter, err := distance.ParseFilter(v.GetStringSlice(config.Filter.String()))
+	if err != nil {
+		return err
+	}
+	metadataFile := v.GetString(config.Metadata.String())
+	if metadataFile == "" {
+		if len(filter) > 0 {
+			return errors.New("Metadata file is required for filter")
+		}
+		return nil
+	}
+	m, err := os.Open(metadataFile)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	metadata, err := distance.ReadMetadata(m)
+	if err != nil {
+		return err
+	}
+	est.WithMetadata(metadata).WithFilter(filter)
+	return nil
 }
@@ -20,15 +20,15 @@ import (
 	"github.com/spf13/viper"
 )
 
-const distanceFlagSize = 2
+const distanceFlagSize = 10
 
 func TestSimilarityBind(t *testing.T) {
-	defer viper.Reset()
+	v := viper.New()
 
-	distanceBind(DistanceCmd)
+	distanceBind(v, NewDistanceCmd(v))
 
-	if len(viper.AllKeys()) != distanceFlagSize {
+	if len(v.AllKeys()) != distanceFlagSize {
 		t.Errorf("Expected distanceBind maps %v keys: %v",
-			distanceFlagSize, viper.AllKeys())
+			distanceFlagSize, v.AllKeys())
 	}
 }
@@ -0,0 +1,187 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const e2eCorpus = "the quick brown fox jumps over the lazy dog\n"
+
+// execute runs the root command with args instead of os.Args, and returns what it writes.
+func execute(args ...string) (string, error) {
+	root := NewRootCmd()
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetArgs(append([]string{}, args...))
+	err := root.Execute()
+	return buf.String(), err
+}
+
+// distanceRows returns the fields of rank rows in the table written by distance, i.e. rank, word and cosine.
+func distanceRows(out string) [][]string {
+	var rows [][]string
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "|")
+		if len(fields) > 2 && strings.TrimSpace(fields[0]) == strconv.Itoa(len(rows)+1) {
+			row := make([]string, len(fields))
+			for i, field := range fields {
+				row[i] = strings.TrimSpace(field)
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func TestE2E(t *testing.T) {
+	dir, err := ioutil.TempDir("", "e2e")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	input := filepath.Join(dir, "input.txt")
+	if err := ioutil.WriteFile(input, []byte(strings.Repeat(e2eCorpus, 20)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, model := range []string{"word2vec", "glove"} {
+		output := filepath.Join(dir, model+".txt")
+		if _, err := execute(model, "-i", input, "-o", output, "-d", "5", "--iter", "2",
+			"--min-count", "1", "--thread", "1", "--seed", "1"); err != nil {
+			t.Fatalf("Expected %s to train: %v", model, err)
+		}
+		if _, err := execute(model, "-i", input, "-o", output); err == nil {
+			t.Errorf("Expected %s to fail overwriting %s", model, output)
+		}
+
+		out, err := execute("distance", "-i", output, "-r", "3", "fox")
+		if err != nil {
+			t.Fatalf("Expected distance on vectors of %s: %v", model, err)
+		}
+		var similar []string
+		for _, row := range distanceRows(out) {
+			similar = append(similar, row[1])
+		}
+		if len(similar) != 3 || strings.Contains(strings.Join(similar, " "), "fox") {
+			t.Errorf("Expected 3 similar words except for fox on vectors of %s: %q", model, out)
+		}
+	}
+
+	queries := filepath.Join(dir, "queries.jsonl")
+	if err := ioutil.WriteFile(queries, []byte(`{"id":"q1","vector":[1,0,0,0,0]}`+"\n"+`{"id":2,"vector":[0,1,0,0,0]}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := execute("nearest-words", "-i", filepath.Join(dir, "word2vec.txt"), "--vectors", queries, "-k", "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var res struct {
+			ID        string            `json:"id"`
+			Neighbors []json.RawMessage `json:"neighbors"`
+		}
+		if err := json.Unmarshal([]byte(line), &res); err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Neighbors) != 2 {
+			t.Errorf("Expected 2 neighbors of %s: %v", res.ID, line)
+		}
+		ids = append(ids, res.ID)
+	}
+	if strings.Join(ids, ",") != "q1,2" {
+		t.Errorf("Expected neighbors of q1 and 2 in order: %v", ids)
+	}
+
+	spectrum := filepath.Join(dir, "spectrum.csv")
+	if out, err = execute("vocab", "-i", input, "--spectrum", "-o", spectrum); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Tokens: 180, Types: 8") {
+		t.Errorf("Expected summary of 180 tokens of 8 words: %q", out)
+	}
+	b, err := ioutil.ReadFile(spectrum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(rows) != 9 || rows[1] != "1,40,0.2222222222222222" || !strings.HasSuffix(rows[8], ",1") {
+		t.Errorf("Expected header and a row per word of spectrum: %q", b)
+	}
+}
+
+func TestE2ESmallDimension(t *testing.T) {
+	dir, err := ioutil.TempDir("", "e2e")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	input := filepath.Join(dir, "input.txt")
+	if err := ioutil.WriteFile(input, []byte(strings.Repeat(e2eCorpus, 20)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := [][]string{
+		{"word2vec", "--optimizer", "hs"},
+		{"word2vec", "--optimizer", "ns", "--model", "skip-gram"},
+		{"glove"},
+	}
+	for _, dim := range []string{"1", "2"} {
+		for _, tc := range testCases {
+			output := filepath.Join(dir, strings.Join(tc, "")+dim+".txt")
+			args := append(append([]string{}, tc...), "-i", input, "-o", output, "-d", dim, "--iter", "2",
+				"--min-count", "1", "--thread", "1", "--seed", "1")
+			if _, err := execute(args...); err != nil {
+				t.Fatalf("Expected %v to train at dimension %s: %v", tc, dim, err)
+			}
+
+			out, err := execute("distance", "-i", output, "-r", "3", "fox")
+			if err != nil {
+				t.Fatalf("Expected distance on vectors of %v at dimension %s: %v", tc, dim, err)
+			}
+			rows := distanceRows(out)
+			if len(rows) != 3 {
+				t.Errorf("Expected 3 similar words on vectors of %v at dimension %s: %q", tc, dim, out)
+			}
+			for _, row := range rows {
+				if cos, err := strconv.ParseFloat(row[2], 64); err != nil || math.IsNaN(cos) || math.Abs(cos) > 1+1e-9 {
+					t.Errorf("Expected cosine in [-1, 1] on vectors of %v at dimension %s: %v", tc, dim, row)
+				}
+			}
+		}
+	}
+
+	if _, err := execute("word2vec", "-i", input, "-o", filepath.Join(dir, "zero.txt"), "-d", "0"); err == nil {
+		t.Error("Expected word2vec to fail at dimension 0")
+	}
+}
+
+func TestE2ESubCommand(t *testing.T) {
+	if _, err := execute(); err == nil || !strings.Contains(err.Error(), "Set sub-command") {
+		t.Errorf("Expected root command without sub-command to fail: %v", err)
+	}
+	if _, err := execute("nearest-words"); err == nil {
+		t.Error("Expected nearest-words without vectors to fail instead of exiting")
+	}
+}
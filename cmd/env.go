@@ -0,0 +1,74 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix is the prefix of environment variables to set flags, e.g. WEGO_DIMENSION for --dimension.
+const EnvPrefix = "WEGO"
+
+// envName returns the environment variable of the flag, e.g. WEGO_MIN_COUNT for --min-count.
+func envName(flag string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.Replace(flag, "-", "_", -1))
+}
+
+// envBind binds the flags of cmd to their environment variables in v, which take precedence over defaults but not
+// over flags given explicitly. It fails on the first variable whose value is invalid for the type of its flag.
+func envBind(v *viper.Viper, cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		name := envName(f.Name)
+		v.BindEnv(f.Name, name)
+		value, ok := os.LookupEnv(name)
+		if !ok || f.Changed || err != nil {
+			return
+		}
+		if e := validateEnv(f.Value.Type(), value); e != nil {
+			err = errors.Errorf("Invalid %s=%q for --%s: %v", name, value, f.Name, e)
+		}
+	})
+	return err
+}
+
+// validateEnv checks value is parsed as the type of flag in the same way as flags are.
+func validateEnv(typ, value string) error {
+	var err error
+	switch typ {
+	case "int", "int64":
+		_, err = strconv.ParseInt(value, 0, 64)
+	case "uint64":
+		_, err = strconv.ParseUint(value, 0, 64)
+	case "float64":
+		_, err = strconv.ParseFloat(value, 64)
+	case "bool":
+		_, err = strconv.ParseBool(value)
+	case "duration":
+		_, err = time.ParseDuration(value)
+	}
+	if err != nil {
+		return errors.Errorf("expected %s", typ)
+	}
+	return nil
+}
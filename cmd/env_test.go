@@ -0,0 +1,78 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/ynqa/wego/config"
+)
+
+func TestEnvName(t *testing.T) {
+	if name := envName(config.MinCount.String()); name != "WEGO_MIN_COUNT" {
+		t.Errorf("Expected WEGO_MIN_COUNT: %v", name)
+	}
+}
+
+func TestEnvPrecedence(t *testing.T) {
+	os.Setenv("WEGO_DIMENSION", "7")
+	os.Setenv("WEGO_ITER", "9")
+	defer os.Unsetenv("WEGO_DIMENSION")
+	defer os.Unsetenv("WEGO_ITER")
+
+	v := viper.New()
+	cmd := NewWord2vecCmd(v)
+	if err := cmd.Flags().Parse([]string{"--iter", "3"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.PreRunE(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if d := v.GetInt(config.Dimension.String()); d != 7 {
+		t.Errorf("Expected env to override default: dimension=%v", d)
+	}
+	if iter := v.GetInt(config.Iteration.String()); iter != 3 {
+		t.Errorf("Expected flag to override env: iter=%v", iter)
+	}
+	if w := v.GetInt(config.Window.String()); w != config.DefaultWindow {
+		t.Errorf("Expected default without flag and env: window=%v", w)
+	}
+}
+
+func TestInvalidEnv(t *testing.T) {
+	os.Setenv("WEGO_DIMENSION", "ten")
+	defer os.Unsetenv("WEGO_DIMENSION")
+
+	v := viper.New()
+	cmd := NewGloveCmd(v)
+	if err := cmd.PreRunE(cmd, nil); err == nil || !strings.Contains(err.Error(), "WEGO_DIMENSION") {
+		t.Errorf("Expected invalid WEGO_DIMENSION to fail naming it: %v", err)
+	}
+
+	// explicit flag makes the invalid env unused.
+	v = viper.New()
+	cmd = NewGloveCmd(v)
+	if err := cmd.Flags().Parse([]string{"-d", "5"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.PreRunE(cmd, nil); err != nil {
+		t.Errorf("Expected env overridden by flag not to be validated: %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ynqa/wego/config"
+	"github.com/ynqa/wego/export"
+	"github.com/ynqa/wego/validate"
+	"github.com/ynqa/wego/vectorio"
+)
+
+const exportExample = `  wego export -i example/word_vectors.txt --format idtable --dict dict.tsv --fill unk -o table.bin
+  wego export -i example/word_vectors.txt --format fasttext-vec -o word_vectors.vec`
+
+// NewExportCmd creates the subcommand to export trained word vectors.
+func NewExportCmd(v *viper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "export",
+		Short:   "Export trained word vectors into another format",
+		Long:    "Export trained word vectors into another format",
+		Example: exportExample,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			exportBind(v, cmd)
+			return envBind(v, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeExport(v, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringP(config.InputFile.String(), "i", config.DefaultInputFile,
+		"input file path for trained word vector")
+	cmd.Flags().StringP(config.OutputFile.String(), "o", config.DefaultOutputFile,
+		"output file path to export")
+	cmd.Flags().String(config.Format.String(), config.DefaultFormat,
+		"format to export. One of: idtable|fasttext-vec")
+	cmd.Flags().String(config.Dict.String(), config.DefaultDict,
+		"dictionary file path whose lines are token<TAB>id (for idtable only)")
+	cmd.Flags().String(config.Fill.String(), config.DefaultFill,
+		"vector for tokens not in vocabulary. One of: zeros|mean|unk (for idtable only)")
+	cmd.Flags().String(config.Unk.String(), config.DefaultUnk,
+		"word whose vector is used for fill=unk (for idtable only)")
+	cmd.Flags().String(config.Norms.String(), config.DefaultNorms,
+		"file path to write L2 norm of each word vector as word<TAB>norm lines additionally")
+	return cmd
+}
+
+func exportBind(v *viper.Viper, cmd *cobra.Command) {
+	v.BindPFlag(config.InputFile.String(), cmd.Flags().Lookup(config.InputFile.String()))
+	v.BindPFlag(config.OutputFile.String(), cmd.Flags().Lookup(config.OutputFile.String()))
+	v.BindPFlag(config.Format.String(), cmd.Flags().Lookup(config.Format.String()))
+	v.BindPFlag(config.Dict.String(), cmd.Flags().Lookup(config.Dict.String()))
+	v.BindPFlag(config.Fill.String(), cmd.Flags().Lookup(config.Fill.String()))
+	v.BindPFlag(config.Unk.String(), cmd.Flags().Lookup(config.Unk.String()))
+	v.BindPFlag(config.Norms.String(), cmd.Flags().Lookup(config.Norms.String()))
+}
+
+func executeExport(v *viper.Viper, out io.Writer) error {
+	inputFile := v.GetString(config.InputFile.String())
+	outputFile := v.GetString(config.OutputFile.String())
+	format := v.GetString(config.Format.String())
+
+	if format != "idtable" && format != vectorio.FormatFastTextVec {
+		return errors.Errorf("Invalid format: %s not in idtable|%s", format, vectorio.FormatFastTextVec)
+	}
+	if validate.FileExists(outputFile) {
+		return errors.Errorf("%s is already existed", outputFile)
+	}
+
+	input, err := os.Open(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	vectors, err := vectorio.ReadText(input)
+	if err != nil {
+		return err
+	}
+
+	output, err := os.Create(outputFile)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+
+	if format == vectorio.FormatFastTextVec {
+		err = vectorio.WriteFastTextVec(output, vectors)
+	} else {
+		err = exportIDTable(v, out, output, vectors)
+	}
+	if err != nil {
+		return err
+	}
+
+	if normsFile := v.GetString(config.Norms.String()); normsFile != "" {
+		norms, err := os.Create(normsFile)
+		if err != nil {
+			return err
+		}
+		defer norms.Close()
+		return export.WriteNorms(norms, vectors)
+	}
+	return nil
+}
+
+func exportIDTable(v *viper.Viper, out, output io.Writer, vectors *vectorio.Vectors) error {
+	dictFile := v.GetString(config.Dict.String())
+	d, err := os.Open(dictFile)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	dict, err := export.ReadDict(d)
+	if err != nil {
+		return err
+	}
+
+	coverage, err := export.WriteIDTable(output, vectors, dict,
+		v.GetString(config.Fill.String()), v.GetString(config.Unk.String()))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Rows: %d, Covered: %d/%d tokens in dictionary\n", coverage.Rows, coverage.Covered, len(dict))
+	if len(coverage.Missing) > 0 {
+		fmt.Fprintf(out, "Missing: %v\n", coverage.Missing)
+	}
+	return nil
+}
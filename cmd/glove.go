@@ -15,6 +15,8 @@
 package cmd
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"runtime/pprof"
 
@@ -27,57 +29,80 @@ import (
 	"github.com/ynqa/wego/validate"
 )
 
-// GloveCmd is the subcommand for Glove.
-var GloveCmd = &cobra.Command{
-	Use:   "glove",
-	Short: "GloVe: Global Vectors for Word Representation",
-	PreRun: func(cmd *cobra.Command, args []string) {
-		configBind(cmd)
-		gloveBind(cmd)
-	},
-	RunE: func(cmd *cobra.Command, args []string) error {
-		if viper.GetBool(config.Prof.String()) {
-			f, err := os.Create("cpu.prof")
-			if err != nil {
-				os.Exit(1)
+// NewGloveCmd creates the subcommand for Glove.
+func NewGloveCmd(v *viper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "glove",
+		Short: "GloVe: Global Vectors for Word Representation",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			configBind(v, cmd)
+			gloveBind(v, cmd)
+			return envBind(v, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if v.GetBool(config.Prof.String()) {
+				f, err := os.Create("cpu.prof")
+				if err != nil {
+					return err
+				}
+				pprof.StartCPUProfile(f)
+				defer pprof.StopCPUProfile()
 			}
-			pprof.StartCPUProfile(f)
-			defer pprof.StopCPUProfile()
-		}
 
-		return executeGlove()
-	},
-}
+			return executeGlove(v, cmd.OutOrStdout())
+		},
+	}
 
-func init() {
-	GloveCmd.Flags().AddFlagSet(ConfigFlagSet())
-	GloveCmd.Flags().String(config.Solver.String(), config.DefaultSolver,
+	cmd.Flags().AddFlagSet(ConfigFlagSet())
+	cmd.Flags().String(config.Solver.String(), config.DefaultSolver,
 		"solver for GloVe objective. One of: sgd|adagrad")
-	GloveCmd.Flags().Int(config.Xmax.String(), config.DefaultXmax,
+	cmd.Flags().Int(config.Xmax.String(), config.DefaultXmax,
 		"specifying cutoff in weighting function")
-	GloveCmd.Flags().Float64(config.Alpha.String(), config.DefaultAlpha,
+	cmd.Flags().Float64(config.Alpha.String(), config.DefaultAlpha,
 		"exponent of weighting function")
+	cmd.Flags().Float64(config.SubsampleThreshold.String(), config.DefaultGloveSubsampleThreshold,
+		"threshold for subsampling on counting co-occurrences, threshold=0 means no subsampling")
+	cmd.Flags().String(config.CooccurrenceFile.String(), config.DefaultCooccurrenceFile,
+		"co-occurrence file path to train on instead of counting on corpus, e.g. cooccur.bin of Stanford GloVe")
+	cmd.Flags().String(config.CooccurrenceFormat.String(), config.DefaultCooccurrenceFormat,
+		"format of co-occurrence file. One of: stanford|text")
+	cmd.Flags().String(config.CooccurrenceVocab.String(), config.DefaultCooccurrenceVocab,
+		"vocabulary file path of co-occurrence file whose lines are \"word frequency\", e.g. vocab.txt of Stanford GloVe")
+	cmd.Flags().String(config.CheckpointDir.String(), config.DefaultCheckpointDir,
+		"directory to save checkpoint of training, checkpoint-dir=\"\" means no checkpoint")
+	cmd.Flags().Int(config.CheckpointEvery.String(), config.DefaultCheckpointEvery,
+		"interval of iterations to save checkpoint")
+	cmd.Flags().String(config.ResumeFrom.String(), config.DefaultResumeFrom,
+		"checkpoint file path to resume training from")
+	return cmd
 }
 
-func gloveBind(cmd *cobra.Command) {
-	viper.BindPFlag(config.Solver.String(), cmd.Flags().Lookup(config.Solver.String()))
-	viper.BindPFlag(config.Xmax.String(), cmd.Flags().Lookup(config.Xmax.String()))
-	viper.BindPFlag(config.Alpha.String(), cmd.Flags().Lookup(config.Alpha.String()))
+func gloveBind(v *viper.Viper, cmd *cobra.Command) {
+	v.BindPFlag(config.Solver.String(), cmd.Flags().Lookup(config.Solver.String()))
+	v.BindPFlag(config.Xmax.String(), cmd.Flags().Lookup(config.Xmax.String()))
+	v.BindPFlag(config.Alpha.String(), cmd.Flags().Lookup(config.Alpha.String()))
+	v.BindPFlag(config.SubsampleThreshold.String(), cmd.Flags().Lookup(config.SubsampleThreshold.String()))
+	v.BindPFlag(config.CooccurrenceFile.String(), cmd.Flags().Lookup(config.CooccurrenceFile.String()))
+	v.BindPFlag(config.CooccurrenceFormat.String(), cmd.Flags().Lookup(config.CooccurrenceFormat.String()))
+	v.BindPFlag(config.CooccurrenceVocab.String(), cmd.Flags().Lookup(config.CooccurrenceVocab.String()))
+	v.BindPFlag(config.CheckpointDir.String(), cmd.Flags().Lookup(config.CheckpointDir.String()))
+	v.BindPFlag(config.CheckpointEvery.String(), cmd.Flags().Lookup(config.CheckpointEvery.String()))
+	v.BindPFlag(config.ResumeFrom.String(), cmd.Flags().Lookup(config.ResumeFrom.String()))
 }
 
-func executeGlove() error {
-	outputFile := viper.GetString(config.OutputFile.String())
+func executeGlove(v *viper.Viper, out io.Writer) error {
+	outputFile := v.GetString(config.OutputFile.String())
 	if validate.FileExists(outputFile) {
 		return errors.Errorf("%s is already existed", outputFile)
 	}
 
-	glove := builder.NewGloveBuilderFromViper()
-	mod, err := glove.Build()
+	glove := builder.NewGloveBuilderWithViper(v)
+	mod, err := glove.BuildAndTrain()
 	if err != nil {
 		return err
 	}
-	if err := mod.Train(); err != nil {
-		return err
+	if v.GetBool(config.Verbose.String()) {
+		fmt.Fprintf(out, "Summary: %v\n", mod.Summary())
 	}
 	return mod.Save(outputFile)
 }
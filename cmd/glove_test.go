@@ -20,27 +20,30 @@ import (
 	"github.com/spf13/viper"
 )
 
-const gloveFlagSize = 3
+const gloveFlagSize = 10
 
 func TestGloveBind(t *testing.T) {
-	defer viper.Reset()
+	v := viper.New()
 
-	gloveBind(GloveCmd)
+	gloveBind(v, NewGloveCmd(v))
 
-	if len(viper.AllKeys()) != gloveFlagSize {
+	if len(v.AllKeys()) != gloveFlagSize {
 		t.Errorf("Expected gloveBind maps %v keys: %v",
-			gloveFlagSize, viper.AllKeys())
+			gloveFlagSize, v.AllKeys())
 	}
 }
 
 func TestGloveCmdPreRun(t *testing.T) {
-	defer viper.Reset()
+	v := viper.New()
 
 	var empty []string
-	GloveCmd.PreRun(GloveCmd, empty)
+	cmd := NewGloveCmd(v)
+	if err := cmd.PreRunE(cmd, empty); err != nil {
+		t.Fatal(err)
+	}
 
-	if len(viper.AllKeys()) != gloveFlagSize+configFlagSize {
+	if len(v.AllKeys()) != gloveFlagSize+configFlagSize {
 		t.Errorf("Expected PreRun of GloveCmd maps %v keys: %v",
-			gloveFlagSize+configFlagSize, viper.AllKeys())
+			gloveFlagSize+configFlagSize, v.AllKeys())
 	}
 }
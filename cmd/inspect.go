@@ -0,0 +1,105 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ynqa/wego/config"
+	"github.com/ynqa/wego/export"
+	"github.com/ynqa/wego/vectorio"
+)
+
+// NewInspectCmd creates the subcommand to report statistics of trained word vectors.
+func NewInspectCmd(v *viper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "inspect",
+		Short: "Report statistics of trained word vectors",
+		Long: "Report per-dimension mean/std/min/max, percentiles of norms, zero vectors and vectors with NaN/Inf " +
+			"of trained word vectors in a single pass, e.g. to diagnose dead dimensions or exploding norms",
+		Example: "  wego inspect -i example/word_vectors.txt --format json",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			inspectBind(v, cmd)
+			return envBind(v, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeInspect(v, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringP(config.InputFile.String(), "i", config.DefaultInputFile,
+		"input file path for trained word vector")
+	cmd.Flags().String(config.InputFormat.String(), config.DefaultInputFormat,
+		"format of input file. One of: text|binary|fasttext-vec")
+	cmd.Flags().String(config.Format.String(), config.DefaultInspectFormat,
+		"format to report statistics. One of: text|json")
+	return cmd
+}
+
+func inspectBind(v *viper.Viper, cmd *cobra.Command) {
+	v.BindPFlag(config.InputFile.String(), cmd.Flags().Lookup(config.InputFile.String()))
+	v.BindPFlag(config.InputFormat.String(), cmd.Flags().Lookup(config.InputFormat.String()))
+	v.BindPFlag(config.Format.String(), cmd.Flags().Lookup(config.Format.String()))
+}
+
+func executeInspect(v *viper.Viper, out io.Writer) error {
+	inputFile := v.GetString(config.InputFile.String())
+	format := v.GetString(config.Format.String())
+
+	switch format {
+	case "text", "json":
+	default:
+		return errors.Errorf("Invalid format: %s not in text|json", format)
+	}
+
+	input, err := os.Open(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	r, err := vectorio.NewReader(bufio.NewReader(input), v.GetString(config.InputFormat.String()))
+	if err != nil {
+		return err
+	}
+
+	report, err := inspect(r)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to inspect %s", inputFile)
+	}
+	return export.WriteStatsReport(out, report, format)
+}
+
+// inspect streams word vectors of r into StatsReport.
+func inspect(r vectorio.Reader) (*export.StatsReport, error) {
+	s := export.NewStatsCollector()
+	for {
+		word, vec, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if err := s.Add(word, vec); err != nil {
+			return nil, err
+		}
+	}
+	return s.Report(), nil
+}
@@ -0,0 +1,47 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/ynqa/wego/vectorio"
+)
+
+const inspectFlagSize = 3
+
+func TestInspectBind(t *testing.T) {
+	v := viper.New()
+
+	inspectBind(v, NewInspectCmd(v))
+
+	if len(v.AllKeys()) != inspectFlagSize {
+		t.Errorf("Expected inspectBind maps %v keys: %v",
+			inspectFlagSize, v.AllKeys())
+	}
+}
+
+func TestInspect(t *testing.T) {
+	report, err := inspect(vectorio.NewTextReader(strings.NewReader("a 1 0\nb 0 0\nc NaN 1\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Words != 3 || report.ZeroVectors != 1 || len(report.NonFinite) != 1 {
+		t.Errorf("Expected 3 words with a zero vector and a vector with NaN: %+v", report)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ynqa/wego/config"
+	"github.com/ynqa/wego/distance"
+)
+
+// NewNearestWordsCmd creates the subcommand to search the most similar words to each of external vectors.
+func NewNearestWordsCmd(v *viper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "nearest-words",
+		Short: "Search the most similar words to each of external vectors",
+		Long: "Search the most similar words to each of external vectors, e.g. centroids of clustered sentence " +
+			"embeddings, whose JSONL lines are {\"id\": ..., \"vector\": [...]}, and output a JSONL line of neighbors per id",
+		Example: "  wego nearest-words -i example/word_vectors.txt --vectors centroids.jsonl -k 5",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			nearestWordsBind(v, cmd)
+			return envBind(v, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeNearestWords(v, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringP(config.InputFile.String(), "i", config.DefaultInputFile,
+		"input file path for trained word vector")
+	cmd.Flags().String(config.QueryVectors.String(), config.DefaultQueryVectors,
+		"JSONL file path of vectors to search whose lines are {\"id\": ..., \"vector\": [...]}")
+	cmd.Flags().IntP(config.Rank.String(), "k", config.DefaultNearestRank,
+		"how many the most similar words are searched per vector")
+	cmd.Flags().Int(config.Workers.String(), config.DefaultWorkers,
+		"number of goroutines to search vectors in parallel")
+	return cmd
+}
+
+func nearestWordsBind(v *viper.Viper, cmd *cobra.Command) {
+	v.BindPFlag(config.InputFile.String(), cmd.Flags().Lookup(config.InputFile.String()))
+	v.BindPFlag(config.QueryVectors.String(), cmd.Flags().Lookup(config.QueryVectors.String()))
+	v.BindPFlag(config.Rank.String(), cmd.Flags().Lookup(config.Rank.String()))
+	v.BindPFlag(config.Workers.String(), cmd.Flags().Lookup(config.Workers.String()))
+}
+
+func executeNearestWords(v *viper.Viper, out io.Writer) error {
+	queryFile := v.GetString(config.QueryVectors.String())
+	if queryFile == "" {
+		return errors.New("Vectors file is required")
+	}
+	q, err := os.Open(queryFile)
+	if err != nil {
+		return err
+	}
+	defer q.Close()
+	queries, err := distance.ReadQueries(q)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(v.GetString(config.InputFile.String()))
+	if err != nil {
+		return err
+	}
+	est := distance.NewEstimator("", v.GetInt(config.Rank.String()))
+	if err := est.Estimate(f); err != nil {
+		return err
+	}
+	results, err := est.SearchBatch(queries, v.GetInt(config.Rank.String()), v.GetInt(config.Workers.String()))
+	if err != nil {
+		return err
+	}
+	return writeNeighbors(out, queries, results)
+}
+
+type neighbor struct {
+	Word       string  `json:"word"`
+	Similarity float64 `json:"similarity"`
+}
+
+// writeNeighbors writes a JSONL line of the similar words per query, i.e. {"id": ..., "neighbors": [...]}.
+func writeNeighbors(w io.Writer, queries []distance.Query, results []distance.Measures) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for i, q := range queries {
+		neighbors := make([]neighbor, len(results[i]))
+		for j, m := range results[i] {
+			neighbors[j] = neighbor{Word: m.Word(), Similarity: m.Similarity()}
+		}
+		if err := enc.Encode(struct {
+			ID        string     `json:"id"`
+			Neighbors []neighbor `json:"neighbors"`
+		}{q.ID, neighbors}); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,59 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"github.com/ynqa/wego/distance"
+)
+
+const nearestWordsFlagSize = 4
+
+func TestNearestWordsBind(t *testing.T) {
+	v := viper.New()
+
+	nearestWordsBind(v, NewNearestWordsCmd(v))
+
+	if len(v.AllKeys()) != nearestWordsFlagSize {
+		t.Errorf("Expected nearestWordsBind maps %v keys: %v",
+			nearestWordsFlagSize, v.AllKeys())
+	}
+}
+
+func TestWriteNeighbors(t *testing.T) {
+	est := distance.NewEstimator("", 1)
+	if err := est.Estimate(ioutil.NopCloser(strings.NewReader("a 1 0\nb 0 1\n"))); err != nil {
+		t.Fatal(err)
+	}
+	queries := []distance.Query{{ID: "c1", Vector: []float64{0.1, 1}}}
+	results, err := est.SearchBatch(queries, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeNeighbors(&buf, queries, results); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), `{"id":"c1","neighbors":[{"word":"b","similarity":`) {
+		t.Errorf("Expected b to be the nearest word of c1: %v", buf.String())
+	}
+}
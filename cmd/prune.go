@@ -0,0 +1,141 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ynqa/wego/config"
+	"github.com/ynqa/wego/export"
+	"github.com/ynqa/wego/validate"
+	"github.com/ynqa/wego/vectorio"
+)
+
+// NewPruneCmd creates the subcommand to prune vocabulary of trained word vectors.
+func NewPruneCmd(v *viper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "prune",
+		Short:   "Prune vocabulary of trained word vectors",
+		Long:    "Prune vocabulary of trained word vectors to the most frequent words and the words to keep",
+		Example: "  wego prune -i example/word_vectors.txt --vocab vocab.txt --top 100000 --keep keep.txt -o small.txt",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			pruneBind(v, cmd)
+			return envBind(v, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executePrune(v, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringP(config.InputFile.String(), "i", config.DefaultInputFile,
+		"input file path for trained word vector")
+	cmd.Flags().StringP(config.OutputFile.String(), "o", config.DefaultOutputFile,
+		"output file path to save pruned word vectors")
+	cmd.Flags().Int(config.Top.String(), config.DefaultTop,
+		"number of the most frequent words to keep")
+	cmd.Flags().String(config.Keep.String(), config.DefaultKeep,
+		"file path for the list of words to keep regardless of frequency (one word per line)")
+	cmd.Flags().String(config.Vocab.String(), config.DefaultVocab,
+		"vocabulary file path whose lines are \"word frequency\"")
+	return cmd
+}
+
+func pruneBind(v *viper.Viper, cmd *cobra.Command) {
+	v.BindPFlag(config.InputFile.String(), cmd.Flags().Lookup(config.InputFile.String()))
+	v.BindPFlag(config.OutputFile.String(), cmd.Flags().Lookup(config.OutputFile.String()))
+	v.BindPFlag(config.Top.String(), cmd.Flags().Lookup(config.Top.String()))
+	v.BindPFlag(config.Keep.String(), cmd.Flags().Lookup(config.Keep.String()))
+	v.BindPFlag(config.Vocab.String(), cmd.Flags().Lookup(config.Vocab.String()))
+}
+
+func executePrune(v *viper.Viper, out io.Writer) error {
+	inputFile := v.GetString(config.InputFile.String())
+	outputFile := v.GetString(config.OutputFile.String())
+	vocabFile := v.GetString(config.Vocab.String())
+	keepFile := v.GetString(config.Keep.String())
+
+	if vocabFile == "" {
+		return errors.Errorf("--%s is required for frequency of words", config.Vocab.String())
+	}
+	if validate.FileExists(outputFile) {
+		return errors.Errorf("%s is already existed", outputFile)
+	}
+
+	input, err := os.Open(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	vectors, err := vectorio.ReadText(input)
+	if err != nil {
+		return err
+	}
+
+	vocab, err := os.Open(vocabFile)
+	if err != nil {
+		return err
+	}
+	defer vocab.Close()
+	freqs, err := export.ReadVocab(vocab)
+	if err != nil {
+		return err
+	}
+
+	keep := make([]string, 0)
+	if keepFile != "" {
+		k, err := os.Open(keepFile)
+		if err != nil {
+			return err
+		}
+		defer k.Close()
+		scanner := bufio.NewScanner(k)
+		for scanner.Scan() {
+			if word := strings.TrimSpace(scanner.Text()); word != "" {
+				keep = append(keep, word)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return errors.Wrap(err, "Unable to complete scanning")
+		}
+	}
+
+	pruned, missing := export.Prune(vectors, freqs, export.PruneOptions{
+		Top:  v.GetInt(config.Top.String()),
+		Keep: keep,
+	})
+
+	output, err := os.Create(outputFile)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+	if err := vectorio.WriteText(output, pruned); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Pruned: %d -> %d words\n", len(vectors.Words), len(pruned.Words))
+	if len(missing) > 0 {
+		fmt.Fprintf(out, "Missing words to keep: %v\n", missing)
+	}
+	return nil
+}
@@ -23,18 +23,39 @@ import (
 	"github.com/ynqa/wego/config"
 )
 
-// RootCmd is the root command for word embedding.
-var RootCmd = &cobra.Command{
-	Use:   "wego",
-	Short: "tools for embedding words into vector space",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return errors.New("Set sub-command. One of distance|word2vec|glove")
-	},
+// NewRootCmd creates the root command for word embedding with all sub-commands. Each sub-command binds its flags
+// to its own viper, not the global one, so that commands are executed in the same process, e.g. in tests,
+// or mounted under another root command by AddCommand of NewXxxCmd(viper.New()).
+func NewRootCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wego",
+		Short: "tools for embedding words into vector space",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return errors.New("Set sub-command. One of distance|word2vec|glove|export|prune|cooccur|average|coverage|convert|classify-train|classify-predict|inspect|nearest-words|dedupe")
+		},
+	}
+	cmd.AddCommand(NewWord2vecCmd(viper.New()))
+	cmd.AddCommand(NewDistanceCmd(viper.New()))
+	cmd.AddCommand(NewGloveCmd(viper.New()))
+	cmd.AddCommand(NewExportCmd(viper.New()))
+	cmd.AddCommand(NewPruneCmd(viper.New()))
+	cmd.AddCommand(NewCooccurCmd(viper.New()))
+	cmd.AddCommand(NewAverageCmd(viper.New()))
+	cmd.AddCommand(NewCoverageCmd(viper.New()))
+	cmd.AddCommand(NewConvertCmd(viper.New()))
+	cmd.AddCommand(NewClassifyTrainCmd(viper.New()))
+	cmd.AddCommand(NewClassifyPredictCmd(viper.New()))
+	cmd.AddCommand(NewInspectCmd(viper.New()))
+	cmd.AddCommand(NewNearestWordsCmd(viper.New()))
+	cmd.AddCommand(NewDedupeCmd(viper.New()))
+	cmd.AddCommand(NewCanonicalizeCmd(viper.New()))
+	cmd.AddCommand(NewVocabCmd(viper.New()))
+	return cmd
 }
 
 // ConfigFlagSet creates the common config flags.
 func ConfigFlagSet() *pflag.FlagSet {
-	fs := pflag.NewFlagSet(RootCmd.Name(), pflag.ExitOnError)
+	fs := pflag.NewFlagSet("wego", pflag.ContinueOnError)
 	fs.StringP(config.InputFile.String(), "i", config.DefaultInputFile,
 		"input file path for corpus")
 	fs.StringP(config.OutputFile.String(), "o", config.DefaultOutputFile,
@@ -46,7 +67,7 @@ func ConfigFlagSet() *pflag.FlagSet {
 	fs.Int(config.MinCount.String(), config.DefaultMinCount,
 		"lower limit to filter rare words")
 	fs.Int(config.ThreadSize.String(), config.DefaultThreadSize,
-		"number of goroutine")
+		"number of goroutine, thread=0 means to choose it automatically")
 	fs.IntP(config.Window.String(), "w", config.DefaultWindow,
 		"context window size")
 	fs.Float64(config.Initlr.String(), config.DefaultInitlr,
@@ -57,25 +78,67 @@ func ConfigFlagSet() *pflag.FlagSet {
 		"whether the words on corpus convert to lowercase or not")
 	fs.Bool(config.Verbose.String(), config.DefaultVerbose,
 		"verbose mode")
+	fs.String(config.OutputFormat.String(), config.DefaultOutputFormat,
+		"format to save word vectors. One of: text|binary|json|npy|fasttext-vec")
+	fs.String(config.SanitizeUTF8.String(), config.DefaultSanitizeUTF8,
+		"how to treat invalid UTF-8 sequences in corpus. One of: none|replace|skip")
+	fs.Int64(config.MaxTokens.String(), config.DefaultMaxTokens,
+		"limit of tokens to train on per iteration, max-tokens=0 means no limit")
+	fs.Int64(config.MaxVocabTokens.String(), config.DefaultMaxVocabTokens,
+		"limit of tokens to read from corpus to build vocabulary, max-vocab-tokens=0 means no limit")
+	fs.StringSlice(config.Scripts.String(), nil,
+		"scripts to keep tokens predominantly in them, e.g. latin,cyrillic,han (default all tokens)")
+	fs.Float64(config.ScriptThreshold.String(), config.DefaultScriptThreshold,
+		"fraction of runes in the scripts for tokens to keep")
+	fs.Int(config.ReadRetries.String(), config.DefaultReadRetries,
+		"times to retry reading corpus on transient errors, read-retries=0 means no retry")
+	fs.Duration(config.ReadRetryDelay.String(), config.DefaultReadRetryDelay,
+		"delay before the first retry of reading corpus, which doubles on each retry")
+	fs.String(config.SaveFormat.String(), config.DefaultSaveFormat,
+		"style to format values of word vectors in text format. One of: fixed|scientific|shortest")
+	fs.Int(config.SavePrecision.String(), config.DefaultSavePrecision,
+		"digits to format values of word vectors in text format, save-precision=-1 means the minimal digits to represent them exactly")
+	fs.Float64(config.MinCoverage.String(), config.DefaultMinCoverage,
+		"fraction of token occurrences for vocabulary to cover, which overrides min-count if it is positive")
+	fs.Bool(config.CheckOutput.String(), config.DefaultCheckOutput,
+		"whether to validate the directory of output file is writable before training")
+	fs.Bool(config.SplitHyphens.String(), config.DefaultSplitHyphens,
+		"whether to split tokens on hyphens, e.g. state-of-the-art into state, of, the and art")
+	fs.Bool(config.SplitApostrophes.String(), config.DefaultSplitApostrophes,
+		"whether to split tokens on apostrophes, e.g. don't into don and t")
+	fs.Uint64(config.Seed.String(), config.DefaultSeed,
+		"master seed of the run to derive the seeds of threads from, seed=0 means to generate it")
+	fs.StringSlice(config.SpecialTokens.String(), nil,
+		"special tokens always in vocabulary with the reserved ids from 0 in order, e.g. <pad>,<bos>,<eos>")
 	return fs
 }
 
-func configBind(cmd *cobra.Command) {
-	viper.BindPFlag(config.InputFile.String(), cmd.Flags().Lookup(config.InputFile.String()))
-	viper.BindPFlag(config.OutputFile.String(), cmd.Flags().Lookup(config.OutputFile.String()))
-	viper.BindPFlag(config.Dimension.String(), cmd.Flags().Lookup(config.Dimension.String()))
-	viper.BindPFlag(config.Iteration.String(), cmd.Flags().Lookup(config.Iteration.String()))
-	viper.BindPFlag(config.MinCount.String(), cmd.Flags().Lookup(config.MinCount.String()))
-	viper.BindPFlag(config.ThreadSize.String(), cmd.Flags().Lookup(config.ThreadSize.String()))
-	viper.BindPFlag(config.Window.String(), cmd.Flags().Lookup(config.Window.String()))
-	viper.BindPFlag(config.Initlr.String(), cmd.Flags().Lookup(config.Initlr.String()))
-	viper.BindPFlag(config.Prof.String(), cmd.Flags().Lookup(config.Prof.String()))
-	viper.BindPFlag(config.ToLower.String(), cmd.Flags().Lookup(config.ToLower.String()))
-	viper.BindPFlag(config.Verbose.String(), cmd.Flags().Lookup(config.Verbose.String()))
-}
-
-func init() {
-	RootCmd.AddCommand(Word2vecCmd)
-	RootCmd.AddCommand(DistanceCmd)
-	RootCmd.AddCommand(GloveCmd)
+func configBind(v *viper.Viper, cmd *cobra.Command) {
+	v.BindPFlag(config.InputFile.String(), cmd.Flags().Lookup(config.InputFile.String()))
+	v.BindPFlag(config.OutputFile.String(), cmd.Flags().Lookup(config.OutputFile.String()))
+	v.BindPFlag(config.Dimension.String(), cmd.Flags().Lookup(config.Dimension.String()))
+	v.BindPFlag(config.Iteration.String(), cmd.Flags().Lookup(config.Iteration.String()))
+	v.BindPFlag(config.MinCount.String(), cmd.Flags().Lookup(config.MinCount.String()))
+	v.BindPFlag(config.ThreadSize.String(), cmd.Flags().Lookup(config.ThreadSize.String()))
+	v.BindPFlag(config.Window.String(), cmd.Flags().Lookup(config.Window.String()))
+	v.BindPFlag(config.Initlr.String(), cmd.Flags().Lookup(config.Initlr.String()))
+	v.BindPFlag(config.Prof.String(), cmd.Flags().Lookup(config.Prof.String()))
+	v.BindPFlag(config.ToLower.String(), cmd.Flags().Lookup(config.ToLower.String()))
+	v.BindPFlag(config.Verbose.String(), cmd.Flags().Lookup(config.Verbose.String()))
+	v.BindPFlag(config.OutputFormat.String(), cmd.Flags().Lookup(config.OutputFormat.String()))
+	v.BindPFlag(config.SanitizeUTF8.String(), cmd.Flags().Lookup(config.SanitizeUTF8.String()))
+	v.BindPFlag(config.MaxTokens.String(), cmd.Flags().Lookup(config.MaxTokens.String()))
+	v.BindPFlag(config.MaxVocabTokens.String(), cmd.Flags().Lookup(config.MaxVocabTokens.String()))
+	v.BindPFlag(config.Scripts.String(), cmd.Flags().Lookup(config.Scripts.String()))
+	v.BindPFlag(config.ScriptThreshold.String(), cmd.Flags().Lookup(config.ScriptThreshold.String()))
+	v.BindPFlag(config.ReadRetries.String(), cmd.Flags().Lookup(config.ReadRetries.String()))
+	v.BindPFlag(config.ReadRetryDelay.String(), cmd.Flags().Lookup(config.ReadRetryDelay.String()))
+	v.BindPFlag(config.SaveFormat.String(), cmd.Flags().Lookup(config.SaveFormat.String()))
+	v.BindPFlag(config.SavePrecision.String(), cmd.Flags().Lookup(config.SavePrecision.String()))
+	v.BindPFlag(config.MinCoverage.String(), cmd.Flags().Lookup(config.MinCoverage.String()))
+	v.BindPFlag(config.CheckOutput.String(), cmd.Flags().Lookup(config.CheckOutput.String()))
+	v.BindPFlag(config.SplitHyphens.String(), cmd.Flags().Lookup(config.SplitHyphens.String()))
+	v.BindPFlag(config.SplitApostrophes.String(), cmd.Flags().Lookup(config.SplitApostrophes.String()))
+	v.BindPFlag(config.Seed.String(), cmd.Flags().Lookup(config.Seed.String()))
+	v.BindPFlag(config.SpecialTokens.String(), cmd.Flags().Lookup(config.SpecialTokens.String()))
 }
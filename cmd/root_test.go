@@ -21,7 +21,7 @@ import (
 	"github.com/spf13/viper"
 )
 
-const configFlagSize = 11
+const configFlagSize = 27
 
 func TestConfigFlagSet(t *testing.T) {
 	fs := ConfigFlagSet()
@@ -32,14 +32,14 @@ func TestConfigFlagSet(t *testing.T) {
 }
 
 func TestConfigBind(t *testing.T) {
-	defer viper.Reset()
+	v := viper.New()
 
 	config := &cobra.Command{}
 	config.Flags().AddFlagSet(ConfigFlagSet())
-	configBind(config)
+	configBind(v, config)
 
-	if len(viper.AllKeys()) != configFlagSize {
+	if len(v.AllKeys()) != configFlagSize {
 		t.Errorf("Expected configBind maps %v keys: %v",
-			configFlagSize, viper.AllKeys())
+			configFlagSize, v.AllKeys())
 	}
 }
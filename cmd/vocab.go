@@ -0,0 +1,123 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/ynqa/wego/config"
+	"github.com/ynqa/wego/corpus"
+	"github.com/ynqa/wego/export"
+	"github.com/ynqa/wego/validate"
+)
+
+// NewVocabCmd creates the subcommand to count words on corpus without training.
+func NewVocabCmd(v *viper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vocab",
+		Short: "Count words on corpus without training",
+		Long: "Count words on corpus tokenized in the same way as training, and save vocabulary or frequency spectrum, " +
+			"along with the tokens removed by candidates of min-count and subsample threshold, e.g. to choose them before training",
+		Example: "  wego vocab -i example/input.txt -o vocab.txt\n" +
+			"  wego vocab -i example/input.txt --spectrum -o spectrum.csv",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			vocabBind(v, cmd)
+			return envBind(v, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return executeVocab(v, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringP(config.InputFile.String(), "i", config.DefaultInputFile,
+		"input file path for corpus")
+	cmd.Flags().StringP(config.OutputFile.String(), "o", config.DefaultSaveVocab,
+		"output file path to save vocabulary whose lines are \"word frequency\", or spectrum with --spectrum")
+	cmd.Flags().Bool(config.Spectrum.String(), config.DefaultSpectrum,
+		"whether to save frequency spectrum as CSV of \"rank,frequency,cumulative_share\" instead of vocabulary")
+	cmd.Flags().Bool(config.ToLower.String(), config.DefaultToLower,
+		"whether the words on corpus convert to lowercase or not")
+	cmd.Flags().String(config.SanitizeUTF8.String(), config.DefaultSanitizeUTF8,
+		"how to treat invalid UTF-8 sequences in corpus. One of: none|replace|skip")
+	cmd.Flags().StringSlice(config.Scripts.String(), nil,
+		"scripts to keep tokens predominantly in them, e.g. latin,cyrillic,han (default all tokens)")
+	cmd.Flags().Float64(config.ScriptThreshold.String(), config.DefaultScriptThreshold,
+		"fraction of runes in the scripts for tokens to keep")
+	cmd.Flags().Bool(config.SplitHyphens.String(), config.DefaultSplitHyphens,
+		"whether to split tokens on hyphens, e.g. state-of-the-art into state, of, the and art")
+	cmd.Flags().Bool(config.SplitApostrophes.String(), config.DefaultSplitApostrophes,
+		"whether to split tokens on apostrophes, e.g. don't into don and t")
+	return cmd
+}
+
+func vocabBind(v *viper.Viper, cmd *cobra.Command) {
+	v.BindPFlag(config.InputFile.String(), cmd.Flags().Lookup(config.InputFile.String()))
+	v.BindPFlag(config.OutputFile.String(), cmd.Flags().Lookup(config.OutputFile.String()))
+	v.BindPFlag(config.Spectrum.String(), cmd.Flags().Lookup(config.Spectrum.String()))
+	v.BindPFlag(config.ToLower.String(), cmd.Flags().Lookup(config.ToLower.String()))
+	v.BindPFlag(config.SanitizeUTF8.String(), cmd.Flags().Lookup(config.SanitizeUTF8.String()))
+	v.BindPFlag(config.Scripts.String(), cmd.Flags().Lookup(config.Scripts.String()))
+	v.BindPFlag(config.ScriptThreshold.String(), cmd.Flags().Lookup(config.ScriptThreshold.String()))
+	v.BindPFlag(config.SplitHyphens.String(), cmd.Flags().Lookup(config.SplitHyphens.String()))
+	v.BindPFlag(config.SplitApostrophes.String(), cmd.Flags().Lookup(config.SplitApostrophes.String()))
+}
+
+func executeVocab(v *viper.Viper, out io.Writer) error {
+	inputFile := v.GetString(config.InputFile.String())
+	outputFile := v.GetString(config.OutputFile.String())
+	if validate.FileExists(outputFile) {
+		return errors.Errorf("%s is already existed", outputFile)
+	}
+
+	f, err := os.Open(inputFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	freqs := make(map[string]int)
+	if err := corpus.ScanTokens(f, corpus.ParseConfig{
+		ToLower:         v.GetBool(config.ToLower.String()),
+		Sanitize:        v.GetString(config.SanitizeUTF8.String()),
+		Scripts:         v.GetStringSlice(config.Scripts.String()),
+		ScriptThreshold: v.GetFloat64(config.ScriptThreshold.String()),
+
+		SplitHyphens:     v.GetBool(config.SplitHyphens.String()),
+		SplitApostrophes: v.GetBool(config.SplitApostrophes.String()),
+	}, func(word string) {
+		freqs[word]++
+	}); err != nil {
+		return err
+	}
+
+	report := export.FrequencySpectrum(freqs)
+	write := func(w io.Writer) error {
+		return export.WriteRankedVocab(w, report)
+	}
+	if v.GetBool(config.Spectrum.String()) {
+		write = func(w io.Writer) error {
+			return export.WriteSpectrum(w, report)
+		}
+	}
+	if err := saveTo(outputFile, write); err != nil {
+		return err
+	}
+	export.WriteSpectrumSummary(out, report)
+	return nil
+}
@@ -15,6 +15,8 @@
 package cmd
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"runtime/pprof"
 
@@ -27,69 +29,122 @@ import (
 	"github.com/ynqa/wego/validate"
 )
 
-// Word2vecCmd is the subcommand for Word2vec.
-var Word2vecCmd = &cobra.Command{
-	Use:   "word2vec",
-	Short: "Word2Vec: Continuous Bag-of-Words and Skip-gram model",
-	PreRun: func(cmd *cobra.Command, args []string) {
-		configBind(cmd)
-		word2vecBind(cmd)
-	},
-	RunE: func(cmd *cobra.Command, args []string) error {
-		if viper.GetBool(config.Prof.String()) {
-			f, err := os.Create("cpu.prof")
-			if err != nil {
-				os.Exit(1)
+// NewWord2vecCmd creates the subcommand for Word2vec.
+func NewWord2vecCmd(v *viper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "word2vec",
+		Short: "Word2Vec: Continuous Bag-of-Words and Skip-gram model",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			configBind(v, cmd)
+			word2vecBind(v, cmd)
+			return envBind(v, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if v.GetBool(config.Prof.String()) {
+				f, err := os.Create("cpu.prof")
+				if err != nil {
+					return err
+				}
+				pprof.StartCPUProfile(f)
+				defer pprof.StopCPUProfile()
 			}
-			pprof.StartCPUProfile(f)
-			defer pprof.StopCPUProfile()
-		}
 
-		return executeWord2vec()
-	},
-}
+			return executeWord2vec(v, cmd.OutOrStdout())
+		},
+	}
 
-func init() {
-	Word2vecCmd.Flags().AddFlagSet(ConfigFlagSet())
-	Word2vecCmd.Flags().String(config.Model.String(), config.DefaultModel,
+	cmd.Flags().AddFlagSet(ConfigFlagSet())
+	cmd.Flags().String(config.Model.String(), config.DefaultModel,
 		"which model does it use? one of: cbow|skip-gram")
-	Word2vecCmd.Flags().String(config.Optimizer.String(), config.DefaultOptimizer,
+	cmd.Flags().String(config.Optimizer.String(), config.DefaultOptimizer,
 		"which optimizer does it use? one of: hs|ns")
-	Word2vecCmd.Flags().Int(config.BatchSize.String(), config.DefaultBatchSize,
-		"interval word size to update learning rate")
-	Word2vecCmd.Flags().Int(config.MaxDepth.String(), config.DefaultMaxDepth,
+	cmd.Flags().Int(config.BatchSize.String(), config.DefaultBatchSize,
+		"interval word size to update learning rate, which is capped to the tokens of corpus")
+	cmd.Flags().Int(config.MaxDepth.String(), config.DefaultMaxDepth,
 		"times to track huffman tree, max-depth=0 means to track full path from root to word (for hierarchical softmax only)")
-	Word2vecCmd.Flags().Int(config.NegativeSampleSize.String(), config.DefaultNegativeSampleSize,
+	cmd.Flags().Int(config.NegativeSampleSize.String(), config.DefaultNegativeSampleSize,
 		"negative sample size(for negative sampling only)")
-	Word2vecCmd.Flags().Float64(config.SubsampleThreshold.String(), config.DefaultSubsampleThreshold,
-		"threshold for subsampling")
-	Word2vecCmd.Flags().Float64(config.Theta.String(), config.DefaultTheta,
+	cmd.Flags().Float64(config.SubsampleThreshold.String(), config.DefaultSubsampleThreshold,
+		"threshold for subsampling, threshold=0 means no subsampling")
+	cmd.Flags().Float64(config.Theta.String(), config.DefaultTheta,
 		"lower limit of learning rate (lr >= initlr * theta)")
+	cmd.Flags().Bool(config.ExcludeSelfContext.String(), config.DefaultExcludeSelfContext,
+		"whether the other occurrences of the target word in the window are excluded from context")
+	cmd.Flags().Bool(config.CbowMean.String(), config.DefaultCbowMean,
+		"whether the hidden layer of cbow is the average of context vectors or their sum (for cbow only)")
+	cmd.Flags().Bool(config.SwapRoles.String(), config.DefaultSwapRoles,
+		"whether the target word is the input to predict context words, instead of the reverse (for skip-gram only)")
+	cmd.Flags().String(config.PretrainedVectors.String(), config.DefaultPretrainedVectors,
+		"file path of pretrained word vectors to initialize words' vector")
+	cmd.Flags().String(config.TrainOnly.String(), config.DefaultTrainOnly,
+		"train only one side of vectors and freeze the other. One of: input|context (context requires pretrainedVectors)")
+	cmd.Flags().String(config.SyncMode.String(), config.DefaultSyncMode,
+		"how threads update the shared vectors. One of: hogwild|periodic")
+	cmd.Flags().Int(config.SyncInterval.String(), config.DefaultSyncInterval,
+		"interval of sentences for each thread to merge its updates (for periodic sync mode only)")
+	cmd.Flags().Bool(config.ShuffleSentences.String(), config.DefaultShuffleSentences,
+		"whether to shuffle sentences, i.e. lines of corpus, every iteration")
+	cmd.Flags().String(config.TreeFile.String(), config.DefaultTreeFile,
+		"file path of binary tree whose lines are \"word code\" to use instead of huffman tree (for hierarchical softmax only)")
+	cmd.Flags().Bool(config.SentenceWeights.String(), config.DefaultSentenceWeights,
+		"whether each line of corpus begins with the weight of the sentence to scale learning rate, e.g. \"2.5 the quick fox\"")
+	cmd.Flags().Int64(config.MaxTotalTokens.String(), config.DefaultMaxTotalTokens,
+		"limit of tokens to train on across all iterations, maxTotalTokens=0 means no limit")
+	cmd.Flags().String(config.CorpusFormat.String(), config.DefaultCorpusFormat,
+		"format of corpus. One of: text|pairs. pairs trains skip-gram directly on lines of \"target context weight\" (for skip-gram and ns only)")
+	cmd.Flags().Bool(config.SaveContexts.String(), config.DefaultSaveContexts,
+		"whether to save contexts' vector instead of targets' vector (for pairs corpus format only)")
+	cmd.Flags().Float64(config.HeldoutFraction.String(), config.DefaultHeldoutFraction,
+		"fraction of sentences held out from corpus to evaluate perplexity after each iteration, heldoutFraction=0 means no evaluation")
+	cmd.Flags().Int(config.WindowStride.String(), config.DefaultWindowStride,
+		"stride to sample context words within the window, e.g. windowStride=2 samples every other word")
+	cmd.Flags().String(config.StorageFile.String(), config.DefaultStorageFile,
+		"file to map words' vector into memory instead of allocating them, e.g. for large vocabulary (on unix only)")
+	return cmd
 }
 
-func word2vecBind(cmd *cobra.Command) {
-	viper.BindPFlag(config.Model.String(), cmd.Flags().Lookup(config.Model.String()))
-	viper.BindPFlag(config.Optimizer.String(), cmd.Flags().Lookup(config.Optimizer.String()))
-	viper.BindPFlag(config.BatchSize.String(), cmd.Flags().Lookup(config.BatchSize.String()))
-	viper.BindPFlag(config.MaxDepth.String(), cmd.Flags().Lookup(config.MaxDepth.String()))
-	viper.BindPFlag(config.NegativeSampleSize.String(), cmd.Flags().Lookup(config.NegativeSampleSize.String()))
-	viper.BindPFlag(config.SubsampleThreshold.String(), cmd.Flags().Lookup(config.SubsampleThreshold.String()))
-	viper.BindPFlag(config.Theta.String(), cmd.Flags().Lookup(config.Theta.String()))
+func word2vecBind(v *viper.Viper, cmd *cobra.Command) {
+	v.BindPFlag(config.Model.String(), cmd.Flags().Lookup(config.Model.String()))
+	v.BindPFlag(config.Optimizer.String(), cmd.Flags().Lookup(config.Optimizer.String()))
+	v.BindPFlag(config.BatchSize.String(), cmd.Flags().Lookup(config.BatchSize.String()))
+	v.BindPFlag(config.MaxDepth.String(), cmd.Flags().Lookup(config.MaxDepth.String()))
+	v.BindPFlag(config.NegativeSampleSize.String(), cmd.Flags().Lookup(config.NegativeSampleSize.String()))
+	v.BindPFlag(config.SubsampleThreshold.String(), cmd.Flags().Lookup(config.SubsampleThreshold.String()))
+	v.BindPFlag(config.Theta.String(), cmd.Flags().Lookup(config.Theta.String()))
+	v.BindPFlag(config.ExcludeSelfContext.String(), cmd.Flags().Lookup(config.ExcludeSelfContext.String()))
+	v.BindPFlag(config.CbowMean.String(), cmd.Flags().Lookup(config.CbowMean.String()))
+	v.BindPFlag(config.SwapRoles.String(), cmd.Flags().Lookup(config.SwapRoles.String()))
+	v.BindPFlag(config.PretrainedVectors.String(), cmd.Flags().Lookup(config.PretrainedVectors.String()))
+	v.BindPFlag(config.TrainOnly.String(), cmd.Flags().Lookup(config.TrainOnly.String()))
+	v.BindPFlag(config.SyncMode.String(), cmd.Flags().Lookup(config.SyncMode.String()))
+	v.BindPFlag(config.SyncInterval.String(), cmd.Flags().Lookup(config.SyncInterval.String()))
+	v.BindPFlag(config.ShuffleSentences.String(), cmd.Flags().Lookup(config.ShuffleSentences.String()))
+	v.BindPFlag(config.TreeFile.String(), cmd.Flags().Lookup(config.TreeFile.String()))
+	v.BindPFlag(config.SentenceWeights.String(), cmd.Flags().Lookup(config.SentenceWeights.String()))
+	v.BindPFlag(config.MaxTotalTokens.String(), cmd.Flags().Lookup(config.MaxTotalTokens.String()))
+	v.BindPFlag(config.CorpusFormat.String(), cmd.Flags().Lookup(config.CorpusFormat.String()))
+	v.BindPFlag(config.SaveContexts.String(), cmd.Flags().Lookup(config.SaveContexts.String()))
+	v.BindPFlag(config.HeldoutFraction.String(), cmd.Flags().Lookup(config.HeldoutFraction.String()))
+	v.BindPFlag(config.WindowStride.String(), cmd.Flags().Lookup(config.WindowStride.String()))
+	v.BindPFlag(config.StorageFile.String(), cmd.Flags().Lookup(config.StorageFile.String()))
 }
 
-func executeWord2vec() error {
-	outputFile := viper.GetString(config.OutputFile.String())
+func executeWord2vec(v *viper.Viper, out io.Writer) error {
+	outputFile := v.GetString(config.OutputFile.String())
 	if validate.FileExists(outputFile) {
 		return errors.Errorf("%s is already existed", outputFile)
 	}
 
-	w2v := builder.NewWord2vecBuilderFromViper()
-	mod, err := w2v.Build()
+	w2v := builder.NewWord2vecBuilderWithViper(v)
+	mod, err := w2v.BuildAndTrain()
 	if err != nil {
 		return err
 	}
-	if err := mod.Train(); err != nil {
-		return err
+	if closer, ok := mod.(io.Closer); ok {
+		defer closer.Close()
+	}
+	if v.GetBool(config.Verbose.String()) {
+		fmt.Fprintf(out, "Summary: %v\n", mod.Summary())
 	}
 	return mod.Save(outputFile)
 }
@@ -20,28 +20,31 @@ import (
 	"github.com/spf13/viper"
 )
 
-const word2vecFlagSize = 7
+const word2vecFlagSize = 23
 
 func TestWord2vecBind(t *testing.T) {
-	defer viper.Reset()
+	v := viper.New()
 
-	word2vecBind(Word2vecCmd)
+	word2vecBind(v, NewWord2vecCmd(v))
 
-	if len(viper.AllKeys()) != word2vecFlagSize {
+	if len(v.AllKeys()) != word2vecFlagSize {
 		t.Errorf("Expected word2vecBind maps %v keys: %v",
 			word2vecFlagSize,
-			viper.AllKeys())
+			v.AllKeys())
 	}
 }
 
 func TestWord2vecCmdPreRun(t *testing.T) {
-	defer viper.Reset()
+	v := viper.New()
 
 	var empty []string
-	Word2vecCmd.PreRun(Word2vecCmd, empty)
+	cmd := NewWord2vecCmd(v)
+	if err := cmd.PreRunE(cmd, empty); err != nil {
+		t.Fatal(err)
+	}
 
-	if len(viper.AllKeys()) != word2vecFlagSize+configFlagSize {
+	if len(v.AllKeys()) != word2vecFlagSize+configFlagSize {
 		t.Errorf("Expected PreRun of Word2vecCmd maps %v keys: %v",
-			word2vecFlagSize+configFlagSize, viper.AllKeys())
+			word2vecFlagSize+configFlagSize, v.AllKeys())
 	}
 }
@@ -0,0 +1,45 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// AverageConfig is enum of the Average config.
+type AverageConfig int
+
+// The list of AverageConfig.
+const (
+	Normalize AverageConfig = iota
+	Align
+	Intersect
+)
+
+// The defaults of AverageConfig.
+const (
+	DefaultNormalize bool = false
+	DefaultAlign     bool = false
+	DefaultIntersect bool = false
+)
+
+func (a AverageConfig) String() string {
+	switch a {
+	case Normalize:
+		return "normalize"
+	case Align:
+		return "align"
+	case Intersect:
+		return "intersect"
+	default:
+		return "unknown"
+	}
+}
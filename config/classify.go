@@ -0,0 +1,56 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// ClassifyConfig is enum of the Classify config.
+type ClassifyConfig int
+
+// The list of ClassifyConfig.
+const (
+	LabelPrefix ClassifyConfig = iota
+	Pretrained
+	FinetuneLr
+	Holdout
+	Classifier
+)
+
+// The defaults of ClassifyConfig.
+const (
+	DefaultLabelPrefix        string  = "__label__"
+	DefaultPretrained         string  = "example/word_vectors.txt"
+	DefaultFinetuneLr         float64 = 0
+	DefaultHoldout            float64 = 0.1
+	DefaultClassifier         string  = "example/classifier.json"
+	DefaultClassifyIteration  int     = 5
+	DefaultClassifyInitlr     float64 = 0.1
+	DefaultClassifyPredictTop int     = 1
+)
+
+func (c ClassifyConfig) String() string {
+	switch c {
+	case LabelPrefix:
+		return "label-prefix"
+	case Pretrained:
+		return "pretrained"
+	case FinetuneLr:
+		return "finetune-lr"
+	case Holdout:
+		return "holdout"
+	case Classifier:
+		return "classifier"
+	default:
+		return "unknown"
+	}
+}
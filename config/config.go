@@ -16,6 +16,7 @@ package config
 
 import (
 	"runtime"
+	"time"
 )
 
 // Config is enum of the common config.
@@ -34,20 +35,50 @@ const (
 	Prof
 	ToLower
 	Verbose
+	OutputFormat
+	SanitizeUTF8
+	MaxTokens
+	MaxVocabTokens
+	Scripts
+	ScriptThreshold
+	ReadRetries
+	ReadRetryDelay
+	SaveFormat
+	SavePrecision
+	MinCoverage
+	CheckOutput
+	SplitHyphens
+	SplitApostrophes
+	Seed
+	SpecialTokens
 )
 
 // The defaults of Config.
 const (
-	DefaultInputFile  string  = "example/input.txt"
-	DefaultOutputFile string  = "example/word_vectors.txt"
-	DefaultDimension  int     = 10
-	DefaultIteration  int     = 15
-	DefaultMinCount   int     = 5
-	DefaultWindow     int     = 5
-	DefaultInitlr     float64 = 0.025
-	DefaultProf       bool    = false
-	DefaultToLower    bool    = false
-	DefaultVerbose    bool    = false
+	DefaultInputFile        string        = "example/input.txt"
+	DefaultOutputFile       string        = "example/word_vectors.txt"
+	DefaultDimension        int           = 10
+	DefaultIteration        int           = 15
+	DefaultMinCount         int           = 5
+	DefaultWindow           int           = 5
+	DefaultInitlr           float64       = 0.025
+	DefaultProf             bool          = false
+	DefaultToLower          bool          = false
+	DefaultVerbose          bool          = false
+	DefaultOutputFormat     string        = "text"
+	DefaultSanitizeUTF8     string        = "none"
+	DefaultMaxTokens        int64         = 0
+	DefaultMaxVocabTokens   int64         = 0
+	DefaultScriptThreshold  float64       = 0.5
+	DefaultReadRetries      int           = 0
+	DefaultReadRetryDelay   time.Duration = 100 * time.Millisecond
+	DefaultSaveFormat       string        = "fixed"
+	DefaultSavePrecision    int           = -1
+	DefaultMinCoverage      float64       = 0
+	DefaultCheckOutput      bool          = true
+	DefaultSplitHyphens     bool          = false
+	DefaultSplitApostrophes bool          = false
+	DefaultSeed             uint64        = 0
 )
 
 // DefaultThreadSize is number of CPU.
@@ -77,6 +108,38 @@ func (c Config) String() string {
 		return "lower"
 	case Verbose:
 		return "verbose"
+	case OutputFormat:
+		return "output-format"
+	case SanitizeUTF8:
+		return "sanitize-utf8"
+	case MaxTokens:
+		return "max-tokens"
+	case MaxVocabTokens:
+		return "max-vocab-tokens"
+	case Scripts:
+		return "scripts"
+	case ScriptThreshold:
+		return "script-threshold"
+	case ReadRetries:
+		return "read-retries"
+	case ReadRetryDelay:
+		return "read-retry-delay"
+	case SaveFormat:
+		return "save-format"
+	case SavePrecision:
+		return "save-precision"
+	case MinCoverage:
+		return "min-coverage"
+	case CheckOutput:
+		return "check-output"
+	case SplitHyphens:
+		return "split-hyphens"
+	case SplitApostrophes:
+		return "split-apostrophes"
+	case Seed:
+		return "seed"
+	case SpecialTokens:
+		return "special-tokens"
 	default:
 		return "unknown"
 	}
@@ -75,6 +75,70 @@ func TestConfigString(t *testing.T) {
 			input:    Verbose,
 			expected: "verbose",
 		},
+		{
+			input:    OutputFormat,
+			expected: "output-format",
+		},
+		{
+			input:    SanitizeUTF8,
+			expected: "sanitize-utf8",
+		},
+		{
+			input:    MaxTokens,
+			expected: "max-tokens",
+		},
+		{
+			input:    MaxVocabTokens,
+			expected: "max-vocab-tokens",
+		},
+		{
+			input:    Scripts,
+			expected: "scripts",
+		},
+		{
+			input:    ScriptThreshold,
+			expected: "script-threshold",
+		},
+		{
+			input:    ReadRetries,
+			expected: "read-retries",
+		},
+		{
+			input:    ReadRetryDelay,
+			expected: "read-retry-delay",
+		},
+		{
+			input:    SaveFormat,
+			expected: "save-format",
+		},
+		{
+			input:    SavePrecision,
+			expected: "save-precision",
+		},
+		{
+			input:    MinCoverage,
+			expected: "min-coverage",
+		},
+		{
+			input:    CheckOutput,
+			expected: "check-output",
+		},
+		{
+			input:    SplitHyphens,
+			expected: "split-hyphens",
+		},
+		{
+			input:    SplitApostrophes,
+			expected: "split-apostrophes",
+		},
+		{
+			input:    Seed,
+			expected: "seed",
+		},
+		{
+			input:    SpecialTokens,
+			expected: "special-tokens",
+		},
 	}
 
 	for _, testCase := range testCases {
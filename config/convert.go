@@ -0,0 +1,41 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// ConvertConfig is enum of the Convert config.
+type ConvertConfig int
+
+// The list of ConvertConfig.
+const (
+	From ConvertConfig = iota
+	To
+)
+
+// The defaults of ConvertConfig.
+const (
+	DefaultFrom string = "text"
+	DefaultTo   string = "binary"
+)
+
+func (c ConvertConfig) String() string {
+	switch c {
+	case From:
+		return "from"
+	case To:
+		return "to"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// CoverageConfig is enum of the Coverage config.
+type CoverageConfig int
+
+// The list of CoverageConfig.
+const (
+	Corpus CoverageConfig = iota
+	Uncovered
+)
+
+// The defaults of CoverageConfig.
+const (
+	DefaultCorpus         string = ""
+	DefaultUncovered      int    = 20
+	DefaultCoverageFormat string = "text"
+)
+
+func (c CoverageConfig) String() string {
+	switch c {
+	case Corpus:
+		return "corpus"
+	case Uncovered:
+		return "uncovered"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// DedupeConfig is enum of the Dedupe config.
+type DedupeConfig int
+
+// The list of DedupeConfig.
+const (
+	DedupeThreshold DedupeConfig = iota
+	Canonicals
+)
+
+// The defaults of DedupeConfig.
+const (
+	DefaultDedupeThreshold float64 = 0.97
+	DefaultCanonicals      string  = ""
+	DefaultDedupeOutput    string  = "groups.tsv"
+)
+
+func (d DedupeConfig) String() string {
+	switch d {
+	case DedupeThreshold:
+		return "threshold"
+	case Canonicals:
+		return "canonicals"
+	default:
+		return "unknown"
+	}
+}
@@ -20,17 +20,44 @@ type DistanceConfig int
 // The list of DistanceConfig.
 const (
 	Rank DistanceConfig = iota
+	MinFreq
+	Metadata
+	Filter
+	MMR
+	Expr
+	SenseDelimiter
+	SenseMerge
 )
 
 // The defaults of DistanceConfig.
 const (
-	DefaultRank int = 10
+	DefaultRank           int     = 10
+	DefaultMinFreq        int     = 0
+	DefaultMetadata       string  = ""
+	DefaultMMR            float64 = 1
+	DefaultExpr           string  = ""
+	DefaultSenseDelimiter string  = ""
+	DefaultSenseMerge     string  = "union"
 )
 
 func (d DistanceConfig) String() string {
 	switch d {
 	case Rank:
 		return "rank"
+	case MinFreq:
+		return "min-freq"
+	case Metadata:
+		return "metadata"
+	case Filter:
+		return "filter"
+	case MMR:
+		return "mmr"
+	case Expr:
+		return "expr"
+	case SenseDelimiter:
+		return "sense-delimiter"
+	case SenseMerge:
+		return "sense-merge"
 	default:
 		return "unknown"
 	}
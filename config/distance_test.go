@@ -35,6 +35,34 @@ func TestDistanceConfigString(t *testing.T) {
 			input:    Rank,
 			expected: "rank",
 		},
+		{
+			input:    MinFreq,
+			expected: "min-freq",
+		},
+		{
+			input:    Metadata,
+			expected: "metadata",
+		},
+		{
+			input:    Filter,
+			expected: "filter",
+		},
+		{
+			input:    MMR,
+			expected: "mmr",
+		},
+		{
+			input:    Expr,
+			expected: "expr",
+		},
+		{
+			input:    SenseDelimiter,
+			expected: "sense-delimiter",
+		},
+		{
+			input:    SenseMerge,
+			expected: "sense-merge",
+		},
 	}
 
 	for _, testCase := range testCases {
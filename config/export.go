@@ -0,0 +1,53 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// ExportConfig is enum of the Export config.
+type ExportConfig int
+
+// The list of ExportConfig.
+const (
+	Format ExportConfig = iota
+	Dict
+	Fill
+	Unk
+	Norms
+)
+
+// The defaults of ExportConfig.
+const (
+	DefaultFormat string = "idtable"
+	DefaultDict   string = ""
+	DefaultFill   string = "zeros"
+	DefaultUnk    string = "<unk>"
+	DefaultNorms  string = ""
+)
+
+func (e ExportConfig) String() string {
+	switch e {
+	case Format:
+		return "format"
+	case Dict:
+		return "dict"
+	case Fill:
+		return "fill"
+	case Unk:
+		return "unk"
+	case Norms:
+		return "norms"
+	default:
+		return "unknown"
+	}
+}
@@ -22,6 +22,12 @@ const (
 	Solver GloveConfig = iota
 	Xmax
 	Alpha
+	CooccurrenceFile
+	CooccurrenceFormat
+	CooccurrenceVocab
+	CheckpointDir
+	CheckpointEvery
+	ResumeFrom
 )
 
 // The defaults of GloveConfig.
@@ -29,6 +35,17 @@ const (
 	DefaultSolver string  = "sgd"
 	DefaultXmax   int     = 100
 	DefaultAlpha  float64 = 0.75
+
+	// DefaultGloveSubsampleThreshold disables subsampling for GloVe, which uses SubsampleThreshold as the key.
+	DefaultGloveSubsampleThreshold float64 = 0
+
+	DefaultCooccurrenceFile   string = ""
+	DefaultCooccurrenceFormat string = "stanford"
+	DefaultCooccurrenceVocab  string = ""
+
+	DefaultCheckpointDir   string = ""
+	DefaultCheckpointEvery int    = 1
+	DefaultResumeFrom      string = ""
 )
 
 func (g GloveConfig) String() string {
@@ -39,6 +56,18 @@ func (g GloveConfig) String() string {
 		return "xmax"
 	case Alpha:
 		return "alpha"
+	case CooccurrenceFile:
+		return "cooccurrenceFile"
+	case CooccurrenceFormat:
+		return "cooccurrenceFormat"
+	case CooccurrenceVocab:
+		return "cooccurrenceVocab"
+	case CheckpointDir:
+		return "checkpoint-dir"
+	case CheckpointEvery:
+		return "checkpoint-every"
+	case ResumeFrom:
+		return "resume-from"
 	default:
 		return "unknown"
 	}
@@ -43,6 +43,30 @@ func TestGloveConfigString(t *testing.T) {
 			input:    Alpha,
 			expected: "alpha",
 		},
+		{
+			input:    CooccurrenceFile,
+			expected: "cooccurrenceFile",
+		},
+		{
+			input:    CooccurrenceFormat,
+			expected: "cooccurrenceFormat",
+		},
+		{
+			input:    CooccurrenceVocab,
+			expected: "cooccurrenceVocab",
+		},
+		{
+			input:    CheckpointDir,
+			expected: "checkpoint-dir",
+		},
+		{
+			input:    CheckpointEvery,
+			expected: "checkpoint-every",
+		},
+		{
+			input:    ResumeFrom,
+			expected: "resume-from",
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -0,0 +1,38 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// InspectConfig is enum of the Inspect config.
+type InspectConfig int
+
+// The list of InspectConfig.
+const (
+	InputFormat InspectConfig = iota
+)
+
+// The defaults of InspectConfig.
+const (
+	DefaultInputFormat   string = "text"
+	DefaultInspectFormat string = "text"
+)
+
+func (i InspectConfig) String() string {
+	switch i {
+	case InputFormat:
+		return "input-format"
+	default:
+		return "unknown"
+	}
+}
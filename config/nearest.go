@@ -0,0 +1,42 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// NearestConfig is enum of the NearestWords config.
+type NearestConfig int
+
+// The list of NearestConfig.
+const (
+	QueryVectors NearestConfig = iota
+	Workers
+)
+
+// The defaults of NearestConfig.
+const (
+	DefaultQueryVectors string = ""
+	DefaultNearestRank  int    = 5
+	DefaultWorkers      int    = 4
+)
+
+func (n NearestConfig) String() string {
+	switch n {
+	case QueryVectors:
+		return "vectors"
+	case Workers:
+		return "workers"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// PruneConfig is enum of the Prune config.
+type PruneConfig int
+
+// The list of PruneConfig.
+const (
+	Top PruneConfig = iota
+	Keep
+	Vocab
+)
+
+// The defaults of PruneConfig.
+const (
+	DefaultTop   int    = 100000
+	DefaultKeep  string = ""
+	DefaultVocab string = ""
+)
+
+func (p PruneConfig) String() string {
+	switch p {
+	case Top:
+		return "top"
+	case Keep:
+		return "keep"
+	case Vocab:
+		return "vocab"
+	default:
+		return "unknown"
+	}
+}
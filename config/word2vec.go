@@ -26,6 +26,22 @@ const (
 	NegativeSampleSize
 	SubsampleThreshold
 	Theta
+	ExcludeSelfContext
+	PretrainedVectors
+	TrainOnly
+	CbowMean
+	SyncMode
+	SyncInterval
+	ShuffleSentences
+	TreeFile
+	SentenceWeights
+	CorpusFormat
+	SaveContexts
+	MaxTotalTokens
+	SwapRoles
+	HeldoutFraction
+	WindowStride
+	StorageFile
 )
 
 // The defaults of Word2vecConfig.
@@ -37,6 +53,22 @@ const (
 	DefaultNegativeSampleSize int     = 5
 	DefaultSubsampleThreshold float64 = 1.0e-3
 	DefaultTheta              float64 = 1.0e-4
+	DefaultExcludeSelfContext bool    = false
+	DefaultPretrainedVectors  string  = ""
+	DefaultTrainOnly          string  = ""
+	DefaultCbowMean           bool    = true
+	DefaultSyncMode           string  = "hogwild"
+	DefaultSyncInterval       int     = 10
+	DefaultShuffleSentences   bool    = false
+	DefaultTreeFile           string  = ""
+	DefaultSentenceWeights    bool    = false
+	DefaultCorpusFormat       string  = "text"
+	DefaultSaveContexts       bool    = false
+	DefaultMaxTotalTokens     int64   = 0
+	DefaultSwapRoles          bool    = false
+	DefaultHeldoutFraction    float64 = 0
+	DefaultWindowStride       int     = 1
+	DefaultStorageFile        string  = ""
 )
 
 func (w Word2vecConfig) String() string {
@@ -55,6 +87,38 @@ func (w Word2vecConfig) String() string {
 		return "threshold"
 	case Theta:
 		return "theta"
+	case ExcludeSelfContext:
+		return "excludeSelfContext"
+	case PretrainedVectors:
+		return "pretrainedVectors"
+	case TrainOnly:
+		return "trainOnly"
+	case CbowMean:
+		return "cbowMean"
+	case SyncMode:
+		return "syncMode"
+	case SyncInterval:
+		return "syncInterval"
+	case ShuffleSentences:
+		return "shuffleSentences"
+	case TreeFile:
+		return "treeFile"
+	case SentenceWeights:
+		return "sentenceWeights"
+	case CorpusFormat:
+		return "corpusFormat"
+	case SaveContexts:
+		return "saveContexts"
+	case MaxTotalTokens:
+		return "maxTotalTokens"
+	case SwapRoles:
+		return "swapRoles"
+	case HeldoutFraction:
+		return "heldoutFraction"
+	case WindowStride:
+		return "windowStride"
+	case StorageFile:
+		return "storageFile"
 	default:
 		return "unknown"
 	}
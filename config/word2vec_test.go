@@ -59,6 +59,66 @@ func TestWord2vecConfigString(t *testing.T) {
 			input:    Theta,
 			expected: "theta",
 		},
+		{
+			input:    ExcludeSelfContext,
+			expected: "excludeSelfContext",
+		},
+		{
+			input:    PretrainedVectors,
+			expected: "pretrainedVectors",
+		},
+		{
+			input:    TrainOnly,
+			expected: "trainOnly",
+		},
+		{
+			input:    CbowMean,
+			expected: "cbowMean",
+		},
+		{
+			input:    SyncMode,
+			expected: "syncMode",
+		},
+		{
+			input:    SyncInterval,
+			expected: "syncInterval",
+		},
+		{
+			input:    ShuffleSentences,
+			expected: "shuffleSentences",
+		},
+		{
+			input:    TreeFile,
+			expected: "treeFile",
+		},
+		{
+			input:    SentenceWeights,
+			expected: "sentenceWeights",
+		},
+		{
+			input:    CorpusFormat,
+			expected: "corpusFormat",
+		},
+		{
+			input:    SaveContexts,
+			expected: "saveContexts",
+		},
+		{
+			input:    MaxTotalTokens,
+			expected: "maxTotalTokens",
+		},
+		{
+			input:    SwapRoles,
+			expected: "swapRoles",
+		},
+		{
+			input:    HeldoutFraction,
+			expected: "heldoutFraction",
+		},
+		{
+			input:    WindowStride,
+			expected: "windowStride",
+		},
 	}
 
 	for _, testCase := range testCases {
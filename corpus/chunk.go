@@ -0,0 +1,117 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Chunk is a window of words read by ChunkReader. The words in [From, To) are new in it,
+// and the others overlap the adjacent chunks to give context to the words near the edges.
+type Chunk struct {
+	Words    []string
+	From, To int
+}
+
+// ChunkReader reads words of corpus in chunks of fixed size regardless of lines, e.g. for corpus whose
+// sentence boundaries are unreliable, and keeps at most a chunk in memory.
+// Each chunk has size new words, along with overlap words before and after them.
+type ChunkReader struct {
+	scanner       *bufio.Scanner
+	size, overlap int
+
+	// the last words of the previous chunk, and the words read ahead as its overlap after them.
+	behind, ahead []string
+
+	// whether the first word is scanned, which may begin with BOM.
+	started bool
+}
+
+// NewChunkReader creates *ChunkReader.
+func NewChunkReader(r io.Reader, size, overlap int) (*ChunkReader, error) {
+	if size <= 0 {
+		return nil, errors.Errorf("Invalid chunk size: %d must be positive", size)
+	}
+	if overlap < 0 {
+		return nil, errors.Errorf("Invalid chunk overlap: %d must be non-negative", overlap)
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+	return &ChunkReader{
+		scanner: scanner,
+		size:    size,
+		overlap: overlap,
+	}, nil
+}
+
+// Read returns the next chunk, or io.EOF if there are no more new words.
+func (c *ChunkReader) Read() (*Chunk, error) {
+	words := c.ahead
+	c.ahead = nil
+	for len(words) < c.size {
+		word, ok := c.scan()
+		if !ok {
+			break
+		}
+		words = append(words, word)
+	}
+	for len(c.ahead) < c.overlap {
+		word, ok := c.scan()
+		if !ok {
+			break
+		}
+		c.ahead = append(c.ahead, word)
+	}
+	if err := c.scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "Unable to complete scanning")
+	}
+	if len(words) == 0 {
+		return nil, io.EOF
+	}
+
+	chunk := &Chunk{
+		Words: make([]string, 0, len(c.behind)+len(words)+len(c.ahead)),
+		From:  len(c.behind),
+		To:    len(c.behind) + len(words),
+	}
+	chunk.Words = append(chunk.Words, c.behind...)
+	chunk.Words = append(chunk.Words, words...)
+	chunk.Words = append(chunk.Words, c.ahead...)
+
+	start := chunk.To - c.overlap
+	if start < 0 {
+		start = 0
+	}
+	c.behind = append([]string(nil), chunk.Words[start:chunk.To]...)
+	return chunk, nil
+}
+
+// scan returns the next word, or false if there are no more words.
+func (c *ChunkReader) scan() (string, bool) {
+	for c.scanner.Scan() {
+		word := c.scanner.Text()
+		if !c.started {
+			c.started = true
+			if word = trimBOM(word); word == "" {
+				continue
+			}
+		}
+		return word, true
+	}
+	return "", false
+}
@@ -0,0 +1,58 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChunkReader(t *testing.T) {
+	r, err := NewChunkReader(strings.NewReader("a b c\nd\n\ne f g"), 3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Chunk{
+		{Words: []string{"a", "b", "c", "d"}, From: 0, To: 3},
+		{Words: []string{"c", "d", "e", "f", "g"}, From: 1, To: 4},
+		{Words: []string{"f", "g"}, From: 1, To: 2},
+	}
+	for _, e := range expected {
+		chunk, err := r.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(*chunk, e) {
+			t.Errorf("Expected chunk %v: %v", e, *chunk)
+		}
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("Expected io.EOF after all words: %v", err)
+	}
+
+	r, err = NewChunkReader(strings.NewReader("\ufeff a\r\nb\r\n"), 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chunk, err := r.Read(); err != nil || !reflect.DeepEqual(chunk.Words, []string{"a", "b"}) {
+		t.Errorf("Expected words without BOM and CR: %v, %v", chunk, err)
+	}
+
+	if _, err := NewChunkReader(strings.NewReader(""), 0, 1); err == nil {
+		t.Error("Expected to fail creating with chunk size 0")
+	}
+}
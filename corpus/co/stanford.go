@@ -0,0 +1,106 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package co
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// The list of formats for co-occurrence file.
+const (
+	// FormatStanford is the binary records of cooccur in Stanford GloVe,
+	// whose word ids are 1-origin line numbers of vocab.txt.
+	FormatStanford = "stanford"
+	// FormatText is the lines of "word1 word2 count".
+	FormatText = "text"
+)
+
+// ValidateFormat validates format for co-occurrence file.
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatStanford, FormatText:
+		return nil
+	default:
+		return errors.Errorf("Invalid co-occurrence format: %s not in %s|%s", format, FormatStanford, FormatText)
+	}
+}
+
+// record is the layout of co-occurrence in Stanford GloVe: word1 int32, word2 int32, count float64.
+type record struct {
+	Word1 int32
+	Word2 int32
+	Count float64
+}
+
+// SortedBigrams returns the pair ids of cooccurrence sorted by the first and then the second word id.
+func SortedBigrams(cooccurrence map[uint64]float64) []uint64 {
+	pids := make([]uint64, 0, len(cooccurrence))
+	for pid := range cooccurrence {
+		pids = append(pids, pid)
+	}
+	sort.Slice(pids, func(i, j int) bool {
+		l1, l2 := DecodeBigram(pids[i])
+		m1, m2 := DecodeBigram(pids[j])
+		if l1 != m1 {
+			return l1 < m1
+		}
+		return l2 < m2
+	})
+	return pids
+}
+
+// WriteStanford writes cooccurrence in Stanford GloVe binary records, little endian,
+// shifting 0-origin word ids to 1-origin.
+func WriteStanford(w io.Writer, cooccurrence map[uint64]float64) error {
+	bw := bufio.NewWriter(w)
+	for _, pid := range SortedBigrams(cooccurrence) {
+		l1, l2 := DecodeBigram(pid)
+		rec := record{
+			Word1: int32(l1 + 1),
+			Word2: int32(l2 + 1),
+			Count: cooccurrence[pid],
+		}
+		if err := binary.Write(bw, binary.LittleEndian, rec); err != nil {
+			return errors.Wrap(err, "Unable to write co-occurrence")
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadStanford reads co-occurrence from Stanford GloVe binary records, shifting word ids to 0-origin.
+// Counts of duplicated pairs are summed up. size is the size of vocabulary to check word ids.
+func ReadStanford(r io.Reader, size int) (map[uint64]float64, error) {
+	cooccurrence := make(map[uint64]float64)
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		var rec record
+		err := binary.Read(br, binary.LittleEndian, &rec)
+		if err == io.EOF {
+			return cooccurrence, nil
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "Unable to read co-occurrence record %d", n)
+		}
+		if rec.Word1 < 1 || int(rec.Word1) > size || rec.Word2 < 1 || int(rec.Word2) > size {
+			return nil, errors.Errorf("Invalid co-occurrence record %d: word ids (%d, %d) not in [1, %d]",
+				n, rec.Word1, rec.Word2, size)
+		}
+		cooccurrence[EncodeBigram(uint64(rec.Word1-1), uint64(rec.Word2-1))] += rec.Count
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package co
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestStanfordRoundTrip(t *testing.T) {
+	cooccurrence := map[uint64]float64{
+		EncodeBigram(0, 1): 1.5,
+		EncodeBigram(1, 0): 1.5,
+		EncodeBigram(2, 0): 0.3333333333333333,
+		EncodeBigram(0, 2): 1e-9,
+	}
+
+	buf := &bytes.Buffer{}
+	if err := WriteStanford(buf, cooccurrence); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != len(cooccurrence)*16 {
+		t.Errorf("Expected %d bytes for 16 bytes per record: %d", len(cooccurrence)*16, buf.Len())
+	}
+
+	// the first record is the pair of 1-origin word ids (1, 2).
+	var first record
+	if err := binary.Read(bytes.NewReader(buf.Bytes()), binary.LittleEndian, &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Word1 != 1 || first.Word2 != 2 || first.Count != 1.5 {
+		t.Errorf("Expected the first record {1 2 1.5}: %v", first)
+	}
+
+	actual, err := ReadStanford(buf, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != len(cooccurrence) {
+		t.Fatalf("Expected %d pairs: %v", len(cooccurrence), actual)
+	}
+	for pid, count := range cooccurrence {
+		if actual[pid] != count {
+			t.Errorf("Expected count=%v for %v: %v", count, pid, actual[pid])
+		}
+	}
+}
+
+func TestReadStanfordInvalid(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteStanford(buf, map[uint64]float64{EncodeBigram(0, 3): 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadStanford(bytes.NewReader(buf.Bytes()), 3); err == nil {
+		t.Error("Expected to fail reading word id out of vocabulary")
+	}
+	if _, err := ReadStanford(bytes.NewReader(buf.Bytes()[:10]), 4); err == nil {
+		t.Error("Expected to fail reading truncated record")
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	for _, format := range []string{FormatStanford, FormatText} {
+		if err := ValidateFormat(format); err != nil {
+			t.Errorf("Expected %s is valid: %v", format, err)
+		}
+	}
+	if err := ValidateFormat("fake"); err == nil {
+		t.Error("Expected fake is invalid")
+	}
+}
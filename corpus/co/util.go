@@ -14,6 +14,10 @@
 
 package co
 
+import (
+	"github.com/pkg/errors"
+)
+
 // The data structure for co-occurrence is referred from:
 //   https://blog.chewxy.com/2017/07/12/21-bits-english/
 
@@ -27,3 +31,19 @@ func DecodeBigram(pid uint64) (uint64, uint64) {
 	f := pid >> 32
 	return pid - (f << 32), f
 }
+
+// maxWords is the upper limit of words whose ids are encoded into pair ids by EncodeBigram, i.e. 32 bits for each.
+const maxWords = uint64(1) << 32
+
+// ValidateWords validates the number of words in vocabulary, whose ids must be encoded into pair ids without
+// collisions.
+func ValidateWords(words int) error {
+	return validateWords(uint64(words), maxWords)
+}
+
+func validateWords(words, limit uint64) error {
+	if words > limit {
+		return errors.Errorf("Unable to count co-occurrences of %d words: exceed the limit %d of pair ids", words, limit)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package co
+
+import (
+	"testing"
+)
+
+func TestValidateWords(t *testing.T) {
+	if err := validateWords(4, 4); err != nil {
+		t.Errorf("Expected 4 words to fit in the limit 4: %v", err)
+	}
+	if err := validateWords(5, 4); err == nil {
+		t.Error("Expected 5 words to exceed the limit 4")
+	}
+	if err := ValidateWords(1 << 20); err != nil {
+		t.Errorf("Expected 1<<20 words to fit in pair ids: %v", err)
+	}
+}
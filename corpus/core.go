@@ -16,7 +16,12 @@ package corpus
 
 import (
 	"bufio"
+	"fmt"
 	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/chewxy/lingo/corpus"
@@ -27,6 +32,22 @@ type core struct {
 	*corpus.Corpus
 	// TODO: more efficient data structure, such as radix tree (trie).
 	document []int
+
+	// settings to parse corpus, which are also applied to look up words.
+	parseConfig ParseConfig
+
+	// number of lines with invalid UTF-8 sequences.
+	invalidUTF8Lines int
+
+	// lower limit of frequency applied to document, words more frequent than it are kept.
+	minCount int
+
+	// offsets in document where sentences begin, and their weights if corpus has them.
+	sentences []int
+	weights   []float64
+
+	// whether the word of each id is kept in document.
+	kept []bool
 }
 
 func newCore() *core {
@@ -38,30 +59,471 @@ func newCore() *core {
 }
 
 // Document returns list of word id.
+// Word ids are ranked by frequency, i.e. id 0 is the most frequent word, unless WordIDs of ParseConfig is set.
 func (c *core) Document() []int {
 	return c.document
 }
 
-func (c *core) parse(f io.ReadCloser, toLower bool, minCount int) error {
-	fullDoc := make([]int, 0)
-	scanner := bufio.NewScanner(f)
-	scanner.Split(bufio.ScanWords)
-	for scanner.Scan() {
-		word := scanner.Text()
-		if toLower {
-			word = strings.ToLower(word)
+// InvalidUTF8Lines returns the number of lines with invalid UTF-8 sequences, which are sanitized on parsing.
+// It is always 0 without sanitizing.
+func (c *core) InvalidUTF8Lines() int {
+	return c.invalidUTF8Lines
+}
+
+// MinCount returns minCount applied to document, which is computed from MinCoverage of ParseConfig if it is positive.
+func (c *core) MinCount() int {
+	return c.minCount
+}
+
+// Kept returns whether the word of id is kept in document, i.e. not filtered out as rare word.
+func (c *core) Kept(id int) bool {
+	return id >= 0 && id < len(c.kept) && c.kept[id]
+}
+
+// ParseConfig returns the settings to parse corpus.
+func (c *core) ParseConfig() ParseConfig {
+	return c.parseConfig
+}
+
+// Lookup returns the id of word, which is normalized in the same way as the words parsed from corpus.
+func (c *core) Lookup(word string) (int, bool) {
+	return c.Id(c.parseConfig.Normalize(word))
+}
+
+// WordIDs returns the ids of words in vocabulary, without duplication, and the words not in vocabulary.
+// The words are normalized in the same way as the words parsed from corpus.
+func (c *core) WordIDs(words []string) ([]int, []string) {
+	ids := make([]int, 0, len(words))
+	missing := make([]string, 0)
+	seen := make(map[int]struct{})
+	for _, word := range words {
+		id, ok := c.Lookup(word)
+		if !ok {
+			missing = append(missing, word)
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	return ids, missing
+}
+
+// MatchingIDs returns the ids of words in vocabulary matching the regular expression pattern, in order of ids.
+func (c *core) MatchingIDs(pattern string) ([]int, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid pattern: %s", pattern)
+	}
+	ids := make([]int, 0)
+	for id := 0; id < c.Size(); id++ {
+		if word, _ := c.Word(id); re.MatchString(word) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// vocabulary is the maps between words and ids along with frequencies of the ids, e.g. *corpus.Corpus.
+type vocabulary interface {
+	Size() int
+	Word(id int) (string, bool)
+	Id(word string) (int, bool)
+	IDFreq(id int) int
+}
+
+// Validate checks the consistency of corpus, i.e. the maps of word->id and id->word are bijective,
+// frequencies are non-negative, and document consists of ids in vocabulary.
+// It catches corruption of corpus, e.g. after merging or loading vocabulary.
+func (c *core) Validate() error {
+	if err := validateVocabulary(c.Corpus); err != nil {
+		return err
+	}
+	size := c.Size()
+	for i, id := range c.document {
+		if id < 0 || id >= size {
+			return errors.Errorf("Invalid word id %d at position %d of document: vocabulary has %d words", id, i, size)
+		}
+	}
+	if c.kept != nil && len(c.kept) != size {
+		return errors.Errorf("Size of kept words %d and vocabulary %d are different", len(c.kept), size)
+	}
+	return nil
+}
+
+func validateVocabulary(v vocabulary) error {
+	for id := 0; id < v.Size(); id++ {
+		word, ok := v.Word(id)
+		if !ok {
+			return errors.Errorf("No word for id %d in vocabulary of %d words", id, v.Size())
+		}
+		other, ok := v.Id(word)
+		if !ok {
+			return errors.Errorf("No id for word %s of id %d", word, id)
+		}
+		if other != id {
+			return errors.Errorf("Duplicated word %s for ids %d and %d", word, other, id)
+		}
+		if freq := v.IDFreq(id); freq < 0 {
+			return errors.Errorf("Negative frequency %d of word %s", freq, word)
+		}
+	}
+	return nil
+}
+
+// parse scans words of f with parseConfig to build vocabulary and document.
+func (c *core) parse(f io.ReadCloser, parseConfig ParseConfig, minCount int) error {
+	if err := parseConfig.Validate(); err != nil {
+		return err
+	}
+	c.parseConfig = parseConfig
+
+	fullDoc, fullSentences := make([]int, 0), make([]int, 0)
+	var fullWeights, weightedFreqs []float64
+	newSentence := true
+	invalidUTF8Lines, err := scanTokens(f, parseConfig, func(word string, newLine bool, weight float64) {
+		if newLine || newSentence {
+			fullSentences = append(fullSentences, len(fullDoc))
+			if parseConfig.SentenceWeights {
+				fullWeights = append(fullWeights, weight)
+			}
+			newSentence = false
 		}
 		c.Add(word)
 		wordID, _ := c.Id(word)
 		fullDoc = append(fullDoc, wordID)
+		if parseConfig.WeightedCounts {
+			if wordID == len(weightedFreqs) {
+				weightedFreqs = append(weightedFreqs, 0)
+			}
+			weightedFreqs[wordID] += weight
+		}
+	})
+	if err != nil {
+		return err
+	}
+	c.invalidUTF8Lines = invalidUTF8Lines
+	if parseConfig.WeightedCounts {
+		c.countWeighted(weightedFreqs)
+	}
+	return c.buildDocument(fullDoc, fullSentences, fullWeights, parseConfig, minCount)
+}
+
+// countWeighted replaces the frequency of each id with freqs[id] rounded, which is at least 1 to keep the word in
+// vocabulary. The ids are kept.
+func (c *core) countWeighted(freqs []float64) {
+	weighted, _ := corpus.Construct()
+	for id, freq := range freqs {
+		word, _ := c.Word(id)
+		n := int(math.Round(freq))
+		if n < 1 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			weighted.Add(word)
+		}
+	}
+	c.Corpus = weighted
+}
+
+// ScanTokens calls fn with each token of r in the same way as corpus is parsed with parseConfig,
+// e.g. to count the tokens of another corpus against vocabulary of trained word vectors.
+func ScanTokens(r io.Reader, parseConfig ParseConfig, fn func(word string)) error {
+	if err := parseConfig.Validate(); err != nil {
+		return err
+	}
+	_, err := scanTokens(r, parseConfig, func(word string, _ bool, _ float64) {
+		fn(word)
+	})
+	return err
+}
+
+// scanTokens calls fn with each token of r kept by parseConfig, whether it begins a new line after the previous
+// token, and the weight of the line, which is 1 unless SentenceWeights of parseConfig is set.
+// It returns the number of lines with invalid UTF-8 sequences if they are sanitized.
+func scanTokens(r io.Reader, parseConfig ParseConfig, fn func(word string, newLine bool, weight float64)) (int, error) {
+	filter, _ := parseConfig.scriptFilter()
+	if parseConfig.ReadRetries > 0 {
+		r = newRetryReader(r, parseConfig.ReadRetries, parseConfig.ReadRetryDelay)
+	}
+	var sanitizer *utf8Sanitizer
+	if parseConfig.sanitizes() {
+		sanitizer = newUTF8Sanitizer(r, parseConfig.Sanitize == SanitizeSkip)
+		r = sanitizer
+	}
+
+	first, newLine, weighted := true, false, parseConfig.SentenceWeights
+	weight := 1.0
+	splitter := &wordSplitter{}
+	var parts []string
+	scanner := bufio.NewScanner(r)
+	scanner.Split(splitter.split)
+	maxTokens := parseConfig.MaxTokens
+	for n := int64(0); (maxTokens <= 0 || n < maxTokens) && scanner.Scan(); {
+		if splitter.newLine {
+			newLine, splitter.newLine = true, false
+			weighted = parseConfig.SentenceWeights
+		}
+		word := scanner.Text()
+		if first {
+			if word, first = trimBOM(word), false; word == "" {
+				continue
+			}
+		}
+		if weighted {
+			w, err := strconv.ParseFloat(word, 64)
+			if err != nil || w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+				return 0, errors.Errorf("Invalid sentence weight: %q must be a non-negative number", word)
+			}
+			weight, weighted = w, false
+			continue
+		}
+		n++
+		if parseConfig.ToLower {
+			word = strings.ToLower(word)
+		}
+		parts = parseConfig.splitWord(word, parts[:0])
+		for _, part := range parts {
+			if filter != nil && !filter.keep(part) {
+				continue
+			}
+			fn(part, newLine, weight)
+			newLine = false
+		}
 	}
 	if err := scanner.Err(); err != nil && err != io.EOF {
-		return errors.Wrap(err, "Unable to complete scanning")
+		return 0, errors.Wrap(err, "Unable to complete scanning")
 	}
-	for _, d := range fullDoc {
-		if c.IDFreq(d) > minCount {
-			c.document = append(c.document, d)
+	if sanitizer != nil {
+		return sanitizer.affected, nil
+	}
+	return 0, nil
+}
+
+// buildDocument ranks word ids by frequency, or assigns WordIDs of parseConfig if it is set, and builds document
+// of the words more frequent than minCount, or the words MinCountFunc of parseConfig keeps, from fullDoc of
+// the word ids before ranking.
+// fullSentences are the offsets in fullDoc where sentences begin, and fullWeights are their weights or nil.
+func (c *core) buildDocument(fullDoc, fullSentences []int, fullWeights []float64, parseConfig ParseConfig,
+	minCount int) error {
+	var rank []int
+	if parseConfig.WordIDs != nil {
+		wordIDs, err := parseConfig.wordIDs()
+		if err != nil {
+			return err
+		}
+		if rank, err = c.assignIDs(wordIDs); err != nil {
+			return err
+		}
+	} else {
+		special, err := parseConfig.specialTokens()
+		if err != nil {
+			return err
+		}
+		for _, token := range special {
+			if _, ok := c.Id(token); !ok {
+				c.Add(token)
+			}
 		}
+		rank = c.rankByFrequency(special...)
+	}
+	if parseConfig.MinCoverage > 0 {
+		minCount = c.coverageMinCount(parseConfig.MinCoverage)
+	}
+	c.keepWords(minCount)
+	for id := range parseConfig.SpecialTokens {
+		c.kept[id] = true
+	}
+	var s int
+	newSentence := false
+	for i, d := range fullDoc {
+		if s < len(fullSentences) && fullSentences[s] == i {
+			newSentence = true
+			s++
+		}
+		if !c.kept[rank[d]] {
+			continue
+		}
+		if newSentence {
+			c.sentences = append(c.sentences, len(c.document))
+			if fullWeights != nil {
+				c.weights = append(c.weights, fullWeights[s-1])
+			}
+			newSentence = false
+		}
+		c.document = append(c.document, rank[d])
 	}
 	return nil
 }
+
+// keepWords decides whether the word of each id is kept, i.e. more frequent than minCount,
+// or kept by MinCountFunc of ParseConfig if it is set.
+func (c *core) keepWords(minCount int) {
+	c.minCount = minCount
+	keep := c.parseConfig.MinCountFunc
+	if keep == nil {
+		keep = func(_ string, freq int) bool {
+			return freq > minCount
+		}
+	}
+	c.kept = make([]bool, c.Size())
+	for id := range c.kept {
+		word, _ := c.Word(id)
+		c.kept[id] = keep(word, c.IDFreq(id))
+	}
+}
+
+// coverageMinCount returns the largest minCount with which the kept words cover at least coverage of tokens.
+// It is the frequency of the last word needed to reach coverage in descending order of frequency minus 1,
+// which keeps all the words as frequent as it.
+func (c *core) coverageMinCount(coverage float64) int {
+	freqs := make([]int, c.Size())
+	for id := range freqs {
+		freqs[id] = c.IDFreq(id)
+	}
+	// word ids aren't ranked by frequency with WordIDs of ParseConfig.
+	sort.Sort(sort.Reverse(sort.IntSlice(freqs)))
+
+	target := coverage * float64(c.TotalFreq())
+	var covered int
+	for _, freq := range freqs {
+		covered += freq
+		if float64(covered) >= target {
+			return freq - 1
+		}
+	}
+	return 0
+}
+
+// WriteVocab writes lines of "word frequency" in order of word ids, i.e. in descending order of frequency.
+func (c *core) WriteVocab(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for id := 0; id < c.Size(); id++ {
+		word, _ := c.Word(id)
+		if _, err := fmt.Fprintf(bw, "%s %d\n", word, c.IDFreq(id)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// Vocabulary returns the words in order of word ids, which is also the order of words of saved vectors.
+func (c *core) Vocabulary() []string {
+	words := make([]string, c.Size())
+	for id := range words {
+		words[id], _ = c.Word(id)
+	}
+	return words
+}
+
+// SaveVocab writes a line per word in order of word ids, i.e. in the same order as saved vectors, so that they are
+// zipped together line by line. WriteVocab writes the words with their frequencies in the same order.
+func (c *core) SaveVocab(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, word := range c.Vocabulary() {
+		if _, err := fmt.Fprintln(bw, word); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// readVocab adds the words of lines of "word frequency" in order, whose ids are the line numbers from 0.
+func (c *core) readVocab(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if lineNum == 1 {
+			line = trimBOM(line)
+		}
+		sep := strings.Fields(line)
+		if len(sep) == 0 {
+			continue
+		}
+		if len(sep) != 2 {
+			return errors.Errorf("Invalid vocabulary line %d: %q not in word frequency", lineNum, scanner.Text())
+		}
+		if _, ok := c.Id(sep[0]); ok {
+			return errors.Errorf("Invalid vocabulary line %d: %s is duplicated", lineNum, sep[0])
+		}
+		freq, err := strconv.Atoi(sep[1])
+		if err != nil || freq < 1 {
+			return errors.Errorf("Invalid frequency at vocabulary line %d: %s", lineNum, sep[1])
+		}
+		for n := 0; n < freq; n++ {
+			c.Add(sep[0])
+		}
+	}
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		return errors.Wrap(err, "Unable to complete scanning")
+	}
+	return nil
+}
+
+// assignIDs reassigns word ids by wordIDs, and returns the new id indexed by old id.
+// It fails if a word of corpus isn't in wordIDs, or a word of wordIDs isn't in corpus, which leaves a gap of ids.
+func (c *core) assignIDs(wordIDs map[string]int) ([]int, error) {
+	rank := make([]int, c.Size())
+	for id := range rank {
+		word, _ := c.Word(id)
+		newID, ok := wordIDs[word]
+		if !ok {
+			return nil, errors.Errorf("Word %s of corpus is not in word ids", word)
+		}
+		rank[id] = newID
+	}
+	words := make([]string, len(wordIDs))
+	for word, id := range wordIDs {
+		if _, ok := c.Id(word); !ok {
+			return nil, errors.Errorf("Gap of word ids: %s of id %d is not in corpus", word, id)
+		}
+		words[id] = word
+	}
+
+	assigned, _ := corpus.Construct()
+	for _, word := range words {
+		id, _ := c.Id(word)
+		for n := 0; n < c.IDFreq(id); n++ {
+			assigned.Add(word)
+		}
+	}
+	c.Corpus = assigned
+	return rank, nil
+}
+
+// rankByFrequency reassigns word ids in descending order of frequency,
+// so that id 0 is the most frequent word, and returns the new id indexed by old id.
+// Words with the same frequency keep the order of appearance. The reserved words, which must be in vocabulary,
+// take the ids 0 to len-1 in order before the others.
+func (c *core) rankByFrequency(reserved ...string) []int {
+	ids := make([]int, 0, c.Size())
+	isReserved := make(map[int]bool, len(reserved))
+	for _, word := range reserved {
+		id, _ := c.Id(word)
+		ids = append(ids, id)
+		isReserved[id] = true
+	}
+	others := make([]int, 0, c.Size()-len(reserved))
+	for i := 0; i < c.Size(); i++ {
+		if !isReserved[i] {
+			others = append(others, i)
+		}
+	}
+	sort.SliceStable(others, func(i, j int) bool {
+		return c.IDFreq(others[i]) > c.IDFreq(others[j])
+	})
+	ids = append(ids, others...)
+
+	ranked, _ := corpus.Construct()
+	rank := make([]int, c.Size())
+	for _, id := range ids {
+		word, _ := c.Word(id)
+		for n := 0; n < c.IDFreq(id); n++ {
+			rank[id] = ranked.Add(word)
+		}
+	}
+	c.Corpus = ranked
+	return rank
+}
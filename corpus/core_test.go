@@ -0,0 +1,411 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFrequencyRankedID(t *testing.T) {
+	c := newCore()
+	f := ioutil.NopCloser(bytes.NewReader([]byte("a b b c c c c")))
+	if err := c.parse(f, ParseConfig{ToLower: true}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		word     string
+		expected int
+	}{
+		{"c", 0},
+		{"b", 1},
+		{"a", 2},
+	}
+
+	for _, testCase := range testCases {
+		actual, _ := c.Id(testCase.word)
+		if actual != testCase.expected {
+			t.Errorf("Expected id of %v=%v: %v", testCase.word, testCase.expected, actual)
+		}
+	}
+
+	expectedDocument := []int{2, 1, 1, 0, 0, 0, 0}
+	for i, d := range c.Document() {
+		if d != expectedDocument[i] {
+			t.Errorf("Expected document=%v: %v", expectedDocument, c.Document())
+			break
+		}
+	}
+}
+
+func TestWordIDs(t *testing.T) {
+	wordIDs := map[string]int{"a": 0, "b": 1, "c": 2}
+	c := newCore()
+	f := ioutil.NopCloser(bytes.NewReader([]byte("a b b C c c c")))
+	if err := c.parse(f, ParseConfig{ToLower: true, WordIDs: wordIDs}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	for word, expected := range wordIDs {
+		if actual, _ := c.Id(word); actual != expected {
+			t.Errorf("Expected id of %v=%v: %v", word, expected, actual)
+		}
+	}
+	if freq := c.IDFreq(2); freq != 4 {
+		t.Errorf("Expected frequency of c=4: %v", freq)
+	}
+	expectedDocument := []int{0, 1, 1, 2, 2, 2, 2}
+	if !reflect.DeepEqual(c.Document(), expectedDocument) {
+		t.Errorf("Expected document=%v: %v", expectedDocument, c.Document())
+	}
+	if err := c.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInvalidWordIDs(t *testing.T) {
+	testCases := []struct {
+		wordIDs  map[string]int
+		expected string
+	}{
+		{map[string]int{"a": 0, "b": 0, "c": 2}, "Conflicted word id"},
+		{map[string]int{"a": 0, "b": 1, "c": 3}, "Invalid word id"},
+		{map[string]int{"a": 0, "b": 1, "B": 2, "c": 3}, "after normalizing"},
+		{map[string]int{"a": 0, "b": 1}, "not in word ids"},
+		{map[string]int{"a": 0, "b": 1, "c": 2, "d": 3}, "Gap of word ids"},
+	}
+
+	for _, testCase := range testCases {
+		c := newCore()
+		f := ioutil.NopCloser(bytes.NewReader([]byte("a b b c c c c")))
+		err := c.parse(f, ParseConfig{ToLower: true, WordIDs: testCase.wordIDs}, 0)
+		if err == nil || !strings.Contains(err.Error(), testCase.expected) {
+			t.Errorf("Expected error of %q for %v: %v", testCase.expected, testCase.wordIDs, err)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	text := strings.Repeat("the quick brown fox jumps over the lazy dog ", 10000)
+	for i := 0; i < b.N; i++ {
+		c := newCore()
+		c.parse(ioutil.NopCloser(strings.NewReader(text)), ParseConfig{}, 0)
+	}
+}
+
+func TestSpecialTokens(t *testing.T) {
+	c := newCore()
+	f := ioutil.NopCloser(strings.NewReader("a b b c c c c <EOS>"))
+	if err := c.parse(f, ParseConfig{ToLower: true, SpecialTokens: []string{"<pad>", "<bos>", "<EOS>"}}, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"<pad>", "<bos>", "<eos>", "c", "b", "a"}
+	if vocab := c.Vocabulary(); !reflect.DeepEqual(vocab, expected) {
+		t.Errorf("Expected special tokens to occupy the reserved ids before the others: %v, %v", expected, vocab)
+	}
+	for id := 0; id < 3; id++ {
+		if !c.Kept(id) {
+			t.Errorf("Expected special token of id %d to be kept regardless of min count", id)
+		}
+	}
+	if c.Kept(5) {
+		t.Error("Expected a of frequency 1 to be filtered out")
+	}
+	if doc := c.Document(); !reflect.DeepEqual(doc, []int{4, 4, 3, 3, 3, 3, 2}) {
+		t.Errorf("Expected <eos> of corpus in document with id 2: %v", doc)
+	}
+
+	invalid := []ParseConfig{
+		{SpecialTokens: []string{"<pad>", "<PAD>"}, ToLower: true},
+		{SpecialTokens: []string{""}},
+		{SpecialTokens: []string{"<pad>"}, WordIDs: map[string]int{"a": 0}},
+	}
+	for _, parseConfig := range invalid {
+		if err := newCore().parse(ioutil.NopCloser(strings.NewReader("a")), parseConfig, 0); err == nil {
+			t.Errorf("Expected to fail parsing with special tokens %v", parseConfig.SpecialTokens)
+		}
+	}
+}
+
+func TestMaxVocabTokens(t *testing.T) {
+	c := newCore()
+	f := ioutil.NopCloser(strings.NewReader("a b b c c c c"))
+	if err := c.parse(f, ParseConfig{MaxTokens: 3}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if c.Size() != 2 || len(c.Document()) != 3 {
+		t.Errorf("Expected vocabulary of 2 words and document of 3 tokens: %d, %d", c.Size(), len(c.Document()))
+	}
+}
+
+func TestMinCoverage(t *testing.T) {
+	text := "a b b c c c c d d d d d d d d"
+	testCases := []struct {
+		coverage         float64
+		expectedMinCount int
+	}{
+		{0.5, 7},
+		{0.6, 3},
+		{0.8, 3},
+		{0.9, 1},
+		{1, 0},
+	}
+	for _, testCase := range testCases {
+		c := newCore()
+		f := ioutil.NopCloser(strings.NewReader(text))
+		if err := c.parse(f, ParseConfig{MinCoverage: testCase.coverage}, 100); err != nil {
+			t.Fatal(err)
+		}
+		if c.MinCount() != testCase.expectedMinCount {
+			t.Errorf("Expected minCount=%v for coverage %v: %v",
+				testCase.expectedMinCount, testCase.coverage, c.MinCount())
+		}
+		covered := float64(len(c.Document())) / float64(c.TotalFreq())
+		if covered < testCase.coverage {
+			t.Errorf("Expected document to cover at least %v of tokens: %v", testCase.coverage, covered)
+		}
+	}
+
+	if err := newCore().parse(ioutil.NopCloser(strings.NewReader(text)), ParseConfig{MinCoverage: 1.5}, 0); err == nil {
+		t.Error("Expected to fail parsing with coverage more than 1")
+	}
+}
+
+func TestMinCountFunc(t *testing.T) {
+	text := "a a b b b 1 1 1 2 2 2 2 2"
+	keep := func(word string, freq int) bool {
+		if _, err := strconv.Atoi(word); err == nil {
+			return freq > 3
+		}
+		return freq > 1
+	}
+	c := newCore()
+	f := ioutil.NopCloser(strings.NewReader(text))
+	if err := c.parse(f, ParseConfig{MinCountFunc: keep}, 100); err != nil {
+		t.Fatal(err)
+	}
+	kept := make(map[string]int)
+	for _, id := range c.Document() {
+		word, _ := c.Word(id)
+		kept[word]++
+	}
+	expected := map[string]int{"a": 2, "b": 3, "2": 5}
+	if !reflect.DeepEqual(kept, expected) {
+		t.Errorf("Expected numeric tokens to require a higher count than words: %v", kept)
+	}
+}
+
+func TestSentences(t *testing.T) {
+	c := newCore()
+	f := ioutil.NopCloser(strings.NewReader("a b\n\nc x a\r\ny\n  b c\nc"))
+	if err := c.parse(f, ParseConfig{}, 1); err != nil {
+		t.Fatal(err)
+	}
+	// the line of only rare words is omitted.
+	expected := []int{0, 2, 4, 6}
+	if !reflect.DeepEqual(c.Sentences(), expected) {
+		t.Errorf("Expected sentences=%v: %v", expected, c.Sentences())
+	}
+}
+
+func TestSentenceWeights(t *testing.T) {
+	c := newCore()
+	f := ioutil.NopCloser(strings.NewReader("2 a b\n\n0.5 c x a\n3 y\n1  b c"))
+	if err := c.parse(f, ParseConfig{SentenceWeights: true, MaxTokens: 7}, 1); err != nil {
+		t.Fatal(err)
+	}
+	// the weights are not words, and the line of only rare words is omitted with its weight.
+	if expected := []int{0, 2, 3}; !reflect.DeepEqual(c.Sentences(), expected) {
+		t.Errorf("Expected sentences=%v: %v", expected, c.Sentences())
+	}
+	if expected := []float64{2, 0.5, 1}; !reflect.DeepEqual(c.SentenceWeights(), expected) {
+		t.Errorf("Expected sentence weights=%v: %v", expected, c.SentenceWeights())
+	}
+
+	for _, corpus := range []string{"a b\n", "1 a\n-1 b\n"} {
+		c := newCore()
+		if err := c.parse(ioutil.NopCloser(strings.NewReader(corpus)), ParseConfig{SentenceWeights: true}, 0); err == nil {
+			t.Errorf("Expected to fail parsing invalid weight: %q", corpus)
+		}
+	}
+
+	c = newCore()
+	if err := c.parse(ioutil.NopCloser(strings.NewReader("2 a b")), ParseConfig{}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if c.SentenceWeights() != nil {
+		t.Errorf("Expected no weights without SentenceWeights: %v", c.SentenceWeights())
+	}
+}
+
+func TestWeightedCounts(t *testing.T) {
+	c := newCore()
+	f := ioutil.NopCloser(strings.NewReader("3 a b\n0.4 b c\n0 d"))
+	if err := c.parse(f, ParseConfig{SentenceWeights: true, WeightedCounts: true}, 0); err != nil {
+		t.Fatal(err)
+	}
+	// b is counted 3.4 times, and c and d are counted at least once.
+	for word, expected := range map[string]int{"a": 3, "b": 3, "c": 1, "d": 1} {
+		if freq := c.WordFreq(word); freq != expected {
+			t.Errorf("Expected frequency of %s=%d: %d", word, expected, freq)
+		}
+	}
+	if expected := 4; len(c.Document()) != 5 || c.Size() != expected {
+		t.Errorf("Expected document of 5 words in vocabulary of %d: %v", expected, c.Document())
+	}
+
+	if err := (ParseConfig{WeightedCounts: true}).Validate(); err == nil {
+		t.Error("Expected to fail validating weighted counts without sentence weights")
+	}
+}
+
+type fakeVocabulary struct {
+	words []string
+	ids   map[string]int
+	freqs []int
+}
+
+func (f fakeVocabulary) Size() int { return len(f.words) }
+
+func (f fakeVocabulary) Word(id int) (string, bool) {
+	if id < 0 || id >= len(f.words) {
+		return "", false
+	}
+	return f.words[id], true
+}
+
+func (f fakeVocabulary) Id(word string) (int, bool) {
+	id, ok := f.ids[word]
+	return id, ok
+}
+
+func (f fakeVocabulary) IDFreq(id int) int { return f.freqs[id] }
+
+func TestValidate(t *testing.T) {
+	c := newCore()
+	if err := c.parse(ioutil.NopCloser(strings.NewReader("a b b c c c")), ParseConfig{}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("Expected parsed corpus is valid: %v", err)
+	}
+	c.document = append(c.document, 3)
+	if err := c.Validate(); err == nil {
+		t.Error("Expected an error for word id out of vocabulary in document")
+	}
+
+	valid := fakeVocabulary{
+		words: []string{"a", "b"},
+		ids:   map[string]int{"a": 0, "b": 1},
+		freqs: []int{2, 1},
+	}
+	if err := validateVocabulary(valid); err != nil {
+		t.Fatalf("Expected valid vocabulary: %v", err)
+	}
+	testCases := map[string]fakeVocabulary{
+		"duplicated word": {
+			words: []string{"a", "a"},
+			ids:   map[string]int{"a": 0},
+			freqs: []int{2, 1},
+		},
+		"missing id": {
+			words: []string{"a", "b"},
+			ids:   map[string]int{"a": 0},
+			freqs: []int{2, 1},
+		},
+		"swapped ids": {
+			words: []string{"a", "b"},
+			ids:   map[string]int{"a": 1, "b": 0},
+			freqs: []int{2, 1},
+		},
+		"negative frequency": {
+			words: []string{"a", "b"},
+			ids:   map[string]int{"a": 0, "b": 1},
+			freqs: []int{2, -1},
+		},
+	}
+	for name, v := range testCases {
+		if err := validateVocabulary(v); err == nil {
+			t.Errorf("Expected an error for %s: %+v", name, v)
+		}
+	}
+}
+
+func TestParseCRLF(t *testing.T) {
+	c := newCore()
+	f := ioutil.NopCloser(strings.NewReader("\ufeff2 a b\r\n1 b c\r\n\r\n3 c\r\n"))
+	if err := c.parse(f, ParseConfig{SentenceWeights: true}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if c.Size() != 3 {
+		t.Errorf("Expected vocabulary of a, b and c without BOM and CR: %v", c.Words())
+	}
+	for _, word := range []string{"a", "b", "c"} {
+		if _, ok := c.Id(word); !ok {
+			t.Errorf("Expected %s in vocabulary: %v", word, c.Words())
+		}
+	}
+	if expected := []int{0, 2, 4}; !reflect.DeepEqual(c.Sentences(), expected) {
+		t.Errorf("Expected sentences=%v: %v", expected, c.Sentences())
+	}
+	if expected := []float64{2, 1, 3}; !reflect.DeepEqual(c.SentenceWeights(), expected) {
+		t.Errorf("Expected sentence weights=%v: %v", expected, c.SentenceWeights())
+	}
+
+	c = newCore()
+	if err := c.readVocab(strings.NewReader("\ufeffa 2\r\nb 1\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Id("a"); !ok {
+		t.Errorf("Expected a in vocabulary without BOM: %v", c.Words())
+	}
+}
+
+func TestSplitTokens(t *testing.T) {
+	testCases := []struct {
+		parseConfig ParseConfig
+		expected    []string
+	}{
+		{
+			parseConfig: ParseConfig{},
+			expected:    []string{"don't", "state-of-the-art", "'90s"},
+		},
+		{
+			parseConfig: ParseConfig{SplitApostrophes: true},
+			expected:    []string{"don", "t", "state-of-the-art", "90s"},
+		},
+		{
+			parseConfig: ParseConfig{SplitHyphens: true, SplitApostrophes: true},
+			expected:    []string{"don", "t", "state", "of", "the", "art", "90s"},
+		},
+	}
+	for _, testCase := range testCases {
+		var actual []string
+		if err := ScanTokens(strings.NewReader("don't state-of-the-art\n'90s"), testCase.parseConfig, func(word string) {
+			actual = append(actual, word)
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(actual, testCase.expected) {
+			t.Errorf("Expected tokens %v with %+v: %v", testCase.expected, testCase.parseConfig, actual)
+		}
+	}
+}
@@ -15,8 +15,12 @@
 package corpus
 
 import (
+	"bufio"
+	"fmt"
 	"io"
 	"math"
+	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -27,18 +31,70 @@ import (
 type GloveCorpus struct {
 	*core
 	cooccurrence map[uint64]float64
+
+	// number of tokens discarded by subsampling, and of tokens to count co-occurrences on.
+	subsampled int
+	counted    int
+}
+
+// Random generates random values in [0, 1) to subsample words, e.g. *model.Random.
+type Random interface {
+	Float64() float64
 }
 
 // NewGloveCorpus creates *GloveCorpus.
-func NewGloveCorpus(f io.ReadCloser, toLower bool, minCount, window int) (*GloveCorpus, error) {
+// Co-occurrences are counted on the first maxTokens words of document if it is positive,
+// after frequent words are discarded by subsampling with subsampleThreshold if it is positive,
+// which draws from rnd. rnd may be nil without subsampling.
+func NewGloveCorpus(f io.ReadCloser, parseConfig ParseConfig, minCount, window int,
+	maxTokens int64, subsampleThreshold float64, rnd Random) (*GloveCorpus, error) {
+	gloveCorpus := &GloveCorpus{
+		core:         newCore(),
+		cooccurrence: make(map[uint64]float64),
+	}
+	if err := gloveCorpus.parse(f, parseConfig, minCount); err != nil {
+		return nil, errors.Wrap(err, "Unable to generate *GloveCorpus")
+	}
+	if err := co.ValidateWords(gloveCorpus.Size()); err != nil {
+		return nil, errors.Wrap(err, "Unable to generate *GloveCorpus")
+	}
+	gloveCorpus.build(window, maxTokens, subsampleThreshold, rnd)
+	return gloveCorpus, nil
+}
+
+// NewGloveCorpusFromCooccurrence creates *GloveCorpus from co-occurrence counted in advance, e.g. by Stanford GloVe.
+// vocab has lines of "word frequency", whose order defines word ids, e.g. vocab.txt of Stanford GloVe.
+// The format of cooccurrence is one of: stanford|text
+func NewGloveCorpusFromCooccurrence(vocab, cooccurrence io.Reader, format string,
+	parseConfig ParseConfig) (*GloveCorpus, error) {
+	if err := co.ValidateFormat(format); err != nil {
+		return nil, err
+	}
+	if err := parseConfig.Validate(); err != nil {
+		return nil, err
+	}
 	gloveCorpus := &GloveCorpus{
 		core:         newCore(),
 		cooccurrence: make(map[uint64]float64),
 	}
-	if err := gloveCorpus.parse(f, toLower, minCount); err != nil {
+	gloveCorpus.parseConfig = parseConfig
+	if err := gloveCorpus.readVocab(vocab); err != nil {
+		return nil, errors.Wrap(err, "Unable to generate *GloveCorpus")
+	}
+	if err := co.ValidateWords(gloveCorpus.Size()); err != nil {
+		return nil, errors.Wrap(err, "Unable to generate *GloveCorpus")
+	}
+
+	var err error
+	switch format {
+	case co.FormatStanford:
+		gloveCorpus.cooccurrence, err = co.ReadStanford(cooccurrence, gloveCorpus.Size())
+	case co.FormatText:
+		err = gloveCorpus.readCooccurrenceText(cooccurrence)
+	}
+	if err != nil {
 		return nil, errors.Wrap(err, "Unable to generate *GloveCorpus")
 	}
-	gloveCorpus.build(window)
 	return gloveCorpus, nil
 }
 
@@ -47,15 +103,129 @@ func (gc *GloveCorpus) Cooccurrence() map[uint64]float64 {
 	return gc.cooccurrence
 }
 
-func (gc *GloveCorpus) build(window int) {
-	for i := 0; i < len(gc.document); i++ {
+// Subsampled returns the number of tokens discarded by subsampling on counting co-occurrences,
+// and the number of tokens before subsampling.
+func (gc *GloveCorpus) Subsampled() (int, int) {
+	return gc.subsampled, gc.counted
+}
+
+// build counts co-occurrences of words in window, which are scaled by the weight of the sentence of the former word
+// if corpus has sentence weights.
+func (gc *GloveCorpus) build(window int, maxTokens int64, subsampleThreshold float64, rnd Random) {
+	document, weights := gc.document, gc.wordWeights()
+	if maxTokens > 0 && int64(len(document)) > maxTokens {
+		document = document[:maxTokens]
+		if weights != nil {
+			weights = weights[:maxTokens]
+		}
+	}
+	gc.counted = len(document)
+	if subsampleThreshold > 0 {
+		document, weights = gc.subsample(document, weights, subsampleThreshold, rnd)
+	}
+	for i := 0; i < len(document); i++ {
 		for j := i + 1; j <= i+window; j++ {
-			if j >= len(gc.document) {
+			if j >= len(document) {
 				continue
 			}
 			f := 1. / math.Abs(float64(i-j))
-			gc.cooccurrence[co.EncodeBigram(uint64(gc.document[i]), uint64(gc.document[j]))] += f
-			gc.cooccurrence[co.EncodeBigram(uint64(gc.document[j]), uint64(gc.document[i]))] += f
+			if weights != nil {
+				f *= weights[i]
+			}
+			gc.cooccurrence[co.EncodeBigram(uint64(document[i]), uint64(document[j]))] += f
+			gc.cooccurrence[co.EncodeBigram(uint64(document[j]), uint64(document[i]))] += f
+		}
+	}
+}
+
+// WriteCooccurrence writes co-occurrence sorted by word ids. The format is one of: stanford|text
+// The word ids of stanford format refer to the lines of WriteVocab.
+func (gc *GloveCorpus) WriteCooccurrence(w io.Writer, format string) error {
+	if err := co.ValidateFormat(format); err != nil {
+		return err
+	}
+	if format == co.FormatStanford {
+		return co.WriteStanford(w, gc.cooccurrence)
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, pid := range co.SortedBigrams(gc.cooccurrence) {
+		l1, l2 := co.DecodeBigram(pid)
+		word1, _ := gc.Word(int(l1))
+		word2, _ := gc.Word(int(l2))
+		count := strconv.FormatFloat(gc.cooccurrence[pid], 'g', -1, 64)
+		if _, err := fmt.Fprintf(bw, "%s %s %s\n", word1, word2, count); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func (gc *GloveCorpus) readCooccurrenceText(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		sep := strings.Fields(scanner.Text())
+		if len(sep) == 0 {
+			continue
+		}
+		if len(sep) != 3 {
+			return errors.Errorf("Invalid co-occurrence line %d: %q not in word1 word2 count", lineNum, scanner.Text())
+		}
+		l1, ok1 := gc.Id(sep[0])
+		l2, ok2 := gc.Id(sep[1])
+		if !ok1 || !ok2 {
+			return errors.Errorf("Invalid co-occurrence line %d: %q has words not in vocabulary", lineNum, scanner.Text())
+		}
+		count, err := strconv.ParseFloat(sep[2], 64)
+		if err != nil {
+			return errors.Wrapf(err, "Invalid count at co-occurrence line %d", lineNum)
+		}
+		gc.cooccurrence[co.EncodeBigram(uint64(l1), uint64(l2))] += count
+	}
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		return errors.Wrap(err, "Unable to complete scanning")
+	}
+	return nil
+}
+
+// subsample discards each word of document with the probability in the same way as word2vec,
+// so that the pairs dominated by frequent words decrease.
+func (gc *GloveCorpus) subsample(document []int, weights []float64, threshold float64,
+	rnd Random) ([]int, []float64) {
+	subsampled := make([]int, 0, len(document))
+	var subsampledWeights []float64
+	for i, id := range document {
+		z := float64(gc.IDFreq(id)) / float64(gc.TotalFreq())
+		p := (math.Sqrt(z/threshold) + 1.0) * threshold / z
+		if p < rnd.Float64() {
+			gc.subsampled++
+			continue
+		}
+		subsampled = append(subsampled, id)
+		if weights != nil {
+			subsampledWeights = append(subsampledWeights, weights[i])
+		}
+	}
+	return subsampled, subsampledWeights
+}
+
+// wordWeights returns the weight of the sentence of each word in document, or nil if corpus has no weights.
+func (gc *GloveCorpus) wordWeights() []float64 {
+	if gc.weights == nil {
+		return nil
+	}
+	weights := make([]float64, len(gc.document))
+	for i := range weights {
+		weights[i] = 1
+	}
+	for s, weight := range gc.weights {
+		end := len(gc.document)
+		if s+1 < len(gc.sentences) {
+			end = gc.sentences[s+1]
+		}
+		for i := gc.sentences[s]; i < end; i++ {
+			weights[i] = weight
 		}
 	}
+	return weights
 }
@@ -0,0 +1,176 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ynqa/wego/corpus/co"
+)
+
+func TestGloveMaxTokens(t *testing.T) {
+	f := ioutil.NopCloser(strings.NewReader("a b b c c c c"))
+	cps, err := NewGloveCorpus(f, ParseConfig{}, 0, 5, 2, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cps.Size() != 3 {
+		t.Errorf("Expected vocabulary is built from the full corpus: %d", cps.Size())
+	}
+	if len(cps.Cooccurrence()) != 2 {
+		t.Errorf("Expected co-occurrences of a and b only: %v", cps.Cooccurrence())
+	}
+}
+
+func TestGloveSentenceWeights(t *testing.T) {
+	f := ioutil.NopCloser(strings.NewReader("2 a b\n1 c d"))
+	cps, err := NewGloveCorpus(f, ParseConfig{SentenceWeights: true}, 0, 1, 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// co-occurrences are scaled by the weight of the sentence of the former word.
+	for pair, expected := range map[[2]string]float64{{"a", "b"}: 2, {"b", "c"}: 2, {"c", "d"}: 1} {
+		a, _ := cps.Id(pair[0])
+		b, _ := cps.Id(pair[1])
+		if actual := cps.Cooccurrence()[co.EncodeBigram(uint64(a), uint64(b))]; actual != expected {
+			t.Errorf("Expected co-occurrence of %v=%v: %v", pair, expected, actual)
+		}
+	}
+}
+
+func TestCooccurrenceRoundTrip(t *testing.T) {
+	f := ioutil.NopCloser(strings.NewReader("a b b c c c a b c"))
+	cps, err := NewGloveCorpus(f, ParseConfig{}, 0, 3, 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []string{co.FormatStanford, co.FormatText} {
+		vocab, cooccurrence := &bytes.Buffer{}, &bytes.Buffer{}
+		if err := cps.WriteVocab(vocab); err != nil {
+			t.Fatal(err)
+		}
+		if err := cps.WriteCooccurrence(cooccurrence, format); err != nil {
+			t.Fatal(err)
+		}
+
+		imported, err := NewGloveCorpusFromCooccurrence(vocab, cooccurrence, format, ParseConfig{})
+		if err != nil {
+			t.Fatalf("Expected to import %s: %v", format, err)
+		}
+		if imported.Size() != cps.Size() {
+			t.Errorf("Expected vocabulary size=%d by %s: %d", cps.Size(), format, imported.Size())
+		}
+		for id := 0; id < cps.Size(); id++ {
+			word, _ := cps.Word(id)
+			importedWord, _ := imported.Word(id)
+			if word != importedWord || cps.IDFreq(id) != imported.IDFreq(id) {
+				t.Errorf("Expected %s(%d) at id %d by %s: %s(%d)",
+					word, cps.IDFreq(id), id, format, importedWord, imported.IDFreq(id))
+			}
+		}
+		if !reflect.DeepEqual(imported.Cooccurrence(), cps.Cooccurrence()) {
+			t.Errorf("Expected co-occurrences survive %s: %v, %v", format, cps.Cooccurrence(), imported.Cooccurrence())
+		}
+	}
+}
+
+func TestWriteVocab(t *testing.T) {
+	f := ioutil.NopCloser(strings.NewReader("a b b c c c"))
+	cps, err := NewGloveCorpus(f, ParseConfig{}, 0, 3, 0, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := &bytes.Buffer{}
+	if err := cps.WriteVocab(buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "c 3\nb 2\na 1\n" {
+		t.Errorf("Expected vocabulary in descending order of frequency: %q", buf.String())
+	}
+}
+
+func TestInvalidCooccurrence(t *testing.T) {
+	testCases := []struct {
+		vocab        string
+		cooccurrence string
+	}{
+		{vocab: "a 1\na 2\n", cooccurrence: ""},
+		{vocab: "a\n", cooccurrence: ""},
+		{vocab: "a x\n", cooccurrence: ""},
+		{vocab: "a 1\n", cooccurrence: "a b 1\n"},
+		{vocab: "a 1\n", cooccurrence: "a a x\n"},
+		{vocab: "a 1\n", cooccurrence: "a a\n"},
+	}
+
+	for _, testCase := range testCases {
+		_, err := NewGloveCorpusFromCooccurrence(strings.NewReader(testCase.vocab),
+			strings.NewReader(testCase.cooccurrence), co.FormatText, ParseConfig{})
+		if err == nil {
+			t.Errorf("Expected to fail importing vocab=%q, cooccurrence=%q", testCase.vocab, testCase.cooccurrence)
+		}
+	}
+	if _, err := NewGloveCorpusFromCooccurrence(strings.NewReader("a 1\n"),
+		strings.NewReader(""), "fake", ParseConfig{}); err == nil {
+		t.Error("Expected to fail importing invalid format")
+	}
+}
+
+func TestGloveSubsample(t *testing.T) {
+	// "the" occurs between 1000 distinct words, which are half of tokens.
+	words := make([]string, 0, 2000)
+	for i := 0; i < 1000; i++ {
+		words = append(words, "the", fmt.Sprintf("w%d", i))
+	}
+	text := strings.Join(words, " ")
+
+	theCooccurrence := func(subsampleThreshold float64) (float64, int) {
+		f := ioutil.NopCloser(strings.NewReader(text))
+		cps, err := NewGloveCorpus(f, ParseConfig{}, 0, 2, 0, subsampleThreshold, rand.New(rand.NewSource(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		the, _ := cps.Id("the")
+		var total float64
+		for pid, f := range cps.Cooccurrence() {
+			l1, l2 := co.DecodeBigram(pid)
+			if int(l1) == the || int(l2) == the {
+				total += f
+			}
+		}
+		discarded, _ := cps.Subsampled()
+		return total, discarded
+	}
+
+	full, discarded := theCooccurrence(0)
+	if discarded != 0 {
+		t.Errorf("Expected no tokens discarded without subsampling: %d", discarded)
+	}
+	subsampled, discarded := theCooccurrence(1.0e-3)
+	if subsampled > full*0.1 {
+		t.Errorf("Expected co-occurrences of the drop below 10%%: %v -> %v", full, subsampled)
+	}
+	// rare words are always kept since the probability to keep them exceeds 1,
+	// and the is kept with the probability about 0.0467 drawn from the fixed seed.
+	if discarded != 952 {
+		t.Errorf("Expected %d of the 1000 the to be discarded: %d", 952, discarded)
+	}
+}
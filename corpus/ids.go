@@ -0,0 +1,112 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// SentenceReader reads sentences of word ids one by one, and returns io.EOF after the last one.
+// The ids are indices of the dictionary given along with it, e.g. by a tokenizer in another service.
+type SentenceReader interface {
+	Read() ([]uint32, error)
+}
+
+// SentenceSlice is SentenceReader over sentences in memory.
+type SentenceSlice struct {
+	sentences [][]uint32
+	read      int
+}
+
+// NewSentenceSlice creates *SentenceSlice.
+func NewSentenceSlice(sentences [][]uint32) *SentenceSlice {
+	return &SentenceSlice{
+		sentences: sentences,
+	}
+}
+
+// Read reads the next sentence.
+func (s *SentenceSlice) Read() ([]uint32, error) {
+	if s.read >= len(s.sentences) {
+		return nil, io.EOF
+	}
+	s.read++
+	return s.sentences[s.read-1], nil
+}
+
+// NewWord2vecCorpusFromIDs creates *Word2vecCorpus from sentences of word ids instead of text,
+// whose words are given by dictionary indexed by the ids. Sentences are concatenated into document like lines
+// of text. The words of dictionary are normalized and filtered by parseConfig in the same way as parsed words.
+func NewWord2vecCorpusFromIDs(dictionary []string, sentences SentenceReader, parseConfig ParseConfig,
+	minCount int) (*Word2vecCorpus, error) {
+	word2vecCorpus := &Word2vecCorpus{
+		core: newCore(),
+	}
+	if err := word2vecCorpus.parseIDs(dictionary, sentences, parseConfig, minCount); err != nil {
+		return nil, errors.Wrap(err, "Unable to generate Word2vecCorpus")
+	}
+	return word2vecCorpus, nil
+}
+
+// parseIDs reads sentences of word ids with parseConfig to build vocabulary and document.
+func (c *core) parseIDs(dictionary []string, sentences SentenceReader, parseConfig ParseConfig,
+	minCount int) error {
+	if err := parseConfig.Validate(); err != nil {
+		return err
+	}
+	c.parseConfig = parseConfig
+	filter, _ := parseConfig.scriptFilter()
+
+	words := make([]string, len(dictionary))
+	for i, word := range dictionary {
+		if word = parseConfig.Normalize(word); filter == nil || filter.keep(word) {
+			words[i] = word
+		}
+	}
+
+	fullDoc, fullSentences := make([]int, 0), make([]int, 0)
+	maxTokens := parseConfig.MaxTokens
+	var n int64
+	for num := 1; maxTokens <= 0 || n < maxTokens; num++ {
+		sentence, err := sentences.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return errors.Wrapf(err, "Unable to read sentence %d", num)
+		}
+		newSentence := true
+		for _, id := range sentence {
+			if int(id) >= len(words) {
+				return errors.Errorf("Invalid word id %d in sentence %d: dictionary has %d words",
+					id, num, len(words))
+			}
+			if maxTokens > 0 && n >= maxTokens {
+				break
+			}
+			n++
+			if words[id] == "" {
+				continue
+			}
+			if newSentence {
+				fullSentences = append(fullSentences, len(fullDoc))
+				newSentence = false
+			}
+			fullDoc = append(fullDoc, c.Add(words[id]))
+		}
+	}
+	return c.buildDocument(fullDoc, fullSentences, nil, parseConfig, minCount)
+}
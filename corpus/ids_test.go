@@ -0,0 +1,79 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestNewWord2vecCorpusFromIDs(t *testing.T) {
+	dictionary := []string{"A", "b", "c"}
+	sentences := NewSentenceSlice([][]uint32{{0, 1}, {1, 2, 2}, {2, 2}})
+	fromIDs, err := NewWord2vecCorpusFromIDs(dictionary, sentences, ParseConfig{ToLower: true}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := ioutil.NopCloser(strings.NewReader("A b\nb c c\nc c"))
+	fromText, err := NewWord2vecCorpus(f, ParseConfig{ToLower: true}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fromIDs.Size() != fromText.Size() || len(fromIDs.Document()) != len(fromText.Document()) {
+		t.Fatalf("Expected the same vocabulary and document as text: %v, %v", fromIDs.Document(), fromText.Document())
+	}
+	for i, id := range fromText.Document() {
+		if fromIDs.Document()[i] != id {
+			t.Errorf("Expected document=%v: %v", fromText.Document(), fromIDs.Document())
+			break
+		}
+	}
+	if id, ok := fromIDs.Lookup("A"); !ok || id != 2 {
+		t.Errorf("Expected A to be normalized in dictionary with id=2: %v, %v", id, ok)
+	}
+}
+
+func TestNewWord2vecCorpusFromInvalidIDs(t *testing.T) {
+	sentences := NewSentenceSlice([][]uint32{{0, 1}, {3}})
+	_, err := NewWord2vecCorpusFromIDs([]string{"a", "b"}, sentences, ParseConfig{}, 0)
+	if err == nil || !strings.Contains(err.Error(), "sentence 2") {
+		t.Errorf("Expected to fail reading word id out of dictionary in sentence 2: %v", err)
+	}
+
+	if _, err := NewWord2vecCorpusFromIDs([]string{"a"}, failingSentenceReader{}, ParseConfig{}, 0); err == nil {
+		t.Error("Expected to fail with the error of reading sentences")
+	}
+}
+
+func TestNewWord2vecCorpusFromIDsMaxTokens(t *testing.T) {
+	sentences := NewSentenceSlice([][]uint32{{0, 1}, {1, 2, 2}, {2, 2}})
+	cps, err := NewWord2vecCorpusFromIDs([]string{"a", "b", "c"}, sentences, ParseConfig{MaxTokens: 3}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cps.Size() != 2 || len(cps.Document()) != 3 {
+		t.Errorf("Expected vocabulary of 2 words and document of 3 tokens: %d, %d", cps.Size(), len(cps.Document()))
+	}
+}
+
+type failingSentenceReader struct{}
+
+func (failingSentenceReader) Read() ([]uint32, error) {
+	return nil, errors.New("connection reset")
+}
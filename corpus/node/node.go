@@ -94,3 +94,34 @@ func (n *Nodes) Build(dimension int) error {
 	}
 	return nil
 }
+
+// BuildFromCodes builds the binary tree whose leaf i is reached from root by the codes[i] of 0|1,
+// e.g. given by Brown clusters instead of word frequencies, and returns the leaves.
+// The codes must be prefix-free and not empty.
+func BuildFromCodes(codes [][]int, dimension int) Nodes {
+	root := &Node{Vector: make([]float64, dimension)}
+	inner := make(map[string]*Node)
+	leaves := make(Nodes, len(codes))
+	for i, code := range codes {
+		parent := root
+		prefix := make([]byte, 0, len(code))
+		for _, c := range code[:len(code)-1] {
+			prefix = append(prefix, byte('0'+c))
+			n, ok := inner[string(prefix)]
+			if !ok {
+				n = &Node{
+					parent: parent,
+					Code:   c,
+					Vector: make([]float64, dimension),
+				}
+				inner[string(prefix)] = n
+			}
+			parent = n
+		}
+		leaves[i] = &Node{
+			parent: parent,
+			Code:   code[len(code)-1],
+		}
+	}
+	return leaves
+}
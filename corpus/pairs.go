@@ -0,0 +1,109 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Pair is a pair of target and context word ids, and the weight to scale learning rate on it.
+type Pair struct {
+	Target  int
+	Context int
+	Weight  float64
+}
+
+// PairsCorpus stores weighted pairs of target and context, e.g. (query, clicked item, weight) of clickstream,
+// instead of sentences to slide windows over. Targets and contexts have their own vocabularies.
+type PairsCorpus struct {
+	targets  *Word2vecCorpus
+	contexts *Word2vecCorpus
+	pairs    []Pair
+}
+
+// NewPairsCorpus creates *PairsCorpus from lines of "target context weight", where weight is a non-negative number.
+// Ids of both vocabularies are ranked by frequency of the pairs, and the pairs whose target or context is
+// not more frequent than minCount, or not kept by MinCountFunc of parseConfig, are filtered out.
+func NewPairsCorpus(f io.ReadCloser, parseConfig ParseConfig, minCount int) (*PairsCorpus, error) {
+	if err := parseConfig.Validate(); err != nil {
+		return nil, errors.Wrap(err, "Unable to generate PairsCorpus")
+	}
+	pc := &PairsCorpus{
+		targets:  &Word2vecCorpus{core: newCore()},
+		contexts: &Word2vecCorpus{core: newCore()},
+	}
+	pc.targets.parseConfig, pc.contexts.parseConfig = parseConfig, parseConfig
+
+	var fullPairs []Pair
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if lineNum == 1 {
+			line = trimBOM(line)
+		}
+		sep := strings.Fields(line)
+		if len(sep) == 0 {
+			continue
+		}
+		if len(sep) != 3 {
+			return nil, errors.Errorf("Invalid pair line %d: %q not in target context weight", lineNum, line)
+		}
+		weight, err := strconv.ParseFloat(sep[2], 64)
+		if err != nil || weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, errors.Errorf("Invalid weight at pair line %d: %q must be a non-negative number",
+				lineNum, sep[2])
+		}
+		fullPairs = append(fullPairs, Pair{
+			Target:  pc.targets.Add(parseConfig.Normalize(sep[0])),
+			Context: pc.contexts.Add(parseConfig.Normalize(sep[1])),
+			Weight:  weight,
+		})
+	}
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "Unable to complete scanning")
+	}
+
+	targetRank, contextRank := pc.targets.rankByFrequency(), pc.contexts.rankByFrequency()
+	pc.targets.keepWords(minCount)
+	pc.contexts.keepWords(minCount)
+	for _, p := range fullPairs {
+		p.Target, p.Context = targetRank[p.Target], contextRank[p.Context]
+		if pc.targets.kept[p.Target] && pc.contexts.kept[p.Context] {
+			pc.pairs = append(pc.pairs, p)
+		}
+	}
+	return pc, nil
+}
+
+// Targets returns the vocabulary of targets.
+func (pc *PairsCorpus) Targets() *Word2vecCorpus {
+	return pc.targets
+}
+
+// Contexts returns the vocabulary of contexts.
+func (pc *PairsCorpus) Contexts() *Word2vecCorpus {
+	return pc.contexts
+}
+
+// Pairs returns the pairs kept for training, in order of lines of corpus.
+func (pc *PairsCorpus) Pairs() []Pair {
+	return pc.pairs
+}
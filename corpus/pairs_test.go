@@ -0,0 +1,86 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewPairsCorpus(t *testing.T) {
+	f := ioutil.NopCloser(strings.NewReader("Q1 item1 2.5\nq2 item2 1\n\nq2 item1 0\nq3 item1 1\n"))
+	pc, err := NewPairsCorpus(f, ParseConfig{ToLower: true}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if targets := pc.Targets().Vocabulary(); !reflect.DeepEqual(targets, []string{"q2", "q1", "q3"}) {
+		t.Errorf("Expected targets ranked by frequency of pairs: %v", targets)
+	}
+	if contexts := pc.Contexts().Vocabulary(); !reflect.DeepEqual(contexts, []string{"item1", "item2"}) {
+		t.Errorf("Expected contexts ranked by frequency of pairs: %v", contexts)
+	}
+	expected := []Pair{
+		{Target: 1, Context: 0, Weight: 2.5},
+		{Target: 0, Context: 1, Weight: 1},
+		{Target: 0, Context: 0, Weight: 0},
+		{Target: 2, Context: 0, Weight: 1},
+	}
+	if !reflect.DeepEqual(pc.Pairs(), expected) {
+		t.Errorf("Expected pairs=%v: %v", expected, pc.Pairs())
+	}
+	if id, ok := pc.Targets().Lookup("Q1"); !ok || id != 1 {
+		t.Errorf("Expected Q1 to be normalized in targets with id=1: %v, %v", id, ok)
+	}
+}
+
+func TestNewPairsCorpusMinCount(t *testing.T) {
+	f := ioutil.NopCloser(strings.NewReader("q1 item1 1\nq2 item2 1\nq2 item1 1\nq1 item1 1\n"))
+	pc, err := NewPairsCorpus(f, ParseConfig{}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// item2 appears once, so the pair of it is filtered out.
+	if len(pc.Pairs()) != 3 {
+		t.Errorf("Expected 3 pairs whose target and context are both kept: %v", pc.Pairs())
+	}
+	for _, p := range pc.Pairs() {
+		if word, _ := pc.Contexts().Word(p.Context); word == "item2" {
+			t.Errorf("Expected item2 of frequency 1 to be filtered out: %v", pc.Pairs())
+		}
+	}
+}
+
+func TestNewPairsCorpusInvalidLine(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{name: "missing weight", input: "q1 item1 1\nq1 item1\n"},
+		{name: "negative weight", input: "q1 item1 -1\n"},
+		{name: "not a number", input: "q1 item1 heavy\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewPairsCorpus(ioutil.NopCloser(strings.NewReader(tc.input)), ParseConfig{}, 0); err == nil {
+				t.Errorf("Expected to fail parsing %q", tc.input)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// ParseConfig stores the settings to parse corpus into words.
+// It is captured once and applied identically both to build vocabulary and document from corpus,
+// and to look up words in vocabulary, so that they never diverge.
+type ParseConfig struct {
+	// whether the words convert to lowercase or not.
+	ToLower bool
+	// way to treat invalid UTF-8 sequences. One of: none|replace|skip
+	Sanitize string
+	// limit of words to read from corpus, no limit if it is not positive.
+	MaxTokens int64
+	// fraction of token occurrences for the kept words to cover, which overrides minCount if it is positive.
+	MinCoverage float64
+	// decides whether the word of freq is kept in vocabulary, which overrides minCount and MinCoverage if it is set.
+	MinCountFunc func(word string, freq int) bool
+	// scripts to keep tokens predominantly in them, e.g. latin, han. All tokens are kept if it is empty.
+	Scripts []string
+	// fraction of runes in Scripts for tokens to keep, DefaultScriptThreshold if it is 0.
+	ScriptThreshold float64
+	// times to retry reading corpus on transient errors, and the first delay which doubles on each retry.
+	ReadRetries    int
+	ReadRetryDelay time.Duration
+	// whether tokens are split on hyphens, e.g. "state-of-the-art", and on apostrophes, e.g. "don't".
+	// Tokens are kept intact by default.
+	SplitHyphens     bool
+	SplitApostrophes bool
+	// whether each line of corpus begins with the weight of the sentence, a non-negative number, e.g. "2.5 the fox".
+	SentenceWeights bool
+	// whether words are counted by the weights of their sentences instead of 1, e.g. to upweight in-domain corpus
+	// for vocabulary. The counts are rounded per word, and at least 1. It requires SentenceWeights.
+	WeightedCounts bool
+	// fixed ids of words which vocabulary honors instead of ranking words by frequency, e.g. of an external
+	// embedding layer. The ids must be 0 to len-1 without duplication, and the words must be the ones of corpus.
+	WordIDs map[string]int
+	// special tokens, e.g. <pad>, <bos>, <eos>, which are always in vocabulary with the reserved ids 0 to len-1
+	// in order regardless of frequency. The ones not in corpus are counted once.
+	SpecialTokens []string
+}
+
+// Validate validates the settings.
+func (p ParseConfig) Validate() error {
+	switch p.Sanitize {
+	case "", SanitizeNone, SanitizeReplace, SanitizeSkip:
+	default:
+		return errors.Errorf("Invalid sanitize: %s not in %s|%s|%s",
+			p.Sanitize, SanitizeNone, SanitizeReplace, SanitizeSkip)
+	}
+	if p.MinCoverage < 0 || p.MinCoverage > 1 {
+		return errors.Errorf("Invalid min coverage: %v not in [0, 1]", p.MinCoverage)
+	}
+	if p.ReadRetries < 0 {
+		return errors.Errorf("Invalid read retries: %d must be non-negative", p.ReadRetries)
+	}
+	if p.WeightedCounts && !p.SentenceWeights {
+		return errors.New("Weighted counts require sentence weights")
+	}
+	if _, err := p.wordIDs(); err != nil {
+		return err
+	}
+	if _, err := p.specialTokens(); err != nil {
+		return err
+	}
+	_, err := p.scriptFilter()
+	return err
+}
+
+// wordIDs returns WordIDs whose words are normalized in the same way as the words parsed from corpus,
+// or nil if it is not set. It fails if the ids have duplication or gaps, or the words conflict after normalizing.
+func (p ParseConfig) wordIDs() (map[string]int, error) {
+	if p.WordIDs == nil {
+		return nil, nil
+	}
+	words := make([]string, len(p.WordIDs))
+	normalized := make(map[string]int, len(p.WordIDs))
+	for word, id := range p.WordIDs {
+		if id < 0 || id >= len(p.WordIDs) {
+			return nil, errors.Errorf("Invalid word id %d of %s: ids of %d words must be in [0, %d)",
+				id, word, len(p.WordIDs), len(p.WordIDs))
+		}
+		if words[id] != "" {
+			return nil, errors.Errorf("Conflicted word id %d for %s and %s", id, words[id], word)
+		}
+		words[id] = word
+		norm := p.Normalize(word)
+		if other, ok := normalized[norm]; ok {
+			return nil, errors.Errorf("Conflicted words %s and %s of ids %d and %d after normalizing",
+				words[other], word, other, id)
+		}
+		normalized[norm] = id
+	}
+	return normalized, nil
+}
+
+// specialTokens returns SpecialTokens normalized in the same way as the words parsed from corpus.
+// It fails if a token is empty or has whitespace, the tokens conflict after normalizing, or WordIDs is also set.
+func (p ParseConfig) specialTokens() ([]string, error) {
+	if len(p.SpecialTokens) == 0 {
+		return nil, nil
+	}
+	if p.WordIDs != nil {
+		return nil, errors.New("Special tokens are not available with word ids, which fix the ids of all words")
+	}
+	tokens := make([]string, len(p.SpecialTokens))
+	seen := make(map[string]bool, len(p.SpecialTokens))
+	for i, token := range p.SpecialTokens {
+		if token == "" || strings.IndexFunc(token, unicode.IsSpace) >= 0 {
+			return nil, errors.Errorf("Invalid special token %q: must be non-empty without whitespace", token)
+		}
+		tokens[i] = p.Normalize(token)
+		if seen[tokens[i]] {
+			return nil, errors.Errorf("Duplicated special token %s after normalizing", token)
+		}
+		seen[tokens[i]] = true
+	}
+	return tokens, nil
+}
+
+// scriptFilter creates *scriptFilter for Scripts, or nil if it is empty.
+func (p ParseConfig) scriptFilter() (*scriptFilter, error) {
+	if len(p.Scripts) == 0 {
+		return nil, nil
+	}
+	threshold := p.ScriptThreshold
+	if threshold == 0 {
+		threshold = DefaultScriptThreshold
+	}
+	return newScriptFilter(p.Scripts, threshold)
+}
+
+// Normalize converts the word in the same way as the words parsed from corpus.
+func (p ParseConfig) Normalize(word string) string {
+	if p.sanitizes() {
+		word = sanitizeWord(word, p.Sanitize == SanitizeSkip)
+	}
+	if p.ToLower {
+		word = strings.ToLower(word)
+	}
+	return word
+}
+
+// splitWord appends the parts of word split on hyphens and apostrophes to parts, or word itself without splitting.
+func (p ParseConfig) splitWord(word string, parts []string) []string {
+	if !p.SplitHyphens && !p.SplitApostrophes {
+		return append(parts, word)
+	}
+	start := -1
+	for i, r := range word {
+		if p.SplitHyphens && isHyphen(r) || p.SplitApostrophes && isApostrophe(r) {
+			if start >= 0 {
+				parts = append(parts, word[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		parts = append(parts, word[start:])
+	}
+	return parts
+}
+
+func isHyphen(r rune) bool {
+	return r == '-' || r == '\u2010' || r == '\u2011'
+}
+
+func isApostrophe(r rune) bool {
+	return r == '\'' || r == '\u2019'
+}
+
+func (p ParseConfig) sanitizes() bool {
+	return p.Sanitize == SanitizeReplace || p.Sanitize == SanitizeSkip
+}
@@ -0,0 +1,61 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseConfigConsistency(t *testing.T) {
+	text := "Apple apple APPLE caf\xe9 Caf\xe9 bad\xff\xfe"
+	testCases := []ParseConfig{
+		{},
+		{ToLower: true},
+		{ToLower: true, Sanitize: SanitizeReplace},
+		{Sanitize: SanitizeSkip},
+	}
+
+	for _, parseConfig := range testCases {
+		c := newCore()
+		if err := c.parse(ioutil.NopCloser(strings.NewReader(text)), parseConfig, 0); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(c.ParseConfig(), parseConfig) {
+			t.Errorf("Expected corpus keeps %+v: %+v", parseConfig, c.ParseConfig())
+		}
+
+		// Lookup of the raw words has to give the same ids as the document built on parsing.
+		raw := strings.Fields(text)
+		if len(raw) != len(c.Document()) {
+			t.Fatalf("Expected %d words in document with %+v: %d", len(raw), parseConfig, len(c.Document()))
+		}
+		for i, word := range raw {
+			id, ok := c.Lookup(word)
+			if !ok || id != c.Document()[i] {
+				t.Errorf("Expected %q looks up id=%d in document with %+v: %d, %v",
+					word, c.Document()[i], parseConfig, id, ok)
+			}
+		}
+	}
+}
+
+func TestInvalidParseConfig(t *testing.T) {
+	if err := (ParseConfig{Sanitize: "fake"}).Validate(); err == nil {
+		t.Error("Expected to fail validating sanitize except for none|replace|skip")
+	}
+}
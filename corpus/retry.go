@@ -0,0 +1,59 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// retryReader retries reads of r on errors except io.EOF, up to retries times in a row,
+// waiting for delay which doubles on each consecutive failure.
+type retryReader struct {
+	r       io.Reader
+	retries int
+	delay   time.Duration
+	sleep   func(time.Duration)
+}
+
+func newRetryReader(r io.Reader, retries int, delay time.Duration) *retryReader {
+	return &retryReader{
+		r:       r,
+		retries: retries,
+		delay:   delay,
+		sleep:   time.Sleep,
+	}
+}
+
+func (r *retryReader) Read(p []byte) (int, error) {
+	delay := r.delay
+	for attempt := 0; ; attempt++ {
+		n, err := r.r.Read(p)
+		if err == nil || err == io.EOF {
+			return n, err
+		}
+		if n > 0 {
+			// return the bytes read so far, the next read retries.
+			return n, nil
+		}
+		if attempt >= r.retries {
+			return 0, errors.Wrapf(err, "Failed to read corpus after %d attempts", attempt+1)
+		}
+		r.sleep(delay)
+		delay *= 2
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+// flakyReader fails the first fails reads, and then reads r.
+type flakyReader struct {
+	r     io.Reader
+	fails int
+	reads int
+}
+
+func (f *flakyReader) Read(p []byte) (int, error) {
+	f.reads++
+	if f.reads <= f.fails {
+		return 0, errors.New("connection reset")
+	}
+	return f.r.Read(p)
+}
+
+func TestReadRetries(t *testing.T) {
+	flaky := &flakyReader{r: strings.NewReader("a b b c c c"), fails: 2}
+	c := newCore()
+	parseConfig := ParseConfig{ReadRetries: 2, ReadRetryDelay: time.Millisecond}
+	if err := c.parse(ioutil.NopCloser(flaky), parseConfig, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Size() != 3 {
+		t.Errorf("Expected vocabulary size=3: %d", c.Size())
+	}
+	if len(c.Document()) != 6 {
+		t.Errorf("Expected document length=6: %d", len(c.Document()))
+	}
+}
+
+func TestReadRetriesExhausted(t *testing.T) {
+	flaky := &flakyReader{r: strings.NewReader("a b b c c c"), fails: 3}
+	c := newCore()
+	parseConfig := ParseConfig{ReadRetries: 2, ReadRetryDelay: time.Millisecond}
+	if err := c.parse(ioutil.NopCloser(flaky), parseConfig, 0); err == nil {
+		t.Error("Expected to fail parsing after retries are exhausted")
+	}
+	if flaky.reads != 3 {
+		t.Errorf("Expected 3 attempts to read: %d", flaky.reads)
+	}
+}
+
+func TestRetryReaderBackoff(t *testing.T) {
+	var delays []time.Duration
+	r := newRetryReader(&flakyReader{r: strings.NewReader("a"), fails: 3}, 3, time.Second)
+	r.sleep = func(d time.Duration) {
+		delays = append(delays, d)
+	}
+
+	if _, err := ioutil.ReadAll(r); err != nil {
+		t.Fatal(err)
+	}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}
+	if len(delays) != len(expected) {
+		t.Fatalf("Expected delays=%v: %v", expected, delays)
+	}
+	for i := range expected {
+		if delays[i] != expected[i] {
+			t.Errorf("Expected delays=%v: %v", expected, delays)
+		}
+	}
+}
+
+func TestInvalidReadRetries(t *testing.T) {
+	if err := (ParseConfig{ReadRetries: -1}).Validate(); err == nil {
+		t.Error("Expected to fail validating negative ReadRetries")
+	}
+}
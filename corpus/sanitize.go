@@ -0,0 +1,103 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// The list of ways to treat invalid UTF-8 sequences in corpus.
+const (
+	SanitizeNone    = "none"
+	SanitizeReplace = "replace"
+	SanitizeSkip    = "skip"
+)
+
+// byteOrderMark is UTF-8 BOM, which editors on Windows often put at the beginning of text files.
+const byteOrderMark = "\ufeff"
+
+// utf8Sanitizer reads runes from r, and replaces invalid UTF-8 sequences with utf8.RuneError,
+// or skips them. It counts the lines which have invalid sequences.
+type utf8Sanitizer struct {
+	r    *bufio.Reader
+	skip bool
+
+	affected int
+	dirty    bool
+}
+
+func newUTF8Sanitizer(r io.Reader, skip bool) *utf8Sanitizer {
+	return &utf8Sanitizer{
+		r:    bufio.NewReader(r),
+		skip: skip,
+	}
+}
+
+// trimBOM trims UTF-8 BOM at the beginning of the first token or line of file.
+func trimBOM(s string) string {
+	return strings.TrimPrefix(s, byteOrderMark)
+}
+
+// sanitizeWord treats invalid UTF-8 sequences of the word in the same way as utf8Sanitizer.
+func sanitizeWord(word string, skip bool) string {
+	if utf8.ValidString(word) {
+		return word
+	}
+	b := make([]byte, 0, len(word)+utf8.UTFMax)
+	for i := 0; i < len(word); {
+		r, size := utf8.DecodeRuneInString(word[i:])
+		i += size
+		if r == utf8.RuneError && size == 1 && skip {
+			continue
+		}
+		b = append(b, string(r)...)
+	}
+	return string(b)
+}
+
+func (s *utf8Sanitizer) Read(p []byte) (int, error) {
+	var n int
+	for n+utf8.UTFMax <= len(p) {
+		r, size, err := s.r.ReadRune()
+		if err != nil {
+			s.endLine()
+			if n > 0 && err == io.EOF {
+				return n, nil
+			}
+			return n, err
+		}
+		if r == utf8.RuneError && size == 1 {
+			s.dirty = true
+			if s.skip {
+				continue
+			}
+		}
+		if r == '\n' {
+			s.endLine()
+		}
+		n += utf8.EncodeRune(p[n:], r)
+	}
+	return n, nil
+}
+
+func (s *utf8Sanitizer) endLine() {
+	if s.dirty {
+		s.affected++
+		s.dirty = false
+	}
+}
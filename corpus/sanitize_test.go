@@ -0,0 +1,66 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+const invalidUTF8Text = "caf\xe9 ok\nok ok\nbad\xff\xfe bad\xc3\n"
+
+func TestSanitizeUTF8(t *testing.T) {
+	testCases := []struct {
+		sanitize string
+		expected []string
+	}{
+		{
+			sanitize: SanitizeReplace,
+			expected: []string{"caf�", "ok", "bad��", "bad�"},
+		},
+		{
+			sanitize: SanitizeSkip,
+			expected: []string{"caf", "ok", "bad"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		c := newCore()
+		f := ioutil.NopCloser(strings.NewReader(invalidUTF8Text))
+		if err := c.parse(f, ParseConfig{Sanitize: testCase.sanitize}, 0); err != nil {
+			t.Fatal(err)
+		}
+		if c.InvalidUTF8Lines() != 2 {
+			t.Errorf("Expected 2 lines are sanitized by %v: %d", testCase.sanitize, c.InvalidUTF8Lines())
+		}
+		if c.Size() != len(testCase.expected) {
+			t.Errorf("Expected vocabulary size=%d by %v: %d", len(testCase.expected), testCase.sanitize, c.Size())
+		}
+		for _, word := range testCase.expected {
+			if _, ok := c.Id(word); !ok {
+				t.Errorf("Expected %q in vocabulary by %v", word, testCase.sanitize)
+			}
+		}
+	}
+}
+
+func TestInvalidSanitize(t *testing.T) {
+	c := newCore()
+	f := ioutil.NopCloser(strings.NewReader(invalidUTF8Text))
+	if err := c.parse(f, ParseConfig{Sanitize: "fake"}, 0); err == nil {
+		t.Error("Expected to fail parsing with invalid sanitize except for none|replace|skip")
+	}
+}
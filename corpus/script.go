@@ -0,0 +1,90 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultScriptThreshold is the default fraction of runes in the scripts for tokens to keep.
+const DefaultScriptThreshold = 0.5
+
+// scriptFilter keeps tokens which are predominantly in the scripts.
+type scriptFilter struct {
+	scripts   []*unicode.RangeTable
+	threshold float64
+}
+
+// newScriptFilter creates *scriptFilter for the script names of unicode.Scripts, case-insensitive, e.g. latin, han.
+func newScriptFilter(names []string, threshold float64) (*scriptFilter, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, errors.Errorf("Invalid script threshold: %v not in (0, 1]", threshold)
+	}
+	scripts := make([]*unicode.RangeTable, len(names))
+	for i, name := range names {
+		table, ok := scriptTable(name)
+		if !ok {
+			return nil, errors.Errorf("Invalid script: %s not in unicode.Scripts, e.g. latin|cyrillic|han", name)
+		}
+		scripts[i] = table
+	}
+	return &scriptFilter{
+		scripts:   scripts,
+		threshold: threshold,
+	}, nil
+}
+
+func scriptTable(name string) (*unicode.RangeTable, bool) {
+	for n, table := range unicode.Scripts {
+		if strings.EqualFold(n, name) {
+			return table, true
+		}
+	}
+	return nil, false
+}
+
+// keep returns whether the fraction of runes in the scripts is at least threshold for the token.
+// Runes of Common and Inherited scripts, e.g. digits, punctuation, emoji and combining marks, are not counted,
+// so the tokens only with them are kept.
+func (s *scriptFilter) keep(token string) bool {
+	var inScripts, total int
+	for _, r := range token {
+		if unicode.In(r, unicode.Common, unicode.Inherited) {
+			continue
+		}
+		total++
+		if unicode.In(r, s.scripts...) {
+			inScripts++
+		}
+	}
+	if total == 0 {
+		return true
+	}
+	return float64(inScripts)/float64(total) >= s.threshold
+}
+
+// ScriptNames returns the names of scripts available for the filter, which are lowercase.
+func ScriptNames() []string {
+	names := make([]string, 0, len(unicode.Scripts))
+	for name := range unicode.Scripts {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	return names
+}
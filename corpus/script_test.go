@@ -0,0 +1,112 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestScriptFilterKeep(t *testing.T) {
+	testCases := []struct {
+		scripts   []string
+		threshold float64
+		token     string
+		expected  bool
+	}{
+		{scripts: []string{"latin"}, token: "café", expected: true},
+		{scripts: []string{"latin"}, token: "naïve", expected: true},
+		{scripts: []string{"latin"}, token: "café", expected: true},
+		{scripts: []string{"latin"}, token: "日本語", expected: false},
+		{scripts: []string{"han"}, token: "日本語", expected: true},
+		{scripts: []string{"Latin", "Han"}, token: "日本語", expected: true},
+		{scripts: []string{"cyrillic"}, token: "привет", expected: true},
+		{scripts: []string{"cyrillic"}, token: "hello", expected: false},
+		{scripts: []string{"latin"}, token: "👍", expected: true},
+		{scripts: []string{"latin"}, token: "ok👍", expected: true},
+		{scripts: []string{"latin"}, token: "日本😀", expected: false},
+		{scripts: []string{"latin"}, token: "2017", expected: true},
+		{scripts: []string{"latin"}, threshold: 0.5, token: "ab日本", expected: true},
+		{scripts: []string{"latin"}, threshold: 0.6, token: "ab日本", expected: false},
+		{scripts: []string{"latin"}, threshold: 1, token: "abc日", expected: false},
+	}
+
+	for _, testCase := range testCases {
+		threshold := testCase.threshold
+		if threshold == 0 {
+			threshold = DefaultScriptThreshold
+		}
+		filter, err := newScriptFilter(testCase.scripts, threshold)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := filter.keep(testCase.token); actual != testCase.expected {
+			t.Errorf("Expected keep(%q)=%v with %v, threshold=%v: %v",
+				testCase.token, testCase.expected, testCase.scripts, threshold, actual)
+		}
+	}
+}
+
+func TestScriptFilterParse(t *testing.T) {
+	c := newCore()
+	f := ioutil.NopCloser(strings.NewReader("café 日本 naïve привет 👍 café"))
+	if err := c.parse(f, ParseConfig{Scripts: []string{"latin"}}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, word := range []string{"café", "naïve", "👍"} {
+		if _, ok := c.Id(word); !ok {
+			t.Errorf("Expected %q in vocabulary", word)
+		}
+	}
+	for _, word := range []string{"日本", "привет"} {
+		if _, ok := c.Id(word); ok {
+			t.Errorf("Expected %q not in vocabulary", word)
+		}
+	}
+	if len(c.Document()) != 4 {
+		t.Errorf("Expected document length=4: %d", len(c.Document()))
+	}
+}
+
+func TestInvalidScriptFilter(t *testing.T) {
+	testCases := []ParseConfig{
+		{Scripts: []string{"klingon"}},
+		{Scripts: []string{"latin"}, ScriptThreshold: 1.5},
+		{Scripts: []string{"latin"}, ScriptThreshold: -0.1},
+	}
+
+	for _, parseConfig := range testCases {
+		if err := parseConfig.Validate(); err == nil {
+			t.Errorf("Expected to fail validating %v", parseConfig)
+		}
+	}
+}
+
+func TestScriptNames(t *testing.T) {
+	names := ScriptNames()
+	for _, expected := range []string{"latin", "cyrillic", "han"} {
+		found := false
+		for _, name := range names {
+			if name == expected {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected %q in ScriptNames", expected)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"bufio"
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+)
+
+// wordSplitter splits words like bufio.ScanWords, and records whether a line break precedes the next word,
+// which begins a new sentence of corpus.
+type wordSplitter struct {
+	newLine bool
+	// whether the delimiter consumed along with the last word is a line break.
+	pending bool
+}
+
+func (s *wordSplitter) split(data []byte, atEOF bool) (int, []byte, error) {
+	start := 0
+	for width := 0; start < len(data); start += width {
+		var r rune
+		r, width = utf8.DecodeRune(data[start:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		if r == '\n' {
+			s.newLine = true
+		}
+	}
+	advance, token, err := bufio.ScanWords(data[start:], atEOF)
+	if token != nil {
+		if s.pending {
+			s.newLine = true
+		}
+		s.pending = bytes.IndexByte(data[start+len(token):start+advance], '\n') >= 0
+	}
+	return start + advance, token, err
+}
+
+// Sentences returns the offsets in document where sentences begin, i.e. lines of corpus or sentences of word ids,
+// in ascending order. A sentence whose words are all filtered out is omitted.
+func (c *core) Sentences() []int {
+	return c.sentences
+}
+
+// SentenceWeights returns the weights of Sentences given by SentenceWeights of ParseConfig,
+// or nil if corpus has no weights.
+func (c *core) SentenceWeights() []float64 {
+	return c.weights
+}
@@ -28,5 +28,5 @@ var (
 	text       = "a b b c c c c"
 	fakeSeeker = fakeNopSeeker{ReadCloser: ioutil.NopCloser(bytes.NewReader([]byte(text)))}
 	// TestWord2vecCorpus is mock for test.
-	TestWord2vecCorpus, _ = NewWord2vecCorpus(fakeSeeker, true, 0)
+	TestWord2vecCorpus, _ = NewWord2vecCorpus(fakeSeeker, ParseConfig{ToLower: true}, 0)
 )
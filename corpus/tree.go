@@ -0,0 +1,100 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/corpus/node"
+)
+
+// ReadTree reads the binary tree over words, e.g. Brown clusters or WordNet, whose lines are "word code".
+// code is the path from root to the word as 0|1, e.g. "dog 0110", and the codes must be prefix-free.
+func ReadTree(r io.Reader) (map[string]string, error) {
+	tree := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		sep := strings.Fields(scanner.Text())
+		if len(sep) == 0 {
+			continue
+		}
+		if len(sep) != 2 {
+			return nil, errors.Errorf("Invalid tree line %d: %q not in word code", lineNum, scanner.Text())
+		}
+		word, code := sep[0], sep[1]
+		if strings.Trim(code, "01") != "" {
+			return nil, errors.Errorf("Invalid code %q of %s at tree line %d: not in 0|1", code, word, lineNum)
+		}
+		if _, ok := tree[word]; ok {
+			return nil, errors.Errorf("Duplicated word %s at tree line %d", word, lineNum)
+		}
+		tree[word] = code
+	}
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "Unable to complete scanning")
+	}
+
+	words := make([]string, 0, len(tree))
+	for word := range tree {
+		words = append(words, word)
+	}
+	// a code is a prefix of another if and only if it is a prefix of the next one in lexical order.
+	sort.Slice(words, func(i, j int) bool { return tree[words[i]] < tree[words[j]] })
+	for i := 1; i < len(words); i++ {
+		if prev, code := tree[words[i-1]], tree[words[i]]; strings.HasPrefix(code, prev) {
+			return nil, errors.Errorf("Invalid code %q of %s: a prefix of code %q of %s",
+				prev, words[i-1], code, words[i])
+		}
+	}
+	return tree, nil
+}
+
+// CodeTree builds word nodes map on the binary tree read by ReadTree instead of huffman tree.
+// All the words in vocabulary must be on the tree, whose words are normalized in the same way as corpus.
+func (wc *Word2vecCorpus) CodeTree(tree map[string]string, dimension int) (map[int]*node.Node, error) {
+	normalized := make(map[string]string, len(tree))
+	for word, code := range tree {
+		normalized[wc.parseConfig.Normalize(word)] = code
+	}
+	codes := make([][]int, wc.Size())
+	missing := make([]string, 0)
+	for i := range codes {
+		word, _ := wc.Word(i)
+		code, ok := normalized[word]
+		if !ok {
+			missing = append(missing, word)
+			continue
+		}
+		codes[i] = make([]int, len(code))
+		for p, c := range code {
+			codes[i][p] = int(c - '0')
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.Errorf("Words in vocabulary are not on tree: %q", missing)
+	}
+
+	nm := make(map[int]*node.Node)
+	for i, leaf := range node.BuildFromCodes(codes, dimension) {
+		leaf.Value = wc.IDFreq(i)
+		nm[i] = leaf
+	}
+	return nm, nil
+}
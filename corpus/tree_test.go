@@ -0,0 +1,74 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCodeTree(t *testing.T) {
+	// c is on the left of root, and a, b under the right, along with the word not in vocabulary.
+	tree, err := ReadTree(strings.NewReader("A 100\nb 101\nc 0\nunused 11\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodeMap, err := TestWord2vecCorpus.CodeTree(tree, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]int{"a": {1, 0, 0}, "b": {1, 0, 1}, "c": {0}}
+	for word, code := range expected {
+		id, _ := TestWord2vecCorpus.Id(word)
+		path := nodeMap[id].GetPath()
+		if len(path) != len(code)+1 {
+			t.Fatalf("Expected path of %v with length=%v: %v", word, len(code)+1, len(path))
+		}
+		for p, c := range code {
+			if path[p+1].Code != c {
+				t.Errorf("Expected code %v of %v on path: %v at %d", code, word, path[p+1].Code, p)
+			}
+		}
+		if len(path[0].Vector) != 5 {
+			t.Errorf("Expected vector of root with dimension=5: %v", path[0].Vector)
+		}
+	}
+	if nodeMap[0].GetPath()[0] != nodeMap[2].GetPath()[0] {
+		t.Error("Expected all words under the same root")
+	}
+
+	if _, err := TestWord2vecCorpus.CodeTree(map[string]string{"a": "0", "b": "1"}, 5); err == nil ||
+		!strings.Contains(err.Error(), `"c"`) {
+		t.Errorf("Expected to fail pinpointing the word not on tree: %v", err)
+	}
+}
+
+func TestInvalidTree(t *testing.T) {
+	testCases := []struct {
+		tree     string
+		expected string
+	}{
+		{"a 0\nb 012\n", `"012"`},
+		{"a 0\nb\n", "line 2"},
+		{"a 0\na 1\n", "Duplicated word a"},
+		{"a 01\nb 1\nc 0\n", `"0" of c`},
+	}
+	for _, testCase := range testCases {
+		_, err := ReadTree(strings.NewReader(testCase.tree))
+		if err == nil || !strings.Contains(err.Error(), testCase.expected) {
+			t.Errorf("Expected error with %v for tree %q: %v", testCase.expected, testCase.tree, err)
+		}
+	}
+}
@@ -28,11 +28,11 @@ type Word2vecCorpus struct {
 }
 
 // NewWord2vecCorpus creates *Word2vecCorpus.
-func NewWord2vecCorpus(f io.ReadCloser, toLower bool, minCount int) (*Word2vecCorpus, error) {
+func NewWord2vecCorpus(f io.ReadCloser, parseConfig ParseConfig, minCount int) (*Word2vecCorpus, error) {
 	word2vecCorpus := &Word2vecCorpus{
 		core: newCore(),
 	}
-	if err := word2vecCorpus.parse(f, toLower, minCount); err != nil {
+	if err := word2vecCorpus.parse(f, parseConfig, minCount); err != nil {
 		return nil, errors.Wrap(err, "Unable to generate Word2vecCorpus")
 	}
 	return word2vecCorpus, nil
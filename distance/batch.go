@@ -0,0 +1,116 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distance
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// Query is an external vector to search the similar words of, e.g. a centroid of clustered sentence embeddings.
+type Query struct {
+	// ID identifies the query in results and errors.
+	ID     string
+	Vector []float64
+}
+
+// ReadQueries reads queries from JSONL whose lines are {"id": ..., "vector": [...]}.
+// id is either a string or a number, which is kept as written.
+func ReadQueries(r io.Reader) ([]Query, error) {
+	var queries []Query
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var q struct {
+			ID     json.RawMessage `json:"id"`
+			Vector []float64       `json:"vector"`
+		}
+		if err := json.Unmarshal(line, &q); err != nil {
+			return nil, errors.Wrapf(err, "Invalid query at line %d", lineNum)
+		}
+		if len(q.ID) == 0 {
+			return nil, errors.Errorf("Invalid query at line %d: no id", lineNum)
+		}
+		id := string(q.ID)
+		var s string
+		if json.Unmarshal(q.ID, &s) == nil {
+			id = s
+		}
+		queries = append(queries, Query{ID: id, Vector: q.Vector})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "Unable to complete scanning")
+	}
+	return queries, nil
+}
+
+// SearchBatch returns at most k similar words to the vector of each query by SearchByVector, in the order of queries.
+// The queries are searched by workers in parallel. It fails with the id of the query whose dimension differs
+// from word vectors.
+func (e *Estimator) SearchBatch(queries []Query, k, workers int) ([]Measures, error) {
+	if workers < 1 {
+		return nil, errors.Errorf("Invalid workers: %d must be positive", workers)
+	}
+	dim := -1
+	for _, vec := range e.dense {
+		dim = vec.Shape()[0]
+		break
+	}
+	if dim < 0 {
+		return nil, errors.New("No word vectors to search")
+	}
+	for _, q := range queries {
+		if len(q.Vector) != dim {
+			return nil, errors.Errorf("Dimension of query %s is %d, but word vectors have %d",
+				q.ID, len(q.Vector), dim)
+		}
+	}
+
+	results := make([]Measures, len(queries))
+	errs := make([]error, len(queries))
+	indices := make(chan int)
+	waitGroup := &sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for i := range indices {
+				vec := tensor.New(tensor.WithShape(dim), tensor.WithBacking(queries[i].Vector))
+				results[i], errs[i] = e.SearchByVector(vec, k)
+			}
+		}()
+	}
+	for i := range queries {
+		indices <- i
+	}
+	close(indices)
+	waitGroup.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, errors.Wrapf(err, "Unable to search query %s", queries[i].ID)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,63 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distance
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestReadQueries(t *testing.T) {
+	queries, err := ReadQueries(strings.NewReader("{\"id\": \"c1\", \"vector\": [1, 0]}\n\n{\"id\": 2, \"vector\": [0, 1]}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 2 || queries[0].ID != "c1" || queries[1].ID != "2" || queries[1].Vector[1] != 1 {
+		t.Errorf("Expected queries of c1 and 2: %+v", queries)
+	}
+
+	if _, err := ReadQueries(strings.NewReader("{\"vector\": [1, 0]}\n")); err == nil {
+		t.Error("Expected to fail with a query without id")
+	}
+}
+
+func TestSearchBatch(t *testing.T) {
+	estimator := NewEstimator("", 1)
+	f := ioutil.NopCloser(strings.NewReader("a 1 0\nb 0 1\nc -1 0\n"))
+	if err := estimator.Estimate(f); err != nil {
+		t.Fatal(err)
+	}
+
+	queries := []Query{
+		{ID: "c1", Vector: []float64{0.9, 0.1}},
+		{ID: "c2", Vector: []float64{0.1, 0.9}},
+		{ID: "c3", Vector: []float64{-1, 0.1}},
+	}
+	results, err := estimator.SearchBatch(queries, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expected := range []string{"a", "b", "c"} {
+		if len(results[i]) != 1 || results[i][0].Word() != expected {
+			t.Errorf("Expected the nearest word of %s=%s: %v", queries[i].ID, expected, results[i])
+		}
+	}
+
+	queries = append(queries, Query{ID: "bad", Vector: []float64{1, 0, 0}})
+	if _, err := estimator.SearchBatch(queries, 1, 2); err == nil || !strings.Contains(err.Error(), "bad") {
+		t.Errorf("Expected error of dimension mismatch with the id of the query: %v", err)
+	}
+}
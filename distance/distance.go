@@ -18,6 +18,7 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"sort"
 	"strconv"
@@ -26,6 +27,9 @@ import (
 	"github.com/olekukonko/tablewriter"
 	"github.com/pkg/errors"
 	"gorgonia.org/tensor"
+
+	"github.com/ynqa/wego/model"
+	"github.com/ynqa/wego/vectorio"
 )
 
 // Estimator stores the elements for cosine similarity.
@@ -33,6 +37,24 @@ type Estimator struct {
 	target string
 	rank   int
 	dense  map[string]*tensor.Dense
+
+	// frequencies of words in training corpus, and lower limit of frequency for similar words.
+	freqs   map[string]int
+	minFreq int
+
+	// tags of words, and tags which similar words must have.
+	metadata map[string]map[string]string
+	filter   map[string]string
+
+	// lambda of MMR to re-rank similar words, which are not re-ranked if it is 1.
+	lambda float64
+
+	// expression of word vectors to search instead of target word.
+	expr string
+
+	// delimiter of sense tags in words, and how to merge the similar words of senses.
+	senseDelimiter string
+	senseMerge     string
 }
 
 // NewEstimator creates *SimilarityEstimator
@@ -41,18 +63,40 @@ func NewEstimator(target string, rank int) *Estimator {
 		target: target,
 		rank:   rank,
 		dense:  make(map[string]*tensor.Dense),
+		lambda: 1,
 	}
 }
 
+// WithFrequency sets frequencies of words in training corpus to show them along with similar words,
+// and filters out the words whose frequency is lower than minFreq. The words without frequency are regarded as 0.
+func (e *Estimator) WithFrequency(freqs map[string]int, minFreq int) *Estimator {
+	e.freqs = freqs
+	e.minFreq = minFreq
+	return e
+}
+
+// WithMetadata sets tags of words, e.g. read by ReadMetadata, to filter similar words by WithFilter.
+func (e *Estimator) WithMetadata(metadata map[string]map[string]string) *Estimator {
+	e.metadata = metadata
+	return e
+}
+
+// WithFilter sets tags which similar words must have all of. The words without metadata are filtered out
+// if any tag is set.
+func (e *Estimator) WithFilter(filter map[string]string) *Estimator {
+	e.filter = filter
+	return e
+}
+
 // Estimate estimates the similarity for target word.
 func (e *Estimator) Estimate(f io.ReadCloser) error {
 	defer f.Close()
 
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
-		line := scanner.Text()
+		line := vectorio.TrimLine(scanner.Text())
 
-		if strings.HasPrefix(line, " ") {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, " ") {
 			continue
 		}
 		word, vec, err := parse(line)
@@ -70,65 +114,314 @@ func (e *Estimator) Estimate(f io.ReadCloser) error {
 	return nil
 }
 
+// LoadFastTextVec loads word vectors in the .vec format of fastText, e.g. distributed pretrained vectors,
+// instead of Estimate, which would read the header "<words> <dimension>" as a word. The header is validated
+// against the lines, and the space fastText writes after each value is ignored.
+func (e *Estimator) LoadFastTextVec(r io.Reader) error {
+	vectors, err := vectorio.ReadFastTextVec(r)
+	if err != nil {
+		return errors.Wrap(err, "Unable to load fastText vectors")
+	}
+	for _, word := range vectors.Words {
+		vec := vectors.Vector[word]
+		dense := tensor.NewDense(tensor.Float64, tensor.Shape{len(vec)})
+		copy(dense.Data().([]float64), vec)
+		e.dense[word] = dense
+	}
+	return nil
+}
+
+// LoadStorage sets the vectors of words in s, where words[id] is the word of id, e.g. Vocabulary of the model
+// trained on s. The vectors are queried in place without copying, so s must not be closed while querying.
+func (e *Estimator) LoadStorage(words []string, s model.Storage) {
+	for id, word := range words {
+		vec := s.Get(id)
+		e.dense[word] = tensor.NewDense(tensor.Float64, tensor.Shape{len(vec)}, tensor.WithBacking(vec))
+	}
+}
+
 // Describe shows the similar words list for target word.
 func (e *Estimator) Describe() error {
-	// TODO: Save to the file also.
-	return e.stdout()
+	return e.DescribeTo(os.Stdout)
 }
 
-func (e *Estimator) stdout() error {
-	tvec, ok := e.dense[e.target]
+// DescribeTo writes the similar words list for target word to w as a table.
+func (e *Estimator) DescribeTo(w io.Writer) error {
+	res, err := e.similar()
+	if err != nil {
+		return err
+	}
+
+	header := []string{"Rank", "Word", "Cosine"}
+	if e.freqs != nil {
+		header = append(header, "Frequency")
+	}
+	if e.senseDelimiter != "" {
+		header = append(header, "Sense")
+	}
+	table := make([][]string, len(res))
+	for r := range res {
+		table[r] = []string{
+			fmt.Sprintf("%d", r+1),
+			res[r].word,
+			fmt.Sprintf("%f", res[r].similarity),
+		}
+		if e.freqs != nil {
+			table[r] = append(table[r], fmt.Sprintf("%d", res[r].frequency))
+		}
+		if e.senseDelimiter != "" {
+			table[r] = append(table[r], res[r].sense)
+		}
+	}
+
+	tw := tablewriter.NewWriter(w)
+	tw.SetHeader(header)
+	tw.SetBorder(false)
+	tw.AppendBulk(table)
+	tw.Render()
+	return nil
+}
+
+// similar returns at most rank words in descending order of similarity to target word,
+// or to the expression set by WithExpression.
+func (e *Estimator) similar() (Measures, error) {
+	k := e.rank
+	if e.lambda != 1 {
+		k *= mmrPoolFactor
+	}
+	var (
+		candidates Measures
+		err        error
+	)
+	if e.expr != "" {
+		var (
+			vec   *tensor.Dense
+			words []string
+		)
+		if vec, words, err = e.Evaluate(e.expr); err != nil {
+			return nil, err
+		}
+		exclude := make(map[string]bool)
+		for _, word := range words {
+			exclude[word] = true
+		}
+		candidates, err = e.search(vec, k, e.filter, exclude)
+	} else {
+		candidates, err = e.SearchFiltered(e.target, k, e.filter)
+	}
+	if err != nil || e.lambda == 1 {
+		return candidates, err
+	}
+	return e.MMR(candidates, e.rank, e.lambda)
+}
+
+// SearchFiltered returns at most k words which have all tags of filter in metadata set by WithMetadata,
+// in descending order of similarity to word. Words are filtered on scoring, so that k words are returned
+// as long as enough words match. The word not in vocabulary is searched as its senses set by WithSenseDelimiter.
+func (e *Estimator) SearchFiltered(word string, k int, filter map[string]string) (Measures, error) {
+	tvec, ok := e.dense[word]
 	if !ok {
-		return fmt.Errorf("%v is not found", e.target)
+		if senses := e.senses(word); len(senses) > 0 {
+			return e.searchSenses(word, senses, k, filter)
+		}
+		return nil, fmt.Errorf("%v is not found", word)
+	}
+	return e.search(tvec, k, filter, map[string]bool{word: true})
+}
+
+// SearchByVector returns at most k words in descending order of similarity to vec, e.g. evaluated by Evaluate,
+// except for the words of exclude.
+func (e *Estimator) SearchByVector(vec *tensor.Dense, k int, exclude ...string) (Measures, error) {
+	excluded := make(map[string]bool)
+	for _, word := range exclude {
+		excluded[word] = true
 	}
+	return e.search(vec, k, nil, excluded)
+}
 
+func (e *Estimator) search(tvec *tensor.Dense, k int, filter map[string]string, exclude map[string]bool) (Measures, error) {
 	tvecNorm, err := norm(tvec)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	res := make(Measures, len(e.dense))
+	res := make(Measures, 0, len(e.dense))
 
-	for word, vec := range e.dense {
-		if word == e.target {
+	for other, vec := range e.dense {
+		if exclude[other] || !e.matches(other, filter) {
+			continue
+		}
+		freq := e.freqs[other]
+		if e.freqs != nil && freq < e.minFreq {
 			continue
 		}
 		vecNorm, err := norm(vec)
 
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		sim, err := cosine(tvec, vec, tvecNorm, vecNorm)
 
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		res = append(res, Measure{
-			word:       word,
+			word:       other,
 			similarity: sim,
+			frequency:  freq,
 		})
 	}
 
 	sort.Sort(sort.Reverse(res))
+	if len(res) > k {
+		res = res[:k]
+	}
+	return res, nil
+}
 
-	table := make([][]string, e.rank)
-	for r := 0; r < e.rank; r++ {
-		table[r] = []string{
-			fmt.Sprintf("%d", r+1),
-			res[r].word,
-			fmt.Sprintf("%f", res[r].similarity),
+// matches returns whether word has all tags of filter in metadata.
+func (e *Estimator) matches(word string, filter map[string]string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	tags, ok := e.metadata[word]
+	if !ok {
+		return false
+	}
+	for k, v := range filter {
+		if tags[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// DoesntMatch returns the word which is the least similar to the centroid of words.
+// The words not in vocabulary are skipped.
+func (e *Estimator) DoesntMatch(words []string) (string, error) {
+	vecs := make([]*tensor.Dense, 0, len(words))
+	known := make([]string, 0, len(words))
+	for _, word := range words {
+		vec, ok := e.dense[word]
+		if !ok {
+			continue
 		}
+		vecs = append(vecs, vec)
+		known = append(known, word)
+	}
+	if len(known) < 2 {
+		return "", errors.Errorf("At least 2 words in vocabulary are required: %v", words)
 	}
 
-	tw := tablewriter.NewWriter(os.Stdout)
-	tw.SetHeader([]string{"Rank", "Word", "Cosine"})
-	tw.SetBorder(false)
-	tw.AppendBulk(table)
-	tw.Render()
-	return nil
+	dim := vecs[0].Shape()[0]
+	centroid := tensor.NewDense(tensor.Float64, tensor.Shape{dim})
+	cdat := centroid.Data().([]float64)
+	for k, vec := range vecs {
+		dat := vec.Data().([]float64)
+		if len(dat) != dim {
+			return "", errors.Errorf("Dimension of %v is %d, but expected %d", known[k], len(dat), dim)
+		}
+		for i := 0; i < dim; i++ {
+			cdat[i] += dat[i] / float64(len(vecs))
+		}
+	}
+	centroidNorm, err := norm(centroid)
+	if err != nil {
+		return "", err
+	}
+
+	var res Measure
+	for k, vec := range vecs {
+		vecNorm, err := norm(vec)
+		if err != nil {
+			return "", err
+		}
+		sim, err := cosine(centroid, vec, centroidNorm, vecNorm)
+		if err != nil {
+			return "", err
+		}
+		if k == 0 || sim < res.similarity {
+			res = Measure{
+				word:       known[k],
+				similarity: sim,
+			}
+		}
+	}
+	return res.word, nil
+}
+
+// SoftCosine returns soft cosine similarity between bags of words doc1 and doc2, weighted by term frequency.
+// Unlike cosine of averaged vectors, it compares each pair of terms with the cosine of their vectors,
+// regarding negative cosine as unrelated, so that the documents sharing no words but related ones are similar.
+// The words not in vocabulary are skipped.
+func (e *Estimator) SoftCosine(doc1, doc2 []string) (float64, error) {
+	terms := make([]string, 0, len(doc1)+len(doc2))
+	index := make(map[string]int)
+	count := func(doc []string) (map[int]float64, error) {
+		tf := make(map[int]float64)
+		for _, word := range doc {
+			if _, ok := e.dense[word]; !ok {
+				continue
+			}
+			i, ok := index[word]
+			if !ok {
+				i = len(terms)
+				index[word] = i
+				terms = append(terms, word)
+			}
+			tf[i]++
+		}
+		if len(tf) == 0 {
+			return nil, errors.Errorf("At least 1 word in vocabulary is required: %v", doc)
+		}
+		return tf, nil
+	}
+	tf1, err := count(doc1)
+	if err != nil {
+		return 0, err
+	}
+	tf2, err := count(doc2)
+	if err != nil {
+		return 0, err
+	}
+
+	norms := make([]float64, len(terms))
+	for i, term := range terms {
+		if norms[i], err = norm(e.dense[term]); err != nil {
+			return 0, err
+		}
+	}
+	sims := make([][]float64, len(terms))
+	for i := range sims {
+		sims[i] = make([]float64, len(terms))
+	}
+	for i := range terms {
+		sims[i][i] = 1
+		for j := i + 1; j < len(terms); j++ {
+			sim, err := cosine(e.dense[terms[i]], e.dense[terms[j]], norms[i], norms[j])
+			if err != nil {
+				return 0, err
+			}
+			if sim < 0 {
+				sim = 0
+			}
+			sims[i][j], sims[j][i] = sim, sim
+		}
+	}
+
+	inner := func(a, b map[int]float64) float64 {
+		var sum float64
+		for i, x := range a {
+			for j, y := range b {
+				sum += x * sims[i][j] * y
+			}
+		}
+		return sum
+	}
+	return inner(tf1, tf2) / math.Sqrt(inner(tf1, tf1)*inner(tf2, tf2)), nil
 }
 
 func parse(line string) (string, *tensor.Dense, error) {
@@ -158,7 +451,11 @@ func norm(d *tensor.Dense) (float64, error) {
 	return n, nil
 }
 
+// cosine returns cosine similarity of d1 and d2 with their norms, which is 0 if either of them is zero vector.
 func cosine(d1, d2 *tensor.Dense, d1Norm, d2Norm float64) (float64, error) {
+	if d1Norm == 0 || d2Norm == 0 {
+		return 0, nil
+	}
 	inner, err := tensor.Inner(d1, d2)
 
 	if err != nil {
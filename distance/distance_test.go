@@ -17,7 +17,11 @@ package distance
 import (
 	"bytes"
 	"io/ioutil"
+	"math"
+	"strings"
 	"testing"
+
+	"github.com/ynqa/wego/model"
 )
 
 var testVector = `apple 1 1 1 1 1
@@ -39,3 +43,322 @@ func TestEstimate(t *testing.T) {
 		t.Errorf("Expected estimator.tensor len=4: %d", len(estimator.dense))
 	}
 }
+
+func TestEstimateCRLF(t *testing.T) {
+	estimator := NewEstimator("apple", 3)
+
+	f := ioutil.NopCloser(strings.NewReader("\ufeffapple 1 1\r\nbanana 1 0.9\r\n\r\ncar 0 1\r\n"))
+	if err := estimator.Estimate(f); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := estimator.dense["apple"]; !ok || len(estimator.dense) != 3 {
+		t.Errorf("Expected apple, banana and car without BOM: %v", estimator.dense)
+	}
+	res, err := estimator.similar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res[0].word != "banana" {
+		t.Errorf("Expected banana is the most similar to apple: %v", res)
+	}
+}
+
+func TestLoadFastTextVec(t *testing.T) {
+	estimator := NewEstimator("apple", 2)
+
+	vec := "3 3 \napple 1 1 0.1 \nbanana 0.9 1 0 \ncar 0 0.1 1 \n"
+	if err := estimator.LoadFastTextVec(strings.NewReader(vec)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := estimator.dense["3"]; ok || len(estimator.dense) != 3 {
+		t.Errorf("Expected apple, banana and car without header: %v", estimator.dense)
+	}
+	res, err := estimator.SearchFiltered("apple", 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].word != "banana" {
+		t.Errorf("Expected banana is the most similar to apple: %v", res)
+	}
+
+	if err := estimator.LoadFastTextVec(strings.NewReader("2 3\napple 1 1 0\n")); err == nil {
+		t.Error("Expected to fail loading fewer vectors than the header")
+	}
+}
+
+func TestLoadStorage(t *testing.T) {
+	s, err := model.NewMemoryStorage(3, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Set(0, []float64{1, 1, 0.1})
+	s.Set(1, []float64{0.9, 1, 0})
+	s.Set(2, []float64{0, 0.1, 1})
+
+	estimator := NewEstimator("apple", 2)
+	estimator.LoadStorage([]string{"apple", "banana", "car"}, s)
+	res, err := estimator.SearchFiltered("apple", 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].word != "banana" {
+		t.Errorf("Expected banana is the most similar to apple: %v", res)
+	}
+}
+
+func TestZeroVector(t *testing.T) {
+	estimator := NewEstimator("apple", 3)
+
+	f := ioutil.NopCloser(strings.NewReader("apple 1 0\nbanana 0.9 0.1\nzero 0 0\n"))
+	if err := estimator.Estimate(f); err != nil {
+		t.Fatal(err)
+	}
+	res, err := estimator.similar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || res[0].word != "banana" || res[1].word != "zero" || res[1].similarity != 0 {
+		t.Errorf("Expected similarity 0 to zero vector instead of NaN: %v", res)
+	}
+
+	res, err = estimator.SearchFiltered("zero", 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range res {
+		if m.similarity != 0 {
+			t.Errorf("Expected similarity 0 from zero vector instead of NaN: %v", res)
+		}
+	}
+}
+
+func TestDoesntMatch(t *testing.T) {
+	estimator := NewEstimator("", 1)
+
+	f := ioutil.NopCloser(bytes.NewReader([]byte(`apple 1 1 0
+	banana 1 0.9 0
+	cherry 0.9 1 0
+	car 0 0.1 1`)))
+	if err := estimator.Estimate(f); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := estimator.DoesntMatch([]string{"apple", "banana", "car", "cherry", "unknown"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual != "car" {
+		t.Errorf("Expected DoesntMatch=car: %v", actual)
+	}
+
+	if _, err := estimator.DoesntMatch([]string{"apple", "unknown"}); err == nil {
+		t.Error("Expected to fail with less than 2 words in vocabulary")
+	}
+}
+
+func TestSimilarWithFrequency(t *testing.T) {
+	vectors := `apple 1 1 0
+	banana 1 0.9 0
+	cherry 0.9 1 0
+	car 0 0.1 1`
+
+	estimator := NewEstimator("apple", 2)
+	f := ioutil.NopCloser(bytes.NewReader([]byte(vectors)))
+	if err := estimator.Estimate(f); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := estimator.similar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || res[0].frequency != 0 || res[1].frequency != 0 {
+		t.Errorf("Expected 2 similar words without frequency: %v", res)
+	}
+
+	// banana is too rare, and car is not in vocabulary.
+	estimator.WithFrequency(map[string]int{"apple": 10, "banana": 1, "cherry": 5}, 2)
+	res, err = estimator.similar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].word != "cherry" || res[0].frequency != 5 {
+		t.Errorf("Expected only cherry with frequency=5: %v", res)
+	}
+}
+
+func TestSearchFiltered(t *testing.T) {
+	vectors := `apple 1 1 0
+	banana 1 0.9 0
+	cherry 0.85 1 0
+	sony 0.8 1 0
+	car 0 0.1 1`
+	metadata := "apple\tcategory=brand\tcountry=us\n" +
+		"banana\tcategory=generic\n" +
+		"sony\tcategory=brand\tcountry=jp\n" +
+		"car\tcategory=brand\tcountry=us\n"
+
+	estimator := NewEstimator("apple", 2)
+	f := ioutil.NopCloser(bytes.NewReader([]byte(vectors)))
+	if err := estimator.Estimate(f); err != nil {
+		t.Fatal(err)
+	}
+	m, err := ReadMetadata(strings.NewReader(metadata))
+	if err != nil {
+		t.Fatal(err)
+	}
+	estimator.WithMetadata(m)
+
+	// cherry has no metadata, and banana is not a brand.
+	res, err := estimator.SearchFiltered("apple", 2, map[string]string{"category": "brand"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || res[0].Word() != "sony" || res[1].Word() != "car" {
+		t.Errorf("Expected 2 brands in order of similarity: %v", res)
+	}
+
+	res, err = estimator.SearchFiltered("apple", 2, map[string]string{"category": "brand", "country": "us"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].Word() != "car" {
+		t.Errorf("Expected only car to match all tags: %v", res)
+	}
+
+	res, err = estimator.WithFilter(nil).similar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || res[0].Word() != "banana" {
+		t.Errorf("Expected the words without metadata with no filter: %v", res)
+	}
+
+	if _, err := ReadMetadata(strings.NewReader("apple\tbrand\n")); err == nil {
+		t.Error("Expected to fail reading tag not in key=value")
+	}
+}
+
+func TestSoftCosine(t *testing.T) {
+	vectors := `president 1 0.1 0
+	chief 0.9 0.2 0
+	speaks 0 1 0.1
+	talks 0.1 0.9 0
+	banana 0 0 1
+	yellow 0.1 -0.1 0.9`
+	estimator := NewEstimator("", 1)
+	f := ioutil.NopCloser(bytes.NewReader([]byte(vectors)))
+	if err := estimator.Estimate(f); err != nil {
+		t.Fatal(err)
+	}
+
+	paraphrase, err := estimator.SoftCosine([]string{"president", "speaks"}, []string{"chief", "talks", "unknown"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unrelated, err := estimator.SoftCosine([]string{"president", "speaks"}, []string{"yellow", "banana"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paraphrase <= unrelated {
+		t.Errorf("Expected paraphrases to be more similar than unrelated words: %v <= %v", paraphrase, unrelated)
+	}
+	if same, _ := estimator.SoftCosine([]string{"banana", "yellow"}, []string{"yellow", "banana"}); math.Abs(same-1) > 1e-9 {
+		t.Errorf("Expected 1 for the same bag of words: %v", same)
+	}
+
+	if _, err := estimator.SoftCosine([]string{"unknown"}, []string{"banana"}); err == nil {
+		t.Error("Expected to fail with no words in vocabulary")
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	vectors := `cat 1 0 0
+	kitten 0.9 0.1 0
+	tiger 0.8 -0.2 0.1
+	car 0 0 1
+	truck 0.1 0 0.9
+	road 0 0.2 0.8`
+	estimator := NewEstimator("", 1)
+	f := ioutil.NopCloser(bytes.NewReader([]byte(vectors)))
+	if err := estimator.Estimate(f); err != nil {
+		t.Fatal(err)
+	}
+
+	neighbors, err := estimator.SearchFiltered("cat", 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start, err := estimator.Interpolate("cat", "car", 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(start) != 2 || start[0].Word() != neighbors[0].Word() || start[1].Word() != neighbors[1].Word() {
+		t.Errorf("Expected the neighbors of cat at t=0: %v, %v", neighbors, start)
+	}
+	end, err := estimator.Interpolate("cat", "car", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(end) != 1 || end[0].Word() != "truck" {
+		t.Errorf("Expected truck is the nearest to car at t=1: %v", end)
+	}
+
+	if _, err := estimator.Interpolate("cat", "car", 1.5, 2); err == nil {
+		t.Error("Expected to fail with t not in [0, 1]")
+	}
+	if _, err := estimator.Interpolate("cat", "unknown", 0.5, 2); err == nil {
+		t.Error("Expected to fail with the word not in vocabulary")
+	}
+}
+
+func TestMMR(t *testing.T) {
+	// king, kings and kingdom are near duplicates, while queen and prince are less similar but diverse.
+	vectors := `royal 1 0 0
+	king 0.9 0.3 0
+	kings 0.9 0.31 0
+	kingdom 0.9 0.29 0.01
+	queen 0.8 -0.3 0.2
+	prince 0.8 0 -0.4`
+	estimator := NewEstimator("royal", 3)
+	f := ioutil.NopCloser(bytes.NewReader([]byte(vectors)))
+	if err := estimator.Estimate(f); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := estimator.similar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reranked, err := estimator.WithMMR(0.5).similar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reranked) != 3 || reranked[0].Word() != raw[0].Word() {
+		t.Errorf("Expected 3 words starting with the most similar word: %v", reranked)
+	}
+
+	averageSimilarity := func(res Measures) float64 {
+		var sum float64
+		var pairs int
+		for i := range res {
+			for j := i + 1; j < len(res); j++ {
+				v1, v2 := estimator.dense[res[i].Word()], estimator.dense[res[j].Word()]
+				n1, _ := norm(v1)
+				n2, _ := norm(v2)
+				sim, _ := cosine(v1, v2, n1, n2)
+				sum += sim
+				pairs++
+			}
+		}
+		return sum / float64(pairs)
+	}
+	if averageSimilarity(reranked) >= averageSimilarity(raw) {
+		t.Errorf("Expected MMR to be more diverse than raw top-n: %v for %v, %v for %v",
+			averageSimilarity(reranked), reranked, averageSimilarity(raw), raw)
+	}
+
+	if _, err := estimator.MMR(raw, 3, 1.5); err == nil {
+		t.Error("Expected to fail with lambda not in [0, 1]")
+	}
+}
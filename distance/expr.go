@@ -0,0 +1,297 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distance
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// WithExpression sets expression of word vectors, e.g. "0.5*paris + 0.5*berlin - france",
+// to search similar words to instead of target word. See Evaluate for the syntax.
+func (e *Estimator) WithExpression(expr string) *Estimator {
+	e.expr = expr
+	return e
+}
+
+// Evaluate evaluates expr into the vector to search by SearchByVector, and returns the words in expr.
+// expr consists of words, numbers, +, -, * and parentheses, where * multiplies a vector by a number,
+// e.g. "0.5*(paris + berlin) - france". A token beginning with a digit or . is regarded as number, not word.
+// It fails with the names of the words not in vocabulary, or if expr evaluates to number without any word.
+func (e *Estimator) Evaluate(expr string) (*tensor.Dense, []string, error) {
+	p := &exprParser{tokens: tokenize(expr)}
+	node, err := p.parseSum()
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Invalid expression %q", expr)
+	}
+	if p.pos < len(p.tokens) {
+		return nil, nil, errors.Errorf("Invalid expression %q: unexpected %q", expr, p.tokens[p.pos])
+	}
+
+	words := node.words(nil)
+	var unknown []string
+	for _, word := range words {
+		if _, ok := e.dense[word]; !ok {
+			unknown = append(unknown, word)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, nil, errors.Errorf("Unknown words in expression: %v", strings.Join(unknown, ", "))
+	}
+
+	v, err := node.eval(e.dense)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Invalid expression %q", expr)
+	}
+	if v.vec == nil {
+		return nil, nil, errors.Errorf("Invalid expression %q: evaluated to number without any word", expr)
+	}
+	return tensor.NewDense(tensor.Float64, tensor.Shape{len(v.vec)}, tensor.WithBacking(v.vec)), words, nil
+}
+
+// exprValue is either of number or vector, which is set if not nil.
+type exprValue struct {
+	num float64
+	vec []float64
+}
+
+type exprNode interface {
+	eval(dense map[string]*tensor.Dense) (exprValue, error)
+	words(acc []string) []string
+}
+
+type numNode float64
+
+func (n numNode) eval(map[string]*tensor.Dense) (exprValue, error) {
+	return exprValue{num: float64(n)}, nil
+}
+
+func (n numNode) words(acc []string) []string {
+	return acc
+}
+
+type wordNode string
+
+func (n wordNode) eval(dense map[string]*tensor.Dense) (exprValue, error) {
+	dat := dense[string(n)].Data().([]float64)
+	vec := make([]float64, len(dat))
+	copy(vec, dat)
+	return exprValue{vec: vec}, nil
+}
+
+func (n wordNode) words(acc []string) []string {
+	for _, word := range acc {
+		if word == string(n) {
+			return acc
+		}
+	}
+	return append(acc, string(n))
+}
+
+type negNode struct {
+	x exprNode
+}
+
+func (n negNode) eval(dense map[string]*tensor.Dense) (exprValue, error) {
+	v, err := n.x.eval(dense)
+	if err != nil {
+		return exprValue{}, err
+	}
+	return scale(v, -1), nil
+}
+
+func (n negNode) words(acc []string) []string {
+	return n.x.words(acc)
+}
+
+type binaryNode struct {
+	op   byte
+	x, y exprNode
+}
+
+func (n binaryNode) eval(dense map[string]*tensor.Dense) (exprValue, error) {
+	x, err := n.x.eval(dense)
+	if err != nil {
+		return exprValue{}, err
+	}
+	y, err := n.y.eval(dense)
+	if err != nil {
+		return exprValue{}, err
+	}
+	switch n.op {
+	case '*':
+		switch {
+		case x.vec != nil && y.vec != nil:
+			return exprValue{}, errors.New("Unable to multiply vector by vector")
+		case x.vec != nil:
+			return scale(x, y.num), nil
+		default:
+			return scale(y, x.num), nil
+		}
+	default:
+		if (x.vec == nil) != (y.vec == nil) {
+			return exprValue{}, errors.Errorf("Unable to %c number and vector", n.op)
+		}
+		sign := 1.
+		if n.op == '-' {
+			sign = -1
+		}
+		if x.vec == nil {
+			return exprValue{num: x.num + sign*y.num}, nil
+		}
+		if len(x.vec) != len(y.vec) {
+			return exprValue{}, errors.Errorf("Dimension mismatch: %d and %d", len(x.vec), len(y.vec))
+		}
+		for i := range x.vec {
+			x.vec[i] += sign * y.vec[i]
+		}
+		return x, nil
+	}
+}
+
+func (n binaryNode) words(acc []string) []string {
+	return n.y.words(n.x.words(acc))
+}
+
+func scale(v exprValue, a float64) exprValue {
+	if v.vec == nil {
+		return exprValue{num: a * v.num}
+	}
+	for i := range v.vec {
+		v.vec[i] *= a
+	}
+	return v
+}
+
+const exprOperators = "+-*()"
+
+// tokenize splits expr into operators and the other runs of non-space characters.
+func tokenize(expr string) []string {
+	var tokens []string
+	start := -1
+	for i, r := range expr {
+		if unicode.IsSpace(r) || strings.ContainsRune(exprOperators, r) {
+			if start >= 0 {
+				tokens = append(tokens, expr[start:i])
+				start = -1
+			}
+			if !unicode.IsSpace(r) {
+				tokens = append(tokens, string(r))
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, expr[start:])
+	}
+	return tokens
+}
+
+// exprParser is recursive descent parser of the grammar:
+//
+//	sum     = product { ("+" | "-") product }
+//	product = unary { "*" unary }
+//	unary   = "-" unary | operand
+//	operand = number | word | "(" sum ")"
+type exprParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *exprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *exprParser) parseSum() (exprNode, error) {
+	x, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for tok := p.peek(); tok == "+" || tok == "-"; tok = p.peek() {
+		p.pos++
+		y, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		x = binaryNode{op: tok[0], x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *exprParser) parseProduct() (exprNode, error) {
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "*" {
+		p.pos++
+		y, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		x = binaryNode{op: '*', x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if p.peek() == "-" {
+		p.pos++
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return negNode{x: x}, nil
+	}
+	return p.parseOperand()
+}
+
+func (p *exprParser) parseOperand() (exprNode, error) {
+	tok := p.peek()
+	switch {
+	case tok == "":
+		return nil, errors.New("unexpected end")
+	case tok == "(":
+		p.pos++
+		x, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, errors.New("missing )")
+		}
+		p.pos++
+		return x, nil
+	case strings.Contains(exprOperators, tok):
+		return nil, errors.Errorf("unexpected %q", tok)
+	}
+	p.pos++
+	if c := tok[0]; c == '.' || '0' <= c && c <= '9' {
+		num, err := strconv.ParseFloat(tok, 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid number %q", tok)
+		}
+		return numNode(num), nil
+	}
+	return wordNode(tok), nil
+}
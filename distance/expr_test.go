@@ -0,0 +1,116 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distance
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	estimator := NewEstimator("", 1)
+	f := ioutil.NopCloser(bytes.NewReader([]byte(`paris 1 0
+	berlin 0 1
+	france 1 1`)))
+	if err := estimator.Estimate(f); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		expr     string
+		expected []float64
+		words    []string
+	}{
+		{expr: "paris", expected: []float64{1, 0}, words: []string{"paris"}},
+		{expr: "paris + berlin * 2", expected: []float64{1, 2}, words: []string{"paris", "berlin"}},
+		{expr: "(paris + berlin) * 2", expected: []float64{2, 2}, words: []string{"paris", "berlin"}},
+		{expr: "paris - berlin - france", expected: []float64{0, -2}, words: []string{"paris", "berlin", "france"}},
+		{expr: "paris - (berlin - france)", expected: []float64{2, 0}, words: []string{"paris", "berlin", "france"}},
+		{expr: "0.5*paris + 0.5*berlin - france", expected: []float64{-0.5, -0.5}, words: []string{"paris", "berlin", "france"}},
+		{expr: "-2*-paris+paris", expected: []float64{3, 0}, words: []string{"paris"}},
+		{expr: "2 * 3 * paris", expected: []float64{6, 0}, words: []string{"paris"}},
+	}
+	for _, testCase := range testCases {
+		vec, words, err := estimator.Evaluate(testCase.expr)
+		if err != nil {
+			t.Errorf("Expected to evaluate %q: %v", testCase.expr, err)
+			continue
+		}
+		if actual := vec.Data().([]float64); !reflect.DeepEqual(actual, testCase.expected) {
+			t.Errorf("Expected %q to be %v: %v", testCase.expr, testCase.expected, actual)
+		}
+		if !reflect.DeepEqual(words, testCase.words) {
+			t.Errorf("Expected words of %q to be %v: %v", testCase.expr, testCase.words, words)
+		}
+	}
+
+	// the vectors of words must not be modified by evaluation.
+	if actual := estimator.dense["paris"].Data().([]float64); !reflect.DeepEqual(actual, []float64{1, 0}) {
+		t.Errorf("Expected the vector of paris not to be modified: %v", actual)
+	}
+}
+
+func TestEvaluateMalformed(t *testing.T) {
+	estimator := NewEstimator("", 1)
+	f := ioutil.NopCloser(bytes.NewReader([]byte(`paris 1 0
+	berlin 0 1`)))
+	if err := estimator.Estimate(f); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expr := range []string{
+		"",
+		"paris +",
+		"* paris",
+		"(paris + berlin",
+		"paris + berlin)",
+		"paris berlin",
+		"paris * berlin",
+		"paris + 1",
+		"0.5 * 2",
+		"1.2.3 * paris",
+	} {
+		if _, _, err := estimator.Evaluate(expr); err == nil {
+			t.Errorf("Expected to fail evaluating %q", expr)
+		}
+	}
+
+	_, _, err := estimator.Evaluate("paris - tokyo + rome")
+	if err == nil || err.Error() != "Unknown words in expression: tokyo, rome" {
+		t.Errorf("Expected to fail with the names of unknown words: %v", err)
+	}
+}
+
+func TestSimilarWithExpression(t *testing.T) {
+	estimator := NewEstimator("", 1).WithExpression("paris - france + germany")
+	f := ioutil.NopCloser(bytes.NewReader([]byte(`paris 1 0 1
+	france 0 0 1
+	germany 0 1 0
+	berlin 1 1 0
+	rome 1 0 0`)))
+	if err := estimator.Estimate(f); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := estimator.similar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].word != "berlin" {
+		t.Errorf("Expected the most similar word except for the expression to be berlin: %v", res)
+	}
+}
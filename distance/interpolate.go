@@ -0,0 +1,48 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distance
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// Interpolate returns at most n words in descending order of similarity to the linear interpolation
+// (1-t)*v1 + t*v2 of the vectors of word1 and word2, e.g. to walk the path between two concepts by t.
+// Both words are excluded from the results, and t must be in [0, 1].
+func (e *Estimator) Interpolate(word1, word2 string, t float64, n int) (Measures, error) {
+	if t < 0 || t > 1 {
+		return nil, errors.Errorf("Invalid interpolation %v not in [0, 1]", t)
+	}
+	v1, ok := e.dense[word1]
+	if !ok {
+		return nil, errors.Errorf("%v is not found", word1)
+	}
+	v2, ok := e.dense[word2]
+	if !ok {
+		return nil, errors.Errorf("%v is not found", word2)
+	}
+
+	d1, d2 := v1.Data().([]float64), v2.Data().([]float64)
+	if len(d1) != len(d2) {
+		return nil, errors.Errorf("Dimension of %v is %d, but %v is %d", word1, len(d1), word2, len(d2))
+	}
+	vec := make([]float64, len(d1))
+	for i := range vec {
+		vec[i] = (1-t)*d1[i] + t*d2[i]
+	}
+	return e.SearchByVector(tensor.NewDense(tensor.Float64, tensor.Shape{len(vec)}, tensor.WithBacking(vec)),
+		n, word1, word2)
+}
@@ -0,0 +1,76 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distance
+
+import (
+	"bufio"
+	"io"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ExportKNNGraph writes the k most similar words of every word to w as an adjacency list, e.g. for recommendation,
+// whose lines are "word neighbor1:sim1 neighbor2:sim2 ..." in descending order of similarity, and in sorted order
+// of words. A word is never its own neighbor, and the words filtered out by WithFrequency are never neighbors.
+// The neighbors are searched by as many goroutines as CPUs in parallel.
+func (e *Estimator) ExportKNNGraph(w io.Writer, k int) error {
+	if k < 1 {
+		return errors.Errorf("Invalid k: %d must be positive", k)
+	}
+	words := make([]string, 0, len(e.dense))
+	for word := range e.dense {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+
+	results := make([]Measures, len(words))
+	errs := make([]error, len(words))
+	indices := make(chan int)
+	waitGroup := &sync.WaitGroup{}
+	for worker := 0; worker < runtime.NumCPU(); worker++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for i := range indices {
+				results[i], errs[i] = e.SearchFiltered(words[i], k, nil)
+			}
+		}()
+	}
+	for i := range words {
+		indices <- i
+	}
+	close(indices)
+	waitGroup.Wait()
+
+	bw := bufio.NewWriter(w)
+	for i, word := range words {
+		if errs[i] != nil {
+			return errors.Wrapf(errs[i], "Unable to search neighbors of %s", word)
+		}
+		bw.WriteString(word)
+		for _, m := range results[i] {
+			bw.WriteString(" ")
+			bw.WriteString(m.word)
+			bw.WriteString(":")
+			bw.WriteString(strconv.FormatFloat(m.similarity, 'f', 6, 64))
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,60 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distance
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestExportKNNGraph(t *testing.T) {
+	estimator := NewEstimator("", 0)
+	f := ioutil.NopCloser(strings.NewReader("a 1 0\nb 0.9 0.1\nc 0 1\nd -1 0\n"))
+	if err := estimator.Estimate(f); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := estimator.ExportKNNGraph(&buf, 2); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected a line per word: %q", buf.String())
+	}
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if word := string('a' + rune(i)); fields[0] != word {
+			t.Errorf("Expected line of %s in sorted order of words: %q", word, line)
+		}
+		if len(fields)-1 > 2 {
+			t.Errorf("Expected at most 2 neighbors: %q", line)
+		}
+		for _, neighbor := range fields[1:] {
+			if strings.HasPrefix(neighbor, fields[0]+":") {
+				t.Errorf("Expected %s not to be its own neighbor: %q", fields[0], line)
+			}
+		}
+	}
+	if !strings.HasPrefix(lines[0], "a b:0.993884 ") {
+		t.Errorf("Expected b to be the nearest neighbor of a: %q", lines[0])
+	}
+
+	if err := estimator.ExportKNNGraph(&buf, 0); err == nil {
+		t.Error("Expected to fail exporting with k=0")
+	}
+}
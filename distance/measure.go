@@ -15,9 +15,35 @@
 package distance
 
 // Measure stores the word with cosine similarity value on the target.
+// frequency is the frequency of word in training corpus, which is set only if vocabulary is given.
+// sense is the tag of the target's sense which word is the most similar to, which is set only if the target is
+// searched as its senses.
 type Measure struct {
 	word       string
 	similarity float64
+	frequency  int
+	sense      string
+}
+
+// Word returns the word.
+func (m Measure) Word() string {
+	return m.word
+}
+
+// Similarity returns cosine similarity of the word on the target.
+func (m Measure) Similarity() float64 {
+	return m.similarity
+}
+
+// Frequency returns frequency of the word in training corpus, or 0 without vocabulary.
+func (m Measure) Frequency() int {
+	return m.frequency
+}
+
+// Sense returns the tag of the target's sense which the word is the most similar to, e.g. "1" of bank@1,
+// or empty unless the target is searched as its senses.
+func (m Measure) Sense() string {
+	return m.sense
 }
 
 // Measures is the list of Sim.
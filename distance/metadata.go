@@ -0,0 +1,66 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distance
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/vectorio"
+)
+
+// ReadMetadata reads tags of words from lines of "word<TAB>key=value<TAB>key=value ...".
+// Blank lines are skipped, and the tags of the duplicated word are merged.
+func ReadMetadata(r io.Reader) (map[string]map[string]string, error) {
+	metadata := make(map[string]map[string]string)
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := vectorio.TrimLine(scanner.Text())
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		sep := strings.Split(line, "\t")
+		tags, err := ParseFilter(sep[1:])
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid metadata line %d", lineNum)
+		}
+		if _, ok := metadata[sep[0]]; !ok {
+			metadata[sep[0]] = make(map[string]string, len(tags))
+		}
+		for k, v := range tags {
+			metadata[sep[0]][k] = v
+		}
+	}
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "Unable to complete scanning")
+	}
+	return metadata, nil
+}
+
+// ParseFilter parses the list of "key=value" into the tags which similar words must have.
+func ParseFilter(pairs []string) (map[string]string, error) {
+	filter := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, errors.Errorf("Invalid tag: %q not in key=value", pair)
+		}
+		filter[kv[0]] = kv[1]
+	}
+	return filter, nil
+}
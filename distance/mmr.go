@@ -0,0 +1,85 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distance
+
+import (
+	"github.com/pkg/errors"
+	"gorgonia.org/tensor"
+)
+
+// mmrPoolFactor is the ratio of candidates to re-rank by MMR to the number of similar words.
+const mmrPoolFactor = 10
+
+// WithMMR sets lambda in [0, 1] to re-rank similar words by Maximal Marginal Relevance, which balances
+// similarity to target word (lambda=1, the same as without re-ranking) and diversity among similar words (lambda=0).
+func (e *Estimator) WithMMR(lambda float64) *Estimator {
+	e.lambda = lambda
+	return e
+}
+
+// MMR selects at most k words from candidates, e.g. returned by SearchFiltered, by Maximal Marginal Relevance.
+// Each step selects the candidate maximizing lambda * similarity to target - (1 - lambda) * the largest similarity
+// to the selected words. The similarity of Measure is left as to target word.
+func (e *Estimator) MMR(candidates Measures, k int, lambda float64) (Measures, error) {
+	if lambda < 0 || lambda > 1 {
+		return nil, errors.Errorf("Invalid lambda of MMR: %v not in [0, 1]", lambda)
+	}
+	vecs := make([]*tensor.Dense, len(candidates))
+	norms := make([]float64, len(candidates))
+	for i, c := range candidates {
+		vec, ok := e.dense[c.word]
+		if !ok {
+			return nil, errors.Errorf("%v is not found", c.word)
+		}
+		n, err := norm(vec)
+		if err != nil {
+			return nil, err
+		}
+		vecs[i], norms[i] = vec, n
+	}
+
+	// redundancy is the largest similarity of each candidate to the selected words.
+	redundancy := make([]float64, len(candidates))
+	selected := make([]bool, len(candidates))
+	res := make(Measures, 0, k)
+	for len(res) < k && len(res) < len(candidates) {
+		best := -1
+		var bestScore float64
+		for i, c := range candidates {
+			if selected[i] {
+				continue
+			}
+			score := lambda*c.similarity - (1-lambda)*redundancy[i]
+			if best < 0 || score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		selected[best] = true
+		res = append(res, candidates[best])
+		for i := range candidates {
+			if selected[i] {
+				continue
+			}
+			sim, err := cosine(vecs[i], vecs[best], norms[i], norms[best])
+			if err != nil {
+				return nil, err
+			}
+			if len(res) == 1 || sim > redundancy[i] {
+				redundancy[i] = sim
+			}
+		}
+	}
+	return res, nil
+}
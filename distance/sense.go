@@ -0,0 +1,108 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distance
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The ways to merge the similar words of senses.
+const (
+	// SenseUnion ranks each word by its largest similarity to any sense.
+	SenseUnion = "union"
+	// SenseAverage ranks each word by its average similarity to all senses.
+	SenseAverage = "average"
+)
+
+// WithSenseDelimiter sets the delimiter of sense tags in words, e.g. "@" for bank@1 and bank@2, and how to merge
+// the similar words of senses, one of: union|average. A target word not in vocabulary is searched as all of its
+// tagged senses, and each similar word is labeled with the sense it is the most similar to.
+func (e *Estimator) WithSenseDelimiter(delimiter, merge string) *Estimator {
+	e.senseDelimiter = delimiter
+	e.senseMerge = merge
+	return e
+}
+
+// senses returns the tagged senses of word in sorted order, which are empty without sense delimiter.
+func (e *Estimator) senses(word string) []string {
+	if e.senseDelimiter == "" {
+		return nil
+	}
+	prefix := word + e.senseDelimiter
+	senses := make([]string, 0)
+	for other := range e.dense {
+		if strings.HasPrefix(other, prefix) && len(other) > len(prefix) {
+			senses = append(senses, other)
+		}
+	}
+	sort.Strings(senses)
+	return senses
+}
+
+// searchSenses returns at most k words in descending order of similarity merged over senses of word,
+// except for the senses.
+func (e *Estimator) searchSenses(word string, senses []string, k int, filter map[string]string) (Measures, error) {
+	if e.senseMerge != SenseUnion && e.senseMerge != SenseAverage {
+		return nil, errors.Errorf("Invalid merge of senses: %v not in union|average", e.senseMerge)
+	}
+	exclude := make(map[string]bool)
+	for _, sense := range senses {
+		exclude[sense] = true
+	}
+	// averages need the similarities of every word to all senses, not only to the senses it is near to.
+	n := k
+	if e.senseMerge == SenseAverage {
+		n = len(e.dense)
+	}
+
+	merged := make(map[string]*Measure)
+	sums := make(map[string]float64)
+	for _, sense := range senses {
+		res, err := e.search(e.dense[sense], n, filter, exclude)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range res {
+			sums[m.word] += m.similarity
+			if prev, ok := merged[m.word]; ok && prev.similarity >= m.similarity {
+				continue
+			}
+			best := m
+			best.sense = strings.TrimPrefix(sense, word+e.senseDelimiter)
+			merged[m.word] = &best
+		}
+	}
+
+	res := make(Measures, 0, len(merged))
+	for word, m := range merged {
+		if e.senseMerge == SenseAverage {
+			m.similarity = sums[word] / float64(len(senses))
+		}
+		res = append(res, *m)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].similarity != res[j].similarity {
+			return res[i].similarity > res[j].similarity
+		}
+		return res[i].word < res[j].word
+	})
+	if len(res) > k {
+		res = res[:k]
+	}
+	return res, nil
+}
@@ -0,0 +1,106 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distance
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+var testSenseVector = `bank@1 1 0
+	bank@2 0 1
+	money 1 0.05
+	loan 0.8 0.6
+	river 0.1 1
+	shore 0.3 1`
+
+func newTestSenseEstimator(t *testing.T, merge string) *Estimator {
+	estimator := NewEstimator("bank", 10).WithSenseDelimiter("@", merge)
+	if err := estimator.Estimate(ioutil.NopCloser(strings.NewReader(testSenseVector))); err != nil {
+		t.Fatal(err)
+	}
+	return estimator
+}
+
+func TestSenseUnion(t *testing.T) {
+	res, err := newTestSenseEstimator(t, SenseUnion).SearchFiltered("bank", 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct{ word, sense string }{{"money", "1"}, {"river", "2"}, {"shore", "2"}}
+	if len(res) != len(expected) {
+		t.Fatalf("Expected %v similar words: %v", len(expected), res)
+	}
+	for i, e := range expected {
+		if res[i].Word() != e.word || res[i].Sense() != e.sense {
+			t.Errorf("Expected %v of sense %v at rank %v: %v", e.word, e.sense, i+1, res)
+		}
+	}
+}
+
+func TestSenseAverage(t *testing.T) {
+	res, err := newTestSenseEstimator(t, SenseAverage).SearchFiltered("bank", 10, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct{ word, sense string }{{"loan", "1"}, {"shore", "2"}, {"river", "2"}, {"money", "1"}}
+	if len(res) != len(expected) {
+		t.Fatalf("Expected %v similar words without senses: %v", len(expected), res)
+	}
+	for i, e := range expected {
+		if res[i].Word() != e.word || res[i].Sense() != e.sense {
+			t.Errorf("Expected %v of sense %v at rank %v: %v", e.word, e.sense, i+1, res)
+		}
+	}
+	if sim := res[0].Similarity(); sim < 0.7-1e-9 || sim > 0.7+1e-9 {
+		t.Errorf("Expected the average similarity of loan to senses=0.7: %v", sim)
+	}
+}
+
+func TestSenseFallback(t *testing.T) {
+	estimator := newTestSenseEstimator(t, SenseUnion)
+	if res, err := estimator.SearchFiltered("bank@2", 1, nil); err != nil || res[0].Word() != "river" || res[0].Sense() != "" {
+		t.Errorf("Expected a tagged word to be searched as it is: %v, %v", res, err)
+	}
+	if _, err := estimator.SearchFiltered("ban", 1, nil); err == nil {
+		t.Error("Expected no senses for the prefix of word")
+	}
+
+	estimator = NewEstimator("bank", 1)
+	if err := estimator.Estimate(ioutil.NopCloser(strings.NewReader(testSenseVector))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := estimator.SearchFiltered("bank", 1, nil); err == nil {
+		t.Error("Expected bank not to be found without sense delimiter")
+	}
+
+	if _, err := newTestSenseEstimator(t, "max").SearchFiltered("bank", 1, nil); err == nil {
+		t.Error("Expected to fail with invalid merge of senses")
+	}
+}
+
+func TestSenseDescribe(t *testing.T) {
+	estimator := newTestSenseEstimator(t, SenseUnion)
+	estimator.rank = 1
+	var buf bytes.Buffer
+	if err := estimator.DescribeTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(strings.ToLower(out), "sense") || !strings.Contains(out, "money") {
+		t.Errorf("Expected money with its sense: %v", out)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/vectorio"
+)
+
+// AverageOptions stores the options to average word vectors of several runs.
+type AverageOptions struct {
+	// Normalize scales each vector to unit L2 norm per run before averaging.
+	Normalize bool
+	// Align rotates each run onto the first run by orthogonal Procrustes before averaging,
+	// which resolves the arbitrary signs and rotations between runs.
+	Align bool
+	// Intersect averages the words shared by all runs if vocabularies differ, instead of failing.
+	Intersect bool
+}
+
+// Average averages the vectors of several runs element-wise, e.g. trained on the same corpus with different seeds,
+// and returns them with the order of words in the first run. The runs must have the same dimension.
+func Average(runs []*vectorio.Vectors, opts AverageOptions) (*vectorio.Vectors, error) {
+	if len(runs) == 0 {
+		return nil, errors.New("At least 1 run is required to average")
+	}
+	words, err := sharedWords(runs, opts.Intersect)
+	if err != nil {
+		return nil, err
+	}
+	dim := runs[0].Dimension()
+	matrices := make([][][]float64, len(runs))
+	for i, run := range runs {
+		if run.Dimension() != dim {
+			return nil, errors.Errorf("Dimension of run %d is %d, but expected %d", i+1, run.Dimension(), dim)
+		}
+		matrices[i] = make([][]float64, len(words))
+		for j, word := range words {
+			vec := run.Vector[word]
+			if len(vec) != dim {
+				return nil, errors.Errorf("Dimension of %v in run %d is %d, but expected %d", word, i+1, len(vec), dim)
+			}
+			row := make([]float64, dim)
+			copy(row, vec)
+			if norm := vectorio.Norm(row); opts.Normalize && norm > 0 {
+				for d := range row {
+					row[d] /= norm
+				}
+			}
+			matrices[i][j] = row
+		}
+	}
+	if opts.Align {
+		for _, m := range matrices[1:] {
+			rotate(m, procrustes(m, matrices[0]))
+		}
+	}
+
+	averaged := &vectorio.Vectors{
+		Words:  words,
+		Vector: make(map[string][]float64, len(words)),
+	}
+	for j, word := range words {
+		vec := make([]float64, dim)
+		for _, m := range matrices {
+			for d, v := range m[j] {
+				vec[d] += v / float64(len(matrices))
+			}
+		}
+		averaged.Vector[word] = vec
+	}
+	return averaged, nil
+}
+
+// sharedWords returns the words of the first run which all the runs have. It fails if vocabularies differ
+// unless intersect.
+func sharedWords(runs []*vectorio.Vectors, intersect bool) ([]string, error) {
+	words := make([]string, 0, len(runs[0].Words))
+	for _, word := range runs[0].Words {
+		shared := true
+		for _, run := range runs[1:] {
+			if _, ok := run.Vector[word]; !ok {
+				shared = false
+				break
+			}
+		}
+		if shared {
+			words = append(words, word)
+		}
+	}
+	if intersect {
+		return words, nil
+	}
+	for i, run := range runs {
+		if len(run.Words) != len(words) {
+			return nil, errors.Errorf("Vocabulary of run %d has %d words, but %d are shared by all runs",
+				i+1, len(run.Words), len(words))
+		}
+	}
+	return words, nil
+}
+
+// procrustes returns the orthogonal matrix r minimizing ||x r - y||, i.e. u v^T for the SVD u s v^T of x^T y.
+// It is computed as m v s^-1 v^T for m = x^T y, with the eigen decomposition v s^2 v^T of m^T m.
+func procrustes(x, y [][]float64) [][]float64 {
+	dim := len(x[0])
+	m := make([][]float64, dim)
+	for a := range m {
+		m[a] = make([]float64, dim)
+	}
+	for i := range x {
+		for a := 0; a < dim; a++ {
+			for b := 0; b < dim; b++ {
+				m[a][b] += x[i][a] * y[i][b]
+			}
+		}
+	}
+	mtm := make([][]float64, dim)
+	for a := range mtm {
+		mtm[a] = make([]float64, dim)
+		for b := range mtm[a] {
+			for c := 0; c < dim; c++ {
+				mtm[a][b] += m[c][a] * m[c][b]
+			}
+		}
+	}
+	values, v := jacobiEigen(mtm)
+
+	r := make([][]float64, dim)
+	for a := range r {
+		r[a] = make([]float64, dim)
+	}
+	for k, value := range values {
+		if value <= 1e-12 {
+			continue
+		}
+		s := math.Sqrt(value)
+		// column k of u is m v_k / s_k, and r adds u_k v_k^T.
+		for a := 0; a < dim; a++ {
+			var u float64
+			for b := 0; b < dim; b++ {
+				u += m[a][b] * v[b][k]
+			}
+			u /= s
+			for b := 0; b < dim; b++ {
+				r[a][b] += u * v[b][k]
+			}
+		}
+	}
+	return r
+}
+
+// rotate overwrites each row of m by its product with r.
+func rotate(m, r [][]float64) {
+	for i, row := range m {
+		rotated := make([]float64, len(row))
+		for a, v := range row {
+			for b := range rotated {
+				rotated[b] += v * r[a][b]
+			}
+		}
+		m[i] = rotated
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/ynqa/wego/vectorio"
+)
+
+func readVectors(t *testing.T, text string) *vectorio.Vectors {
+	vectors, err := vectorio.ReadText(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vectors
+}
+
+func TestAverage(t *testing.T) {
+	run1 := readVectors(t, "a 1 0\nb 0 2\nc 1 1\n")
+	run2 := readVectors(t, "b 0 4\na 3 0\nc 3 3\n")
+
+	averaged, err := Average([]*vectorio.Vectors{run1, run2}, AverageOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]float64{"a": {2, 0}, "b": {0, 3}, "c": {2, 2}}
+	for i, word := range []string{"a", "b", "c"} {
+		if averaged.Words[i] != word {
+			t.Errorf("Expected the order of words in the first run: %v", averaged.Words)
+		}
+		for d, v := range expected[word] {
+			if averaged.Vector[word][d] != v {
+				t.Errorf("Expected %v for %v: %v", expected[word], word, averaged.Vector[word])
+			}
+		}
+	}
+
+	normalized, err := Average([]*vectorio.Vectors{run1, run2}, AverageOptions{Normalize: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if norm, _ := normalized.Norm("c"); math.Abs(norm-1) > 1e-9 {
+		t.Errorf("Expected unit norm for the same direction of c: %v", norm)
+	}
+}
+
+func TestAverageAlign(t *testing.T) {
+	// run2 is run1 rotated by 90 degrees, with the sign of the first axis flipped.
+	run1 := readVectors(t, "a 1 0\nb 0.5 2\nc -1 1\n")
+	run2 := readVectors(t, "a 0 1\nb 2 0.5\nc 1 -1\n")
+
+	averaged, err := Average([]*vectorio.Vectors{run1, run2}, AverageOptions{Align: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, word := range run1.Words {
+		for d, v := range run1.Vector[word] {
+			if math.Abs(averaged.Vector[word][d]-v) > 1e-9 {
+				t.Errorf("Expected aligned average to equal run1 for %v: %v", word, averaged.Vector[word])
+			}
+		}
+	}
+}
+
+func TestAverageVocabulary(t *testing.T) {
+	run1 := readVectors(t, "a 1 0\nb 0 2\nc 1 1\n")
+	run2 := readVectors(t, "c 3 3\na 3 0\nd 1 1\n")
+
+	if _, err := Average([]*vectorio.Vectors{run1, run2}, AverageOptions{}); err == nil {
+		t.Error("Expected to fail averaging different vocabularies")
+	}
+	averaged, err := Average([]*vectorio.Vectors{run1, run2}, AverageOptions{Intersect: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(averaged.Words) != 2 || averaged.Words[0] != "a" || averaged.Words[1] != "c" {
+		t.Errorf("Expected the shared words [a c]: %v", averaged.Words)
+	}
+
+	run3 := readVectors(t, "a 1 0 0\nb 0 2 0\nc 1 1 0\n")
+	if _, err := Average([]*vectorio.Vectors{run1, run3}, AverageOptions{}); err == nil {
+		t.Error("Expected to fail averaging different dimensions")
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"math"
+
+	"github.com/ynqa/wego/vectorio"
+)
+
+// Canonicalize returns the vectors rotated onto their principal axes, so that the runs whose vector spaces differ
+// only by rotation and reflection become comparable, e.g. by diffing files. The axes are the principal components
+// of the mean-centered vectors in descending order of variance. The sign of each axis is fixed so that the value
+// of the largest magnitude on it among all words is positive, which unlike the sign of PCA doesn't depend on
+// the original axes. The vectors themselves are rotated without centering, so that their norms and pairwise cosine
+// similarities are preserved. vectors are not modified.
+func Canonicalize(vectors *vectorio.Vectors) (*vectorio.Vectors, error) {
+	dim := vectors.Dimension()
+	_, components, _, err := principalComponents(vectors, dim)
+	if err != nil {
+		return nil, err
+	}
+	canonical := &vectorio.Vectors{
+		Words:  vectors.Words,
+		Vector: make(map[string][]float64, len(vectors.Words)),
+	}
+	for _, word := range vectors.Words {
+		vec := vectors.Vector[word]
+		rotated := make([]float64, dim)
+		for j, component := range components {
+			for d := 0; d < dim; d++ {
+				rotated[j] += vec[d] * component[d]
+			}
+		}
+		canonical.Vector[word] = rotated
+	}
+	for j := 0; j < dim; j++ {
+		var largest float64
+		for _, word := range vectors.Words {
+			if v := canonical.Vector[word][j]; math.Abs(v) > math.Abs(largest) {
+				largest = v
+			}
+		}
+		if largest < 0 {
+			for _, word := range vectors.Words {
+				canonical.Vector[word][j] = -canonical.Vector[word][j]
+			}
+		}
+	}
+	return canonical, nil
+}
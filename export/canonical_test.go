@@ -0,0 +1,76 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ynqa/wego/vectorio"
+)
+
+func TestCanonicalize(t *testing.T) {
+	vectors := &vectorio.Vectors{
+		Words: []string{"a", "b", "c", "d", "e"},
+		Vector: map[string][]float64{
+			"a": {3, 0.5, 0.1},
+			"b": {-2, 1, 0.3},
+			"c": {1, -1.5, -0.2},
+			"d": {-1, 0.2, 0.4},
+			"e": {0.5, 0.4, -0.5},
+		},
+	}
+	// rotation around the third axis followed by reflection of the first axis.
+	c, s := math.Cos(0.7), math.Sin(0.7)
+	rotated := &vectorio.Vectors{Words: vectors.Words, Vector: make(map[string][]float64)}
+	for _, word := range vectors.Words {
+		vec := vectors.Vector[word]
+		rotated.Vector[word] = []float64{-(c*vec[0] - s*vec[1]), s*vec[0] + c*vec[1], vec[2]}
+	}
+
+	canonical, err := Canonicalize(vectors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonicalRotated, err := Canonicalize(rotated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cosine := func(v1, v2 []float64) float64 {
+		return dot(v1, v2) / math.Sqrt(dot(v1, v1)*dot(v2, v2))
+	}
+	for _, w1 := range vectors.Words {
+		for d, v := range canonical.Vector[w1] {
+			if math.Abs(v-canonicalRotated.Vector[w1][d]) > 1e-9 {
+				t.Errorf("Expected the same canonical vector of %s regardless of rotation: %v, %v",
+					w1, canonical.Vector[w1], canonicalRotated.Vector[w1])
+				break
+			}
+		}
+		for _, w2 := range vectors.Words {
+			expected := cosine(vectors.Vector[w1], vectors.Vector[w2])
+			if actual := cosine(canonical.Vector[w1], canonical.Vector[w2]); math.Abs(actual-expected) > 1e-12 {
+				t.Errorf("Expected cosine of %s and %s to be preserved: %v -> %v", w1, w2, expected, actual)
+			}
+		}
+	}
+	if vectors.Vector["a"][0] != 3 {
+		t.Errorf("Expected vectors not to be modified: %v", vectors.Vector["a"])
+	}
+
+	if _, err := Canonicalize(&vectorio.Vectors{Words: []string{"a"}, Vector: map[string][]float64{"a": {1}}}); err == nil {
+		t.Error("Expected to fail canonicalizing a single vector")
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/vectorio"
+)
+
+// coverageBuckets is the number of frequency buckets, i.e. deciles, of CoverageReport.
+const coverageBuckets = 10
+
+// CoverageReport stores how much of the tokens of corpus are covered by vocabulary of word vectors.
+type CoverageReport struct {
+	Tokens        int     `json:"tokens"`
+	CoveredTokens int     `json:"covered_tokens"`
+	TokenCoverage float64 `json:"token_coverage"`
+	Types         int     `json:"types"`
+	CoveredTypes  int     `json:"covered_types"`
+	TypeCoverage  float64 `json:"type_coverage"`
+	// Uncovered is the most frequent words not in vocabulary.
+	Uncovered []WordFreq `json:"uncovered"`
+	// Deciles is the coverage of word types divided into 10 buckets, from the most frequent ones.
+	Deciles []DecileCoverage `json:"deciles"`
+}
+
+// WordFreq is a word and its frequency on corpus.
+type WordFreq struct {
+	Word string `json:"word"`
+	Freq int    `json:"freq"`
+}
+
+// DecileCoverage stores the coverage of the word types in a frequency decile, whose frequency is in [MinFreq, MaxFreq].
+type DecileCoverage struct {
+	Decile        int     `json:"decile"`
+	MinFreq       int     `json:"min_freq"`
+	MaxFreq       int     `json:"max_freq"`
+	Tokens        int     `json:"tokens"`
+	TokenCoverage float64 `json:"token_coverage"`
+	Types         int     `json:"types"`
+	TypeCoverage  float64 `json:"type_coverage"`
+}
+
+// CorpusCoverage reports how much of freqs, the frequencies of words on corpus, e.g. counted by corpus.ScanTokens,
+// are covered by vocabulary of vectors, along with at most top uncovered words.
+func CorpusCoverage(vectors *vectorio.Vectors, freqs map[string]int, top int) *CoverageReport {
+	ranked := make([]WordFreq, 0, len(freqs))
+	for word, freq := range freqs {
+		ranked = append(ranked, WordFreq{Word: word, Freq: freq})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Freq != ranked[j].Freq {
+			return ranked[i].Freq > ranked[j].Freq
+		}
+		return ranked[i].Word < ranked[j].Word
+	})
+
+	report := &CoverageReport{
+		Types:     len(ranked),
+		Uncovered: make([]WordFreq, 0),
+		Deciles:   make([]DecileCoverage, 0, coverageBuckets),
+	}
+	var decile *DecileCoverage
+	var decileCoveredTokens, decileCoveredTypes int
+	for i, wf := range ranked {
+		if d := i * coverageBuckets / len(ranked); decile == nil || d+1 != decile.Decile {
+			report.Deciles = append(report.Deciles, DecileCoverage{Decile: d + 1, MaxFreq: wf.Freq})
+			decile = &report.Deciles[len(report.Deciles)-1]
+			decileCoveredTokens, decileCoveredTypes = 0, 0
+		}
+		_, covered := vectors.Vector[wf.Word]
+		report.Tokens += wf.Freq
+		decile.Tokens += wf.Freq
+		decile.Types++
+		decile.MinFreq = wf.Freq
+		if covered {
+			report.CoveredTokens += wf.Freq
+			report.CoveredTypes++
+			decileCoveredTokens += wf.Freq
+			decileCoveredTypes++
+		} else if len(report.Uncovered) < top {
+			report.Uncovered = append(report.Uncovered, wf)
+		}
+		decile.TokenCoverage = ratio(decileCoveredTokens, decile.Tokens)
+		decile.TypeCoverage = ratio(decileCoveredTypes, decile.Types)
+	}
+	report.TokenCoverage = ratio(report.CoveredTokens, report.Tokens)
+	report.TypeCoverage = ratio(report.CoveredTypes, report.Types)
+	return report
+}
+
+func ratio(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+// WriteCoverageReport writes report in format. One of: text|json
+func WriteCoverageReport(w io.Writer, report *CoverageReport, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "text":
+	default:
+		return errors.Errorf("Invalid format: %s not in text|json", format)
+	}
+
+	fmt.Fprintf(w, "Token coverage: %d / %d (%.2f%%)\n", report.CoveredTokens, report.Tokens, 100*report.TokenCoverage)
+	fmt.Fprintf(w, "Type coverage: %d / %d (%.2f%%)\n", report.CoveredTypes, report.Types, 100*report.TypeCoverage)
+
+	fmt.Fprintln(w, "\nCoverage by frequency decile:")
+	tw := tablewriter.NewWriter(w)
+	tw.SetHeader([]string{"Decile", "Frequency", "Tokens", "Token coverage", "Types", "Type coverage"})
+	tw.SetBorder(false)
+	for _, d := range report.Deciles {
+		tw.Append([]string{
+			fmt.Sprintf("%d", d.Decile),
+			fmt.Sprintf("%d-%d", d.MinFreq, d.MaxFreq),
+			fmt.Sprintf("%d", d.Tokens),
+			fmt.Sprintf("%.2f%%", 100*d.TokenCoverage),
+			fmt.Sprintf("%d", d.Types),
+			fmt.Sprintf("%.2f%%", 100*d.TypeCoverage),
+		})
+	}
+	tw.Render()
+
+	if len(report.Uncovered) > 0 {
+		fmt.Fprintln(w, "\nTop uncovered words:")
+		tw := tablewriter.NewWriter(w)
+		tw.SetHeader([]string{"Rank", "Word", "Frequency"})
+		tw.SetBorder(false)
+		for r, wf := range report.Uncovered {
+			tw.Append([]string{fmt.Sprintf("%d", r+1), wf.Word, fmt.Sprintf("%d", wf.Freq)})
+		}
+		tw.Render()
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ynqa/wego/corpus"
+	"github.com/ynqa/wego/vectorio"
+)
+
+func TestCorpusCoverage(t *testing.T) {
+	vectors, err := vectorio.ReadText(strings.NewReader("the 1 1\ncat 2 2\nsat 3 3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	freqs := make(map[string]int)
+	if err := corpus.ScanTokens(strings.NewReader("The cat sat on the mat\nthe dog sat"),
+		corpus.ParseConfig{ToLower: true}, func(word string) {
+			freqs[word]++
+		}); err != nil {
+		t.Fatal(err)
+	}
+
+	report := CorpusCoverage(vectors, freqs, 2)
+	if report.Tokens != 9 || report.CoveredTokens != 6 || report.Types != 6 || report.CoveredTypes != 3 {
+		t.Errorf("Expected 6/9 tokens and 3/6 types covered: %+v", report)
+	}
+	if math.Abs(report.TokenCoverage-6.0/9) > 1e-9 || report.TypeCoverage != 0.5 {
+		t.Errorf("Expected coverage 0.67 for tokens and 0.5 for types: %v, %v", report.TokenCoverage, report.TypeCoverage)
+	}
+	expected := []WordFreq{{Word: "dog", Freq: 1}, {Word: "mat", Freq: 1}}
+	if !reflect.DeepEqual(report.Uncovered, expected) {
+		t.Errorf("Expected top uncovered words %v: %v", expected, report.Uncovered)
+	}
+
+	// 6 types in 10 deciles: the, sat, cat, dog, mat, on in order of frequency.
+	if len(report.Deciles) != 6 {
+		t.Fatalf("Expected a decile per type for 6 types: %+v", report.Deciles)
+	}
+	if d := report.Deciles[0]; d.Decile != 1 || d.MaxFreq != 3 || d.TokenCoverage != 1 {
+		t.Errorf("Expected the first decile covers the: %+v", d)
+	}
+	if d := report.Deciles[5]; d.Decile != 9 || d.MinFreq != 1 || d.TypeCoverage != 0 {
+		t.Errorf("Expected the last decile does not cover on: %+v", d)
+	}
+	var tokens int
+	for _, d := range report.Deciles {
+		tokens += d.Tokens
+	}
+	if tokens != report.Tokens {
+		t.Errorf("Expected deciles to add up to %d tokens: %d", report.Tokens, tokens)
+	}
+}
+
+func TestWriteCoverageReport(t *testing.T) {
+	vectors, err := vectorio.ReadText(strings.NewReader("a 1 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := CorpusCoverage(vectors, map[string]int{"a": 3, "b": 1}, 10)
+
+	var buf bytes.Buffer
+	if err := WriteCoverageReport(&buf, report, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var decoded CoverageReport
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&decoded, report) {
+		t.Errorf("Expected json to decode into %+v: %+v", report, decoded)
+	}
+
+	buf.Reset()
+	if err := WriteCoverageReport(&buf, report, "text"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Token coverage: 3 / 4 (75.00%)") {
+		t.Errorf("Expected token coverage in text: %v", buf.String())
+	}
+	if err := WriteCoverageReport(&buf, report, "csv"); err == nil {
+		t.Error("Expected to fail writing in unknown format")
+	}
+}
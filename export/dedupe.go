@@ -0,0 +1,147 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ynqa/wego/vectorio"
+)
+
+// DedupeGroup is a group of near-duplicate words, e.g. singular and plural forms or typos.
+type DedupeGroup struct {
+	// Canonical is the most frequent word of the group to represent it.
+	Canonical string
+	// Duplicates are the other words of the group in descending order of frequency.
+	Duplicates []string
+}
+
+// Dedupe groups the words whose vectors have cosine similarity of threshold or more, transitively, i.e. the
+// connected components of the graph of such pairs found by union-find. It compares all pairs of words, and skips
+// zero vectors. It returns the groups of 2 or more words in descending order of frequency of their canonicals.
+// The words without frequency are regarded as frequency 0, and ties are broken by the order of words.
+func Dedupe(vectors *vectorio.Vectors, freqs map[string]int, threshold float64) []DedupeGroup {
+	words := make([]string, len(vectors.Words))
+	copy(words, vectors.Words)
+	sort.SliceStable(words, func(i, j int) bool {
+		return freqs[words[i]] > freqs[words[j]]
+	})
+
+	unit := make([][]float64, len(words))
+	for i, word := range words {
+		unit[i] = normalize(vectors.Vector[word])
+	}
+
+	parent := make([]int, len(words))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range words {
+		if unit[i] == nil {
+			continue
+		}
+		for j := i + 1; j < len(words); j++ {
+			if unit[j] == nil || dot(unit[i], unit[j]) < threshold {
+				continue
+			}
+			// the root is always the most frequent word of the group, since words are in order of frequency.
+			if ri, rj := find(i), find(j); ri < rj {
+				parent[rj] = ri
+			} else if rj < ri {
+				parent[ri] = rj
+			}
+		}
+	}
+
+	groups := make([]DedupeGroup, 0)
+	index := make(map[int]int)
+	for i, word := range words {
+		root := find(i)
+		if root == i {
+			continue
+		}
+		k, ok := index[root]
+		if !ok {
+			k = len(groups)
+			index[root] = k
+			groups = append(groups, DedupeGroup{Canonical: words[root]})
+		}
+		groups[k].Duplicates = append(groups[k].Duplicates, word)
+	}
+	return groups
+}
+
+// normalize returns vec scaled to unit L2 norm, or nil for zero vector.
+func normalize(vec []float64) []float64 {
+	norm := vectorio.Norm(vec)
+	if norm == 0 {
+		return nil
+	}
+	unit := make([]float64, len(vec))
+	for i, v := range vec {
+		unit[i] = v / norm
+	}
+	return unit
+}
+
+func dot(v1, v2 []float64) float64 {
+	var sum float64
+	for i := range v1 {
+		sum += v1[i] * v2[i]
+	}
+	return sum
+}
+
+// WriteDedupeGroups writes a line of "canonical<TAB>duplicate<TAB>..." per group.
+func WriteDedupeGroups(w io.Writer, groups []DedupeGroup) error {
+	bw := bufio.NewWriter(w)
+	for _, g := range groups {
+		if _, err := bw.WriteString(g.Canonical + "\t" + strings.Join(g.Duplicates, "\t") + "\n"); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// Canonicals returns the vectors without the duplicates of groups, preserving the order of words.
+func Canonicals(vectors *vectorio.Vectors, groups []DedupeGroup) *vectorio.Vectors {
+	duplicated := make(map[string]struct{})
+	for _, g := range groups {
+		for _, word := range g.Duplicates {
+			duplicated[word] = struct{}{}
+		}
+	}
+	reduced := &vectorio.Vectors{
+		Words:  make([]string, 0, len(vectors.Words)-len(duplicated)),
+		Vector: make(map[string][]float64, len(vectors.Words)-len(duplicated)),
+	}
+	for _, word := range vectors.Words {
+		if _, ok := duplicated[word]; !ok {
+			reduced.Words = append(reduced.Words, word)
+			reduced.Vector[word] = vectors.Vector[word]
+		}
+	}
+	return reduced
+}
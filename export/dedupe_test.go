@@ -0,0 +1,57 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/ynqa/wego/vectorio"
+)
+
+func TestDedupe(t *testing.T) {
+	vectors := &vectorio.Vectors{
+		Words: []string{"car", "cars", "dog", "kar", "zero", "cat"},
+		Vector: map[string][]float64{
+			"car":  {1, 0, 0},
+			"cars": {0.99, 0.05, 0},
+			"kar":  {0.98, 0, 0.1},
+			"dog":  {0, 1, 0},
+			"cat":  {0, 0.9, 0.5},
+			"zero": {0, 0, 0},
+		},
+	}
+	freqs := map[string]int{"car": 10, "cars": 50, "kar": 1, "dog": 30, "cat": 20}
+
+	groups := Dedupe(vectors, freqs, 0.97)
+	expected := []DedupeGroup{{Canonical: "cars", Duplicates: []string{"car", "kar"}}}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Fatalf("Expected car, cars and kar grouped into cars: %+v", groups)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDedupeGroups(&buf, groups); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "cars\tcar\tkar\n" {
+		t.Errorf("Expected a line of canonical and duplicates: %q", buf.String())
+	}
+
+	reduced := Canonicals(vectors, groups)
+	if !reflect.DeepEqual(reduced.Words, []string{"cars", "dog", "zero", "cat"}) || len(reduced.Vector) != 4 {
+		t.Errorf("Expected duplicates to be removed preserving order: %v", reduced.Words)
+	}
+}
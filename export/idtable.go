@@ -0,0 +1,203 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/model"
+	"github.com/ynqa/wego/vectorio"
+)
+
+// The idtable format is the dense binary table whose row i is the vector for the token with id i
+// in the user-supplied dictionary. All values are little-endian:
+//
+//   rows uint32
+//   dim  uint32
+//   rows*dim float32 in row-major order
+
+// The list of fill for the rows whose token is not in vocabulary.
+const (
+	FillZeros = "zeros"
+	FillMean  = "mean"
+	FillUnk   = "unk"
+)
+
+// Coverage reports how many tokens in the dictionary are found in vocabulary, and the missing ones in sorted order.
+type Coverage struct {
+	Rows    int
+	Covered int
+	Missing []string
+}
+
+// ReadDict reads the dictionary whose lines are "token<TAB>id". ids must be less than math.MaxUint32,
+// so that the number of rows fits in the header.
+func ReadDict(r io.Reader) (map[string]int, error) {
+	dict := make(map[string]int)
+	tokens := make(map[int]string)
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := vectorio.TrimLine(scanner.Text())
+		if line == "" {
+			continue
+		}
+		sep := strings.Split(line, "\t")
+		if len(sep) != 2 {
+			return nil, errors.Errorf("Invalid dictionary line %d: %q not in token<TAB>id", lineNum, line)
+		}
+		id, err := strconv.Atoi(sep[1])
+		if err != nil || !validID(id) {
+			return nil, errors.Errorf("Invalid id at dictionary line %d: %q", lineNum, sep[1])
+		}
+		if other, ok := tokens[id]; ok {
+			return nil, errors.Errorf("Duplicate id %d for %v and %v at dictionary line %d", id, other, sep[0], lineNum)
+		}
+		tokens[id] = sep[0]
+		dict[sep[0]] = id
+	}
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "Unable to complete scanning")
+	}
+	return dict, nil
+}
+
+// validID returns whether id is a row of idtable, whose number of rows id+1 fits in uint32.
+func validID(id int) bool {
+	return id >= 0 && int64(id) < math.MaxUint32
+}
+
+// WriteIDTable writes the vectors in idtable format indexed by the ids in dict.
+// The rows for tokens not in vocabulary, and ids not in dict, are filled by one of: zeros|mean|unk,
+// where unk is the vector for the unk word.
+func WriteIDTable(w io.Writer, vectors *vectorio.Vectors, dict map[string]int, fill, unk string) (*Coverage, error) {
+	dim := vectors.Dimension()
+	if dim == 0 {
+		return nil, errors.New("No vectors to export")
+	}
+	for word, vec := range vectors.Vector {
+		if len(vec) != dim {
+			return nil, errors.Errorf("Dimension of %v is %d, but expected %d", word, len(vec), dim)
+		}
+	}
+
+	var fillVec []float64
+	switch fill {
+	case FillZeros:
+		fillVec = make([]float64, dim)
+	case FillMean:
+		fillVec = make([]float64, dim)
+		for _, vec := range vectors.Vector {
+			for i := 0; i < dim; i++ {
+				fillVec[i] += vec[i] / float64(len(vectors.Words))
+			}
+		}
+	case FillUnk:
+		vec, ok := vectors.Vector[unk]
+		if !ok {
+			return nil, errors.Errorf("%v is not found for fill=unk", unk)
+		}
+		fillVec = vec
+	default:
+		return nil, errors.Errorf("Invalid fill: %s not in zeros|mean|unk", fill)
+	}
+
+	rows := 0
+	for token, id := range dict {
+		if !validID(id) {
+			return nil, errors.Errorf("Invalid id of %v: %d", token, id)
+		}
+		if id+1 > rows {
+			rows = id + 1
+		}
+	}
+	table := make([][]float64, rows)
+	coverage := &Coverage{
+		Rows:    rows,
+		Missing: make([]string, 0),
+	}
+	for token, id := range dict {
+		vec, ok := vectors.Vector[token]
+		if !ok {
+			coverage.Missing = append(coverage.Missing, token)
+			continue
+		}
+		table[id] = vec
+		coverage.Covered++
+	}
+	sort.Strings(coverage.Missing)
+
+	wr := bufio.NewWriter(w)
+	if err := binary.Write(wr, binary.LittleEndian, [2]uint32{uint32(rows), uint32(dim)}); err != nil {
+		return nil, err
+	}
+	row := make([]float32, dim)
+	for _, vec := range table {
+		if vec == nil {
+			vec = fillVec
+		}
+		for i := 0; i < dim; i++ {
+			row[i] = float32(vec[i])
+		}
+		if err := binary.Write(wr, binary.LittleEndian, row); err != nil {
+			return nil, err
+		}
+	}
+	if err := wr.Flush(); err != nil {
+		return nil, err
+	}
+	return coverage, nil
+}
+
+// readChunk is the number of values to read at once, so that memory grows with the table actually read
+// rather than the size in the header.
+const readChunk = 1 << 16
+
+// ReadIDTable reads the table written in idtable format, and returns it as row-major values.
+func ReadIDTable(r io.Reader) ([]float32, int, int, error) {
+	var header [2]uint32
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, 0, 0, errors.Wrap(err, "Unable to read header")
+	}
+	rows, dim := int(header[0]), int(header[1])
+	size, err := model.ElementSize(rows, dim)
+	if err != nil {
+		return nil, 0, 0, errors.Wrap(err, "Invalid header")
+	}
+	chunk := make([]float32, minInt(size, readChunk))
+	data := make([]float32, 0, len(chunk))
+	for len(data) < size {
+		n := minInt(size-len(data), len(chunk))
+		if err := binary.Read(r, binary.LittleEndian, chunk[:n]); err != nil {
+			return nil, 0, 0, errors.Wrap(err, "Unable to read table")
+		}
+		data = append(data, chunk[:n]...)
+	}
+	return data, rows, dim, nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
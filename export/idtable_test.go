@@ -0,0 +1,123 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ynqa/wego/vectorio"
+)
+
+var testVector = `apple 1 2
+banana 3 4
+<unk> 0.5 0.5
+`
+
+func TestIDTableRoundTrip(t *testing.T) {
+	vectors, err := vectorio.ReadText(strings.NewReader(testVector))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dict, err := ReadDict(strings.NewReader("banana\t0\nchocolate\t1\napple\t3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		fill     string
+		expected []float32
+	}{
+		{FillZeros, []float32{3, 4, 0, 0, 0, 0, 1, 2}},
+		{FillMean, []float32{3, 4, 1.5, 2.1666667, 1.5, 2.1666667, 1, 2}},
+		{FillUnk, []float32{3, 4, 0.5, 0.5, 0.5, 0.5, 1, 2}},
+	}
+
+	for _, testCase := range testCases {
+		var buf bytes.Buffer
+		coverage, err := WriteIDTable(&buf, vectors, dict, testCase.fill, "<unk>")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if coverage.Rows != 4 || coverage.Covered != 2 || len(coverage.Missing) != 1 {
+			t.Errorf("Expected coverage with rows=4, covered=2, missing=[chocolate]: %+v", coverage)
+		}
+
+		data, rows, dim, err := ReadIDTable(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rows != 4 || dim != 2 {
+			t.Errorf("Expected rows=4, dim=2: rows=%d, dim=%d", rows, dim)
+		}
+		for i := range testCase.expected {
+			if data[i] != testCase.expected[i] {
+				t.Errorf("Expected table with fill=%v: %v, but got %v", testCase.fill, testCase.expected, data)
+				break
+			}
+		}
+	}
+}
+
+func TestInvalidIDTable(t *testing.T) {
+	vectors, _ := vectorio.ReadText(strings.NewReader("apple 1 2\n"))
+	dict := map[string]int{"apple": 0}
+
+	if _, err := WriteIDTable(&bytes.Buffer{}, vectors, dict, "fake_fill", "<unk>"); err == nil {
+		t.Error("Expected to fail with invalid fill except for zeros|mean|unk")
+	}
+	if _, err := WriteIDTable(&bytes.Buffer{}, vectors, dict, FillUnk, "<unk>"); err == nil {
+		t.Error("Expected to fail with fill=unk when unk is not in vocabulary")
+	}
+	if _, err := ReadDict(strings.NewReader("apple\t0\nbanana\t0\n")); err == nil {
+		t.Error("Expected to fail reading dictionary with duplicate ids")
+	}
+	if _, err := ReadDict(strings.NewReader("apple\t4294967295\n")); err == nil {
+		t.Error("Expected to fail reading dictionary with id=MaxUint32, whose rows overflow the header")
+	}
+	if _, err := ReadDict(strings.NewReader("apple\t4294967294\n")); err != nil {
+		t.Errorf("Expected id=MaxUint32-1 to be valid: %v", err)
+	}
+	if _, err := WriteIDTable(&bytes.Buffer{}, vectors, map[string]int{"apple": -1}, FillZeros, "<unk>"); err == nil {
+		t.Error("Expected to fail writing negative id")
+	}
+	short := &vectorio.Vectors{
+		Words:  []string{"apple", "banana"},
+		Vector: map[string][]float64{"apple": {1, 2}, "banana": {3}},
+	}
+	if _, err := WriteIDTable(&bytes.Buffer{}, short, map[string]int{"banana": 0}, FillZeros, "<unk>"); err == nil {
+		t.Error("Expected to fail writing vector whose dimension is different from the others")
+	}
+	if _, _, _, err := ReadIDTable(bytes.NewReader([]byte{255, 255, 255, 255, 255, 255, 255, 255})); err == nil {
+		t.Error("Expected to fail reading the table shorter than the header")
+	}
+}
+
+func TestIDTableMissingOrder(t *testing.T) {
+	vectors, _ := vectorio.ReadText(strings.NewReader("apple 1 2\n"))
+	dict := map[string]int{"apple": 0, "egg": 1, "banana": 2, "durian": 3, "cherry": 4}
+
+	for i := 0; i < 10; i++ {
+		coverage, err := WriteIDTable(&bytes.Buffer{}, vectors, dict, FillZeros, "<unk>")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := []string{"banana", "cherry", "durian", "egg"}; !reflect.DeepEqual(coverage.Missing, expected) {
+			t.Fatalf("Expected missing tokens in sorted order %v: %v", expected, coverage.Missing)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/ynqa/wego/vectorio"
+)
+
+// WriteNorms writes L2 norm of the vector for each word as word<TAB>norm lines with the order of words.
+func WriteNorms(w io.Writer, vectors *vectorio.Vectors) error {
+	wr := bufio.NewWriter(w)
+	for _, word := range vectors.Words {
+		norm, _ := vectors.Norm(word)
+		fmt.Fprintf(wr, "%s\t%s\n", word, strconv.FormatFloat(norm, 'f', -1, 64))
+	}
+	return wr.Flush()
+}
@@ -0,0 +1,38 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ynqa/wego/vectorio"
+)
+
+func TestWriteNorms(t *testing.T) {
+	vectors, err := vectorio.ReadText(strings.NewReader("a 3 4\nb 0 0\nc -1 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteNorms(&buf, vectors); err != nil {
+		t.Fatal(err)
+	}
+	expected := "a\t5\nb\t0\nc\t1\n"
+	if buf.String() != expected {
+		t.Errorf("Expected norms %q: %q", expected, buf.String())
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/vectorio"
+)
+
+// OffsetIndex maps each word to the byte offset of its line in the text vector file,
+// so that the vector can be read without scanning the whole file.
+type OffsetIndex map[string]int64
+
+// OffsetIndexPath returns the path of sidecar index for the text vector file.
+func OffsetIndexPath(path string) string {
+	return path + ".idx"
+}
+
+// BuildOffsetIndex builds OffsetIndex for the text vector file,
+// and persists it to the sidecar index file whose lines are "word offset" in order of the file.
+// The duplicated word is indexed at its last line, which overrides the others in vectorio.ReadAll as well.
+// The first line is skipped if it is the header "<words> <dimension>" of word2vec or fastText.
+func BuildOffsetIndex(path string) (OffsetIndex, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	index := make(OffsetIndex)
+	r := bufio.NewReader(f)
+	var offset int64
+	for {
+		line, err := r.ReadString('\n')
+		_, _, header := vectorio.ParseHeader(line)
+		if len(line) > 0 && !strings.HasPrefix(line, " ") && !(offset == 0 && header) {
+			if sep := strings.Fields(vectorio.TrimLine(line)); len(sep) > 0 {
+				index[sep[0]] = offset
+			}
+		}
+		offset += int64(len(line))
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "Unable to complete scanning")
+		}
+	}
+
+	out, err := os.Create(OffsetIndexPath(path))
+	if err != nil {
+		return nil, err
+	}
+	defer out.Close()
+	words := make([]string, 0, len(index))
+	for word := range index {
+		words = append(words, word)
+	}
+	sort.Slice(words, func(i, j int) bool { return index[words[i]] < index[words[j]] })
+	w := bufio.NewWriter(out)
+	for _, word := range words {
+		fmt.Fprintf(w, "%v %d\n", word, index[word])
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// LoadOffsetIndex loads the sidecar index persisted by BuildOffsetIndex for the text vector file.
+func LoadOffsetIndex(path string) (OffsetIndex, error) {
+	f, err := os.Open(OffsetIndexPath(path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	index := make(OffsetIndex)
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		sep := strings.Fields(scanner.Text())
+		if len(sep) != 2 {
+			return nil, errors.Errorf("Invalid index line %d: %q", lineNum, scanner.Text())
+		}
+		offset, err := strconv.ParseInt(sep[1], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid offset at index line %d", lineNum)
+		}
+		index[sep[0]] = offset
+	}
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "Unable to complete scanning")
+	}
+	return index, nil
+}
+
+// Lookup reads the vector for word from the text vector file via the index.
+func (idx OffsetIndex) Lookup(r io.ReaderAt, word string) ([]float64, bool, error) {
+	offset, ok := idx[word]
+	if !ok {
+		return nil, false, nil
+	}
+	line, err := bufio.NewReader(io.NewSectionReader(r, offset, math.MaxInt64-offset)).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, false, errors.Wrapf(err, "Unable to read %v at offset %d", word, offset)
+	}
+	w, vec, err := vectorio.ParseLine(line)
+	if err != nil {
+		return nil, false, err
+	}
+	if w != word {
+		return nil, false, errors.Errorf("Stale index: expected %v at offset %d, but got %v", word, offset, w)
+	}
+	return vec, true, nil
+}
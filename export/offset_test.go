@@ -0,0 +1,138 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ynqa/wego/vectorio"
+)
+
+func TestOffsetIndex(t *testing.T) {
+	text := "apple 1 2 3 \nbanana 4 5 6 \n\ncherry 7 8 9"
+	f, err := ioutil.TempFile("", "vectors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer os.Remove(OffsetIndexPath(f.Name()))
+	f.WriteString(text)
+	f.Close()
+
+	if _, err := BuildOffsetIndex(f.Name()); err != nil {
+		t.Fatal(err)
+	}
+	index, err := LoadOffsetIndex(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := vectorio.ReadText(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := os.Open(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	for _, word := range expected.Words {
+		actual, ok, err := index.Lookup(r, word)
+		if err != nil || !ok {
+			t.Fatalf("Expected to lookup %v: ok=%v, err=%v", word, ok, err)
+		}
+		for i, v := range expected.Vector[word] {
+			if actual[i] != v {
+				t.Errorf("Expected vector for %v=%v via index: %v", word, expected.Vector[word], actual)
+				break
+			}
+		}
+	}
+
+	if _, ok, _ := index.Lookup(r, "unknown"); ok {
+		t.Error("Expected unknown not to be found")
+	}
+}
+
+func TestOffsetIndexFileOrder(t *testing.T) {
+	// banana is duplicated, whose last line is read by vectorio.ReadAll.
+	text := "cherry 1 2\nbanana 3 4\napple 5 6\nbanana 7 8\n"
+	f, err := ioutil.TempFile("", "vectors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer os.Remove(OffsetIndexPath(f.Name()))
+	f.WriteString(text)
+	f.Close()
+
+	for i := 0; i < 10; i++ {
+		if _, err := BuildOffsetIndex(f.Name()); err != nil {
+			t.Fatal(err)
+		}
+		sidecar, err := ioutil.ReadFile(OffsetIndexPath(f.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := "cherry 0\napple 22\nbanana 32\n"; string(sidecar) != expected {
+			t.Fatalf("Expected the index in order of the file with the last banana: %q, but got %q",
+				expected, string(sidecar))
+		}
+	}
+
+	index, err := LoadOffsetIndex(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, err := vectorio.ReadText(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := os.Open(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	actual, _, err := index.Lookup(r, "banana")
+	if err != nil || !reflect.DeepEqual(actual, all.Vector["banana"]) {
+		t.Errorf("Expected the same vector of banana as the full scan %v: %v, %v", all.Vector["banana"], actual, err)
+	}
+}
+
+func TestOffsetIndexHeader(t *testing.T) {
+	text := "2 3\napple 1 2 3 \n2 3\n"
+	f, err := ioutil.TempFile("", "vectors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer os.Remove(OffsetIndexPath(f.Name()))
+	f.WriteString(text)
+	f.Close()
+
+	index, err := BuildOffsetIndex(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only the first line is the header, and "2" at the last line is the word whose vector is 3.
+	if expected := (OffsetIndex{"apple": 4, "2": 17}); !reflect.DeepEqual(index, expected) {
+		t.Errorf("Expected the header not to be indexed %v: %v", expected, index)
+	}
+}
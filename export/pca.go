@@ -0,0 +1,238 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/vectorio"
+)
+
+// jacobiSweeps is the upper limit of sweeps to diagonalize covariance matrix.
+const jacobiSweeps = 100
+
+// PCA projects the vectors of all words onto the top k principal components, e.g. to reduce them before t-SNE,
+// and returns the projections with the order of words. The components are the eigenvectors of covariance matrix
+// in descending order of eigenvalue, whose signs are fixed so that the largest element is positive.
+func PCA(vectors *vectorio.Vectors, k int) ([][]float64, []string, error) {
+	mean, components, _, err := principalComponents(vectors, k)
+	if err != nil {
+		return nil, nil, err
+	}
+	dim := vectors.Dimension()
+	projections := make([][]float64, len(vectors.Words))
+	for i, word := range vectors.Words {
+		vec := vectors.Vector[word]
+		projections[i] = make([]float64, k)
+		for j, component := range components {
+			for d := 0; d < dim; d++ {
+				projections[i][j] += (vec[d] - mean[d]) * component[d]
+			}
+		}
+	}
+	return projections, vectors.Words, nil
+}
+
+// ExplainedVariance returns the ratio of variance captured by each of the top k principal components of PCA.
+func ExplainedVariance(vectors *vectorio.Vectors, k int) ([]float64, error) {
+	_, _, variances, err := principalComponents(vectors, k)
+	if err != nil {
+		return nil, err
+	}
+	var total float64
+	for _, v := range variances {
+		total += v
+	}
+	ratios := make([]float64, k)
+	for j := range ratios {
+		if total > 0 {
+			ratios[j] = variances[j] / total
+		}
+	}
+	return ratios, nil
+}
+
+// RemoveMeanComponent returns the vectors post-processed by subtracting the mean vector of all words and
+// projecting out the top d principal components, i.e. "all-but-the-top", which makes the vectors isotropic
+// and improves cosine similarity. It only subtracts the mean if d is 0. vectors are not modified.
+func RemoveMeanComponent(vectors *vectorio.Vectors, d int) (*vectorio.Vectors, error) {
+	if d < 0 {
+		return nil, errors.Errorf("Invalid number of components: %d must not be negative", d)
+	}
+	k := d
+	if k == 0 {
+		// principal components are computed along with mean, but none of them are projected out.
+		k = 1
+	}
+	mean, components, _, err := principalComponents(vectors, k)
+	if err != nil {
+		return nil, err
+	}
+	components = components[:d]
+
+	dim := vectors.Dimension()
+	processed := &vectorio.Vectors{
+		Words:  vectors.Words,
+		Vector: make(map[string][]float64, len(vectors.Words)),
+	}
+	for _, word := range vectors.Words {
+		vec := make([]float64, dim)
+		for i, v := range vectors.Vector[word] {
+			vec[i] = v - mean[i]
+		}
+		for _, component := range components {
+			var dot float64
+			for i := range vec {
+				dot += vec[i] * component[i]
+			}
+			for i := range vec {
+				vec[i] -= dot * component[i]
+			}
+		}
+		processed.Vector[word] = vec
+	}
+	return processed, nil
+}
+
+// principalComponents returns mean vector, the top k eigenvectors of covariance matrix, and all the eigenvalues
+// in descending order.
+func principalComponents(vectors *vectorio.Vectors, k int) ([]float64, [][]float64, []float64, error) {
+	dim, n := vectors.Dimension(), len(vectors.Words)
+	if n < 2 {
+		return nil, nil, nil, errors.Errorf("At least 2 vectors are required for PCA: %d", n)
+	}
+	if k < 1 || k > dim {
+		return nil, nil, nil, errors.Errorf("Invalid number of components: %d not in [1, %d]", k, dim)
+	}
+
+	mean := make([]float64, dim)
+	for _, word := range vectors.Words {
+		vec := vectors.Vector[word]
+		if len(vec) != dim {
+			return nil, nil, nil, errors.Errorf("Dimension of %v is %d, but expected %d", word, len(vec), dim)
+		}
+		for d, v := range vec {
+			mean[d] += v / float64(n)
+		}
+	}
+	cov := make([][]float64, dim)
+	for a := range cov {
+		cov[a] = make([]float64, dim)
+	}
+	for _, word := range vectors.Words {
+		vec := vectors.Vector[word]
+		for a := 0; a < dim; a++ {
+			da := vec[a] - mean[a]
+			for b := a; b < dim; b++ {
+				cov[a][b] += da * (vec[b] - mean[b]) / float64(n-1)
+			}
+		}
+	}
+	for a := 0; a < dim; a++ {
+		for b := 0; b < a; b++ {
+			cov[a][b] = cov[b][a]
+		}
+	}
+
+	values, vecs := jacobiEigen(cov)
+	order := make([]int, dim)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return values[order[i]] > values[order[j]] })
+
+	components := make([][]float64, k)
+	variances := make([]float64, dim)
+	for j, o := range order {
+		variances[j] = values[o]
+		if j >= k {
+			continue
+		}
+		component := make([]float64, dim)
+		var largest float64
+		for d := 0; d < dim; d++ {
+			component[d] = vecs[d][o]
+			if math.Abs(component[d]) > math.Abs(largest) {
+				largest = component[d]
+			}
+		}
+		if largest < 0 {
+			for d := range component {
+				component[d] = -component[d]
+			}
+		}
+		components[j] = component
+	}
+	return mean, components, variances, nil
+}
+
+// jacobiEigen diagonalizes symmetric matrix m by cyclic Jacobi rotations, and returns the eigenvalues and
+// the matrix whose column i is the eigenvector for the eigenvalue i. m is overwritten.
+func jacobiEigen(m [][]float64) ([]float64, [][]float64) {
+	n := len(m)
+	v := make([][]float64, n)
+	for i := range v {
+		v[i] = make([]float64, n)
+		v[i][i] = 1
+	}
+	for sweep := 0; sweep < jacobiSweeps; sweep++ {
+		var off float64
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				off += m[p][q] * m[p][q]
+			}
+		}
+		if off < 1e-22 {
+			break
+		}
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				if m[p][q] == 0 {
+					continue
+				}
+				theta := (m[q][q] - m[p][p]) / (2 * m[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for r := 0; r < n; r++ {
+					mrp, mrq := m[r][p], m[r][q]
+					m[r][p] = c*mrp - s*mrq
+					m[r][q] = s*mrp + c*mrq
+				}
+				for r := 0; r < n; r++ {
+					mpr, mqr := m[p][r], m[q][r]
+					m[p][r] = c*mpr - s*mqr
+					m[q][r] = s*mpr + c*mqr
+				}
+				for r := 0; r < n; r++ {
+					vrp, vrq := v[r][p], v[r][q]
+					v[r][p] = c*vrp - s*vrq
+					v[r][q] = s*vrp + c*vrq
+				}
+			}
+		}
+	}
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = m[i][i]
+	}
+	return values, v
+}
@@ -0,0 +1,123 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ynqa/wego/vectorio"
+)
+
+func TestPCA(t *testing.T) {
+	// spread mostly along (1, 1, 0), slightly along (1, -1, 0), and not at all along z.
+	vectors := &vectorio.Vectors{
+		Words: []string{"a", "b", "c", "d", "e"},
+		Vector: map[string][]float64{
+			"a": {-2, -2, 1},
+			"b": {-1.1, -0.9, 1},
+			"c": {0, 0, 1},
+			"d": {0.9, 1.1, 1},
+			"e": {2, 2, 1},
+		},
+	}
+	projections, words, err := PCA(vectors, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projections) != 5 || len(words) != 5 || words[0] != "a" {
+		t.Fatalf("Expected projections of 5 words in order: %v, %v", projections, words)
+	}
+	var first, second float64
+	for _, p := range projections {
+		if len(p) != 2 {
+			t.Fatalf("Expected projections of dimension 2: %v", p)
+		}
+		first += p[0] * p[0]
+		second += p[1] * p[1]
+	}
+	if first <= second {
+		t.Errorf("Expected the first component to capture the most variance: %v, %v", first, second)
+	}
+	if expected := 2 * math.Sqrt(2); math.Abs(math.Abs(projections[4][0])-expected) > 1e-9 {
+		t.Errorf("Expected projection of e on the first component=%v: %v", expected, projections[4][0])
+	}
+
+	ratios, err := ExplainedVariance(vectors, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ratios[0] < 0.99 || ratios[0] < ratios[1] || math.Abs(ratios[2]) > 1e-12 {
+		t.Errorf("Expected the first component to explain almost all variance and z none: %v", ratios)
+	}
+}
+
+func TestInvalidPCA(t *testing.T) {
+	vectors := &vectorio.Vectors{
+		Words:  []string{"a", "b"},
+		Vector: map[string][]float64{"a": {1, 2}, "b": {3, 4}},
+	}
+	if _, _, err := PCA(vectors, 3); err == nil {
+		t.Error("Expected to fail with more components than dimension")
+	}
+	if _, _, err := PCA(vectors, 0); err == nil {
+		t.Error("Expected to fail with no components")
+	}
+	single := &vectorio.Vectors{Words: []string{"a"}, Vector: map[string][]float64{"a": {1, 2}}}
+	if _, _, err := PCA(single, 1); err == nil {
+		t.Error("Expected to fail with a single vector")
+	}
+}
+
+func TestRemoveMeanComponent(t *testing.T) {
+	// vectors in a narrow cone around (5, 5, 5, 5), spread mostly along the first axis.
+	vectors := &vectorio.Vectors{
+		Words: []string{"a", "b", "c", "d", "e", "f"},
+		Vector: map[string][]float64{
+			"a": {8, 5.2, 4.9, 5},
+			"b": {2, 4.8, 5.1, 5.2},
+			"c": {7, 5.1, 5.3, 4.8},
+			"d": {3, 4.9, 4.7, 5.1},
+			"e": {6, 5.3, 5, 4.9},
+			"f": {4, 4.7, 5, 5},
+		},
+	}
+	before := vectors.Anisotropy()
+	if before < 0.8 {
+		t.Fatalf("Expected anisotropic vectors: %v", before)
+	}
+
+	processed, err := RemoveMeanComponent(vectors, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after := processed.Anisotropy(); math.Abs(after) >= before/2 {
+		t.Errorf("Expected anisotropy to drop after removing mean and top component: %v -> %v", before, after)
+	}
+	if vectors.Vector["a"][0] != 8 {
+		t.Errorf("Expected original vectors not to be modified: %v", vectors.Vector["a"])
+	}
+	var sum float64
+	for _, word := range processed.Words {
+		sum += processed.Vector[word][0]
+	}
+	if math.Abs(sum) > 1e-9 {
+		t.Errorf("Expected processed vectors to have zero mean: %v", sum)
+	}
+
+	if _, err := RemoveMeanComponent(vectors, -1); err == nil {
+		t.Error("Expected to fail with negative components")
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/vectorio"
+)
+
+// PruneOptions stores the options to prune vocabulary.
+type PruneOptions struct {
+	// Top is number of the most frequent words to keep.
+	Top int
+	// Keep is the list of words to keep regardless of frequency.
+	Keep []string
+}
+
+// ReadVocab reads the vocabulary file whose lines are "word frequency".
+func ReadVocab(r io.Reader) (map[string]int, error) {
+	freqs := make(map[string]int)
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		sep := strings.Fields(vectorio.TrimLine(scanner.Text()))
+		if len(sep) == 0 {
+			continue
+		}
+		if len(sep) != 2 {
+			return nil, errors.Errorf("Invalid vocabulary line %d: %q not in word frequency", lineNum, scanner.Text())
+		}
+		freq, err := strconv.Atoi(sep[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid frequency at vocabulary line %d", lineNum)
+		}
+		freqs[sep[0]] = freq
+	}
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "Unable to complete scanning")
+	}
+	return freqs, nil
+}
+
+// Prune returns the vectors only for the top most frequent words and the words to keep,
+// preserving the order of words. It also returns the words to keep which are not in vectors.
+// The words without frequency are regarded as frequency 0.
+func Prune(vectors *vectorio.Vectors, freqs map[string]int, opts PruneOptions) (*vectorio.Vectors, []string) {
+	ranked := make([]string, len(vectors.Words))
+	copy(ranked, vectors.Words)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return freqs[ranked[i]] > freqs[ranked[j]]
+	})
+
+	kept := make(map[string]struct{})
+	for i := 0; i < opts.Top && i < len(ranked); i++ {
+		kept[ranked[i]] = struct{}{}
+	}
+	missing := make([]string, 0)
+	for _, word := range opts.Keep {
+		if _, ok := vectors.Vector[word]; !ok {
+			missing = append(missing, word)
+			continue
+		}
+		kept[word] = struct{}{}
+	}
+
+	pruned := &vectorio.Vectors{
+		Words:  make([]string, 0, len(kept)),
+		Vector: make(map[string][]float64, len(kept)),
+	}
+	for _, word := range vectors.Words {
+		if _, ok := kept[word]; ok {
+			pruned.Words = append(pruned.Words, word)
+			pruned.Vector[word] = vectors.Vector[word]
+		}
+	}
+	return pruned, missing
+}
@@ -0,0 +1,61 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ynqa/wego/vectorio"
+)
+
+func TestPrune(t *testing.T) {
+	vectors, err := vectorio.ReadText(strings.NewReader("c 1 1\nb 2 2\na 3 3\nd 4 4\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	freqs, err := ReadVocab(strings.NewReader("a 10\nb 5\nc 3\nd 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pruned, missing := Prune(vectors, freqs, PruneOptions{
+		Top:  2,
+		Keep: []string{"d", "z"},
+	})
+
+	expected := []string{"b", "a", "d"}
+	if len(pruned.Words) != len(expected) {
+		t.Fatalf("Expected pruned words=%v: %v", expected, pruned.Words)
+	}
+	for i, word := range expected {
+		if pruned.Words[i] != word {
+			t.Errorf("Expected pruned words=%v preserving order: %v", expected, pruned.Words)
+			break
+		}
+	}
+	if len(missing) != 1 || missing[0] != "z" {
+		t.Errorf("Expected missing=[z]: %v", missing)
+	}
+
+	var buf bytes.Buffer
+	if err := vectorio.WriteText(&buf, pruned); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "b 2 2 \na 3 3 \nd 4 4 \n" {
+		t.Errorf("Unexpected pruned vectors: %q", buf.String())
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// The candidates of minCount and subsample threshold which SpectrumReport estimates the removal of tokens with.
+var (
+	SpectrumMinCounts  = []int{1, 2, 5, 10, 20, 50, 100}
+	SpectrumThresholds = []float64{1.0e-5, 1.0e-4, 1.0e-3, 1.0e-2}
+)
+
+// SpectrumReport stores the frequency spectrum of words on corpus, e.g. to check Zipf's law and to choose minCount
+// and subsample threshold before training.
+type SpectrumReport struct {
+	Tokens int
+	// Ranked is the words in descending order of frequency, i.e. by rank from 1.
+	Ranked []WordFreq
+	// MinCounts is the removal of tokens by each of SpectrumMinCounts.
+	MinCounts []MinCountRemoval
+	// Thresholds is the removal of tokens by subsampling with each of SpectrumThresholds.
+	Thresholds []SubsampleRemoval
+}
+
+// MinCountRemoval stores the words and tokens removed by minCount, i.e. of the words whose frequency is minCount or less.
+type MinCountRemoval struct {
+	MinCount   int
+	Types      int
+	Tokens     int
+	TokenShare float64
+}
+
+// SubsampleRemoval stores the expected fraction of tokens discarded by subsampling of word2vec with threshold
+// on every iteration.
+type SubsampleRemoval struct {
+	Threshold  float64
+	TokenShare float64
+}
+
+// FrequencySpectrum reports the frequency spectrum of freqs, the frequencies of words on corpus,
+// e.g. counted by corpus.ScanTokens.
+func FrequencySpectrum(freqs map[string]int) *SpectrumReport {
+	report := &SpectrumReport{Ranked: make([]WordFreq, 0, len(freqs))}
+	for word, freq := range freqs {
+		report.Ranked = append(report.Ranked, WordFreq{Word: word, Freq: freq})
+		report.Tokens += freq
+	}
+	sort.Slice(report.Ranked, func(i, j int) bool {
+		if report.Ranked[i].Freq != report.Ranked[j].Freq {
+			return report.Ranked[i].Freq > report.Ranked[j].Freq
+		}
+		return report.Ranked[i].Word < report.Ranked[j].Word
+	})
+
+	for _, minCount := range SpectrumMinCounts {
+		removal := MinCountRemoval{MinCount: minCount}
+		for _, wf := range report.Ranked {
+			if wf.Freq <= minCount {
+				removal.Types++
+				removal.Tokens += wf.Freq
+			}
+		}
+		removal.TokenShare = ratio(removal.Tokens, report.Tokens)
+		report.MinCounts = append(report.MinCounts, removal)
+	}
+	for _, threshold := range SpectrumThresholds {
+		var discarded float64
+		for _, wf := range report.Ranked {
+			// the probability to keep the word is the same as subsampling of word2vec.
+			z := float64(wf.Freq) / float64(report.Tokens)
+			if keep := (math.Sqrt(z/threshold) + 1.0) * threshold / z; keep < 1 {
+				discarded += float64(wf.Freq) * (1 - keep)
+			}
+		}
+		removal := SubsampleRemoval{Threshold: threshold}
+		if report.Tokens > 0 {
+			removal.TokenShare = discarded / float64(report.Tokens)
+		}
+		report.Thresholds = append(report.Thresholds, removal)
+	}
+	return report
+}
+
+// WriteSpectrum writes the spectrum of report as CSV whose rows are "rank,frequency,cumulative_share",
+// where cumulative_share is the fraction of tokens of the words up to the rank.
+func WriteSpectrum(w io.Writer, report *SpectrumReport) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("rank,frequency,cumulative_share\n")
+	var cumulative int
+	for r, wf := range report.Ranked {
+		cumulative += wf.Freq
+		bw.WriteString(strconv.Itoa(r + 1))
+		bw.WriteString(",")
+		bw.WriteString(strconv.Itoa(wf.Freq))
+		bw.WriteString(",")
+		bw.WriteString(strconv.FormatFloat(ratio(cumulative, report.Tokens), 'g', -1, 64))
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// WriteRankedVocab writes the words of report as vocabulary whose lines are "word frequency", e.g. to read
+// by ReadVocab.
+func WriteRankedVocab(w io.Writer, report *SpectrumReport) error {
+	bw := bufio.NewWriter(w)
+	for _, wf := range report.Ranked {
+		if _, err := fmt.Fprintf(bw, "%s %d\n", wf.Word, wf.Freq); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteSpectrumSummary writes the removal of tokens by the candidates of minCount and subsample threshold in report.
+func WriteSpectrumSummary(w io.Writer, report *SpectrumReport) {
+	fmt.Fprintf(w, "Tokens: %d, Types: %d\n", report.Tokens, len(report.Ranked))
+
+	fmt.Fprintln(w, "\nRemoved by min-count:")
+	tw := tablewriter.NewWriter(w)
+	tw.SetHeader([]string{"Min count", "Types", "Tokens", "Token share"})
+	tw.SetBorder(false)
+	for _, m := range report.MinCounts {
+		tw.Append([]string{
+			fmt.Sprintf("%d", m.MinCount),
+			fmt.Sprintf("%d", m.Types),
+			fmt.Sprintf("%d", m.Tokens),
+			fmt.Sprintf("%.2f%%", 100*m.TokenShare),
+		})
+	}
+	tw.Render()
+
+	fmt.Fprintln(w, "\nSubsampled per iteration:")
+	tw = tablewriter.NewWriter(w)
+	tw.SetHeader([]string{"Threshold", "Token share"})
+	tw.SetBorder(false)
+	for _, s := range report.Thresholds {
+		tw.Append([]string{
+			strconv.FormatFloat(s.Threshold, 'g', -1, 64),
+			fmt.Sprintf("%.2f%%", 100*s.TokenShare),
+		})
+	}
+	tw.Render()
+}
@@ -0,0 +1,72 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestFrequencySpectrum(t *testing.T) {
+	freqs := map[string]int{"the": 100, "fox": 10, "dog": 10, "rare": 1, "typo": 2}
+	report := FrequencySpectrum(freqs)
+	if report.Tokens != 123 || len(report.Ranked) != 5 {
+		t.Fatalf("Expected 123 tokens of 5 words: %d, %d", report.Tokens, len(report.Ranked))
+	}
+	if report.Ranked[0].Word != "the" || report.Ranked[1].Word != "dog" || report.Ranked[4].Word != "rare" {
+		t.Errorf("Expected words in descending order of frequency, and of words on ties: %v", report.Ranked)
+	}
+
+	for _, m := range report.MinCounts {
+		switch m.MinCount {
+		case 1:
+			if m.Types != 1 || m.Tokens != 1 {
+				t.Errorf("Expected min-count 1 to remove rare: %+v", m)
+			}
+		case 10:
+			if m.Types != 4 || m.Tokens != 23 || math.Abs(m.TokenShare-23.0/123) > 1e-12 {
+				t.Errorf("Expected min-count 10 to remove all but the: %+v", m)
+			}
+		}
+	}
+	for i, s := range report.Thresholds {
+		if s.TokenShare < 0 || s.TokenShare >= 1 {
+			t.Errorf("Expected fraction of subsampled tokens in [0, 1): %+v", s)
+		}
+		if i > 0 && s.TokenShare > report.Thresholds[i-1].TokenShare {
+			t.Errorf("Expected fewer tokens subsampled with higher threshold: %+v", report.Thresholds)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSpectrum(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 || lines[0] != "rank,frequency,cumulative_share" || lines[5] != "5,1,1" {
+		t.Errorf("Expected header and rows of rank, frequency and cumulative share: %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteRankedVocab(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	vocab, err := ReadVocab(&buf)
+	if err != nil || len(vocab) != 5 || vocab["typo"] != 2 {
+		t.Errorf("Expected vocabulary to be read back: %v, %v", vocab, err)
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/vectorio"
+)
+
+// StatsPercentiles are the percentiles of norms reported by StatsReport.
+var StatsPercentiles = []float64{0, 1, 5, 25, 50, 75, 95, 99, 100}
+
+// StatsReport stores the statistics of word vectors to diagnose them, e.g. dead dimensions or exploding norms.
+// The vectors with NaN or Inf are reported in NonFinite, and excluded from the other statistics.
+type StatsReport struct {
+	Words           int              `json:"words"`
+	Dimension       int              `json:"dimension"`
+	Dimensions      []DimensionStats `json:"dimensions"`
+	NormPercentiles []Percentile     `json:"norm_percentiles"`
+	ZeroVectors     int              `json:"zero_vectors"`
+	NonFinite       []NonFiniteWord  `json:"non_finite"`
+}
+
+// DimensionStats stores the statistics of values of a dimension over words.
+type DimensionStats struct {
+	Dimension int     `json:"dimension"`
+	Mean      float64 `json:"mean"`
+	Std       float64 `json:"std"`
+	Min       float64 `json:"min"`
+	Max       float64 `json:"max"`
+}
+
+// Percentile is the value at the percentile.
+type Percentile struct {
+	Percentile float64 `json:"percentile"`
+	Value      float64 `json:"value"`
+}
+
+// NonFiniteWord is the word whose vector has NaN or Inf values, along with the counts of them.
+type NonFiniteWord struct {
+	Word string `json:"word"`
+	NaN  int    `json:"nan"`
+	Inf  int    `json:"inf"`
+}
+
+// StatsCollector accumulates StatsReport in a single pass over word vectors, e.g. streamed by vectorio.Reader.
+type StatsCollector struct {
+	report *StatsReport
+	// count of finite vectors, and running mean, sum of squared deviations, min and max of each dimension.
+	count          int
+	mean, m2       []float64
+	minVal, maxVal []float64
+	norms          []float64
+}
+
+// NewStatsCollector creates *StatsCollector.
+func NewStatsCollector() *StatsCollector {
+	return &StatsCollector{
+		report: &StatsReport{
+			Dimension: -1,
+		},
+	}
+}
+
+// Add accumulates the vector of word.
+func (s *StatsCollector) Add(word string, vec []float64) error {
+	if s.report.Dimension < 0 {
+		s.report.Dimension = len(vec)
+		s.mean = make([]float64, len(vec))
+		s.m2 = make([]float64, len(vec))
+		s.minVal = make([]float64, len(vec))
+		s.maxVal = make([]float64, len(vec))
+	} else if len(vec) != s.report.Dimension {
+		return errors.Errorf("Dimension of %v is %d, but expected %d", word, len(vec), s.report.Dimension)
+	}
+	s.report.Words++
+
+	nonFinite := NonFiniteWord{Word: word}
+	for _, v := range vec {
+		if math.IsNaN(v) {
+			nonFinite.NaN++
+		} else if math.IsInf(v, 0) {
+			nonFinite.Inf++
+		}
+	}
+	if nonFinite.NaN > 0 || nonFinite.Inf > 0 {
+		s.report.NonFinite = append(s.report.NonFinite, nonFinite)
+		return nil
+	}
+
+	s.count++
+	zero := true
+	for d, v := range vec {
+		if v != 0 {
+			zero = false
+		}
+		if s.count == 1 || v < s.minVal[d] {
+			s.minVal[d] = v
+		}
+		if s.count == 1 || v > s.maxVal[d] {
+			s.maxVal[d] = v
+		}
+		// Welford's online algorithm.
+		delta := v - s.mean[d]
+		s.mean[d] += delta / float64(s.count)
+		s.m2[d] += delta * (v - s.mean[d])
+	}
+	if zero {
+		s.report.ZeroVectors++
+	}
+	s.norms = append(s.norms, vectorio.Norm(vec))
+	return nil
+}
+
+// Report returns StatsReport of the vectors added so far.
+func (s *StatsCollector) Report() *StatsReport {
+	report := *s.report
+	if report.Dimension < 0 {
+		report.Dimension = 0
+	}
+	report.Dimensions = make([]DimensionStats, report.Dimension)
+	for d := range report.Dimensions {
+		report.Dimensions[d].Dimension = d
+		if s.count == 0 {
+			continue
+		}
+		report.Dimensions[d].Mean = s.mean[d]
+		report.Dimensions[d].Std = math.Sqrt(s.m2[d] / float64(s.count))
+		report.Dimensions[d].Min = s.minVal[d]
+		report.Dimensions[d].Max = s.maxVal[d]
+	}
+
+	norms := append([]float64(nil), s.norms...)
+	sort.Float64s(norms)
+	report.NormPercentiles = make([]Percentile, 0, len(StatsPercentiles))
+	for _, p := range StatsPercentiles {
+		report.NormPercentiles = append(report.NormPercentiles, Percentile{
+			Percentile: p,
+			Value:      percentile(norms, p),
+		})
+	}
+	return &report
+}
+
+// percentile returns the value at p-th percentile of sorted values by linear interpolation, or 0 if it is empty.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	if lower+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
+
+// Stats returns StatsReport of word vectors.
+func Stats(vectors *vectorio.Vectors) (*StatsReport, error) {
+	s := NewStatsCollector()
+	for _, word := range vectors.Words {
+		if err := s.Add(word, vectors.Vector[word]); err != nil {
+			return nil, err
+		}
+	}
+	return s.Report(), nil
+}
+
+// WriteStatsReport writes the report in the format. One of: text|json
+func WriteStatsReport(w io.Writer, report *StatsReport, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "text":
+	default:
+		return errors.Errorf("Invalid format: %s not in text|json", format)
+	}
+
+	fmt.Fprintf(w, "Words: %d, Dimension: %d\n", report.Words, report.Dimension)
+	fmt.Fprintf(w, "Zero vectors: %d\n", report.ZeroVectors)
+	fmt.Fprintf(w, "Vectors with NaN/Inf: %d\n", len(report.NonFinite))
+
+	fmt.Fprintln(w, "\nStatistics by dimension:")
+	tw := tablewriter.NewWriter(w)
+	tw.SetHeader([]string{"Dimension", "Mean", "Std", "Min", "Max"})
+	tw.SetBorder(false)
+	for _, d := range report.Dimensions {
+		tw.Append([]string{
+			fmt.Sprintf("%d", d.Dimension),
+			fmt.Sprintf("%.6f", d.Mean),
+			fmt.Sprintf("%.6f", d.Std),
+			fmt.Sprintf("%.6f", d.Min),
+			fmt.Sprintf("%.6f", d.Max),
+		})
+	}
+	tw.Render()
+
+	fmt.Fprintln(w, "\nPercentiles of norms:")
+	tw = tablewriter.NewWriter(w)
+	tw.SetHeader([]string{"Percentile", "Norm"})
+	tw.SetBorder(false)
+	for _, p := range report.NormPercentiles {
+		tw.Append([]string{fmt.Sprintf("%g", p.Percentile), fmt.Sprintf("%.6f", p.Value)})
+	}
+	tw.Render()
+
+	if len(report.NonFinite) > 0 {
+		fmt.Fprintln(w, "\nVectors with NaN/Inf:")
+		tw := tablewriter.NewWriter(w)
+		tw.SetHeader([]string{"Word", "NaN", "Inf"})
+		tw.SetBorder(false)
+		for _, n := range report.NonFinite {
+			tw.Append([]string{n.Word, fmt.Sprintf("%d", n.NaN), fmt.Sprintf("%d", n.Inf)})
+		}
+		tw.Render()
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ynqa/wego/vectorio"
+)
+
+func TestStats(t *testing.T) {
+	vectors, err := vectorio.ReadText(strings.NewReader(
+		"a 3 0 1\nb 0 4 1\nc 0 0 0\nd NaN 1 Inf\ne 1 Inf 2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Stats(vectors)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Words != 5 || report.Dimension != 3 || report.ZeroVectors != 1 {
+		t.Errorf("Expected 5 words of dimension 3 with a zero vector: %+v", report)
+	}
+	expectedNonFinite := []NonFiniteWord{{Word: "d", NaN: 1, Inf: 1}, {Word: "e", Inf: 1}}
+	if !reflect.DeepEqual(report.NonFinite, expectedNonFinite) {
+		t.Errorf("Expected vectors with NaN/Inf %+v: %+v", expectedNonFinite, report.NonFinite)
+	}
+
+	// the statistics are over a, b and c.
+	expectedDims := []DimensionStats{
+		{Dimension: 0, Mean: 1, Std: math.Sqrt(2), Min: 0, Max: 3},
+		{Dimension: 1, Mean: 4.0 / 3, Std: math.Sqrt(32.0 / 9), Min: 0, Max: 4},
+		{Dimension: 2, Mean: 2.0 / 3, Std: math.Sqrt(2.0 / 9), Min: 0, Max: 1},
+	}
+	for d, e := range expectedDims {
+		a := report.Dimensions[d]
+		if a.Dimension != e.Dimension || math.Abs(a.Mean-e.Mean) > 1e-9 || math.Abs(a.Std-e.Std) > 1e-9 ||
+			a.Min != e.Min || a.Max != e.Max {
+			t.Errorf("Expected statistics of dimension %d %+v: %+v", d, e, a)
+		}
+	}
+
+	// norms are 0, sqrt(10) and sqrt(17).
+	percentiles := map[float64]float64{0: 0, 50: math.Sqrt(10), 75: (math.Sqrt(10) + math.Sqrt(17)) / 2, 100: math.Sqrt(17)}
+	for _, p := range report.NormPercentiles {
+		if e, ok := percentiles[p.Percentile]; ok && math.Abs(p.Value-e) > 1e-9 {
+			t.Errorf("Expected %v-th percentile of norms %v: %v", p.Percentile, e, p.Value)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WriteStatsReport(&buf, report, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var decoded StatsReport
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Words != 5 || len(decoded.Dimensions) != 3 || len(decoded.NonFinite) != 2 {
+		t.Errorf("Expected the same report via JSON: %+v", decoded)
+	}
+	buf.Reset()
+	if err := WriteStatsReport(&buf, report, "text"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Vectors with NaN/Inf: 2") {
+		t.Errorf("Expected the count of vectors with NaN/Inf in text: %s", buf.String())
+	}
+	if err := WriteStatsReport(&buf, report, "xml"); err == nil {
+		t.Error("Expected an error for unknown format")
+	}
+}
+
+func TestStatsDimension(t *testing.T) {
+	s := NewStatsCollector()
+	if err := s.Add("a", []float64{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add("b", []float64{1}); err == nil {
+		t.Error("Expected an error for vector of different dimension")
+	}
+	if report := NewStatsCollector().Report(); report.Words != 0 || report.Dimension != 0 {
+		t.Errorf("Expected an empty report without vectors: %+v", report)
+	}
+}
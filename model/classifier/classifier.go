@@ -0,0 +1,319 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package classifier
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/model"
+	"github.com/ynqa/wego/vectorio"
+)
+
+// Options is the parameters to train Classifier.
+type Options struct {
+	Iteration int
+	Initlr    float64
+	// FinetuneLr is the initial learning rate of word vectors, FinetuneLr=0 means to freeze them.
+	FinetuneLr float64
+	// Seed is the master seed to shuffle examples, Seed=0 means to generate it.
+	Seed uint64
+}
+
+// Classifier predicts labels of a line by softmax over labels on the average of its word vectors,
+// like supervised fastText.
+type Classifier struct {
+	Labels []string
+
+	vectors *vectorio.Vectors
+	dim     int
+	weight  [][]float64
+	bias    []float64
+	index   map[string]int
+
+	// seeds of the last Train.
+	summary model.Summary
+}
+
+// Prediction is a label with its probability.
+type Prediction struct {
+	Label       string
+	Probability float64
+}
+
+// New creates *Classifier on vectors with zero initialized weights for labels.
+// The vectors are updated in place on training if FinetuneLr is positive.
+func New(vectors *vectorio.Vectors, labels []string) (*Classifier, error) {
+	if len(labels) == 0 {
+		return nil, errors.New("No labels to classify")
+	}
+	dim := vectors.Dimension()
+	if dim == 0 {
+		return nil, errors.New("No word vectors to classify on")
+	}
+	weight := make([][]float64, len(labels))
+	for i := range weight {
+		weight[i] = make([]float64, dim)
+	}
+	return newClassifier(vectors, labels, weight, make([]float64, len(labels)))
+}
+
+func newClassifier(vectors *vectorio.Vectors, labels []string, weight [][]float64, bias []float64) (*Classifier, error) {
+	index := make(map[string]int, len(labels))
+	for i, label := range labels {
+		if _, ok := index[label]; ok {
+			return nil, errors.Errorf("Duplicated label: %s", label)
+		}
+		index[label] = i
+	}
+	return &Classifier{
+		Labels:  labels,
+		vectors: vectors,
+		dim:     vectors.Dimension(),
+		weight:  weight,
+		bias:    bias,
+		index:   index,
+	}, nil
+}
+
+// Labels returns the sorted labels of examples.
+func Labels(examples []Example) []string {
+	seen := make(map[string]bool)
+	var labels []string
+	for _, e := range examples {
+		for _, label := range e.Labels {
+			if !seen[label] {
+				seen[label] = true
+				labels = append(labels, label)
+			}
+		}
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// Summary returns the master seed of the last Train and the seed of its worker derived from it.
+func (c *Classifier) Summary() model.Summary {
+	return c.summary
+}
+
+// Train updates the classifier by SGD on examples, whose order is shuffled every iteration.
+// The learning rates decay linearly to 0 through training.
+// Examples without known labels or known words are skipped.
+func (c *Classifier) Train(examples []Example, opts Options) error {
+	if opts.Iteration <= 0 {
+		return errors.Errorf("Invalid iteration: %d must be positive", opts.Iteration)
+	}
+	if opts.Initlr <= 0 {
+		return errors.Errorf("Invalid initlr: %v must be positive", opts.Initlr)
+	}
+	if opts.FinetuneLr < 0 {
+		return errors.Errorf("Invalid finetune lr: %v must not be negative", opts.FinetuneLr)
+	}
+
+	seed := opts.Seed
+	if seed == 0 {
+		seed = model.NewSeed()
+	}
+	// training runs on a single worker.
+	c.summary = model.NewSummary(seed, 1)
+	rnd := model.NewRandom(c.summary.WorkerSeeds[0])
+
+	order := make([]int, len(examples))
+	for i := range order {
+		order[i] = i
+	}
+	total := float64(opts.Iteration * len(examples))
+	var step int
+	for i := 0; i < opts.Iteration; i++ {
+		for j := len(order) - 1; j > 0; j-- {
+			k := rnd.Next(j + 1)
+			order[j], order[k] = order[k], order[j]
+		}
+		for _, idx := range order {
+			decay := 1 - float64(step)/total
+			c.update(examples[idx], opts.Initlr*decay, opts.FinetuneLr*decay)
+			step++
+		}
+	}
+	return nil
+}
+
+func (c *Classifier) update(e Example, lr, finetuneLr float64) {
+	target := make([]float64, len(c.Labels))
+	var known int
+	for _, label := range e.Labels {
+		if idx, ok := c.index[label]; ok {
+			target[idx]++
+			known++
+		}
+	}
+	if known == 0 {
+		return
+	}
+	hidden, words := c.hidden(e.Words)
+	if len(words) == 0 {
+		return
+	}
+
+	probs := c.probabilities(hidden)
+	var grad []float64
+	if finetuneLr > 0 {
+		grad = make([]float64, c.dim)
+	}
+	for k := range c.Labels {
+		g := probs[k] - target[k]/float64(known)
+		for d := 0; d < c.dim; d++ {
+			if grad != nil {
+				grad[d] += g * c.weight[k][d]
+			}
+			c.weight[k][d] -= lr * g * hidden[d]
+		}
+		c.bias[k] -= lr * g
+	}
+	if grad == nil {
+		return
+	}
+	scale := finetuneLr / float64(len(words))
+	for _, vec := range words {
+		for d := 0; d < c.dim; d++ {
+			vec[d] -= scale * grad[d]
+		}
+	}
+}
+
+// hidden returns the average of vectors of known words, along with the vectors.
+func (c *Classifier) hidden(words []string) ([]float64, [][]float64) {
+	hidden := make([]float64, c.dim)
+	var vecs [][]float64
+	for _, word := range words {
+		vec, ok := c.vectors.Vector[word]
+		if !ok {
+			continue
+		}
+		vecs = append(vecs, vec)
+		for d := 0; d < c.dim; d++ {
+			hidden[d] += vec[d]
+		}
+	}
+	for d := 0; d < c.dim && len(vecs) > 0; d++ {
+		hidden[d] /= float64(len(vecs))
+	}
+	return hidden, vecs
+}
+
+func (c *Classifier) probabilities(hidden []float64) []float64 {
+	probs := make([]float64, len(c.Labels))
+	max := math.Inf(-1)
+	for k := range c.Labels {
+		probs[k] = c.bias[k]
+		for d := 0; d < c.dim; d++ {
+			probs[k] += c.weight[k][d] * hidden[d]
+		}
+		max = math.Max(max, probs[k])
+	}
+	var sum float64
+	for k := range probs {
+		probs[k] = math.Exp(probs[k] - max)
+		sum += probs[k]
+	}
+	for k := range probs {
+		probs[k] /= sum
+	}
+	return probs
+}
+
+// Predict returns the top k labels of words in descending order of probability,
+// k<=0 means all labels. Unknown words are ignored.
+func (c *Classifier) Predict(words []string, k int) []Prediction {
+	hidden, _ := c.hidden(words)
+	probs := c.probabilities(hidden)
+	predictions := make([]Prediction, len(c.Labels))
+	for i, label := range c.Labels {
+		predictions[i] = Prediction{
+			Label:       label,
+			Probability: probs[i],
+		}
+	}
+	sort.SliceStable(predictions, func(i, j int) bool {
+		return predictions[i].Probability > predictions[j].Probability
+	})
+	if k > 0 && k < len(predictions) {
+		predictions = predictions[:k]
+	}
+	return predictions
+}
+
+type jsonClassifier struct {
+	Labels  []string    `json:"labels"`
+	Bias    []float64   `json:"bias"`
+	Weight  [][]float64 `json:"weight"`
+	Words   []string    `json:"words"`
+	Vectors [][]float64 `json:"vectors"`
+}
+
+// Save writes the classifier along with its word vectors as JSON.
+func (c *Classifier) Save(w io.Writer) error {
+	js := jsonClassifier{
+		Labels:  c.Labels,
+		Bias:    c.bias,
+		Weight:  c.weight,
+		Words:   c.vectors.Words,
+		Vectors: make([][]float64, len(c.vectors.Words)),
+	}
+	for i, word := range c.vectors.Words {
+		js.Vectors[i] = c.vectors.Vector[word]
+	}
+	return json.NewEncoder(w).Encode(js)
+}
+
+// Load reads the classifier saved by Save.
+func Load(r io.Reader) (*Classifier, error) {
+	var js jsonClassifier
+	if err := json.NewDecoder(r).Decode(&js); err != nil {
+		return nil, errors.Wrap(err, "Unable to decode classifier")
+	}
+	if len(js.Words) != len(js.Vectors) {
+		return nil, errors.Errorf("Size of words %d and vectors %d are different", len(js.Words), len(js.Vectors))
+	}
+	if len(js.Labels) == 0 || len(js.Labels) != len(js.Bias) || len(js.Labels) != len(js.Weight) {
+		return nil, errors.Errorf("Size of labels %d, bias %d and weight %d are different",
+			len(js.Labels), len(js.Bias), len(js.Weight))
+	}
+	vectors := &vectorio.Vectors{
+		Words:  js.Words,
+		Vector: make(map[string][]float64, len(js.Words)),
+	}
+	if len(js.Vectors) == 0 {
+		return nil, errors.New("No word vectors in classifier")
+	}
+	dim := len(js.Vectors[0])
+	for i, word := range js.Words {
+		if len(js.Vectors[i]) != dim {
+			return nil, errors.Errorf("Dimension of %s is %d, but expected %d", word, len(js.Vectors[i]), dim)
+		}
+		vectors.Vector[word] = js.Vectors[i]
+	}
+	for i, label := range js.Labels {
+		if len(js.Weight[i]) != dim {
+			return nil, errors.Errorf("Dimension of weight for %s is %d, but expected %d", label, len(js.Weight[i]), dim)
+		}
+	}
+	return newClassifier(vectors, js.Labels, js.Weight, js.Bias)
+}
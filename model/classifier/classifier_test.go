@@ -0,0 +1,159 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package classifier
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ynqa/wego/model"
+	"github.com/ynqa/wego/vectorio"
+)
+
+const labeled = `__label__pos good great
+__label__neg bad awful
+__label__pos great fine
+__label__neg awful poor
+__label__pos good fine
+__label__neg poor bad
+`
+
+func testVectors(t *testing.T) *vectorio.Vectors {
+	vectors, err := vectorio.ReadText(strings.NewReader(
+		"good 1 0.1\ngreat 0.9 0\nfine 0.8 0.2\nbad 0 1\nawful 0.1 0.9\npoor 0.2 0.8\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vectors
+}
+
+func TestParseExample(t *testing.T) {
+	e := ParseExample("__label__a The cat __label__b", "__label__", true)
+	expected := Example{
+		Labels: []string{"__label__a", "__label__b"},
+		Words:  []string{"the", "cat"},
+	}
+	if !reflect.DeepEqual(e, expected) {
+		t.Errorf("Expected %+v: %+v", expected, e)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	examples, err := ReadExamples(strings.NewReader(labeled+"\n"), "__label__", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(examples) != 6 {
+		t.Fatalf("Expected 6 examples without blank lines: %d", len(examples))
+	}
+	train, test := Split(examples, 0.34, model.NewRandom(1))
+	if len(train) != 4 || len(test) != 2 {
+		t.Errorf("Expected 4 examples to train and 2 to test: %d, %d", len(train), len(test))
+	}
+}
+
+func TestTrain(t *testing.T) {
+	examples, err := ReadExamples(strings.NewReader(labeled), "__label__", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := New(testVectors(t), Labels(examples))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev := c.Evaluate(examples); ev.Examples != 6 {
+		t.Errorf("Expected 6 examples to evaluate: %+v", ev)
+	}
+	if err := c.Train(examples, Options{Iteration: 50, Initlr: 0.5}); err != nil {
+		t.Fatal(err)
+	}
+
+	if ev := c.Evaluate(examples); ev.Precision != 1 || ev.Recall != 1 {
+		t.Errorf("Expected all examples classified correctly: %+v", ev)
+	}
+	predictions := c.Predict([]string{"good", "unknown"}, 0)
+	if len(predictions) != 2 || predictions[0].Label != "__label__pos" {
+		t.Fatalf("Expected __label__pos first in all labels: %+v", predictions)
+	}
+	if sum := predictions[0].Probability + predictions[1].Probability; sum < 1-1e-9 || sum > 1+1e-9 {
+		t.Errorf("Expected probabilities sum to 1: %v", sum)
+	}
+}
+
+func TestTrainFinetune(t *testing.T) {
+	examples, err := ReadExamples(strings.NewReader(labeled), "__label__", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, finetuneLr := range []float64{0, 0.1} {
+		vectors := testVectors(t)
+		good := append([]float64(nil), vectors.Vector["good"]...)
+		c, err := New(vectors, Labels(examples))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Train(examples, Options{Iteration: 5, Initlr: 0.5, FinetuneLr: finetuneLr}); err != nil {
+			t.Fatal(err)
+		}
+		if moved := !reflect.DeepEqual(good, vectors.Vector["good"]); moved != (finetuneLr > 0) {
+			t.Errorf("Expected vector of good moved %v with finetune lr %v: %v", finetuneLr > 0, finetuneLr, moved)
+		}
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	examples, err := ReadExamples(strings.NewReader(labeled), "__label__", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := New(testVectors(t), Labels(examples))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Train(examples, Options{Iteration: 5, Initlr: 0.5, FinetuneLr: 0.1}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := c.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	words := []string{"fine", "poor"}
+	if expected, actual := c.Predict(words, 0), loaded.Predict(words, 0); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected the same predictions after loading %+v: %+v", expected, actual)
+	}
+}
+
+func TestInvalidTrain(t *testing.T) {
+	if _, err := New(testVectors(t), nil); err == nil {
+		t.Error("Expected an error without labels")
+	}
+	c, err := New(testVectors(t), []string{"__label__a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Train(nil, Options{Iteration: 0, Initlr: 0.1}); err == nil {
+		t.Error("Expected an error for iteration 0")
+	}
+	if _, err := Load(strings.NewReader(`{"labels":["a"],"bias":[0],"weight":[[0]],"words":["x"],"vectors":[[1,2]]}`)); err == nil {
+		t.Error("Expected an error for weight of different dimension")
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package classifier
+
+// Evaluation is the precision and recall at 1 of Classifier on examples, like the test of supervised fastText.
+// Precision at 1 is the accuracy of the top label for single-label examples.
+type Evaluation struct {
+	Examples  int
+	Precision float64
+	Recall    float64
+}
+
+// Evaluate predicts the top label of each example with labels, and counts whether it is one of the labels.
+func (c *Classifier) Evaluate(examples []Example) Evaluation {
+	var ev Evaluation
+	var hits, labels int
+	for _, e := range examples {
+		if len(e.Labels) == 0 {
+			continue
+		}
+		ev.Examples++
+		labels += len(e.Labels)
+		top := c.Predict(e.Words, 1)[0].Label
+		for _, label := range e.Labels {
+			if label == top {
+				hits++
+				break
+			}
+		}
+	}
+	if ev.Examples > 0 {
+		ev.Precision = float64(hits) / float64(ev.Examples)
+		ev.Recall = float64(hits) / float64(labels)
+	}
+	return ev
+}
@@ -0,0 +1,79 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package classifier
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/model"
+	"github.com/ynqa/wego/vectorio"
+)
+
+// Example is a line of labeled corpus, e.g. "__label__sports the match ended".
+type Example struct {
+	Labels []string
+	Words  []string
+}
+
+// ParseExample splits the line into labels, i.e. tokens beginning with labelPrefix, and words.
+func ParseExample(line, labelPrefix string, toLower bool) Example {
+	var e Example
+	for _, token := range strings.Fields(line) {
+		if labelPrefix != "" && strings.HasPrefix(token, labelPrefix) {
+			e.Labels = append(e.Labels, token)
+			continue
+		}
+		if toLower {
+			token = strings.ToLower(token)
+		}
+		e.Words = append(e.Words, token)
+	}
+	return e
+}
+
+// ReadExamples reads labeled corpus, which has an example per line. Blank lines are skipped.
+func ReadExamples(r io.Reader, labelPrefix string, toLower bool) ([]Example, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024*1024)
+	var examples []Example
+	for scanner.Scan() {
+		e := ParseExample(vectorio.TrimLine(scanner.Text()), labelPrefix, toLower)
+		if len(e.Labels) == 0 && len(e.Words) == 0 {
+			continue
+		}
+		examples = append(examples, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "Unable to complete scanning")
+	}
+	return examples, nil
+}
+
+// Split shuffles examples with rnd and splits them into ones to train and a held-out fraction of them to evaluate.
+// The split is reproducible by rnd of a fixed seed.
+func Split(examples []Example, holdout float64, rnd *model.Random) ([]Example, []Example) {
+	shuffled := make([]Example, len(examples))
+	copy(shuffled, examples)
+	for i := len(shuffled) - 1; i > 0; i-- {
+		j := rnd.Next(i + 1)
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	}
+	size := int(float64(len(shuffled)) * holdout)
+	return shuffled[size:], shuffled[:size]
+}
@@ -14,6 +14,13 @@
 
 package model
 
+import (
+	"time"
+
+	"github.com/ynqa/wego/corpus"
+	"github.com/ynqa/wego/vectorio"
+)
+
 // Config stores the configs for each model.
 type Config struct {
 	Dimension  int
@@ -24,9 +31,92 @@ type Config struct {
 	Initlr     float64
 	ToLower    bool
 	Verbose    bool
+
+	// fraction of token occurrences for the vocabulary to cover, which overrides MinCount if it is positive.
+	MinCoverage float64
+	// decides whether the word of freq is kept in vocabulary, which overrides MinCount and MinCoverage if it is set.
+	MinCountFunc func(word string, freq int) bool
+	// fixed ids of words for vocabulary to honor instead of ranking words by frequency.
+	FixedWordIDs map[string]int
+
+	// master seed of the run to derive the seeds of workers from, which is generated by NewSeed if it is 0.
+	Seed uint64
+
+	// whether tokens of corpus are split on hyphens and on apostrophes.
+	SplitHyphens     bool
+	SplitApostrophes bool
+
+	// special tokens with the reserved ids 0 to len-1 in vocabulary regardless of frequency.
+	SpecialTokens []string
+
+	// whether each line of corpus begins with the weight of the sentence to scale its learning rate.
+	SentenceWeights bool
+	// whether words are counted by the weights of their sentences for vocabulary, which requires SentenceWeights.
+	WeightedCounts bool
+
+	// format to save words' vector.
+	OutputFormat string
+
+	// way to treat invalid UTF-8 sequences in corpus. One of: none|replace|skip
+	SanitizeUTF8 string
+
+	// limits of tokens to train on per iteration and to build vocabulary, no limit if it is not positive.
+	MaxTokens      int64
+	MaxVocabTokens int64
+
+	// scripts to keep tokens predominantly in them, and fraction of runes in them for tokens to keep.
+	Scripts         []string
+	ScriptThreshold float64
+
+	// times to retry reading corpus on transient errors, and the first delay which doubles on each retry.
+	ReadRetries    int
+	ReadRetryDelay time.Duration
+
+	// style and precision to format values of words' vector in text format.
+	SaveFormat    string
+	SavePrecision int
+
+	// creates the storage of size words' vector of dimension for Word2vec to initialize and train them on,
+	// e.g. by NewMmapStorage, or nil to allocate them in memory.
+	NewStorage func(size, dimension int) (Storage, error)
 }
 
-// NewConfig creates *Config
+// ParseConfig returns the settings to parse corpus, which are shared by vocabulary, training and lookup of words.
+func (c *Config) ParseConfig() corpus.ParseConfig {
+	return corpus.ParseConfig{
+		ToLower:   c.ToLower,
+		Sanitize:  c.SanitizeUTF8,
+		MaxTokens: c.MaxVocabTokens,
+
+		MinCoverage:  c.MinCoverage,
+		MinCountFunc: c.MinCountFunc,
+		WordIDs:      c.FixedWordIDs,
+
+		Scripts:         c.Scripts,
+		ScriptThreshold: c.ScriptThreshold,
+
+		ReadRetries:    c.ReadRetries,
+		ReadRetryDelay: c.ReadRetryDelay,
+
+		SplitHyphens:     c.SplitHyphens,
+		SplitApostrophes: c.SplitApostrophes,
+
+		SentenceWeights: c.SentenceWeights,
+		WeightedCounts:  c.WeightedCounts,
+
+		SpecialTokens: c.SpecialTokens,
+	}
+}
+
+// FloatFormat returns the format of values to save words' vector in text format.
+func (c *Config) FloatFormat() vectorio.FloatFormat {
+	return vectorio.FloatFormat{
+		Style:     c.SaveFormat,
+		Precision: c.SavePrecision,
+	}
+}
+
+// NewConfig creates *Config, whose other fields are set to their defaults and can be set directly.
 func NewConfig(dimension, iteration, minCount, threadSize, window int,
 	initlr float64, toLower, verbose bool) *Config {
 
@@ -39,5 +129,8 @@ func NewConfig(dimension, iteration, minCount, threadSize, window int,
 		Initlr:     initlr,
 		ToLower:    toLower,
 		Verbose:    verbose,
+
+		SaveFormat:    vectorio.DefaultFloatFormat.Style,
+		SavePrecision: vectorio.DefaultFloatFormat.Precision,
 	}
 }
@@ -0,0 +1,174 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glove
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// CheckpointFile is the name of the checkpoint file in the directory set by Checkpoint.
+const CheckpointFile = "glove.checkpoint"
+
+// checkpoint is the state of training after an iteration. Co-occurrence pairs aren't included,
+// but they are referred to by the path and the checksum of the file which they are counted from.
+type checkpoint struct {
+	Iteration int
+	Dimension int
+	Size      int
+
+	// word and context vectors with their biases, laid out as the vector of Glove.
+	Vector []float64
+
+	// state of the solver: accumulated squared gradients for AdaGrad, and current learning rate for Sgd.
+	Solver       string
+	Gradsq       []float64
+	LearningRate float64
+
+	Source   string
+	Checksum string
+}
+
+// Checkpoint sets to save the state of training into dir every `every` iterations, which Resume restores.
+// source is the file which co-occurrences are counted from, i.e. co-occurrence file or corpus,
+// and only its path and checksum are saved instead of the co-occurrences.
+func (g *Glove) Checkpoint(dir string, every int, source string) error {
+	if every <= 0 {
+		return errors.Errorf("Interval of checkpoint must be positive: %d", every)
+	}
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return errors.Wrapf(err, "Unable to create checkpoint directory %s", dir)
+	}
+	checksum, err := fileChecksum(source)
+	if err != nil {
+		return err
+	}
+	g.checkpointDir, g.checkpointEvery = dir, every
+	g.source, g.checksum = source, checksum
+	return nil
+}
+
+// Resume restores the state of training from the checkpoint file on path, and Train continues from the next
+// iteration of it. It fails if the checksum of source differs from the one of the checkpoint, i.e. the
+// co-occurrences have changed since then.
+func (g *Glove) Resume(path, source string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to open checkpoint %s", path)
+	}
+	defer f.Close()
+	var c checkpoint
+	if err := gob.NewDecoder(f).Decode(&c); err != nil {
+		return errors.Wrapf(err, "Unable to read checkpoint %s", path)
+	}
+
+	checksum, err := fileChecksum(source)
+	if err != nil {
+		return err
+	}
+	if checksum != c.Checksum {
+		return errors.Errorf("Co-occurrences have changed since checkpoint %s: checksum of %s is %s, but %s of %s is expected",
+			path, source, checksum, c.Checksum, c.Source)
+	}
+	if c.Dimension != g.Config.Dimension || c.Size != g.GloveCorpus.Size() || len(c.Vector) != len(g.vector) {
+		return errors.Errorf("Checkpoint %s has dimension=%d and %d words, but the model has dimension=%d and %d words",
+			path, c.Dimension, c.Size, g.Config.Dimension, g.GloveCorpus.Size())
+	}
+	if name := solverName(g.solver); c.Solver != name {
+		return errors.Errorf("Checkpoint %s is saved by %s solver, but the model uses %s", path, c.Solver, name)
+	}
+
+	copy(g.vector, c.Vector)
+	switch s := g.solver.(type) {
+	case *AdaGrad:
+		copy(s.gradsq, c.Gradsq)
+	case *Sgd:
+		s.currentlr = c.LearningRate
+	}
+	g.resumed = c.Iteration
+	return nil
+}
+
+// saveCheckpoint writes the state after the iteration into a temporary file, and renames it to CheckpointFile
+// so that the previous checkpoint is kept if it fails on the way.
+func (g *Glove) saveCheckpoint(iteration int) error {
+	c := checkpoint{
+		Iteration: iteration,
+		Dimension: g.Config.Dimension,
+		Size:      g.GloveCorpus.Size(),
+		Vector:    g.vector,
+		Solver:    solverName(g.solver),
+		Source:    g.source,
+		Checksum:  g.checksum,
+	}
+	switch s := g.solver.(type) {
+	case *AdaGrad:
+		c.Gradsq = s.gradsq
+	case *Sgd:
+		c.LearningRate = s.currentlr
+	}
+
+	tmp, err := ioutil.TempFile(g.checkpointDir, CheckpointFile+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "Unable to create checkpoint")
+	}
+	if err := gob.NewEncoder(tmp).Encode(&c); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "Unable to write checkpoint")
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "Unable to write checkpoint")
+	}
+	path := filepath.Join(g.checkpointDir, CheckpointFile)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return errors.Wrapf(err, "Unable to save checkpoint %s", path)
+	}
+	return nil
+}
+
+func solverName(solver Solver) string {
+	switch solver.(type) {
+	case *AdaGrad:
+		return "adagrad"
+	case *Sgd:
+		return "sgd"
+	default:
+		return "unknown"
+	}
+}
+
+// fileChecksum returns hex-encoded SHA-256 of the file on path.
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "Unable to compute checksum of %s", path)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", errors.Wrapf(err, "Unable to compute checksum of %s", path)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,106 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glove
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ynqa/wego/model"
+)
+
+func newCheckpointTestGlove(t *testing.T, source string, solver Solver) *Glove {
+	f, err := os.Open(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cnf := model.NewConfig(5, 3, 0, 1, 2, 0.025, false, false)
+	glove, err := NewGlove(f, cnf, solver, 100, 0.75, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return glove
+}
+
+func TestCheckpointResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	source := filepath.Join(dir, "input.txt")
+	if err := ioutil.WriteFile(source, []byte("a b b c c c c\nc b a d d"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	glove := newCheckpointTestGlove(t, source, NewAdaGrad(5, 0.025))
+	if err := glove.Checkpoint(dir, 2, source); err != nil {
+		t.Fatal(err)
+	}
+	if err := glove.Train(); err != nil {
+		t.Fatal(err)
+	}
+
+	// resume from the checkpoint of the 2nd iteration, and train only the 3rd on the same pairs.
+	resumed := newCheckpointTestGlove(t, source, NewAdaGrad(5, 0.025))
+	resumed.pairs = glove.pairs
+	if err := resumed.Resume(filepath.Join(dir, CheckpointFile), source); err != nil {
+		t.Fatal(err)
+	}
+	if resumed.resumed != 2 {
+		t.Errorf("Expected to resume from 2nd iteration: %d", resumed.resumed)
+	}
+	if err := resumed.Train(); err != nil {
+		t.Fatal(err)
+	}
+	for i := range glove.vector {
+		if glove.vector[i] != resumed.vector[i] {
+			t.Fatalf("Expected resumed vector to equal uninterrupted one at %d: %v, %v",
+				i, resumed.vector[i], glove.vector[i])
+		}
+	}
+}
+
+func TestResumeChangedCooccurrence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	source := filepath.Join(dir, "input.txt")
+	if err := ioutil.WriteFile(source, []byte("a b b c c c c"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	glove := newCheckpointTestGlove(t, source, NewAdaGrad(5, 0.025))
+	if err := glove.Checkpoint(dir, 1, source); err != nil {
+		t.Fatal(err)
+	}
+	if err := glove.Train(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(source, []byte("a b b c c c c c"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	resumed := newCheckpointTestGlove(t, source, NewAdaGrad(5, 0.025))
+	err = resumed.Resume(filepath.Join(dir, CheckpointFile), source)
+	if err == nil || !strings.Contains(err.Error(), "changed") {
+		t.Errorf("Expected error of changed co-occurrences: %v", err)
+	}
+}
@@ -16,15 +16,14 @@ package glove
 
 import (
 	"bufio"
-	"bytes"
 	"fmt"
 	"io"
 	"math"
-	"math/rand"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"unsafe"
 
 	"github.com/pkg/errors"
 	"gopkg.in/cheggaaa/pb.v1"
@@ -32,6 +31,7 @@ import (
 	"github.com/ynqa/wego/corpus"
 	"github.com/ynqa/wego/corpus/co"
 	"github.com/ynqa/wego/model"
+	"github.com/ynqa/wego/vectorio"
 )
 
 // Glove stores the configs for Glove models.
@@ -51,20 +51,86 @@ type Glove struct {
 	// words' vector.
 	vector []float64
 
-	// manage data range per thread.
+	// tracker of words' vector per iteration.
+	tracker *model.Tracker
+
+	// number of threads of the last Train, which uses all CPUs if Config.ThreadSize is not positive,
+	// and data range per thread.
+	threadSize     int
 	indexPerThread []int
 
 	// progress bar.
 	progress *pb.ProgressBar
+
+	// callback of progress, and pairs processed and their cost in the current iteration for it.
+	onProgress model.ProgressFunc
+	progressMu sync.Mutex
+	processed  int
+	cost       float64
+
+	// directory and interval of iterations to save checkpoint, and the file of co-occurrences and its checksum.
+	checkpointDir   string
+	checkpointEvery int
+	source          string
+	checksum        string
+
+	// iteration restored by Resume, which Train continues from.
+	resumed int
+
+	// master seed of the run, which initializes vectors and the order of pairs.
+	seed uint64
+
+	// hooks called before and after each iteration.
+	model.Hooks
+
+	// guard against training concurrently.
+	guard model.TrainGuard
 }
 
+// SubsampleSeedKey derives the seed to subsample corpus, e.g. by corpus.NewGloveCorpus, from the master seed,
+// apart from the one to initialize vectors and the order of pairs.
+const SubsampleSeedKey = 1
+
 // NewGlove creates *Glove.
 func NewGlove(f io.ReadCloser, config *model.Config, solver Solver,
-	xmax int, alpha float64) (*Glove, error) {
-	cps, err := corpus.NewGloveCorpus(f, config.ToLower, config.MinCount, config.Window)
+	xmax int, alpha, subsampleThreshold float64) (*Glove, error) {
+	seed := masterSeed(config)
+	cps, err := corpus.NewGloveCorpus(f, config.ParseConfig(), config.MinCount, config.Window, config.MaxTokens,
+		subsampleThreshold, model.NewRandom(model.DeriveSeed(seed, SubsampleSeedKey)))
 	if err != nil {
 		return nil, errors.Wrap(err, "Unable to generate *Glove")
 	}
+	if config.Verbose && cps.InvalidUTF8Lines() > 0 {
+		fmt.Printf("Sanitized invalid UTF-8 in %d lines\n", cps.InvalidUTF8Lines())
+	}
+	if discarded, counted := cps.Subsampled(); config.Verbose && subsampleThreshold > 0 && counted > 0 {
+		fmt.Printf("Subsampled %d of %d tokens (%.2f%%)\n", discarded, counted,
+			100*float64(discarded)/float64(counted))
+	}
+	return newGlove(cps, config, seed, solver, xmax, alpha)
+}
+
+// NewGloveFromCooccurrence creates *Glove from co-occurrence counted in advance instead of corpus.
+// See corpus.NewGloveCorpusFromCooccurrence for vocab and format.
+func NewGloveFromCooccurrence(vocab, cooccurrence io.Reader, format string, config *model.Config,
+	solver Solver, xmax int, alpha float64) (*Glove, error) {
+	cps, err := corpus.NewGloveCorpusFromCooccurrence(vocab, cooccurrence, format, config.ParseConfig())
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to generate *Glove")
+	}
+	return newGlove(cps, config, masterSeed(config), solver, xmax, alpha)
+}
+
+// masterSeed returns Seed of config, or generates it if it is 0.
+func masterSeed(config *model.Config) uint64 {
+	if config.Seed == 0 {
+		return model.NewSeed()
+	}
+	return config.Seed
+}
+
+func newGlove(cps *corpus.GloveCorpus, config *model.Config, seed uint64, solver Solver,
+	xmax int, alpha float64) (*Glove, error) {
 	glove := &Glove{
 		Config:      config,
 		GloveCorpus: cps,
@@ -73,24 +139,36 @@ func NewGlove(f io.ReadCloser, config *model.Config, solver Solver,
 
 		xmax:  xmax,
 		alpha: alpha,
+
+		seed: seed,
+	}
+	if err := glove.initialize(); err != nil {
+		return nil, errors.Wrap(err, "Unable to generate *Glove")
 	}
-	glove.initialize()
 	return glove, nil
 }
 
-func (g *Glove) initialize() {
+func (g *Glove) initialize() error {
+	rnd := model.NewRandom(g.seed)
+
 	// Build pairs based on co-occurrence.
-	g.buildPairs()
+	if err := g.buildPairs(rnd); err != nil {
+		return err
+	}
 
-	// Initialize word vector.
-	vectorSize := g.GloveCorpus.Size() * (g.Config.Dimension + 1) * 2
+	// Initialize word vector, whose rows are doubled for context vectors.
+	vectorSize, err := model.ElementSize(g.GloveCorpus.Size(), 2, g.Config.Dimension+1)
+	if err != nil {
+		return err
+	}
 	g.vector = make([]float64, vectorSize)
 	for i := 0; i < vectorSize; i++ {
-		g.vector[i] = rand.Float64() / float64(g.Config.Dimension)
+		g.vector[i] = rnd.Float64() / float64(g.Config.Dimension)
 	}
 
 	// Initialize solver.
 	g.solver.initialize(vectorSize)
+	return nil
 }
 
 type pair struct {
@@ -98,11 +176,19 @@ type pair struct {
 	f, coefficient float64
 }
 
-func (g *Glove) buildPairs() {
+func (g *Glove) buildPairs(rnd *model.Random) error {
 	coo := g.Cooccurrence()
 	pairSize := len(coo)
+	// pairs and their shuffled order are allocated in bytes, which must not overflow int either.
+	if _, err := model.ElementSize(pairSize, int(unsafe.Sizeof(pair{})+unsafe.Sizeof(0))); err != nil {
+		return errors.Wrap(err, "Unable to build pairs of co-occurrence")
+	}
 	g.pairs = make([]pair, pairSize)
-	shuffle := rand.Perm(pairSize)
+	shuffle := make([]int, pairSize)
+	for i := range shuffle {
+		j := rnd.Next(i + 1)
+		shuffle[i], shuffle[j] = shuffle[j], i
+	}
 
 	if g.Verbose {
 		fmt.Println("Build co-occurrence map from corpus:")
@@ -132,10 +218,90 @@ func (g *Glove) buildPairs() {
 	if g.Verbose {
 		g.progress.Finish()
 	}
+	return nil
+}
+
+// Norm returns L2 norm of the vector for word, which is saved, and whether the word is in vocabulary or not.
+func (g *Glove) Norm(word string) (float64, bool) {
+	id, ok := g.Lookup(word)
+	if !ok {
+		return 0, false
+	}
+	return vectorio.Norm(g.wordVector(id)), true
+}
+
+// TrackWords appends the vectors of words into JSONL file on path after each iteration of Train,
+// and returns the words not in vocabulary, which are not tracked.
+func (g *Glove) TrackWords(words []string, path string) ([]string, error) {
+	ids, missing := g.WordIDs(words)
+	tracked := make([]string, len(ids))
+	for i, id := range ids {
+		tracked[i], _ = g.GloveCorpus.Word(id)
+	}
+	tracker, err := model.NewTracker(ids, tracked, path)
+	if err != nil {
+		return nil, err
+	}
+	g.tracker = tracker
+	return missing, nil
+}
+
+// progressBatch is the number of pairs each thread processes between updates of progress.
+const progressBatch = 1000
+
+// OnProgress sets fn to be called with progress of training, i.e. co-occurrence pairs processed in each iteration
+// and their average cost. Learning rate is reported only for Sgd solver.
+func (g *Glove) OnProgress(fn model.ProgressFunc) {
+	g.onProgress = fn
+}
+
+// reportProgress starts to report progress of the iteration with total pairs if OnProgress is set,
+// and returns the function to stop it.
+func (g *Glove) reportProgress(iteration, total int) func() {
+	if g.onProgress == nil {
+		return func() {}
+	}
+	g.progressMu.Lock()
+	g.processed, g.cost = 0, 0
+	g.progressMu.Unlock()
+	return model.ReportProgress(g.onProgress, func(final bool) model.Progress {
+		g.progressMu.Lock()
+		defer g.progressMu.Unlock()
+		p := model.Progress{
+			Iteration: iteration,
+			Done:      g.processed,
+			Total:     total,
+			Valid:     model.ProgressCost,
+		}
+		if g.processed > 0 {
+			p.Cost = g.cost / float64(g.processed)
+		}
+		if sgd, ok := g.solver.(*Sgd); ok {
+			p.LearningRate = sgd.currentlr
+			p.Valid |= model.ProgressLearningRate
+		}
+		if final {
+			p.Norms = model.EstimateNormPercentiles(g.GloveCorpus.Size(), g.wordVector)
+			p.Valid |= model.ProgressNorms
+		}
+		return p
+	})
+}
+
+// Summary returns the master seed of the run and the number of threads of the last Train.
+// Unlike Word2vec, it has no seeds of workers, since the workers of GloVe draw no randomness,
+// and the seed determines initial vectors and the order of pairs.
+func (g *Glove) Summary() model.Summary {
+	return model.Summary{Seed: g.seed, ThreadSize: g.threadSize}
 }
 
 // Train trains words' vector on corpus.
 func (g *Glove) Train() error {
+	if err := g.guard.Begin(); err != nil {
+		return err
+	}
+	defer g.guard.End()
+
 	pairSize := len(g.pairs)
 	if pairSize <= 0 {
 		return errors.Errorf("No pairs for training")
@@ -145,33 +311,62 @@ func (g *Glove) Train() error {
 		fmt.Printf("Size of Pair: %v\n", len(g.pairs))
 	}
 
-	g.indexPerThread = model.IndexPerThread(g.Config.ThreadSize, pairSize)
+	// the number of threads isn't chosen by words/sec unlike word2vec, but all CPUs are used if it is automatic.
+	g.threadSize = model.MaxThreadSize(g.Config.ThreadSize)
+	g.indexPerThread = model.IndexPerThread(g.threadSize, pairSize)
 
-	semaphore := make(chan struct{}, g.Config.ThreadSize)
+	semaphore := make(chan struct{}, g.threadSize)
 	waitGroup := &sync.WaitGroup{}
 
-	for i := 1; i <= g.Iteration; i++ {
+	for i := g.resumed + 1; i <= g.Iteration; i++ {
+		g.RunBefore(i)
 		if g.Verbose {
 			fmt.Printf("%d-th:\n", i)
 			g.progress = pb.New(pairSize).SetWidth(80)
 			g.progress.Start()
 		}
 
-		for j := 0; j < g.Config.ThreadSize; j++ {
+		stopProgress := g.reportProgress(i, pairSize)
+		for j := 0; j < g.threadSize; j++ {
 			waitGroup.Add(1)
 			go g.trainPerThread(g.indexPerThread[j], g.indexPerThread[j+1],
 				semaphore, waitGroup)
 		}
-		g.solver.postOneIter()
-
 		waitGroup.Wait()
+		g.solver.postOneIter()
+		stopProgress()
 		if g.Verbose {
 			g.progress.Finish()
 		}
+		if g.tracker != nil {
+			if err := g.tracker.Track(i, g.wordVector); err != nil {
+				return err
+			}
+		}
+		if g.checkpointDir != "" && i%g.checkpointEvery == 0 {
+			if err := g.saveCheckpoint(i); err != nil {
+				return err
+			}
+		}
+		g.RunAfter(i, g)
+	}
+	if g.tracker != nil {
+		return g.tracker.Close()
 	}
 	return nil
 }
 
+// wordVector returns the sum of word and context vector for id, which is saved.
+func (g *Glove) wordVector(id int) []float64 {
+	l1 := id * (g.Config.Dimension + 1)
+	l2 := (id + g.GloveCorpus.Size()) * (g.Config.Dimension + 1)
+	vec := make([]float64, g.Config.Dimension)
+	for j := range vec {
+		vec[j] = g.vector[l1+j] + g.vector[l2+j]
+	}
+	return vec
+}
+
 func (g *Glove) trainPerThread(beginIdx, endIdx int,
 	semaphore chan struct{}, waitGroup *sync.WaitGroup) {
 
@@ -181,6 +376,10 @@ func (g *Glove) trainPerThread(beginIdx, endIdx int,
 	}()
 
 	semaphore <- struct{}{}
+	var (
+		processed int
+		cost      float64
+	)
 	for i := beginIdx; i < endIdx; i++ {
 		if g.Config.Verbose {
 			g.progress.Increment()
@@ -188,7 +387,14 @@ func (g *Glove) trainPerThread(beginIdx, endIdx int,
 		pair := g.pairs[i]
 		l1 := pair.l1 * (g.Config.Dimension + 1)
 		l2 := (pair.l2 + g.Corpus.Size()) * (g.Config.Dimension + 1)
-		g.solver.trainOne(l1, l2, pair.f, pair.coefficient, g.vector)
+		cost += g.solver.trainOne(l1, l2, pair.f, pair.coefficient, g.vector)
+		if processed++; g.onProgress != nil && (processed == progressBatch || i == endIdx-1) {
+			g.progressMu.Lock()
+			g.processed += processed
+			g.cost += cost
+			g.progressMu.Unlock()
+			processed, cost = 0, 0
+		}
 	}
 }
 
@@ -217,17 +423,41 @@ func (g *Glove) Save(outputPath string) error {
 		file.Close()
 	}()
 
-	var buf bytes.Buffer
-	for i := 0; i < g.GloveCorpus.Size(); i++ {
-		word, _ := g.GloveCorpus.Word(i)
-		fmt.Fprintf(&buf, "%v ", word)
-		for j := 0; j < g.Config.Dimension; j++ {
-			l1 := i * (g.Config.Dimension + 1)
-			l2 := (i + g.GloveCorpus.Size()) * (g.Config.Dimension + 1)
-			fmt.Fprintf(&buf, "%v ", g.vector[l1+j]+g.vector[l2+j])
+	return g.writeVector(w, nil)
+}
+
+// SaveSubset writes the word vector only for words in vocabulary, and returns the words not in vocabulary.
+func (g *Glove) SaveSubset(w io.Writer, words []string) ([]string, error) {
+	ids, missing := g.WordIDs(words)
+	return missing, g.writeVector(w, ids)
+}
+
+// SaveMatching writes the word vector only for words in vocabulary matching the regular expression pattern.
+func (g *Glove) SaveMatching(w io.Writer, pattern string) error {
+	ids, err := g.MatchingIDs(pattern)
+	if err != nil {
+		return err
+	}
+	return g.writeVector(w, ids)
+}
+
+// writeVector writes the vector for ids, or for all words in vocabulary if ids is nil.
+func (g *Glove) writeVector(w io.Writer, ids []int) error {
+	if ids == nil {
+		ids = make([]int, g.GloveCorpus.Size())
+		for i := range ids {
+			ids[i] = i
 		}
-		fmt.Fprintln(&buf)
 	}
-	w.WriteString(fmt.Sprintf("%v", buf.String()))
-	return nil
+
+	vectors := &vectorio.Vectors{
+		Words:  make([]string, len(ids)),
+		Vector: make(map[string][]float64, len(ids)),
+	}
+	for k, i := range ids {
+		word, _ := g.GloveCorpus.Word(i)
+		vectors.Words[k] = word
+		vectors.Vector[word] = g.wordVector(i)
+	}
+	return vectorio.WriteFloat(w, g.Config.OutputFormat, g.Config.FloatFormat(), vectors)
 }
@@ -0,0 +1,213 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glove
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ynqa/wego/model"
+)
+
+func newTestGlove(t *testing.T) *Glove {
+	f := ioutil.NopCloser(strings.NewReader("a b b c c c c"))
+	cnf := model.NewConfig(5, 1, 0, 1, 2, 0.025, false, false)
+	glove, err := NewGlove(f, cnf, NewSgd(5, 0.025), 100, 0.75, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return glove
+}
+
+func TestSubsampleSeed(t *testing.T) {
+	// "the" occurs between 1000 distinct words, and is kept with the probability about 0.0467.
+	words := make([]string, 0, 2000)
+	for i := 0; i < 1000; i++ {
+		words = append(words, "the", fmt.Sprintf("w%d", i))
+	}
+	subsampled := func(seed uint64) (int, map[uint64]float64) {
+		f := ioutil.NopCloser(strings.NewReader(strings.Join(words, " ")))
+		cnf := model.NewConfig(5, 1, 0, 1, 2, 0.025, false, false)
+		cnf.Seed = seed
+		glove, err := NewGlove(f, cnf, NewSgd(5, 0.025), 100, 0.75, 1.0e-3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		discarded, _ := glove.Subsampled()
+		return discarded, glove.Cooccurrence()
+	}
+
+	discarded1, cooccurrence1 := subsampled(1)
+	discarded2, cooccurrence2 := subsampled(1)
+	if discarded1 != 950 || discarded2 != discarded1 || !reflect.DeepEqual(cooccurrence1, cooccurrence2) {
+		t.Errorf("Expected 950 of the 1000 the to be discarded by seed=1 every time: %d, %d", discarded1, discarded2)
+	}
+}
+
+func TestSaveSubset(t *testing.T) {
+	glove := newTestGlove(t)
+
+	var buf bytes.Buffer
+	missing, err := glove.SaveSubset(&buf, []string{"b", "z"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(missing) != 1 || missing[0] != "z" {
+		t.Errorf("Expected missing=[z]: %v", missing)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 || strings.Fields(lines[0])[0] != "b" {
+		t.Errorf("Expected only b to be written: %v", lines)
+	}
+}
+
+func TestSaveMatching(t *testing.T) {
+	glove := newTestGlove(t)
+
+	var buf bytes.Buffer
+	if err := glove.SaveMatching(&buf, "^[ab]$"); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Errorf("Expected only a and b to be written: %v", lines)
+	}
+	if err := glove.SaveMatching(&buf, "("); err == nil {
+		t.Error("Expected invalid pattern to fail")
+	}
+}
+
+func TestNorm(t *testing.T) {
+	glove := newTestGlove(t)
+
+	id, _ := glove.Id("c")
+	l1, l2 := id*6, (id+glove.Size())*6
+	var sum float64
+	for j := 0; j < 5; j++ {
+		v := glove.vector[l1+j] + glove.vector[l2+j]
+		sum += v * v
+	}
+	norm, ok := glove.Norm("c")
+	if !ok || math.Abs(norm-math.Sqrt(sum)) > 1e-12 {
+		t.Errorf("Expected norm of c=%v: %v, %v", math.Sqrt(sum), norm, ok)
+	}
+}
+
+func TestOnProgress(t *testing.T) {
+	for _, solver := range []Solver{NewSgd(5, 0.025), NewAdaGrad(5, 0.025)} {
+		f := ioutil.NopCloser(strings.NewReader("a b b c c c c"))
+		cnf := model.NewConfig(5, 3, 0, 2, 2, 0.025, false, false)
+		glove, err := NewGlove(f, cnf, solver, 100, 0.75, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var reports []model.Progress
+		glove.OnProgress(func(p model.Progress) {
+			reports = append(reports, p)
+		})
+		if err := glove.Train(); err != nil {
+			t.Fatal(err)
+		}
+
+		_, sgd := solver.(*Sgd)
+		var last model.Progress
+		for _, p := range reports {
+			if p.Iteration < last.Iteration || p.Iteration == last.Iteration && p.Done < last.Done {
+				t.Fatalf("Expected progress to be monotonic: %+v -> %+v", last, p)
+			}
+			if p.Total != len(glove.pairs) || !p.Has(model.ProgressCost) || p.Has(model.ProgressLearningRate) != sgd {
+				t.Fatalf("Expected progress over %d pairs with cost, and learning rate only for sgd: %+v",
+					len(glove.pairs), p)
+			}
+			if !sgd && p.LearningRate != 0 {
+				t.Errorf("Expected learning rate to be zero for adagrad: %+v", p)
+			}
+			last = p
+		}
+		if last.Iteration != 3 || last.Done != last.Total || last.Cost <= 0 {
+			t.Errorf("Expected the last progress is the end of the last iteration with cost: %+v", last)
+		}
+	}
+}
+
+func TestIterationHooks(t *testing.T) {
+	f := ioutil.NopCloser(strings.NewReader("a b b c c c c"))
+	cnf := model.NewConfig(5, 3, 0, 1, 2, 0.025, false, false)
+	glove, err := NewGlove(f, cnf, NewAdaGrad(5, 0.025), 100, 0.75, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var before, after []int
+	glove.BeforeIteration(func(iteration int) {
+		before = append(before, iteration)
+	})
+	glove.AfterIteration(func(iteration int, m model.Model) {
+		if m != model.Model(glove) {
+			t.Errorf("Expected after hook to be called with the model itself: %v", m)
+		}
+		after = append(after, iteration)
+	})
+	// resuming skips the hooks of the iterations already trained.
+	glove.resumed = 1
+	if err := glove.Train(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []int{2, 3}
+	if !reflect.DeepEqual(before, expected) || !reflect.DeepEqual(after, expected) {
+		t.Errorf("Expected hooks to be called on iterations %v: before=%v, after=%v", expected, before, after)
+	}
+}
+
+func TestConcurrentTrain(t *testing.T) {
+	glove := newTestGlove(t)
+	done := make(chan error)
+	glove.BeforeIteration(func(int) {
+		go func() {
+			done <- glove.Train()
+		}()
+		if err := <-done; err != model.ErrTraining {
+			t.Errorf("Expected Train from another goroutine to fail while training: %v", err)
+		}
+	})
+	if err := glove.Train(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAutoThreadSize(t *testing.T) {
+	f := ioutil.NopCloser(strings.NewReader("a b b c c c c"))
+	cnf := model.NewConfig(5, 1, 0, 0, 2, 0.025, false, false)
+	glove, err := NewGlove(f, cnf, NewSgd(5, 0.025), 100, 0.75, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := glove.Train(); err != nil {
+		t.Fatal(err)
+	}
+	if threads := glove.Summary().ThreadSize; threads != model.MaxThreadSize(0) {
+		t.Errorf("Expected all CPUs in summary: %d, but got %d", model.MaxThreadSize(0), threads)
+	}
+	if glove.Config.ThreadSize != 0 {
+		t.Errorf("Expected Config.ThreadSize to be kept 0: %d", glove.Config.ThreadSize)
+	}
+}
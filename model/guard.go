@@ -0,0 +1,43 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// ErrTraining is returned by Train of a model which is already training.
+var ErrTraining = errors.New("Model is already training")
+
+// TrainGuard guards a model against Train called while it's already training, e.g. from another goroutine,
+// which would corrupt the state of training. The zero value is ready to use.
+type TrainGuard struct {
+	training int32
+}
+
+// Begin marks training started, or returns ErrTraining if it's already training.
+func (g *TrainGuard) Begin() error {
+	if !atomic.CompareAndSwapInt32(&g.training, 0, 1) {
+		return ErrTraining
+	}
+	return nil
+}
+
+// End marks training finished.
+func (g *TrainGuard) End() {
+	atomic.StoreInt32(&g.training, 0)
+}
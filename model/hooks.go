@@ -0,0 +1,47 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+// Hooks are the functions called between iterations of training, e.g. to evaluate custom metrics or to change
+// hyperparameters between passes. They are called in the order of registration from the goroutine of Train.
+type Hooks struct {
+	before []func(iteration int)
+	after  []func(iteration int, m Model)
+}
+
+// BeforeIteration registers fn to be called with the iteration, which is 1-origin, before it starts.
+// Copies of Hooks share the hooks registered so far, but never the ones registered after copying.
+func (h *Hooks) BeforeIteration(fn func(iteration int)) {
+	h.before = append(h.before[:len(h.before):len(h.before)], fn)
+}
+
+// AfterIteration registers fn to be called with the iteration and the model after it ends.
+func (h *Hooks) AfterIteration(fn func(iteration int, m Model)) {
+	h.after = append(h.after[:len(h.after):len(h.after)], fn)
+}
+
+// RunBefore calls the hooks registered by BeforeIteration.
+func (h *Hooks) RunBefore(iteration int) {
+	for _, fn := range h.before {
+		fn(iteration)
+	}
+}
+
+// RunAfter calls the hooks registered by AfterIteration with m.
+func (h *Hooks) RunAfter(iteration int, m Model) {
+	for _, fn := range h.after {
+		fn(iteration, m)
+	}
+}
@@ -14,9 +14,12 @@
 
 package model
 
-// Model is the interface that has Train, Save.
+// Model is the interface that has Train, Save, Summary.
 type Model interface {
-	// Train is function for
+	// Train trains the model. A model trains at most once at a time, and Train returns ErrTraining if it's
+	// already training. Queries on the model, e.g. Save, are not guarded and must not run during training.
 	Train() error
 	Save(outputFile string) error
+	// Summary returns the seeds of the run to attribute it.
+	Summary() Summary
 }
@@ -0,0 +1,88 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"time"
+)
+
+// ProgressField is the bitmask of the fields of Progress which the model reports.
+type ProgressField uint
+
+// The list of ProgressField.
+const (
+	ProgressLearningRate ProgressField = 1 << iota
+	ProgressCost
+	ProgressNorms
+	ProgressPerplexity
+)
+
+// Progress is the state of training within an iteration, which is common to all models,
+// so that a single ProgressFunc monitors any of them.
+type Progress struct {
+	// Iteration is 1-origin number of the current iteration.
+	Iteration int
+	// Done is the number of units processed in the iteration out of Total,
+	// i.e. positions of words in corpus for word2vec, and co-occurrence pairs for GloVe.
+	Done, Total int
+	// LearningRate is the current learning rate, valid with ProgressLearningRate, e.g. not for AdaGrad of GloVe.
+	LearningRate float64
+	// Cost is the average cost of the units processed in the iteration so far, valid with ProgressCost.
+	Cost float64
+	// Norms is the approximate percentiles of norms of word vectors, valid with ProgressNorms.
+	// It is estimated only at the end of each iteration, since it scans all the vectors.
+	Norms NormPercentiles
+	// Perplexity is the perplexity on held-out corpus at the end of each iteration, valid with ProgressPerplexity.
+	Perplexity float64
+	// Valid is the fields the model reports, and the others are zero.
+	Valid ProgressField
+}
+
+// Has returns whether the field is valid for the model.
+func (p Progress) Has(field ProgressField) bool {
+	return p.Valid&field != 0
+}
+
+// ProgressFunc is called with Progress during training. It is never called concurrently.
+type ProgressFunc func(Progress)
+
+// ProgressInterval is the interval to call ProgressFunc within an iteration.
+var ProgressInterval = 100 * time.Millisecond
+
+// ReportProgress calls fn with snapshot every ProgressInterval in another goroutine, until stop is called.
+// stop waits for the goroutine and calls fn with the final snapshot of the iteration, for which final is true,
+// e.g. to fill the fields too expensive to compute every interval.
+func ReportProgress(fn ProgressFunc, snapshot func(final bool) Progress) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(ProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fn(snapshot(false))
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+		fn(snapshot(true))
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReportProgress(t *testing.T) {
+	defer func(interval time.Duration) {
+		ProgressInterval = interval
+	}(ProgressInterval)
+	ProgressInterval = time.Millisecond
+
+	var reports []Progress
+	stop := ReportProgress(func(p Progress) {
+		reports = append(reports, p)
+	}, func(final bool) Progress {
+		p := Progress{Iteration: 1, Done: len(reports), Total: 10, Cost: 0.5, Valid: ProgressCost}
+		if final {
+			p.Valid |= ProgressNorms
+		}
+		return p
+	})
+	time.Sleep(5 * time.Millisecond)
+	stop()
+
+	if len(reports) == 0 {
+		t.Fatal("Expected progress to be reported")
+	}
+	if p := reports[len(reports)-1]; p.Done != len(reports)-1 || !p.Has(ProgressCost) || p.Has(ProgressLearningRate) ||
+		!p.Has(ProgressNorms) {
+		t.Errorf("Expected the last report is the final snapshot with valid cost and norms only: %+v", p)
+	}
+	for _, p := range reports[:len(reports)-1] {
+		if p.Has(ProgressNorms) {
+			t.Errorf("Expected norms only in the final snapshot: %+v", p)
+		}
+	}
+	n := len(reports)
+	time.Sleep(5 * time.Millisecond)
+	if len(reports) != n {
+		t.Errorf("Expected no reports after stop: %d -> %d", n, len(reports))
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"math"
+	"sort"
+
+	"github.com/ynqa/wego/vectorio"
+)
+
+// Quantile estimates the p-quantile of a stream of values by the P² algorithm (Jain and Chlamtac, 1985)
+// in constant memory, without storing and sorting the values.
+type Quantile struct {
+	p     float64
+	count int
+	// heights and actual positions of the 5 markers, their desired positions and the increments of them.
+	heights   [5]float64
+	positions [5]float64
+	desired   [5]float64
+	increment [5]float64
+}
+
+// NewQuantile creates *Quantile for p in [0, 1], e.g. 0.5 for median.
+func NewQuantile(p float64) *Quantile {
+	return &Quantile{
+		p:         p,
+		positions: [5]float64{1, 2, 3, 4, 5},
+		desired:   [5]float64{1, 1 + 2*p, 1 + 4*p, 3 + 2*p, 5},
+		increment: [5]float64{0, p / 2, p, (1 + p) / 2, 1},
+	}
+}
+
+// Add adds the value to the stream.
+func (q *Quantile) Add(x float64) {
+	if q.count < len(q.heights) {
+		q.heights[q.count] = x
+		q.count++
+		if q.count == len(q.heights) {
+			sort.Float64s(q.heights[:])
+		}
+		return
+	}
+	q.count++
+
+	var k int
+	switch {
+	case x < q.heights[0]:
+		q.heights[0] = x
+	case x >= q.heights[4]:
+		q.heights[4] = x
+		k = 3
+	default:
+		for k = 0; k < 3 && x >= q.heights[k+1]; k++ {
+		}
+	}
+	for i := k + 1; i < 5; i++ {
+		q.positions[i]++
+	}
+	for i := range q.desired {
+		q.desired[i] += q.increment[i]
+	}
+
+	for i := 1; i < 4; i++ {
+		d := q.desired[i] - q.positions[i]
+		if d >= 1 && q.positions[i+1]-q.positions[i] > 1 || d <= -1 && q.positions[i-1]-q.positions[i] < -1 {
+			s := math.Copysign(1, d)
+			h := q.parabolic(i, s)
+			if q.heights[i-1] < h && h < q.heights[i+1] {
+				q.heights[i] = h
+			} else {
+				q.heights[i] = q.linear(i, int(s))
+			}
+			q.positions[i] += s
+		}
+	}
+}
+
+func (q *Quantile) parabolic(i int, s float64) float64 {
+	n, h := q.positions, q.heights
+	return h[i] + s/(n[i+1]-n[i-1])*
+		((n[i]-n[i-1]+s)*(h[i+1]-h[i])/(n[i+1]-n[i])+(n[i+1]-n[i]-s)*(h[i]-h[i-1])/(n[i]-n[i-1]))
+}
+
+func (q *Quantile) linear(i, s int) float64 {
+	return q.heights[i] + float64(s)*(q.heights[i+s]-q.heights[i])/(q.positions[i+s]-q.positions[i])
+}
+
+// Value returns the estimated quantile, which is exact for less than 5 values, or 0 if no values are added.
+func (q *Quantile) Value() float64 {
+	if q.count == 0 {
+		return 0
+	}
+	if q.count < len(q.heights) {
+		sorted := append([]float64(nil), q.heights[:q.count]...)
+		sort.Float64s(sorted)
+		return sorted[int(math.Round(q.p*float64(q.count-1)))]
+	}
+	return q.heights[2]
+}
+
+// NormPercentiles is the approximate percentiles of norms of word vectors, along with their exact min and max.
+type NormPercentiles struct {
+	P50, P90, P99 float64
+	Min, Max      float64
+}
+
+// EstimateNormPercentiles estimates NormPercentiles of the vectors of size ids in a single pass by Quantile.
+// The percentiles are ordered and within [Min, Max].
+func EstimateNormPercentiles(size int, vector func(id int) []float64) NormPercentiles {
+	var norms NormPercentiles
+	if size <= 0 {
+		return norms
+	}
+	p50, p90, p99 := NewQuantile(0.5), NewQuantile(0.9), NewQuantile(0.99)
+	norms.Min, norms.Max = math.Inf(1), math.Inf(-1)
+	for id := 0; id < size; id++ {
+		norm := vectorio.Norm(vector(id))
+		p50.Add(norm)
+		p90.Add(norm)
+		p99.Add(norm)
+		norms.Min = math.Min(norms.Min, norm)
+		norms.Max = math.Max(norms.Max, norm)
+	}
+	// the estimates are independent of each other, so they are clamped to be consistent.
+	norms.P50 = math.Min(math.Max(p50.Value(), norms.Min), norms.Max)
+	norms.P90 = math.Min(math.Max(p90.Value(), norms.P50), norms.Max)
+	norms.P99 = math.Min(math.Max(p99.Value(), norms.P90), norms.Max)
+	return norms
+}
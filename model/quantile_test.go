@@ -0,0 +1,76 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestQuantile(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	values := make([]float64, 10000)
+	for i := range values {
+		// skewed like norms of word vectors.
+		values[i] = math.Exp(rnd.NormFloat64())
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	for _, p := range []float64{0.5, 0.9, 0.99} {
+		q := NewQuantile(p)
+		for _, v := range values {
+			q.Add(v)
+		}
+		exact := sorted[int(p*float64(len(sorted)-1))]
+		if math.Abs(q.Value()-exact)/exact > 0.05 {
+			t.Errorf("Expected %v-quantile close to %v: %v", p, exact, q.Value())
+		}
+	}
+
+	q := NewQuantile(0.5)
+	for _, v := range []float64{3, 1, 2} {
+		q.Add(v)
+	}
+	if q.Value() != 2 {
+		t.Errorf("Expected the exact median of less than 5 values: %v", q.Value())
+	}
+}
+
+func TestEstimateNormPercentiles(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	vectors := make([][]float64, 1000)
+	for i := range vectors {
+		vectors[i] = []float64{rnd.NormFloat64(), rnd.NormFloat64() * float64(i%10)}
+	}
+	norms := EstimateNormPercentiles(len(vectors), func(id int) []float64 {
+		return vectors[id]
+	})
+	if !(norms.Min <= norms.P50 && norms.P50 <= norms.P90 && norms.P90 <= norms.P99 && norms.P99 <= norms.Max) {
+		t.Errorf("Expected the percentiles ordered within min and max: %+v", norms)
+	}
+	if norms.Min == norms.Max {
+		t.Errorf("Expected the norms to spread: %+v", norms)
+	}
+
+	few := EstimateNormPercentiles(2, func(id int) []float64 {
+		return []float64{float64(id + 1)}
+	})
+	if few.Min != 1 || few.Max != 2 || few.P50 < 1 || few.P99 > 2 {
+		t.Errorf("Expected the percentiles of 2 vectors within [1, 2]: %+v", few)
+	}
+}
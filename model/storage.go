@@ -0,0 +1,68 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Storage stores the vectors of words by id, e.g. on disk by MmapStorage for the vectors which don't fit in RAM.
+// Get returns the vector itself, not a copy, so that updates through it are stored.
+type Storage interface {
+	Get(id int) []float64
+	Set(id int, vector []float64)
+	// Matrix returns all vectors as a row-major matrix of ids, which Get and Set share,
+	// e.g. for training to update the vectors in place.
+	Matrix() []float64
+	Close() error
+}
+
+// MemoryStorage is Storage in memory, which is the default.
+type MemoryStorage struct {
+	matrix    []float64
+	dimension int
+}
+
+// NewMemoryStorage creates *MemoryStorage for size vectors of dimension.
+func NewMemoryStorage(size, dimension int) (*MemoryStorage, error) {
+	elementSize, err := ElementSize(size, dimension)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to allocate storage")
+	}
+	return &MemoryStorage{
+		matrix:    make([]float64, elementSize),
+		dimension: dimension,
+	}, nil
+}
+
+// Get returns the vector of id.
+func (m *MemoryStorage) Get(id int) []float64 {
+	return m.matrix[id*m.dimension : (id+1)*m.dimension]
+}
+
+// Set copies vector to the one of id.
+func (m *MemoryStorage) Set(id int, vector []float64) {
+	copy(m.Get(id), vector)
+}
+
+// Matrix returns all vectors.
+func (m *MemoryStorage) Matrix() []float64 {
+	return m.matrix
+}
+
+// Close does nothing for memory.
+func (m *MemoryStorage) Close() error {
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package model
+
+import (
+	"os"
+	"reflect"
+	"syscall"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+const float64Size = 8
+
+// MmapStorage is Storage on a file mapped into memory, whose pages are loaded on access and written back by OS,
+// so that words' vector larger than RAM is trained and queried. The values are in native byte order.
+type MmapStorage struct {
+	file      *os.File
+	data      []byte
+	matrix    []float64
+	dimension int
+}
+
+// NewMmapStorage creates *MmapStorage for size vectors of dimension on the file of path.
+// The file is created if it doesn't exist, and the vectors in it are kept if it does, e.g. to query a saved model.
+func NewMmapStorage(path string, size, dimension int) (*MmapStorage, error) {
+	elementSize, err := ElementSize(size, dimension)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to allocate storage")
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to open %s for storage", path)
+	}
+	m := &MmapStorage{
+		file:      file,
+		dimension: dimension,
+	}
+	if elementSize == 0 {
+		return m, nil
+	}
+	if err := file.Truncate(int64(elementSize) * float64Size); err != nil {
+		file.Close()
+		return nil, errors.Wrapf(err, "Unable to resize %s for storage", path)
+	}
+	data, err := syscall.Mmap(int(file.Fd()), 0, elementSize*float64Size, syscall.PROT_READ|syscall.PROT_WRITE,
+		syscall.MAP_SHARED)
+	if err != nil {
+		file.Close()
+		return nil, errors.Wrapf(err, "Unable to map %s into memory", path)
+	}
+	m.data = data
+	header := (*reflect.SliceHeader)(unsafe.Pointer(&m.matrix))
+	header.Data = uintptr(unsafe.Pointer(&data[0]))
+	header.Len = elementSize
+	header.Cap = elementSize
+	return m, nil
+}
+
+// Get returns the vector of id.
+func (m *MmapStorage) Get(id int) []float64 {
+	return m.matrix[id*m.dimension : (id+1)*m.dimension]
+}
+
+// Set copies vector to the one of id.
+func (m *MmapStorage) Set(id int, vector []float64) {
+	copy(m.Get(id), vector)
+}
+
+// Matrix returns all vectors, which must not be used after Close.
+func (m *MmapStorage) Matrix() []float64 {
+	return m.matrix
+}
+
+// Close writes back the vectors to the file and unmaps it.
+func (m *MmapStorage) Close() error {
+	if m.data != nil {
+		if err := syscall.Munmap(m.data); err != nil {
+			return errors.Wrap(err, "Unable to unmap storage")
+		}
+		m.data, m.matrix = nil, nil
+	}
+	return m.file.Close()
+}
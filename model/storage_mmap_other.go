@@ -0,0 +1,32 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package model
+
+import (
+	"github.com/pkg/errors"
+)
+
+// MmapStorage is Storage on a file mapped into memory, which isn't available on this platform.
+type MmapStorage struct {
+	MemoryStorage
+}
+
+// NewMmapStorage fails on this platform, which doesn't map files into memory.
+func NewMmapStorage(path string, size, dimension int) (*MmapStorage, error) {
+	return nil, errors.Errorf("Unable to map %s into memory: not supported on this platform", path)
+}
@@ -0,0 +1,64 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package model
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "vectors")
+
+	memory, err := NewMemoryStorage(3, 2)
+	if err != nil {
+		t.Fatalf("NewMemoryStorage: %v", err)
+	}
+	mmap, err := NewMmapStorage(path, 3, 2)
+	if err != nil {
+		t.Fatalf("NewMmapStorage: %v", err)
+	}
+
+	for _, s := range []Storage{memory, mmap} {
+		s.Set(1, []float64{1, 2})
+		s.Get(2)[0] = 3
+		if expected := []float64{0, 0, 1, 2, 3, 0}; !reflect.DeepEqual(s.Matrix(), expected) {
+			t.Errorf("Expected %T to share vectors between Get, Set and Matrix: %v, but got %v", s, expected, s.Matrix())
+		}
+		if err := s.Close(); err != nil {
+			t.Errorf("Close %T: %v", s, err)
+		}
+	}
+
+	reopened, err := NewMmapStorage(path, 3, 2)
+	if err != nil {
+		t.Fatalf("NewMmapStorage: %v", err)
+	}
+	defer reopened.Close()
+	if expected := []float64{1, 2}; !reflect.DeepEqual(reopened.Get(1), expected) {
+		t.Errorf("Expected vectors kept in file: %v, but got %v", expected, reopened.Get(1))
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+)
+
+// Summary is the summary of a run of training to attribute it, e.g. to repeat it with the same seeds
+// under a single thread for debugging.
+type Summary struct {
+	// master seed of the run, given by Config.Seed or generated by NewSeed.
+	Seed uint64
+	// number of threads of the last training, which is chosen automatically if Config.ThreadSize is not positive.
+	ThreadSize int
+	// seeds of the workers derived from Seed by WorkerSeed, indexed by worker id.
+	WorkerSeeds []uint64
+}
+
+// NewSummary creates Summary of the master seed and the seeds derived from it for workers,
+// each of which runs on its own thread.
+func NewSummary(seed uint64, workers int) Summary {
+	s := Summary{
+		Seed:        seed,
+		ThreadSize:  workers,
+		WorkerSeeds: make([]uint64, workers),
+	}
+	for i := range s.WorkerSeeds {
+		s.WorkerSeeds[i] = WorkerSeed(seed, i)
+	}
+	return s
+}
+
+func (s Summary) String() string {
+	return fmt.Sprintf("seed: %d, thread size: %d, worker seeds: %v", s.Seed, s.ThreadSize, s.WorkerSeeds)
+}
@@ -0,0 +1,67 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Throughput measures tokens processed per second averaged over the time spent training, across calls of Train.
+// Tokens are added by threads concurrently, and Rate is safe to call during training. The zero value is ready to use.
+type Throughput struct {
+	tokens int64
+
+	mu      sync.Mutex
+	elapsed time.Duration
+	start   time.Time
+}
+
+// Add adds n tokens processed.
+func (t *Throughput) Add(n int64) {
+	atomic.AddInt64(&t.tokens, n)
+}
+
+// Start starts measuring time of training.
+func (t *Throughput) Start() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.start = time.Now()
+}
+
+// Stop stops measuring time of training, which is accumulated.
+func (t *Throughput) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.start.IsZero() {
+		t.elapsed += time.Since(t.start)
+		t.start = time.Time{}
+	}
+}
+
+// Rate returns tokens per second, including the training in progress. It is 0 before training.
+func (t *Throughput) Rate() float64 {
+	t.mu.Lock()
+	elapsed := t.elapsed
+	if !t.start.IsZero() {
+		elapsed += time.Since(t.start)
+	}
+	t.mu.Unlock()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(atomic.LoadInt64(&t.tokens)) / elapsed.Seconds()
+}
@@ -0,0 +1,80 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// Tracker appends the vectors of tracked words into JSONL file after each iteration,
+// as {"iteration": i, "word": word, "vector": [values]} per line.
+type Tracker struct {
+	ids   []int
+	words []string
+
+	file *os.File
+	w    *bufio.Writer
+}
+
+type trackRecord struct {
+	Iteration int       `json:"iteration"`
+	Word      string    `json:"word"`
+	Vector    []float64 `json:"vector"`
+}
+
+// NewTracker creates *Tracker for the words with the ids, which appends to the file on path.
+func NewTracker(ids []int, words []string, path string) (*Tracker, error) {
+	if len(ids) != len(words) {
+		return nil, errors.Errorf("Length of ids %d is not equal to words %d", len(ids), len(words))
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unable to open %s to track words", path)
+	}
+	return &Tracker{
+		ids:   ids,
+		words: words,
+		file:  file,
+		w:     bufio.NewWriter(file),
+	}, nil
+}
+
+// Track writes the current vectors given by vector for the iteration, and flushes them.
+func (t *Tracker) Track(iteration int, vector func(id int) []float64) error {
+	enc := json.NewEncoder(t.w)
+	for i, id := range t.ids {
+		if err := enc.Encode(trackRecord{
+			Iteration: iteration,
+			Word:      t.words[i],
+			Vector:    vector(id),
+		}); err != nil {
+			return errors.Wrapf(err, "Unable to track %s", t.words[i])
+		}
+	}
+	return t.w.Flush()
+}
+
+// Close flushes and closes the file.
+func (t *Tracker) Close() error {
+	if err := t.w.Flush(); err != nil {
+		t.file.Close()
+		return err
+	}
+	return t.file.Close()
+}
@@ -16,6 +16,13 @@ package model
 
 import (
 	"math"
+	"math/big"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
 )
 
 // IndexPerThread creates interval of indices per thread.
@@ -30,10 +37,94 @@ func IndexPerThread(threadSize, dataSize int) []int {
 	return indexPerThread
 }
 
-var next uint64 = 1
+// MaxThreadSize returns the upper limit of threads, which is threadSize, or runtime.NumCPU() if threadSize is
+// not positive, i.e. the number of threads is chosen automatically.
+func MaxThreadSize(threadSize int) int {
+	if threadSize <= 0 {
+		return runtime.NumCPU()
+	}
+	return threadSize
+}
+
+// DeriveSeed derives the seed for Random from seed and keys, e.g. (seed, iteration),
+// so that the randomness for the keys is reproduced regardless of the randomness consumed before it,
+// e.g. on resuming training.
+func DeriveSeed(seed uint64, keys ...uint64) uint64 {
+	h := seed
+	for _, key := range keys {
+		h = splitmix64(h + key)
+	}
+	return h
+}
+
+// WorkerSeed derives the seed of the worker, i.e. thread, from the master seed of a run by splitmix64
+// over master^worker, so that the randomness of each worker is attributed to the master seed.
+func WorkerSeed(master uint64, worker int) uint64 {
+	return splitmix64(master ^ uint64(worker))
+}
+
+// NewSeed generates a master seed from the current time for a run whose seed isn't given. It is never 0.
+func NewSeed() uint64 {
+	if s := splitmix64(uint64(time.Now().UnixNano())); s != 0 {
+		return s
+	}
+	return 1
+}
 
-// NextRandom is linear congruential generator like rand.Intn(window)
-func NextRandom(value int) int {
-	next = next*uint64(25214903917) + 11
-	return int(next % uint64(value))
+// splitmix64 returns the next value of splitmix64 generator whose state is x.
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// Random is linear congruential generator like the original word2vec, whose state is its own.
+type Random struct {
+	next uint64
+}
+
+// NewRandom creates *Random with seed, e.g. derived by DeriveSeed.
+func NewRandom(seed uint64) *Random {
+	return &Random{next: seed}
+}
+
+// Next returns the next random value in [0, value) like rand.Intn(value).
+func (r *Random) Next(value int) int {
+	r.next = r.next*uint64(25214903917) + 11
+	return int(r.next % uint64(value))
+}
+
+// Float64 returns the next random value in [0, 1) like rand.Float64().
+func (r *Random) Float64() float64 {
+	r.next = r.next*uint64(25214903917) + 11
+	return float64(r.next>>11) / (1 << 53)
+}
+
+// maxInt is the upper limit of int on the platform.
+const maxInt = int64(^uint(0) >> 1)
+
+// ElementSize returns the number of elements for the matrix of dims, e.g. rows x cols,
+// or an error if it overflows int on the platform (e.g. 32-bit builds).
+func ElementSize(dims ...int) (int, error) {
+	return elementSize(dims, maxInt)
+}
+
+func elementSize(dims []int, limit int64) (int, error) {
+	shape := make([]string, len(dims))
+	required := big.NewInt(1)
+	for i, dim := range dims {
+		shape[i] = strconv.Itoa(dim)
+		required.Mul(required, big.NewInt(int64(dim)))
+	}
+	for _, dim := range dims {
+		if dim < 0 {
+			return 0, errors.Errorf("Invalid matrix size: %s", strings.Join(shape, " x "))
+		}
+	}
+	if !required.IsInt64() || required.Int64() > limit {
+		return 0, errors.Errorf("Unable to allocate %s matrix: %s elements exceed the limit %d on this platform",
+			strings.Join(shape, " x "), required.String(), limit)
+	}
+	return int(required.Int64()), nil
 }
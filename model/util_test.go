@@ -15,13 +15,83 @@
 package model
 
 import (
+	"math"
 	"testing"
 )
 
-func TestNextRandom(t *testing.T) {
-	// TODO: Fuzzy Test
-	r := NextRandom(5)
-	if !(0 <= r && r < 5) {
-		t.Errorf("Extected range between 0 < nextRandom(x) < 5: %v", r)
+func TestRandom(t *testing.T) {
+	if DeriveSeed(1, 2) != DeriveSeed(1, 2) {
+		t.Error("Expected the same seed for the same keys")
+	}
+	if DeriveSeed(1, 2) == DeriveSeed(1, 3) || DeriveSeed(1, 2) == DeriveSeed(2, 2) {
+		t.Error("Expected different seeds for different seeds or keys")
+	}
+
+	r1, r2 := NewRandom(DeriveSeed(1, 2)), NewRandom(DeriveSeed(1, 2))
+	for i := 0; i < 10; i++ {
+		v1, v2 := r1.Next(5), r2.Next(5)
+		if v1 != v2 || v1 < 0 || v1 >= 5 {
+			t.Fatalf("Expected the same values in [0, 5) with the same seed: %v, %v", v1, v2)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		if v := r1.Float64(); v < 0 || v >= 1 {
+			t.Fatalf("Expected a value in [0, 1): %v", v)
+		}
+	}
+}
+
+func TestWorkerSeed(t *testing.T) {
+	if WorkerSeed(7, 1) != WorkerSeed(7, 1) {
+		t.Error("Expected the same seed for the same master seed and worker")
+	}
+	if WorkerSeed(7, 0) == WorkerSeed(7, 1) || WorkerSeed(7, 0) == WorkerSeed(8, 0) {
+		t.Error("Expected different seeds for different workers or master seeds")
+	}
+	if NewSeed() == 0 {
+		t.Error("Expected generated seed not to be 0")
+	}
+
+	s := NewSummary(7, 2)
+	if s.Seed != 7 || s.ThreadSize != 2 || len(s.WorkerSeeds) != 2 || s.WorkerSeeds[1] != WorkerSeed(7, 1) {
+		t.Errorf("Expected summary of seed=7 and 2 worker seeds derived from it: %v", s)
+	}
+}
+
+func TestElementSize(t *testing.T) {
+	testCases := []struct {
+		dims     []int
+		limit    int64
+		expected int
+		isErr    bool
+	}{
+		{dims: []int{10, 100}, limit: math.MaxInt32, expected: 1000},
+		{dims: []int{0, 100}, limit: math.MaxInt32, expected: 0},
+		{dims: []int{1, math.MaxInt32}, limit: math.MaxInt32, expected: math.MaxInt32},
+		{dims: []int{65536, 32767}, limit: math.MaxInt32, expected: 65536 * 32767},
+		{dims: []int{65536, 32768}, limit: math.MaxInt32, isErr: true},
+		{dims: []int{3000000, 1000}, limit: math.MaxInt32, isErr: true},
+		{dims: []int{-1, 10}, limit: math.MaxInt32, isErr: true},
+		{dims: []int{0, -1}, limit: math.MaxInt32, isErr: true},
+		{dims: []int{1 << 20, 1 << 20, 0}, limit: math.MaxInt32, expected: 0},
+		// rows doubled for context vectors overflow in the product, not in the rows alone.
+		{dims: []int{math.MaxInt32, 2, 1}, limit: math.MaxInt32, isErr: true},
+		{dims: []int{1000, 2, 101}, limit: math.MaxInt32, expected: 202000},
+	}
+
+	for _, testCase := range testCases {
+		actual, err := elementSize(testCase.dims, testCase.limit)
+		if testCase.isErr {
+			if err == nil {
+				t.Errorf("Expected overflow error for %v under limit=%d", testCase.dims, testCase.limit)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for %v: %v", testCase.dims, err)
+		}
+		if actual != testCase.expected {
+			t.Errorf("Expected elementSize(%v)=%d: %d", testCase.dims, testCase.expected, actual)
+		}
 	}
 }
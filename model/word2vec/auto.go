@@ -0,0 +1,117 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ynqa/wego/model"
+)
+
+// The settings to choose the number of threads automatically.
+const (
+	// words for a thread to take from document at once.
+	autoChunkSize = 1000
+	// interval to measure words/sec and to spawn the next thread.
+	autoInterval = 500 * time.Millisecond
+	// lower limit of the gain in words/sec to keep spawning threads.
+	autoMinGain = 0.05
+)
+
+// trainAuto trains on document spawning threads one by one up to runtime.NumCPU(), while words/sec gains
+// autoMinGain or more by each thread, and returns the number of threads worth spawning.
+// The threads take chunks of document in order, so that document is trained on once however many threads there are.
+func (w *Word2vec) trainAuto(document []int, weights []float64, sentences []int) int {
+	next, exhausted := chunks(document, weights, sentences, autoChunkSize)
+	maxThreads := model.MaxThreadSize(0)
+	semaphore := make(chan struct{}, maxThreads)
+	waitGroup := &sync.WaitGroup{}
+	spawn := func(worker int) {
+		waitGroup.Add(1)
+		go w.trainPerThread(w.workerRandom(worker), next, semaphore, waitGroup)
+	}
+
+	spawn(0)
+	threads, chosen := 1, 0
+	var lastTokens int64
+	var lastRate float64
+	ticker := time.NewTicker(autoInterval)
+	for chosen == 0 {
+		<-ticker.C
+		tokens := atomic.LoadInt64(&w.trainedWordCount)
+		rate := float64(tokens - lastTokens)
+		lastTokens = tokens
+		switch {
+		case exhausted():
+			chosen = threads
+		case threads > 1 && rate < lastRate*(1+autoMinGain):
+			chosen = threads - 1
+		case threads == maxThreads:
+			chosen = threads
+		default:
+			lastRate = rate
+			spawn(threads)
+			threads++
+		}
+	}
+	ticker.Stop()
+	waitGroup.Wait()
+	return chosen
+}
+
+// chunks returns the function to take the next chunk of document, weights and sentences in order, which returns nil
+// at the end of document, and the function to check whether it has reached the end.
+func chunks(document []int, weights []float64, sentences []int, size int) (func() *part, func() bool) {
+	var cursor int64
+	next := func() *part {
+		to := atomic.AddInt64(&cursor, int64(size))
+		from := to - int64(size)
+		if from >= int64(len(document)) {
+			return nil
+		}
+		if to > int64(len(document)) {
+			to = int64(len(document))
+		}
+		return &part{
+			document:  document[from:to],
+			weights:   weightsOf(weights, int(from), int(to)),
+			sentences: sentencesOf(sentences, int(from), int(to)),
+			to:        int(to - from),
+		}
+	}
+	exhausted := func() bool {
+		return atomic.LoadInt64(&cursor) >= int64(len(document))
+	}
+	return next, exhausted
+}
+
+// once returns the function which returns document, weights and sentences at the first call, and nil after that.
+func once(document []int, weights []float64, sentences []int) func() *part {
+	done := false
+	return func() *part {
+		if done {
+			return nil
+		}
+		done = true
+		return &part{
+			document:  document,
+			weights:   weights,
+			sentences: sentences,
+			to:        len(document),
+		}
+	}
+}
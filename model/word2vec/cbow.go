@@ -22,12 +22,18 @@ import (
 type Cbow struct {
 	sums, pools chan []float64
 
-	dimension int
-	window    int
+	dimension   int
+	window      int
+	stride      int
+	excludeSelf bool
+	mean        bool
+	frozen      bool
+	masked      []bool
 }
 
 // NewCbow creates *Cbow
 func NewCbow(dimension, window, threadSize int) *Cbow {
+	threadSize = model.MaxThreadSize(threadSize)
 	pools := make(chan []float64, threadSize)
 	sums := make(chan []float64, threadSize)
 	for i := 0; i < threadSize; i++ {
@@ -40,10 +46,34 @@ func NewCbow(dimension, window, threadSize int) *Cbow {
 
 		dimension: dimension,
 		window:    window,
+		stride:    1,
+		mean:      true,
 	}
 }
 
-func (c *Cbow) trainOne(document []int, wordIndex int, wordVector []float64, lr float64, optimizer Optimizer) {
+// WindowStride sets the stride to sample context words within the window, e.g. stride=2 samples every other word,
+// i.e. the words at distance 2, 4, ... from the target word. All of words in the window are sampled by default.
+func (c *Cbow) WindowStride(stride int) *Cbow {
+	c.stride = stride
+	return c
+}
+
+// Mean sets whether the hidden layer is the average of context vectors or their sum.
+// It is the average by default, like cbow_mean=1 of the original word2vec.
+func (c *Cbow) Mean(mean bool) *Cbow {
+	c.mean = mean
+	return c
+}
+
+// ExcludeSelf sets whether the other occurrences of the target word in the window are excluded from its context.
+// They count as context by default, like the original word2vec.
+func (c *Cbow) ExcludeSelf(excludeSelf bool) *Cbow {
+	c.excludeSelf = excludeSelf
+	return c
+}
+
+func (c *Cbow) trainOne(document []int, wordIndex int, wordVector *matrix, lr float64, optimizer Optimizer,
+	rnd *model.Random) {
 	sum := <-c.sums
 	pool := <-c.pools
 	word := document[wordIndex]
@@ -51,37 +81,96 @@ func (c *Cbow) trainOne(document []int, wordIndex int, wordVector []float64, lr
 		sum[i] = 0.0
 		pool[i] = 0.0
 	}
-	c.dowith(document, wordIndex, sum, pool, wordVector, c.initSum)
-	optimizer.update(word, lr, sum, pool)
-	c.dowith(document, wordIndex, sum, pool, wordVector, c.updateContext)
+	if n := c.dowith(document, wordIndex, sum, pool, wordVector, rnd, c.initSum); c.mean && n > 0 {
+		for i := 0; i < c.dimension; i++ {
+			sum[i] /= float64(n)
+		}
+	}
+	optimizer.update(word, lr, sum, pool, rnd)
+	if !c.frozen {
+		c.dowith(document, wordIndex, sum, pool, wordVector, rnd, c.updateContext)
+	}
 	c.sums <- sum
 	c.pools <- pool
 }
 
-func (c *Cbow) dowith(document []int, wordIndex int, sum, pool, wordVector []float64,
-	opr func(context int, sum, pool, wordVector []float64)) {
+// dowith applies opr to the context words in the window, and returns the number of them.
+func (c *Cbow) dowith(document []int, wordIndex int, sum, pool []float64, wordVector *matrix, rnd *model.Random,
+	opr func(context int, sum, pool []float64, wordVector *matrix)) int {
 
-	shrinkage := model.NextRandom(c.window)
+	word, excludeSelf := document[wordIndex], c.excludeSelf
+	var n int
+	shrinkage := rnd.Next(c.window)
 	for a := shrinkage; a < c.window*2+1-shrinkage; a++ {
-		if a != c.window {
+		if a != c.window && inStride(a-c.window, c.stride) {
 			c := wordIndex - c.window + a
 			if c < 0 || c >= len(document) {
 				continue
 			}
 			context := document[c]
+			if excludeSelf && context == word {
+				continue
+			}
 			opr(context, sum, pool, wordVector)
+			n++
 		}
 	}
+	return n
 }
 
-func (c *Cbow) initSum(context int, sum, pool, wordVector []float64) {
+func (c *Cbow) initSum(context int, sum, pool []float64, wordVector *matrix) {
+	contextVector := wordVector.row(context)
 	for i := 0; i < c.dimension; i++ {
-		sum[i] += wordVector[context*c.dimension+i]
+		sum[i] += contextVector[i]
 	}
 }
 
-func (c *Cbow) updateContext(context int, sum, pool, wordVector []float64) {
+func (c *Cbow) updateContext(context int, sum, pool []float64, wordVector *matrix) {
+	if isMasked(c.masked, context) {
+		return
+	}
+	contextVector := wordVector.row(context)
 	for i := 0; i < c.dimension; i++ {
-		wordVector[context*c.dimension+i] += pool[i]
+		contextVector[i] += pool[i]
 	}
 }
+
+func (c *Cbow) loss(document []int, wordIndex int, wordVector []float64, optimizer Optimizer) (float64, int) {
+	sum := <-c.sums
+	defer func() {
+		c.sums <- sum
+	}()
+	for i := range sum {
+		sum[i] = 0.0
+	}
+	word, vectors := document[wordIndex], newMatrix(wordVector, c.dimension)
+	var n int
+	for ci := wordIndex - c.window; ci <= wordIndex+c.window; ci++ {
+		if ci == wordIndex || ci < 0 || ci >= len(document) || !inStride(ci-wordIndex, c.stride) {
+			continue
+		}
+		context := document[ci]
+		if c.excludeSelf && context == word {
+			continue
+		}
+		c.initSum(context, sum, nil, vectors)
+		n++
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	if c.mean {
+		for i := range sum {
+			sum[i] /= float64(n)
+		}
+	}
+	return optimizer.loss(word, sum), 1
+}
+
+func (c *Cbow) freezeInput() {
+	c.frozen = true
+}
+
+func (c *Cbow) maskInput(masked []bool) {
+	c.masked = masked
+}
@@ -0,0 +1,80 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+import (
+	"testing"
+
+	"github.com/ynqa/wego/corpus"
+	"github.com/ynqa/wego/model"
+)
+
+// recordingOptimizer records the hidden layer and the words to predict, and propagates the constant gradient
+// back to the hidden layer.
+type recordingOptimizer struct {
+	hidden []float64
+	words  []int
+}
+
+func (r *recordingOptimizer) initialize(cps *corpus.Word2vecCorpus, dimension int) error { return nil }
+
+func (r *recordingOptimizer) update(word int, lr float64, vector, poolVector []float64, rnd *model.Random) {
+	r.hidden = append([]float64(nil), vector...)
+	r.words = append(r.words, word)
+	for i := range poolVector {
+		poolVector[i] += lr * vector[i]
+	}
+}
+
+func (r *recordingOptimizer) freezeContext(*model.Random) {}
+
+func (r *recordingOptimizer) loss(int, []float64) float64 {
+	return 0
+}
+
+func (r *recordingOptimizer) context() []float64 { return nil }
+
+func (r *recordingOptimizer) withContext(context *matrix) Optimizer { return r }
+
+func TestCbowMean(t *testing.T) {
+	trainOne := func(mean bool) ([]float64, []float64) {
+		// window=1 never shrinks, so that the target at index 1 has context words at index 0 and 2.
+		document := []int{0, 1, 2}
+		wordVector := []float64{1, 2, 0, 0, 3, 4}
+		opt := &recordingOptimizer{}
+		NewCbow(2, 1, 1).Mean(mean).trainOne(document, 1, newMatrix(wordVector, 2), 1.0, opt, model.NewRandom(1))
+		return opt.hidden, wordVector
+	}
+
+	sumHidden, sumVector := trainOne(false)
+	meanHidden, meanVector := trainOne(true)
+
+	for i, expected := range []float64{4, 6} {
+		if sumHidden[i] != expected {
+			t.Errorf("Expected the hidden layer is the sum of context vectors: %v", sumHidden)
+		}
+		if meanHidden[i] != expected/2 {
+			t.Errorf("Expected the hidden layer is the average of context vectors: %v", meanHidden)
+		}
+	}
+	// the gradient to context vectors is proportional to the hidden layer, so its scale halves by mean.
+	for _, i := range []int{0, 1, 4, 5} {
+		sumGrad := sumVector[i] - []float64{1, 2, 0, 0, 3, 4}[i]
+		meanGrad := meanVector[i] - []float64{1, 2, 0, 0, 3, 4}[i]
+		if sumGrad != 2*meanGrad {
+			t.Errorf("Expected the gradient by sum is twice of that by mean: %v, %v", sumGrad, meanGrad)
+		}
+	}
+}
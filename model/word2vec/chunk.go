@@ -0,0 +1,98 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"gopkg.in/cheggaaa/pb.v1"
+
+	"github.com/ynqa/wego/corpus"
+	"github.com/ynqa/wego/model"
+)
+
+// TrainChunks trains words' vector for an iteration on the chunks read by r, e.g. streaming corpus whose sentence
+// boundaries are unreliable, instead of the document parsed on creation. Each word is trained on once as a target,
+// while the words in the overlap of chunks give context to the words near the edges.
+// The words not in vocabulary, or filtered out as rare words, are skipped.
+func (w *Word2vec) TrainChunks(r *corpus.ChunkReader) error {
+	if err := w.guard.Begin(); err != nil {
+		return err
+	}
+	defer w.guard.End()
+
+	var mu sync.Mutex
+	var readErr error
+	next := func() *part {
+		mu.Lock()
+		defer mu.Unlock()
+		if readErr != nil {
+			return nil
+		}
+		chunk, err := r.Read()
+		if err != nil {
+			if err != io.EOF {
+				readErr = err
+			}
+			return nil
+		}
+		p := &part{
+			document: make([]int, 0, len(chunk.Words)),
+		}
+		for i, word := range chunk.Words {
+			if i == chunk.From {
+				p.from = len(p.document)
+			}
+			if i == chunk.To {
+				p.to = len(p.document)
+			}
+			if id, ok := w.Lookup(word); ok && w.Kept(id) {
+				p.document = append(p.document, id)
+			}
+		}
+		if chunk.To == len(chunk.Words) {
+			p.to = len(p.document)
+		}
+		return p
+	}
+
+	if w.Config.Verbose {
+		w.progress = pb.New(0).SetWidth(80)
+		w.progress.Start()
+	}
+	atomic.StoreInt64(&w.iterationTokens, 0)
+	atomic.StoreInt64(&w.totalTokens, 0)
+	w.throughput.Start()
+	defer w.throughput.Stop()
+
+	threads := model.MaxThreadSize(w.Config.ThreadSize)
+	semaphore := make(chan struct{}, threads)
+	waitGroup := &sync.WaitGroup{}
+	for j := 0; j < threads; j++ {
+		waitGroup.Add(1)
+		go w.trainPerThread(w.workerRandom(j), next, semaphore, waitGroup)
+	}
+	waitGroup.Wait()
+	if w.Config.Verbose {
+		w.progress.Finish()
+	}
+	if readErr != nil {
+		return errors.Wrap(readErr, "Unable to read chunks")
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+import (
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ynqa/wego/corpus"
+	"github.com/ynqa/wego/model"
+)
+
+// pairModel records the pairs of target and the adjacent context words.
+type pairModel struct {
+	mu    sync.Mutex
+	pairs map[[2]int]bool
+}
+
+func (m *pairModel) trainOne(document []int, wordIndex int, wordVector *matrix, lr float64, optimizer Optimizer,
+	rnd *model.Random) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range []int{wordIndex - 1, wordIndex + 1} {
+		if c >= 0 && c < len(document) {
+			m.pairs[[2]int{document[wordIndex], document[c]}] = true
+		}
+	}
+}
+
+func (m *pairModel) freezeInput() {}
+
+func (m *pairModel) loss([]int, int, []float64, Optimizer) (float64, int) {
+	return 0, 0
+}
+
+func (m *pairModel) maskInput([]bool) {}
+
+func TestTrainChunks(t *testing.T) {
+	text := "a b c d e f"
+	trainChunks := func(overlap int) (*Word2vec, *pairModel) {
+		mod := &pairModel{pairs: make(map[[2]int]bool)}
+		cnf := model.NewConfig(5, 1, 0, 2, 1, 0.025, false, false)
+		w2v, err := NewWord2vec(ioutil.NopCloser(strings.NewReader(text)), cnf, mod, NewNegativeSampling(2),
+			10000, 1.0, 1.0e-4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := corpus.NewChunkReader(strings.NewReader(text), 3, overlap)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w2v.TrainChunks(r); err != nil {
+			t.Fatal(err)
+		}
+		return w2v, mod
+	}
+
+	w2v, mod := trainChunks(1)
+	c, _ := w2v.Lookup("c")
+	d, _ := w2v.Lookup("d")
+	// c and d are at the edges of the chunks "a b c" and "d e f".
+	if !mod.pairs[[2]int{c, d}] || !mod.pairs[[2]int{d, c}] {
+		t.Errorf("Expected c and d to be context of each other across chunks: %v", mod.pairs)
+	}
+	if len(mod.pairs) != 10 {
+		t.Errorf("Expected 10 pairs of adjacent words, as many as without chunks: %v", len(mod.pairs))
+	}
+
+	_, mod = trainChunks(0)
+	if mod.pairs[[2]int{c, d}] || mod.pairs[[2]int{d, c}] {
+		t.Errorf("Expected c and d not to be context of each other without overlap: %v", mod.pairs)
+	}
+}
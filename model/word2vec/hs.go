@@ -17,6 +17,7 @@ package word2vec
 import (
 	"github.com/ynqa/wego/corpus"
 	"github.com/ynqa/wego/corpus/node"
+	"github.com/ynqa/wego/model"
 
 	"github.com/pkg/errors"
 )
@@ -26,6 +27,15 @@ type HierarchicalSoftmax struct {
 	*SigmoidTable
 	nodeMap  map[int]*node.Node
 	maxDepth int
+	frozen   bool
+
+	// codes of words on the binary tree given instead of huffman tree, see corpus.ReadTree.
+	tree map[string]string
+
+	// vectors of the inner nodes on huffman tree as a matrix, and the path from root to each word on it.
+	relayVector []float64
+	relayRows   *matrix
+	paths       [][]relayPoint
 
 	dimension  int
 	vocabulary int
@@ -39,23 +49,71 @@ func NewHierarchicalSoftmax(maxDepth int) *HierarchicalSoftmax {
 	return hs
 }
 
+// NewHierarchicalSoftmaxWithTree creates *HierarchicalSoftmax over the binary tree given by codes of words,
+// e.g. Brown clusters or WordNet, instead of huffman tree. See corpus.ReadTree for tree.
+func NewHierarchicalSoftmaxWithTree(maxDepth int, tree map[string]string) *HierarchicalSoftmax {
+	hs := NewHierarchicalSoftmax(maxDepth)
+	hs.tree = tree
+	return hs
+}
+
 func (hs *HierarchicalSoftmax) initialize(cps *corpus.Word2vecCorpus, dimension int) error {
-	nodeMap, err := cps.HuffmanTree(dimension)
+	var nodeMap map[int]*node.Node
+	var err error
+	if hs.tree != nil {
+		nodeMap, err = cps.CodeTree(hs.tree, dimension)
+	} else {
+		nodeMap, err = cps.HuffmanTree(dimension)
+	}
 	if err != nil {
 		return errors.Wrap(err, "Failed to initialize of *HierarchicalSoftmax")
 	}
 	hs.nodeMap = nodeMap
 	hs.dimension = dimension
 	hs.vocabulary = cps.Size()
+	hs.flatten()
 	return nil
 }
 
-func (hs *HierarchicalSoftmax) update(word int, lr float64, vector, poolVector []float64) {
-	path := hs.nodeMap[word].GetPath()
-	for p := 0; p < len(path)-1; p++ {
-		relayPoint := path[p]
-		childCode := path[p+1].Code
-		hs.gradUpd(childCode, lr, relayPoint.Vector, vector, poolVector)
+// relayPoint is the inner node on the path to a word, with the row of its vector and the code of the next node.
+type relayPoint struct {
+	row       int
+	childCode int
+}
+
+// flatten numbers the inner nodes in order of word ids and their paths, and stores their vectors as a matrix,
+// so that the matrix is copied and merged row by row like the other vectors.
+func (hs *HierarchicalSoftmax) flatten() {
+	rows := make(map[*node.Node]int)
+	hs.paths = make([][]relayPoint, hs.vocabulary)
+	for word := 0; word < hs.vocabulary; word++ {
+		path := hs.nodeMap[word].GetPath()
+		points := make([]relayPoint, len(path)-1)
+		for p := 0; p < len(path)-1; p++ {
+			row, ok := rows[path[p]]
+			if !ok {
+				row = len(rows)
+				rows[path[p]] = row
+			}
+			points[p] = relayPoint{
+				row:       row,
+				childCode: path[p+1].Code,
+			}
+		}
+		hs.paths[word] = points
+	}
+	hs.relayVector = make([]float64, len(rows)*hs.dimension)
+	hs.relayRows = newMatrix(hs.relayVector, hs.dimension)
+	for n, row := range rows {
+		copy(hs.relayVector[row*hs.dimension:(row+1)*hs.dimension], n.Vector)
+		n.Vector = hs.relayVector[row*hs.dimension : (row+1)*hs.dimension]
+	}
+}
+
+func (hs *HierarchicalSoftmax) update(word int, lr float64, vector, poolVector []float64, rnd *model.Random) {
+	for p, point := range hs.paths[word] {
+		relayPointVec := hs.relayRows.row(point.row)
+		hs.gradUpd(point.childCode, lr, relayPointVec, vector, poolVector)
 		if hs.maxDepth > 0 && p >= hs.maxDepth {
 			break
 		}
@@ -73,6 +131,46 @@ func (hs *HierarchicalSoftmax) gradUpd(childCode int, lr float64, relayPointVec,
 	g := (1.0 - float64(childCode) - hs.sigmoid(inner)) * lr
 	for i := 0; i < hs.dimension; i++ {
 		poolVector[i] += g * relayPointVec[i]
-		relayPointVec[i] += g * vector[i]
+		if !hs.frozen {
+			relayPointVec[i] += g * vector[i]
+		}
 	}
 }
+
+func (hs *HierarchicalSoftmax) loss(word int, vector []float64) float64 {
+	var nll float64
+	for p, point := range hs.paths[word] {
+		relayPointVec := hs.relayVector[point.row*hs.dimension : (point.row+1)*hs.dimension]
+		var inner float64
+		for i := 0; i < hs.dimension; i++ {
+			inner += vector[i] * relayPointVec[i]
+		}
+		// the probability to take the child of code 0 is sigmoid(inner), and the one of code 1 is sigmoid(-inner).
+		if point.childCode == 1 {
+			inner = -inner
+		}
+		nll += negLogSigmoid(inner)
+		if hs.maxDepth > 0 && p >= hs.maxDepth {
+			break
+		}
+	}
+	return nll
+}
+
+// freezeContext initializes the vectors on huffman tree at random by rnd instead of zeros, and keeps them on training.
+func (hs *HierarchicalSoftmax) freezeContext(rnd *model.Random) {
+	for i := range hs.relayVector {
+		hs.relayVector[i] = (rnd.Float64() - 0.5) / float64(hs.dimension)
+	}
+	hs.frozen = true
+}
+
+func (hs *HierarchicalSoftmax) context() []float64 {
+	return hs.relayVector
+}
+
+func (hs *HierarchicalSoftmax) withContext(context *matrix) Optimizer {
+	replica := *hs
+	replica.relayRows = context
+	return &replica
+}
@@ -42,3 +42,38 @@ func TestHSInit(t *testing.T) {
 			expectedNodeMapSize, len(hs.nodeMap))
 	}
 }
+
+func TestHSInitWithTree(t *testing.T) {
+	// a hand-built tree putting the rarest word a next to the most frequent word c, unlike huffman tree.
+	tree := map[string]string{"a": "00", "c": "01", "b": "1"}
+	hs := NewHierarchicalSoftmaxWithTree(0, tree)
+
+	if err := hs.initialize(corpus.TestWord2vecCorpus, 10); err != nil {
+		t.Fatal(err)
+	}
+	for word, code := range tree {
+		id, _ := corpus.TestWord2vecCorpus.Id(word)
+		points := hs.paths[id]
+		if len(points) != len(code) {
+			t.Fatalf("Expected path to %v with length=%v: %v", word, len(code), points)
+		}
+		for p, point := range points {
+			if point.childCode != int(code[p]-'0') {
+				t.Errorf("Expected code %v to %v: %v", code, word, points)
+			}
+		}
+	}
+	if len(hs.relayVector) != 2*10 {
+		t.Errorf("Expected 2 inner nodes on tree: %v", len(hs.relayVector)/10)
+	}
+
+	w2v := newTestWord2vec(t, NewSkipGram(5, 2, 1), NewHierarchicalSoftmaxWithTree(0, tree))
+	if err := w2v.Train(); err != nil {
+		t.Fatal(err)
+	}
+
+	hs = NewHierarchicalSoftmaxWithTree(0, map[string]string{"a": "0", "b": "1"})
+	if err := hs.initialize(corpus.TestWord2vecCorpus, 10); err == nil {
+		t.Error("Expected to fail initializing with the word not on tree")
+	}
+}
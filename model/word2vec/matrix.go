@@ -0,0 +1,39 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+// matrix is words' vector or context vectors stored in vector as rows of dimension columns,
+// which models and optimizers read and update row by row. The rows of a replica in periodic sync mode
+// are its private copies of the shared ones instead, see localRows.
+type matrix struct {
+	vector    []float64
+	dimension int
+	local     *localRows
+}
+
+func newMatrix(vector []float64, dimension int) *matrix {
+	return &matrix{
+		vector:    vector,
+		dimension: dimension,
+	}
+}
+
+// row returns the row of id to read and update in place.
+func (m *matrix) row(id int) []float64 {
+	if m.local != nil {
+		return m.local.row(m, id)
+	}
+	return m.vector[id*m.dimension : (id+1)*m.dimension]
+}
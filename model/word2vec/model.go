@@ -14,7 +14,29 @@
 
 package word2vec
 
+import (
+	"github.com/ynqa/wego/model"
+)
+
 // Model is the interface to train a word vector.
 type Model interface {
-	trainOne(document []int, wordIndex int, wordVector []float64, lr float64, optimizer Optimizer)
+	trainOne(document []int, wordIndex int, wordVector *matrix, lr float64, optimizer Optimizer,
+		rnd *model.Random)
+	freezeInput()
+	maskInput(masked []bool)
+
+	// loss returns the sum of negative log-likelihood of the predictions for the word at wordIndex over the full
+	// window, and the number of them, without updating anything.
+	loss(document []int, wordIndex int, wordVector []float64, optimizer Optimizer) (float64, int)
+}
+
+// isMasked returns whether the input vector of word is kept on training, where masked is indexed by word id.
+func isMasked(masked []bool, word int) bool {
+	return masked != nil && masked[word]
+}
+
+// inStride returns whether the context word at offset from the target word is sampled with stride,
+// i.e. offset is a multiple of stride. All of context words are sampled if stride is 1.
+func inStride(offset, stride int) bool {
+	return stride <= 1 || offset%stride == 0
 }
@@ -23,7 +23,9 @@ import (
 type NegativeSampling struct {
 	*SigmoidTable
 	contextVector []float64
+	contextRows   *matrix
 	sampleSize    int
+	frozen        bool
 
 	dimension  int
 	vocabulary int
@@ -40,36 +42,32 @@ func NewNegativeSampling(sampleSize int) *NegativeSampling {
 func (ns *NegativeSampling) initialize(cps *corpus.Word2vecCorpus, dimension int) error {
 	ns.vocabulary = cps.Size()
 	ns.dimension = dimension
-	ns.contextVector = make([]float64, ns.vocabulary*ns.dimension)
+	vectorSize, err := model.ElementSize(ns.vocabulary, ns.dimension)
+	if err != nil {
+		return err
+	}
+	ns.contextVector = make([]float64, vectorSize)
+	ns.contextRows = newMatrix(ns.contextVector, ns.dimension)
 	return nil
 }
 
-func (ns *NegativeSampling) update(word int, lr float64, vector, poolVector []float64) {
+func (ns *NegativeSampling) update(word int, lr float64, vector, poolVector []float64, rnd *model.Random) {
 	var label int
 	var sample int
 	var sampleVector []float64
 	for n := -1; n < ns.sampleSize; n++ {
 		if n == -1 {
 			label = 1
-			sampleVector = ns.contextVector[word*ns.dimension : word*ns.dimension+ns.dimension]
+			sampleVector = ns.contextRows.row(word)
 		} else {
 			label = 0
-			sample = model.NextRandom(ns.vocabulary)
-			sampleVector = ns.contextVector[sample*ns.dimension : sample*ns.dimension+ns.dimension]
+			sample = rnd.Next(ns.vocabulary)
 			if word == sample {
 				continue
 			}
+			sampleVector = ns.contextRows.row(sample)
 		}
 		ns.gradUpd(label, lr, sampleVector, vector, poolVector)
-		var index int
-		if n == -1 {
-			index = word
-		} else {
-			index = sample
-		}
-		for i := 0; i < ns.dimension; i++ {
-			ns.contextVector[index*ns.dimension+i] = sampleVector[i]
-		}
 	}
 }
 
@@ -88,6 +86,36 @@ func (ns *NegativeSampling) gradUpd(label int, lr float64, sampledVector, vector
 	}
 	for i := 0; i < ns.dimension; i++ {
 		poolVector[i] += g * sampledVector[i]
-		sampledVector[i] += g * vector[i]
+		if !ns.frozen {
+			sampledVector[i] += g * vector[i]
+		}
+	}
+}
+
+// loss returns negative log-likelihood of the positive pair only, since negative samples are drawn at random.
+func (ns *NegativeSampling) loss(word int, vector []float64) float64 {
+	var inner float64
+	contextVector := ns.contextVector[word*ns.dimension : (word+1)*ns.dimension]
+	for i := 0; i < ns.dimension; i++ {
+		inner += contextVector[i] * vector[i]
+	}
+	return negLogSigmoid(inner)
+}
+
+// freezeContext initializes context vector at random by rnd instead of zeros, and keeps it on training.
+func (ns *NegativeSampling) freezeContext(rnd *model.Random) {
+	for i := range ns.contextVector {
+		ns.contextVector[i] = (rnd.Float64() - 0.5) / float64(ns.dimension)
 	}
+	ns.frozen = true
+}
+
+func (ns *NegativeSampling) context() []float64 {
+	return ns.contextVector
+}
+
+func (ns *NegativeSampling) withContext(context *matrix) Optimizer {
+	replica := *ns
+	replica.contextRows = context
+	return &replica
 }
@@ -16,10 +16,19 @@ package word2vec
 
 import (
 	"github.com/ynqa/wego/corpus"
+	"github.com/ynqa/wego/model"
 )
 
 // Optimizer is the interface to initialize after scanning corpus once, and update the word vector.
 type Optimizer interface {
 	initialize(cps *corpus.Word2vecCorpus, dimension int) error
-	update(word int, lr float64, vector, poolVector []float64)
+	update(word int, lr float64, vector, poolVector []float64, rnd *model.Random)
+	freezeContext(rnd *model.Random)
+	// loss returns negative log-likelihood of word predicted from vector.
+	loss(word int, vector []float64) float64
+
+	// context returns the context vectors held by optimizer as a matrix of dimension columns.
+	context() []float64
+	// withContext returns the copy of optimizer which reads and updates the rows of context instead of its own.
+	withContext(context *matrix) Optimizer
 }
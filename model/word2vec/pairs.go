@@ -0,0 +1,234 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"gopkg.in/cheggaaa/pb.v1"
+
+	"github.com/ynqa/wego/corpus"
+	"github.com/ynqa/wego/model"
+	"github.com/ynqa/wego/vectorio"
+)
+
+// Pairs trains skip-gram with negative sampling directly on weighted pairs of target and context,
+// e.g. (query, clicked item, weight) of clickstream, without sliding windows over sentences, like item2vec.
+// Targets and contexts have their own vocabularies and vectors, and the negative samples are drawn from contexts.
+type Pairs struct {
+	*model.Config
+	*corpus.PairsCorpus
+
+	opt *NegativeSampling
+
+	// number of pairs for a thread to train on before the learning rate is decayed.
+	batchSize int
+
+	// lower limit of learning rate (lr >= initlr * theta).
+	theta float64
+
+	// targets' vector.
+	vector []float64
+
+	// whether Save writes contexts' vector instead of targets' vector.
+	saveContexts bool
+
+	// number of pairs trained over iterations to decay learning rate.
+	trained int64
+
+	// master seed of the run, random generator derived from it for initialization, and the ones for workers.
+	seed       uint64
+	initRandom *model.Random
+	randoms    []*model.Random
+
+	// progress bar.
+	progress *pb.ProgressBar
+
+	// hooks called before and after each iteration.
+	model.Hooks
+
+	// guard against training concurrently.
+	guard model.TrainGuard
+}
+
+// NewPairs creates *Pairs from lines of "target context weight", where weight scales learning rate on the pair.
+// MinCount of config applies to targets and contexts separately, and Window is not used.
+// The learning rate decays per batchSize pairs in the same way as Word2vec.
+func NewPairs(f io.ReadCloser, config *model.Config, opt *NegativeSampling,
+	batchSize int, theta float64) (*Pairs, error) {
+	cps, err := corpus.NewPairsCorpus(f, config.ParseConfig(), config.MinCount)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to generate *Pairs")
+	}
+	// the learning rate would never decay by batches larger than pairs.
+	if total := len(cps.Pairs()); total > 0 && batchSize > total {
+		batchSize = total
+	}
+	p := &Pairs{
+		Config:      config,
+		PairsCorpus: cps,
+
+		opt:       opt,
+		batchSize: batchSize,
+		theta:     theta,
+
+		seed: config.Seed,
+	}
+	if p.seed == 0 {
+		p.seed = model.NewSeed()
+	}
+	p.initRandom = model.NewRandom(p.seed)
+
+	vectorSize, err := model.ElementSize(cps.Targets().Size(), config.Dimension)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to generate *Pairs")
+	}
+	p.vector = make([]float64, vectorSize)
+	for i := range p.vector {
+		p.vector[i] = (p.initRandom.Float64() - 0.5) / float64(config.Dimension)
+	}
+	if err := opt.initialize(cps.Contexts(), config.Dimension); err != nil {
+		return nil, errors.Wrap(err, "Unable to generate *Pairs")
+	}
+	return p, nil
+}
+
+// SaveContexts sets whether Save writes contexts' vector instead of targets' vector.
+func (p *Pairs) SaveContexts(contexts bool) {
+	p.saveContexts = contexts
+}
+
+// Train trains targets' and contexts' vector on pairs.
+func (p *Pairs) Train() error {
+	if err := p.guard.Begin(); err != nil {
+		return err
+	}
+	defer p.guard.End()
+
+	pairs := p.PairsCorpus.Pairs()
+	if len(pairs) == 0 {
+		return errors.New("No pairs for training")
+	}
+
+	threadSize := model.MaxThreadSize(p.Config.ThreadSize)
+	indexPerThread := model.IndexPerThread(threadSize, len(pairs))
+	total := int64(len(pairs)) * int64(p.Config.Iteration)
+	atomic.StoreInt64(&p.trained, 0)
+
+	for i := 1; i <= p.Config.Iteration; i++ {
+		p.RunBefore(i)
+		if p.Config.Verbose {
+			fmt.Printf("%d-th:\n", i)
+			p.progress = pb.New(len(pairs)).SetWidth(80)
+			p.progress.Start()
+		}
+		waitGroup := &sync.WaitGroup{}
+		for j := 0; j < threadSize; j++ {
+			waitGroup.Add(1)
+			go p.trainPerThread(p.workerRandom(j), pairs[indexPerThread[j]:indexPerThread[j+1]], total, waitGroup)
+		}
+		waitGroup.Wait()
+		if p.Config.Verbose {
+			p.progress.Finish()
+		}
+		p.RunAfter(i, p)
+	}
+	return nil
+}
+
+func (p *Pairs) trainPerThread(rnd *model.Random, pairs []corpus.Pair, total int64, waitGroup *sync.WaitGroup) {
+	defer waitGroup.Done()
+
+	dim := p.Config.Dimension
+	pool := make([]float64, dim)
+	// pairs trained by this thread are added to the shared count per batch, same as Word2vec.
+	var pending int64
+	lr := decayLearningRate(p.Config.Initlr, p.theta, atomic.LoadInt64(&p.trained), total, p.batchSize)
+	defer func() {
+		atomic.AddInt64(&p.trained, pending)
+	}()
+	for _, pair := range pairs {
+		if p.Config.Verbose {
+			p.progress.Increment()
+		}
+		for i := range pool {
+			pool[i] = 0.0
+		}
+		target := p.vector[pair.Target*dim : (pair.Target+1)*dim]
+		p.opt.update(pair.Context, lr*pair.Weight, target, pool, rnd)
+		for i := range target {
+			target[i] += pool[i]
+		}
+		if pending++; pending == int64(p.batchSize) {
+			trained := atomic.AddInt64(&p.trained, pending)
+			lr = decayLearningRate(p.Config.Initlr, p.theta, trained, total, p.batchSize)
+			pending = 0
+		}
+	}
+}
+
+// workerRandom returns the random generator of the worker, whose seed is derived from the master seed.
+// It must be called before the worker starts, not concurrently.
+func (p *Pairs) workerRandom(worker int) *model.Random {
+	for len(p.randoms) <= worker {
+		p.randoms = append(p.randoms, model.NewRandom(model.WorkerSeed(p.seed, len(p.randoms))))
+	}
+	return p.randoms[worker]
+}
+
+// Summary returns the master seed of the run and the seeds of the workers derived from it.
+func (p *Pairs) Summary() model.Summary {
+	return model.NewSummary(p.seed, len(p.randoms))
+}
+
+// Save saves targets' vector to outputPath, or contexts' vector if SaveContexts is set.
+func (p *Pairs) Save(outputPath string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0777); err != nil {
+		return err
+	}
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	wr := bufio.NewWriter(file)
+	if err := p.writeVector(wr); err != nil {
+		return err
+	}
+	return wr.Flush()
+}
+
+func (p *Pairs) writeVector(wr io.Writer) error {
+	vocab, vector := p.Targets(), p.vector
+	if p.saveContexts {
+		vocab, vector = p.Contexts(), p.opt.context()
+	}
+	vectors := &vectorio.Vectors{
+		Words:  vocab.Vocabulary(),
+		Vector: make(map[string][]float64, vocab.Size()),
+	}
+	for id, word := range vectors.Words {
+		vectors.Vector[word] = vector[id*p.Config.Dimension : (id+1)*p.Config.Dimension]
+	}
+	return vectorio.WriteFloat(wr, p.Config.OutputFormat, p.Config.FloatFormat(), vectors)
+}
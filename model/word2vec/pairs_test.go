@@ -0,0 +1,91 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ynqa/wego/model"
+)
+
+func newTestPairs(t *testing.T, corpus string) *Pairs {
+	cnf := model.NewConfig(5, 3, 0, 1, 2, 0.025, false, false)
+	cnf.Seed = 1
+	p, err := NewPairs(ioutil.NopCloser(strings.NewReader(corpus)), cnf, NewNegativeSampling(2), 10000, 1.0e-4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestPairsTrain(t *testing.T) {
+	p := newTestPairs(t, "q1 item1 1\nq1 item2 1\nq2 item1 1\nq3 item3 0\n")
+	id, _ := p.Targets().Lookup("q3")
+	initial := append([]float64(nil), p.vector[id*5:(id+1)*5]...)
+
+	if err := p.Train(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p.vector[id*5:(id+1)*5], initial) {
+		t.Errorf("Expected q3 only in pairs of weight 0 to keep its vector: %v, %v", initial, p.vector[id*5:(id+1)*5])
+	}
+	if id, _ := p.Targets().Lookup("q1"); reflect.DeepEqual(p.vector[id*5:(id+1)*5], make([]float64, 5)) {
+		t.Error("Expected q1 to be trained")
+	}
+}
+
+func TestPairsSave(t *testing.T) {
+	p := newTestPairs(t, "q1 item1 1\nq2 item1 1\nq3 item2 1\n")
+	if err := p.Train(); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		contexts bool
+		expected []string
+	}{
+		{contexts: false, expected: []string{"q1", "q2", "q3"}},
+		{contexts: true, expected: []string{"item1", "item2"}},
+	}
+
+	for _, tc := range testCases {
+		p.SaveContexts(tc.contexts)
+		var buf bytes.Buffer
+		if err := p.writeVector(&buf); err != nil {
+			t.Fatal(err)
+		}
+		var words []string
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if fields := strings.Fields(line); len(fields) != 6 {
+				t.Errorf("Expected word and 5 elements of vector: %q", line)
+			}
+			words = append(words, strings.Fields(line)[0])
+		}
+		if !reflect.DeepEqual(words, tc.expected) {
+			t.Errorf("Expected words=%v with contexts=%v: %v", tc.expected, tc.contexts, words)
+		}
+	}
+}
+
+func TestPairsNoPairs(t *testing.T) {
+	p := newTestPairs(t, "")
+	if err := p.Train(); err == nil {
+		t.Error("Expected to fail training without pairs")
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/corpus"
+)
+
+// Perplexity returns perplexity of the model on held-out corpus r, i.e. exp of the average negative log-likelihood
+// of the predictions under the objective of the optimizer, over the full window without subsampling,
+// e.g. to compare runs by a single number. Tokens not in vocabulary, or filtered out as rare words, are skipped.
+// With negative sampling, the likelihood is of the positive pairs only since negative samples are random,
+// so it's comparable between runs of the same optimizer, not between hs and ns.
+func (w *Word2vec) Perplexity(r io.Reader) (float64, error) {
+	var document []int
+	if err := corpus.ScanTokens(r, w.Word2vecCorpus.ParseConfig(), func(word string) {
+		if id, ok := w.Lookup(word); ok && w.Kept(id) {
+			document = append(document, id)
+		}
+	}); err != nil {
+		return 0, errors.Wrap(err, "Unable to read held-out corpus")
+	}
+
+	var nll float64
+	var n int
+	for i := range document {
+		l, k := w.mod.loss(document, i, w.vector, w.opt)
+		nll += l
+		n += k
+	}
+	if n == 0 {
+		return 0, errors.New("No predictions on held-out corpus: at least 2 words in vocabulary are required")
+	}
+	return math.Exp(nll / float64(n)), nil
+}
+
+// Heldout sets held-out corpus r to evaluate after each iteration of Train. Its perplexity is printed with verbose,
+// and reported by the final Progress of each iteration with ProgressPerplexity.
+// It fails if r has no predictions to evaluate, in the same way as Perplexity.
+func (w *Word2vec) Heldout(r io.Reader) error {
+	heldout, err := ioutil.ReadAll(r)
+	if err != nil {
+		return errors.Wrap(err, "Unable to read held-out corpus")
+	}
+	if _, err := w.Perplexity(bytes.NewReader(heldout)); err != nil {
+		return err
+	}
+	w.heldout = heldout
+	return nil
+}
+
+// negLogSigmoid returns -log(sigmoid(x)) without overflow.
+func negLogSigmoid(x float64) float64 {
+	if x > 0 {
+		return math.Log1p(math.Exp(-x))
+	}
+	return -x + math.Log1p(math.Exp(x))
+}
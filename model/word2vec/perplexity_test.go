@@ -0,0 +1,104 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+import (
+	"io/ioutil"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/ynqa/wego/model"
+)
+
+func TestPerplexity(t *testing.T) {
+	// window 1 is not shrunk on training, so that the predictions evaluated are the ones trained on.
+	text := strings.Repeat("the quick brown fox jumps over the lazy dog\n", 20)
+	perplexity := func(iter int, mod Model, opt Optimizer) float64 {
+		f := ioutil.NopCloser(strings.NewReader(text))
+		cnf := model.NewConfig(10, iter, 0, 1, 1, 0.025, false, false)
+		cnf.Seed = 1
+		w2v, err := NewWord2vec(f, cnf, mod, opt, 10000, 1.0, 1.0)
+		if err != nil {
+			t.Fatalf("NewWord2vec: %v", err)
+		}
+		if err := w2v.Train(); err != nil {
+			t.Fatalf("Train: %v", err)
+		}
+		p, err := w2v.Perplexity(strings.NewReader("the quick unknown brown fox jumps over the lazy dog"))
+		if err != nil {
+			t.Fatalf("Perplexity: %v", err)
+		}
+		if math.IsNaN(p) || p < 1 {
+			t.Fatalf("Expected perplexity >= 1, but got %v", p)
+		}
+		return p
+	}
+
+	for _, c := range []struct {
+		name string
+		mod  func() Model
+		opt  func() Optimizer
+	}{
+		{"skip-gram/hs", func() Model { return NewSkipGram(10, 1, 1) }, func() Optimizer { return NewHierarchicalSoftmax(0) }},
+		{"skip-gram/ns", func() Model { return NewSkipGram(10, 1, 1) }, func() Optimizer { return NewNegativeSampling(2) }},
+		{"cbow/hs", func() Model { return NewCbow(10, 1, 1) }, func() Optimizer { return NewHierarchicalSoftmax(0) }},
+		{"cbow/ns", func() Model { return NewCbow(10, 1, 1) }, func() Optimizer { return NewNegativeSampling(2) }},
+	} {
+		short, long := perplexity(1, c.mod(), c.opt()), perplexity(20, c.mod(), c.opt())
+		if long >= short {
+			t.Errorf("%s: Expected perplexity to decrease with more iterations, but got %v (iter=1) -> %v (iter=20)",
+				c.name, short, long)
+		}
+	}
+}
+
+func TestPerplexityNoPrediction(t *testing.T) {
+	f := ioutil.NopCloser(strings.NewReader("a b b c c c c"))
+	cnf := model.NewConfig(5, 1, 0, 1, 2, 0.025, false, false)
+	w2v, err := NewWord2vec(f, cnf, NewSkipGram(5, 2, 1), NewHierarchicalSoftmax(0), 10000, 1.0, 1.0e-4)
+	if err != nil {
+		t.Fatalf("NewWord2vec: %v", err)
+	}
+	if err := w2v.Train(); err != nil {
+		t.Fatalf("Train: %v", err)
+	}
+	if _, err := w2v.Perplexity(strings.NewReader("a x y z")); err == nil {
+		t.Error("Expected error when held-out corpus has only one word in vocabulary")
+	}
+}
+
+func TestHeldout(t *testing.T) {
+	w2v := newTestWord2vec(t, NewSkipGram(5, 2, 1), NewHierarchicalSoftmax(0))
+	if err := w2v.Heldout(strings.NewReader("a x y z")); err == nil {
+		t.Error("Expected error when held-out corpus has only one word in vocabulary")
+	}
+
+	var last model.Progress
+	w2v.OnProgress(func(p model.Progress) { last = p })
+	if err := w2v.Heldout(strings.NewReader("a b c")); err != nil {
+		t.Fatalf("Heldout: %v", err)
+	}
+	if err := w2v.Train(); err != nil {
+		t.Fatalf("Train: %v", err)
+	}
+	expected, err := w2v.Perplexity(strings.NewReader("a b c"))
+	if err != nil {
+		t.Fatalf("Perplexity: %v", err)
+	}
+	if !last.Has(model.ProgressPerplexity) || last.Perplexity != expected {
+		t.Errorf("Expected the final progress to report held-out perplexity %v: %v", expected, last)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+import (
+	"github.com/ynqa/wego/model"
+)
+
+// ShuffleSentences sets whether sentences, i.e. lines of corpus or sentences of word ids, are shuffled
+// every iteration, so that the order of corpus, e.g. sorted by source, doesn't leak into training.
+// The order of each iteration is derived from Seed of model.Config and the iteration,
+// and is reproducible with a fixed seed regardless of the randomness consumed by training before it.
+func (w *Word2vec) ShuffleSentences(shuffle bool) {
+	w.shuffleSentences = shuffle
+}
+
+// iterationSentences returns document and weights of words whose sentences are shuffled for the iteration
+// if shuffleSentences is set, or as they are otherwise, along with the offsets where the sentences begin.
+func (w *Word2vec) iterationSentences(iteration int, document []int,
+	weights []float64) ([]int, []float64, []int) {
+	if !w.shuffleSentences {
+		return document, weights, w.Word2vecCorpus.Sentences()
+	}
+	rnd := model.NewRandom(model.DeriveSeed(w.seed, uint64(iteration)))
+	return shuffleSentences(document, weights, w.Word2vecCorpus.Sentences(), rnd)
+}
+
+// shuffleSentences returns document whose sentences beginning at the offsets are reordered
+// by Fisher-Yates shuffle with rnd, along with weights of words reordered in the same way unless they are nil,
+// and the offsets where the reordered sentences begin. The words before the first sentence stay at the beginning.
+func shuffleSentences(document []int, weights []float64, sentences []int,
+	rnd *model.Random) ([]int, []float64, []int) {
+	if len(sentences) < 2 {
+		return document, weights, sentences
+	}
+	order := make([]int, len(sentences))
+	for i := range order {
+		order[i] = i
+	}
+	for i := len(order) - 1; i > 0; i-- {
+		j := rnd.Next(i + 1)
+		order[i], order[j] = order[j], order[i]
+	}
+
+	shuffled := make([]int, 0, len(document))
+	shuffled = append(shuffled, document[:sentences[0]]...)
+	var shuffledWeights []float64
+	if weights != nil {
+		shuffledWeights = make([]float64, 0, len(weights))
+		shuffledWeights = append(shuffledWeights, weights[:sentences[0]]...)
+	}
+	offsets := make([]int, 0, len(sentences))
+	for _, s := range order {
+		end := len(document)
+		if s+1 < len(sentences) {
+			end = sentences[s+1]
+		}
+		offsets = append(offsets, len(shuffled))
+		shuffled = append(shuffled, document[sentences[s]:end]...)
+		if weights != nil {
+			shuffledWeights = append(shuffledWeights, weights[sentences[s]:end]...)
+		}
+	}
+	return shuffled, shuffledWeights, offsets
+}
@@ -0,0 +1,109 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+import (
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ynqa/wego/model"
+)
+
+func TestShuffleSentences(t *testing.T) {
+	document := []int{9, 0, 1, 2, 3, 4, 5, 6, 7}
+	sentences := []int{1, 3, 4, 7}
+
+	shuffled, _, offsets := shuffleSentences(document, nil, sentences, model.NewRandom(model.DeriveSeed(1, 1)))
+	if again, _, _ := shuffleSentences(document, nil, sentences, model.NewRandom(model.DeriveSeed(1, 1))); !reflect.DeepEqual(shuffled, again) {
+		t.Errorf("Expected the same order with the same seed: %v, %v", shuffled, again)
+	}
+
+	if shuffled[0] != 9 {
+		t.Errorf("Expected the words before the first sentence to stay: %v", shuffled)
+	}
+	got := make([][]int, 0)
+	for i := 1; i < len(shuffled); {
+		var sentence []int
+		switch shuffled[i] {
+		case 0:
+			sentence = shuffled[i : i+2]
+		case 2:
+			sentence = shuffled[i : i+1]
+		case 3:
+			sentence = shuffled[i : i+3]
+		case 6:
+			sentence = shuffled[i : i+2]
+		default:
+			t.Fatalf("Expected sentences to be kept together: %v", shuffled)
+		}
+		got = append(got, sentence)
+		i += len(sentence)
+	}
+	for i, offset := range offsets {
+		if shuffled[offset] != got[i][0] {
+			t.Errorf("Expected the offsets where the shuffled sentences begin: %v, %v", offsets, shuffled)
+			break
+		}
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i][0] < got[j][0] })
+	expected := [][]int{{0, 1}, {2}, {3, 4, 5}, {6, 7}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected sentences %v: %v", expected, got)
+	}
+}
+
+func TestShuffleSentencesPerIteration(t *testing.T) {
+	newWord2vec := func(seed uint64) *Word2vec {
+		f := ioutil.NopCloser(strings.NewReader("a\nb\nc\nd\ne\nf\ng\nh"))
+		cnf := model.NewConfig(5, 1, 0, 1, 2, 0.025, false, false)
+		cnf.Seed = seed
+		w2v, err := NewWord2vec(f, cnf, NewSkipGram(5, 2, 1), NewNegativeSampling(2), 10000, 1.0, 1.0e-4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w2v.ShuffleSentences(true)
+		return w2v
+	}
+	shuffle := func(w2v *Word2vec, iteration int) []int {
+		shuffled, _, _ := w2v.iterationSentences(iteration, w2v.Document(), nil)
+		return shuffled
+	}
+
+	trained := newWord2vec(3)
+	// randomness consumed by training of the first iteration, which varies with its progress.
+	if err := trained.Train(); err != nil {
+		t.Fatal(err)
+	}
+	expected := shuffle(newWord2vec(3), 2)
+	if actual := shuffle(trained, 2); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected the same order of the iteration by the same seed regardless of training before it: "+
+			"%v, %v", expected, actual)
+	}
+
+	differs := false
+	for i := 3; i < 10 && !differs; i++ {
+		differs = !reflect.DeepEqual(expected, shuffle(trained, i))
+	}
+	if !differs {
+		t.Errorf("Expected the orders to differ by iteration: %v", expected)
+	}
+	if reflect.DeepEqual(expected, shuffle(newWord2vec(4), 2)) && reflect.DeepEqual(shuffle(newWord2vec(3), 3),
+		shuffle(newWord2vec(4), 3)) {
+		t.Errorf("Expected the orders to differ by seed: %v", expected)
+	}
+}
@@ -22,12 +22,18 @@ import (
 type SkipGram struct {
 	pools chan []float64
 
-	dimension int
-	window    int
+	dimension   int
+	window      int
+	stride      int
+	excludeSelf bool
+	swap        bool
+	frozen      bool
+	masked      []bool
 }
 
 // NewSkipGram creates *SkipGram
 func NewSkipGram(dimension, window, threadSize int) *SkipGram {
+	threadSize = model.MaxThreadSize(threadSize)
 	pools := make(chan []float64, threadSize)
 	for i := 0; i < threadSize; i++ {
 		pools <- make([]float64, dimension)
@@ -37,15 +43,40 @@ func NewSkipGram(dimension, window, threadSize int) *SkipGram {
 
 		dimension: dimension,
 		window:    window,
+		stride:    1,
 	}
 }
 
-func (s *SkipGram) trainOne(document []int, wordIndex int, wordVector []float64, lr float64, optimizer Optimizer) {
+// SwapRoles sets whether the roles of the target word and its context are swapped.
+// By default, like the original word2vec, the vector of each context word is the input to predict the target word.
+// If swap is true, the vector of the target word is the input to predict each context word instead,
+// i.e. the transpose of the default. Either way, words' vector are the input side of the prediction.
+func (s *SkipGram) SwapRoles(swap bool) *SkipGram {
+	s.swap = swap
+	return s
+}
+
+// WindowStride sets the stride to sample context words within the window, e.g. stride=2 samples every other word,
+// i.e. the words at distance 2, 4, ... from the target word. All of words in the window are sampled by default.
+func (s *SkipGram) WindowStride(stride int) *SkipGram {
+	s.stride = stride
+	return s
+}
+
+// ExcludeSelf sets whether the other occurrences of the target word in the window are excluded from its context.
+// They count as context by default, like the original word2vec.
+func (s *SkipGram) ExcludeSelf(excludeSelf bool) *SkipGram {
+	s.excludeSelf = excludeSelf
+	return s
+}
+
+func (s *SkipGram) trainOne(document []int, wordIndex int, wordVector *matrix, lr float64, optimizer Optimizer,
+	rnd *model.Random) {
 	pool := <-s.pools
 	word := document[wordIndex]
-	shrinkage := model.NextRandom(s.window)
+	shrinkage := rnd.Next(s.window)
 	for a := shrinkage; a < s.window*2+1-shrinkage; a++ {
-		if a == s.window {
+		if a == s.window || !inStride(a-s.window, s.stride) {
 			continue
 		}
 		c := wordIndex - s.window + a
@@ -53,13 +84,54 @@ func (s *SkipGram) trainOne(document []int, wordIndex int, wordVector []float64,
 			continue
 		}
 		context := document[c]
+		if s.excludeSelf && context == word {
+			continue
+		}
+		input, output := context, word
+		if s.swap {
+			input, output = word, context
+		}
 		for i := 0; i < s.dimension; i++ {
 			pool[i] = 0.0
 		}
-		optimizer.update(word, lr, wordVector[context*s.dimension:context*s.dimension+s.dimension], pool)
+		inputVector := wordVector.row(input)
+		optimizer.update(output, lr, inputVector, pool, rnd)
+		if s.frozen || isMasked(s.masked, input) {
+			continue
+		}
 		for i := 0; i < s.dimension; i++ {
-			wordVector[context*s.dimension+i] += pool[i]
+			inputVector[i] += pool[i]
 		}
 	}
 	s.pools <- pool
 }
+
+func (s *SkipGram) loss(document []int, wordIndex int, wordVector []float64, optimizer Optimizer) (float64, int) {
+	word := document[wordIndex]
+	var nll float64
+	var n int
+	for c := wordIndex - s.window; c <= wordIndex+s.window; c++ {
+		if c == wordIndex || c < 0 || c >= len(document) || !inStride(c-wordIndex, s.stride) {
+			continue
+		}
+		context := document[c]
+		if s.excludeSelf && context == word {
+			continue
+		}
+		input, output := context, word
+		if s.swap {
+			input, output = word, context
+		}
+		nll += optimizer.loss(output, wordVector[input*s.dimension:(input+1)*s.dimension])
+		n++
+	}
+	return nll, n
+}
+
+func (s *SkipGram) freezeInput() {
+	s.frozen = true
+}
+
+func (s *SkipGram) maskInput(masked []bool) {
+	s.masked = masked
+}
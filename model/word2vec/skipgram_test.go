@@ -0,0 +1,176 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ynqa/wego/corpus"
+	"github.com/ynqa/wego/model"
+)
+
+func trainSelfContext(mod Model) (changed bool) {
+	// The document "a a a", where all context words are the target word itself.
+	document := []int{0, 0, 0}
+	dimension := 5
+	wordVector := []float64{0.1, 0.2, 0.3, 0.4, 0.5}
+	before := append([]float64(nil), wordVector...)
+
+	opt := NewHierarchicalSoftmax(0)
+	opt.initialize(corpus.TestWord2vecCorpus, dimension)
+	for i := range document {
+		mod.trainOne(document, i, newMatrix(wordVector, dimension), 0.025, opt, model.NewRandom(1))
+	}
+	for i := range before {
+		if before[i] != wordVector[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSelfContext(t *testing.T) {
+	testCases := []struct {
+		name        string
+		mod         Model
+		expectedUpd bool
+	}{
+		{"skip-gram", NewSkipGram(5, 2, 1), true},
+		{"skip-gram with excludeSelf", NewSkipGram(5, 2, 1).ExcludeSelf(true), false},
+		{"cbow with excludeSelf", NewCbow(5, 2, 1).ExcludeSelf(true), false},
+	}
+
+	for _, testCase := range testCases {
+		if actual := trainSelfContext(testCase.mod); actual != testCase.expectedUpd {
+			t.Errorf("Expected %v updates word vector by self context=%v: %v",
+				testCase.name, testCase.expectedUpd, actual)
+		}
+	}
+}
+
+func TestSwapRoles(t *testing.T) {
+	// With only the positive pairs and no shrinkage of window, the swapped mode is the standard one
+	// with words' and contexts' vector exchanged.
+	document := []int{0, 1, 2, 3, 1, 0, 2, 2, 3}
+	dimension, vocabulary := 3, 4
+	words := []float64{0.1, -0.2, 0.3, 0.4, 0.5, -0.6, -0.7, 0.8, 0.9, 0.15, -0.25, 0.35}
+	contexts := []float64{-0.3, 0.2, 0.1, 0.6, -0.5, 0.4, 0.9, 0.7, -0.8, -0.35, 0.45, 0.05}
+
+	train := func(mod *SkipGram, wordVector, contextVector []float64) {
+		opt := NewNegativeSampling(0)
+		opt.dimension, opt.vocabulary = dimension, vocabulary
+		opt.contextVector = contextVector
+		opt.contextRows = newMatrix(contextVector, dimension)
+		for iter := 0; iter < 10; iter++ {
+			for i := range document {
+				mod.trainOne(document, i, newMatrix(wordVector, dimension), 0.1, opt, model.NewRandom(1))
+			}
+		}
+	}
+
+	standardWords := append([]float64(nil), words...)
+	standardContexts := append([]float64(nil), contexts...)
+	train(NewSkipGram(dimension, 1, 1), standardWords, standardContexts)
+
+	swappedWords := append([]float64(nil), contexts...)
+	swappedContexts := append([]float64(nil), words...)
+	train(NewSkipGram(dimension, 1, 1).SwapRoles(true), swappedWords, swappedContexts)
+
+	for i := range words {
+		if standardWords[i] == words[i] {
+			t.Fatalf("Expected words' vector to be trained: %v", standardWords)
+		}
+		if standardWords[i] != swappedContexts[i] || standardContexts[i] != swappedWords[i] {
+			t.Fatalf("Expected swapped mode to exchange words' and contexts' vector of standard mode: "+
+				"%v, %v vs %v, %v", standardWords, standardContexts, swappedContexts, swappedWords)
+		}
+	}
+}
+
+func TestWindowStride(t *testing.T) {
+	// Word ids are the positions in document, so that the target word at index 4 has context word c at offset c-4.
+	document := []int{0, 1, 2, 3, 4, 5, 6, 7, 8}
+	offsets := func(mod Model, dimension int, contexts func(opt *recordingOptimizer) []int) []int {
+		wordVector := make([]float64, len(document)*dimension)
+		for i := 0; i < len(document) && dimension == len(document); i++ {
+			wordVector[i*dimension+i] = 1
+		}
+		seen := make(map[int]bool)
+		rnd := model.NewRandom(1)
+		for n := 0; n < 100; n++ {
+			opt := &recordingOptimizer{}
+			mod.trainOne(document, 4, newMatrix(wordVector, dimension), 0, opt, rnd)
+			for _, c := range contexts(opt) {
+				seen[c-4] = true
+			}
+		}
+		res := make([]int, 0, len(seen))
+		for offset := range seen {
+			res = append(res, offset)
+		}
+		sort.Ints(res)
+		return res
+	}
+	// skip-gram with swapped roles predicts each context word.
+	skipGramContexts := func(opt *recordingOptimizer) []int {
+		return opt.words
+	}
+	// the hidden layer of cbow is the sum of one-hot vectors of context words.
+	cbowContexts := func(opt *recordingOptimizer) []int {
+		contexts := make([]int, 0)
+		for c, v := range opt.hidden {
+			if v != 0 {
+				contexts = append(contexts, c)
+			}
+		}
+		return contexts
+	}
+
+	testCases := []struct {
+		name     string
+		actual   []int
+		expected []int
+	}{
+		{
+			name:     "skip-gram",
+			actual:   offsets(NewSkipGram(1, 4, 1).SwapRoles(true), 1, skipGramContexts),
+			expected: []int{-4, -3, -2, -1, 1, 2, 3, 4},
+		},
+		{
+			name:     "skip-gram with stride=2",
+			actual:   offsets(NewSkipGram(1, 4, 1).SwapRoles(true).WindowStride(2), 1, skipGramContexts),
+			expected: []int{-4, -2, 2, 4},
+		},
+		{
+			name:     "cbow with stride=2",
+			actual:   offsets(NewCbow(len(document), 4, 1).Mean(false).WindowStride(2), len(document), cbowContexts),
+			expected: []int{-4, -2, 2, 4},
+		},
+		{
+			name:     "cbow with stride=3",
+			actual:   offsets(NewCbow(len(document), 4, 1).Mean(false).WindowStride(3), len(document), cbowContexts),
+			expected: []int{-3, 3},
+		},
+	}
+
+	for _, testCase := range testCases {
+		if !reflect.DeepEqual(testCase.actual, testCase.expected) {
+			t.Errorf("Expected %v to pair the target word with context words at offsets %v: %v",
+				testCase.name, testCase.expected, testCase.actual)
+		}
+	}
+}
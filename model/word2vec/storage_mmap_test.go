@@ -0,0 +1,141 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package word2vec
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ynqa/wego/model"
+)
+
+func TestUseStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	train := func(storage func(size, dimension int) (model.Storage, error)) []float64 {
+		f := ioutil.NopCloser(strings.NewReader("a b b c c c c d d d"))
+		cnf := model.NewConfig(5, 3, 0, 1, 2, 0.025, false, false)
+		cnf.Seed = 1
+		w2v, err := NewWord2vec(f, cnf, NewSkipGram(5, 2, 1), NewNegativeSampling(2), 10000, 1.0, 1.0e-4)
+		if err != nil {
+			t.Fatalf("NewWord2vec: %v", err)
+		}
+		if storage != nil {
+			s, err := storage(w2v.Word2vecCorpus.Size(), 5)
+			if err != nil {
+				t.Fatalf("Unable to create storage: %v", err)
+			}
+			defer s.Close()
+			if err := w2v.UseStorage(s); err != nil {
+				t.Fatalf("UseStorage: %v", err)
+			}
+		}
+		if err := w2v.Train(); err != nil {
+			t.Fatalf("Train: %v", err)
+		}
+		return append([]float64(nil), w2v.vector...)
+	}
+
+	expected := train(nil)
+	memory := train(func(size, dimension int) (model.Storage, error) {
+		return model.NewMemoryStorage(size, dimension)
+	})
+	mmap := train(func(size, dimension int) (model.Storage, error) {
+		return model.NewMmapStorage(filepath.Join(dir, "vectors"), size, dimension)
+	})
+	if !reflect.DeepEqual(memory, expected) || !reflect.DeepEqual(mmap, expected) {
+		t.Errorf("Expected the same vectors on storages: %v, but got %v (memory), %v (mmap)", expected, memory, mmap)
+	}
+
+	f := ioutil.NopCloser(strings.NewReader("a b b c c c c d d d"))
+	cnf := model.NewConfig(5, 3, 0, 1, 2, 0.025, false, false)
+	w2v, err := NewWord2vec(f, cnf, NewSkipGram(5, 2, 1), NewNegativeSampling(2), 10000, 1.0, 1.0e-4)
+	if err != nil {
+		t.Fatalf("NewWord2vec: %v", err)
+	}
+	small, _ := model.NewMemoryStorage(1, 5)
+	if err := w2v.UseStorage(small); err == nil {
+		t.Error("Expected error for storage smaller than vocabulary")
+	}
+}
+
+func TestNewStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "vectors")
+
+	newWord2vec := func(storage func(size, dimension int) (model.Storage, error)) (*Word2vec, error) {
+		f := ioutil.NopCloser(strings.NewReader("a b b c c c c d d d"))
+		cnf := model.NewConfig(5, 3, 0, 1, 2, 0.025, false, false)
+		cnf.Seed = 1
+		cnf.NewStorage = storage
+		return NewWord2vec(f, cnf, NewSkipGram(5, 2, 1), NewNegativeSampling(2), 10000, 1.0, 1.0e-4)
+	}
+
+	memory, err := newWord2vec(nil)
+	if err != nil {
+		t.Fatalf("NewWord2vec: %v", err)
+	}
+	mmap, err := newWord2vec(func(size, dimension int) (model.Storage, error) {
+		return model.NewMmapStorage(path, size, dimension)
+	})
+	if err != nil {
+		t.Fatalf("NewWord2vec on storage: %v", err)
+	}
+	if !reflect.DeepEqual(mmap.vector, memory.vector) {
+		t.Errorf("Expected the same initial vectors on storage: %v, but got %v", memory.vector, mmap.vector)
+	}
+	if err := memory.Train(); err != nil {
+		t.Fatalf("Train: %v", err)
+	}
+	if err := mmap.Train(); err != nil {
+		t.Fatalf("Train on storage: %v", err)
+	}
+	if !reflect.DeepEqual(mmap.vector, memory.vector) {
+		t.Errorf("Expected the same vectors on storage: %v, but got %v", memory.vector, mmap.vector)
+	}
+	if err := mmap.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	s, err := model.NewMmapStorage(path, memory.Word2vecCorpus.Size(), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if !reflect.DeepEqual(s.Matrix(), memory.vector) {
+		t.Errorf("Expected the file to keep the trained vectors: %v, but got %v", memory.vector, s.Matrix())
+	}
+
+	if _, err := newWord2vec(func(size, dimension int) (model.Storage, error) {
+		return model.NewMemoryStorage(1, dimension)
+	}); err == nil {
+		t.Error("Expected error for storage smaller than vocabulary")
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// stripeSize is the number of locks shared by the rows of matrices on merging replicas.
+	stripeSize = 64
+	// defaultSyncInterval is the number of sentences for each thread to merge its updates in periodic sync mode.
+	defaultSyncInterval = 10
+)
+
+// replica is the rows of words' vector and context vectors touched by a thread in periodic sync mode,
+// and the optimizer which reads and updates the context vectors of the replica.
+type replica struct {
+	vector, context *matrix
+	opt             Optimizer
+
+	// number of sentences the thread has begun since the last merge.
+	sentences int
+}
+
+func (w *Word2vec) newReplica() *replica {
+	r := &replica{
+		vector: &matrix{
+			vector:    w.vector,
+			dimension: w.Config.Dimension,
+			local:     newLocalRows(w.stripes),
+		},
+		context: &matrix{
+			vector:    w.opt.context(),
+			dimension: w.Config.Dimension,
+			local:     newLocalRows(w.stripes),
+		},
+	}
+	r.opt = w.opt.withContext(r.context)
+	return r
+}
+
+// beginSentence counts the sentence which the thread begins to train on,
+// and merges the replica before it once the thread has trained on syncInterval sentences since the last merge.
+func (w *Word2vec) beginSentence(r *replica) {
+	if r.sentences == w.syncInterval {
+		w.merge(r)
+		r.sentences = 0
+	}
+	r.sentences++
+}
+
+// merge adds the updates of the replica since the last merge into the shared matrices.
+func (w *Word2vec) merge(r *replica) {
+	r.vector.merge()
+	r.context.merge()
+}
+
+// localRows is the private copies of the rows of a matrix touched by a thread since the last merge.
+// Each row is followed by the values of the shared one at the time of copying,
+// so that the difference between them is the update by the thread.
+type localRows struct {
+	stripes []sync.Mutex
+	index   map[int]int
+	ids     []int
+	rows    [][]float64
+	// rows released by the last merge, which are reused for the rows touched next.
+	spare [][]float64
+}
+
+func newLocalRows(stripes []sync.Mutex) *localRows {
+	return &localRows{
+		stripes: stripes,
+		index:   make(map[int]int),
+	}
+}
+
+// row returns the copy of the row of id in m, which is copied from the shared one on the first touch
+// since the last merge.
+func (l *localRows) row(m *matrix, id int) []float64 {
+	dim := m.dimension
+	if i, ok := l.index[id]; ok {
+		return l.rows[i][:dim]
+	}
+	var r []float64
+	if n := len(l.spare); n > 0 {
+		r, l.spare = l.spare[n-1], l.spare[:n-1]
+	} else {
+		r = make([]float64, 2*dim)
+	}
+	stripe := &l.stripes[id%stripeSize]
+	stripe.Lock()
+	copy(r[:dim], m.vector[id*dim:(id+1)*dim])
+	stripe.Unlock()
+	copy(r[dim:], r[:dim])
+	l.index[id] = len(l.rows)
+	l.ids = append(l.ids, id)
+	l.rows = append(l.rows, r)
+	return r[:dim]
+}
+
+// merge adds the updates of the touched rows into the shared ones, and releases them,
+// so that they are copied again including the updates by the other threads when they are touched next.
+func (m *matrix) merge() {
+	l, dim := m.local, m.dimension
+	for i, id := range l.ids {
+		local, base := l.rows[i][:dim], l.rows[i][dim:]
+		shared := m.vector[id*dim : (id+1)*dim]
+		stripe := &l.stripes[id%stripeSize]
+		stripe.Lock()
+		for k := range shared {
+			if local[k] == base[k] {
+				continue
+			}
+			// assign the value as it is if no other thread has updated it, which keeps a single thread exact.
+			if shared[k] == base[k] {
+				shared[k] = local[k]
+			} else {
+				shared[k] += local[k] - base[k]
+			}
+		}
+		stripe.Unlock()
+		delete(l.index, id)
+	}
+	l.spare = append(l.spare, l.rows...)
+	l.ids, l.rows = l.ids[:0], l.rows[:0]
+}
+
+// SyncMode sets how threads update the shared vectors. One of: hogwild|periodic
+// With hogwild, threads update them in place without locking. With periodic, each thread trains its own copies
+// of the rows it touches, and merges the updates into the shared ones under striped locks every SyncInterval
+// sentences, which bounds staleness instead of racing on every update, e.g. for small corpora with many threads.
+func (w *Word2vec) SyncMode(mode string) error {
+	switch mode {
+	case "hogwild":
+	case "periodic":
+		if len(w.others) > 0 {
+			return errors.New("Periodic sync mode is not available with AlsoTrain")
+		}
+		w.stripes = make([]sync.Mutex, stripeSize)
+	default:
+		return errors.Errorf("Invalid sync mode: %s not in hogwild|periodic", mode)
+	}
+	w.syncMode = mode
+	return nil
+}
+
+// SyncInterval sets the number of sentences for each thread to merge its updates in periodic sync mode,
+// i.e. lines of corpus or sentences of word ids. Each chunk counts as a sentence in TrainChunks.
+// It is 10 by default.
+func (w *Word2vec) SyncInterval(sentences int) error {
+	if sentences <= 0 {
+		return errors.Errorf("Invalid sync interval: %d must be positive", sentences)
+	}
+	w.syncInterval = sentences
+	return nil
+}
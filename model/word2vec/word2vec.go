@@ -20,19 +20,25 @@ import (
 	"fmt"
 	"io"
 	"math"
-	"math/rand"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/pkg/errors"
 	"gopkg.in/cheggaaa/pb.v1"
 
 	"github.com/ynqa/wego/corpus"
 	"github.com/ynqa/wego/model"
+	"github.com/ynqa/wego/vectorio"
 )
 
+// freezeSeedKey derives the seeds to initialize frozen context vectors from the master seed,
+// apart from the ones to shuffle sentences keyed by iterations from 1.
+const freezeSeedKey = 0
+
 // Word2vec stores the configs for Word2vec models.
 type Word2vec struct {
 	*model.Config
@@ -41,34 +47,116 @@ type Word2vec struct {
 	mod Model
 	opt Optimizer
 
+	// models trained along with mod on the same pass of corpus.
+	others []*attached
+
+	// which vectors are trained, the other is frozen. One of: input|context, or empty to train both.
+	trainOnly string
+
+	// whether words' vector of each word id is kept on training, or nil if no words are masked.
+	masked []bool
+
+	// how threads update the shared vectors, interval of sentences to merge them in periodic mode, and locks for it.
+	syncMode     string
+	syncInterval int
+	stripes      []sync.Mutex
+
+	// whether sentences are shuffled every iteration.
+	shuffleSentences bool
+
+	// weights of words in document given by the weights of their sentences, or nil if corpus has no weights.
+	weights []float64
+
 	// given parameters.
 	batchSize          int
 	subsampleThreshold float64
 	subSamples         []float64
 	theta              float64
 
-	// words' vector.
-	vector []float64
+	// words' vector, and the storage created for them by Config.NewStorage if any.
+	vector  []float64
+	storage model.Storage
+
+	// tracker of words' vector per iteration.
+	tracker *model.Tracker
+
+	// number of words trained by all threads, which decays the learning rate.
+	trainedWordCount int64
+
+	// number of tokens reserved in the current iteration, shared by threads to stop at MaxTokens.
+	// It is counted only if MaxTokens is positive.
+	iterationTokens int64
 
-	// manage learning rate.
-	currentlr        float64
-	trained          chan struct{}
-	trainedWordCount int
+	// limit of tokens to train on across all iterations of Train, no limit if it is not positive,
+	// and number of tokens reserved so far, which is counted only if the limit is positive.
+	maxTotalTokens int64
+	totalTokens    int64
 
-	// manage data range per thread.
+	// tokens trained per second across calls of Train and TrainChunks.
+	throughput model.Throughput
+
+	// held-out corpus to evaluate after each iteration of Train, and the perplexity on it of the last iteration.
+	heldout           []byte
+	heldoutPerplexity float64
+
+	// number of threads of the last Train, which is chosen in its first iteration if Config.ThreadSize is not
+	// positive, and data range per thread.
+	threadSize     int
 	indexPerThread []int
 
+	// master seed of the run, random generator derived from it for initialization, and the ones for workers
+	// indexed by worker id, which are kept over iterations.
+	seed       uint64
+	initRandom *model.Random
+	randoms    []*model.Random
+
 	// progress bar.
 	progress *pb.ProgressBar
+
+	// callback of progress, and number of positions of words processed in the current iteration for it.
+	onProgress model.ProgressFunc
+	processed  int64
+
+	// hooks called before and after each iteration.
+	model.Hooks
+
+	// guard against training concurrently.
+	guard model.TrainGuard
 }
 
 // NewWord2vec creates *Word2Vec.
 func NewWord2vec(f io.ReadCloser, config *model.Config, mod Model, opt Optimizer,
 	batchSize int, subsampleThreshold, theta float64) (*Word2vec, error) {
-	cps, err := corpus.NewWord2vecCorpus(f, config.ToLower, config.MinCount)
+	cps, err := corpus.NewWord2vecCorpus(f, config.ParseConfig(), config.MinCount)
 	if err != nil {
 		return nil, errors.Wrap(err, "Unable to generate *Word2vec")
 	}
+	if config.Verbose && cps.InvalidUTF8Lines() > 0 {
+		fmt.Printf("Sanitized invalid UTF-8 in %d lines\n", cps.InvalidUTF8Lines())
+	}
+	return newWord2vec(cps, config, mod, opt, batchSize, subsampleThreshold, theta)
+}
+
+// NewWord2vecFromIDs creates *Word2vec from sentences of word ids instead of text, e.g. streamed from a tokenizer
+// in another service. See corpus.NewWord2vecCorpusFromIDs for dictionary and sentences.
+func NewWord2vecFromIDs(dictionary []string, sentences corpus.SentenceReader, config *model.Config,
+	mod Model, opt Optimizer, batchSize int, subsampleThreshold, theta float64) (*Word2vec, error) {
+	cps, err := corpus.NewWord2vecCorpusFromIDs(dictionary, sentences, config.ParseConfig(), config.MinCount)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to generate *Word2vec")
+	}
+	return newWord2vec(cps, config, mod, opt, batchSize, subsampleThreshold, theta)
+}
+
+func newWord2vec(cps *corpus.Word2vecCorpus, config *model.Config, mod Model, opt Optimizer,
+	batchSize int, subsampleThreshold, theta float64) (*Word2vec, error) {
+	// the learning rate would never decay by batches larger than corpus.
+	if total := cps.TotalFreq(); total > 0 && batchSize > total {
+		if config.Verbose {
+			fmt.Printf("Batch size %d is capped to %d tokens of corpus\n", batchSize, total)
+		}
+		batchSize = total
+	}
 	word2vec := &Word2vec{
 		Config:         config,
 		Word2vecCorpus: cps,
@@ -80,69 +168,513 @@ func NewWord2vec(f io.ReadCloser, config *model.Config, mod Model, opt Optimizer
 		batchSize:          batchSize,
 		theta:              theta,
 
-		currentlr: config.Initlr,
-		trained:   make(chan struct{}),
+		syncInterval: defaultSyncInterval,
+
+		seed: config.Seed,
+	}
+	if word2vec.seed == 0 {
+		word2vec.seed = model.NewSeed()
+	}
+	word2vec.initRandom = model.NewRandom(word2vec.seed)
+	if err := word2vec.initialize(); err != nil {
+		word2vec.Close()
+		return nil, errors.Wrap(err, "Unable to generate *Word2vec")
 	}
-	word2vec.initialize()
 	return word2vec, nil
 }
 
-func (w *Word2vec) initialize() {
-	// Store subsumple before training.
-	w.subSamples = make([]float64, w.Word2vecCorpus.Size())
-	for i := 0; i < w.Word2vecCorpus.Size(); i++ {
-		z := float64(w.Word2vecCorpus.IDFreq(i)) / float64(w.Word2vecCorpus.TotalFreq())
-		w.subSamples[i] = (math.Sqrt(z/w.subsampleThreshold) + 1.0) *
-			w.subsampleThreshold / z
+// attached is a pair of model and optimizer trained along with the main one.
+type attached struct {
+	name   string
+	mod    Model
+	opt    Optimizer
+	vector []float64
+}
+
+func (w *Word2vec) initialize() error {
+	// Store subsumple before training, unless subsampling is disabled by non-positive threshold.
+	if w.subsampleThreshold > 0 {
+		w.subSamples = make([]float64, w.Word2vecCorpus.Size())
+		for i := 0; i < w.Word2vecCorpus.Size(); i++ {
+			z := float64(w.Word2vecCorpus.IDFreq(i)) / float64(w.Word2vecCorpus.TotalFreq())
+			w.subSamples[i] = (math.Sqrt(z/w.subsampleThreshold) + 1.0) *
+				w.subsampleThreshold / z
+		}
+	}
+
+	// Expand weights of sentences to words.
+	if sentenceWeights := w.Word2vecCorpus.SentenceWeights(); sentenceWeights != nil {
+		sentences, document := w.Word2vecCorpus.Sentences(), w.Word2vecCorpus.Document()
+		w.weights = make([]float64, len(document))
+		for i := 0; i < len(document) && (len(sentences) == 0 || i < sentences[0]); i++ {
+			w.weights[i] = 1
+		}
+		for s, weight := range sentenceWeights {
+			end := len(document)
+			if s+1 < len(sentences) {
+				end = sentences[s+1]
+			}
+			for i := sentences[s]; i < end; i++ {
+				w.weights[i] = weight
+			}
+		}
 	}
 
-	// Initialize word vector.
-	vectorSize := w.Word2vecCorpus.Size() * w.Config.Dimension
-	w.vector = make([]float64, vectorSize)
-	for i := 0; i < vectorSize; i++ {
-		w.vector[i] = (rand.Float64() - 0.5) / float64(w.Config.Dimension)
+	// Initialize word vector, on the storage given by Config if any.
+	if w.Config.NewStorage != nil {
+		if err := w.newStorage(); err != nil {
+			return err
+		}
+	} else {
+		vector, err := w.newVector()
+		if err != nil {
+			return err
+		}
+		w.vector = vector
 	}
 
 	// Initialize optimizer.
-	w.opt.initialize(w.Word2vecCorpus, w.Config.Dimension)
+	return w.opt.initialize(w.Word2vecCorpus, w.Config.Dimension)
+}
+
+func (w *Word2vec) newVector() ([]float64, error) {
+	vectorSize, err := model.ElementSize(w.Word2vecCorpus.Size(), w.Config.Dimension)
+	if err != nil {
+		return nil, err
+	}
+	vector := make([]float64, vectorSize)
+	w.randomize(vector)
+	return vector, nil
+}
+
+// newStorage creates the storage of words' vector by Config.NewStorage, and initializes them on it
+// in the same way as newVector, without allocating them in memory.
+func (w *Word2vec) newStorage() error {
+	s, err := w.Config.NewStorage(w.Word2vecCorpus.Size(), w.Config.Dimension)
+	if err != nil {
+		return err
+	}
+	if err := w.validateStorage(s); err != nil {
+		s.Close()
+		return err
+	}
+	w.randomize(s.Matrix())
+	w.vector, w.storage = s.Matrix(), s
+	return nil
+}
+
+func (w *Word2vec) randomize(vector []float64) {
+	for i := range vector {
+		vector[i] = (w.initRandom.Float64() - 0.5) / float64(w.Config.Dimension)
+	}
+}
+
+func (w *Word2vec) validateStorage(s model.Storage) error {
+	if len(s.Matrix()) != w.Word2vecCorpus.Size()*w.Config.Dimension {
+		return errors.Errorf("Size of storage %d is not equal to %d words of dimension %d",
+			len(s.Matrix()), w.Word2vecCorpus.Size(), w.Config.Dimension)
+	}
+	return nil
+}
+
+// AlsoTrain attaches another pair of model and optimizer, which is trained on the same pass of corpus
+// with its own words' vector. The sampled words and learning rate are shared with the main model.
+// The attached words' vector is saved by SaveAs with the name.
+func (w *Word2vec) AlsoTrain(name string, mod Model, opt Optimizer) error {
+	if w.syncMode == "periodic" {
+		return errors.New("AlsoTrain is not available with periodic sync mode")
+	}
+	for _, o := range w.others {
+		if o.name == name {
+			return errors.Errorf("%s is already attached", name)
+		}
+	}
+	vector, err := w.newVector()
+	if err != nil {
+		return errors.Wrapf(err, "Unable to attach %s", name)
+	}
+	if err := opt.initialize(w.Word2vecCorpus, w.Config.Dimension); err != nil {
+		return errors.Wrapf(err, "Unable to attach %s", name)
+	}
+	w.freeze(mod, opt, len(w.others)+1)
+	mod.maskInput(w.masked)
+	w.others = append(w.others, &attached{
+		name:   name,
+		mod:    mod,
+		opt:    opt,
+		vector: vector,
+	})
+	return nil
+}
+
+// Norm returns L2 norm of the vector for word, and whether the word is in vocabulary or not.
+func (w *Word2vec) Norm(word string) (float64, bool) {
+	id, ok := w.Lookup(word)
+	if !ok {
+		return 0, false
+	}
+	return vectorio.Norm(w.vector[id*w.Config.Dimension : (id+1)*w.Config.Dimension]), true
+}
+
+// Vector returns a copy of the vector for word, and whether the word is in vocabulary or not.
+func (w *Word2vec) Vector(word string) ([]float64, bool) {
+	id, ok := w.Lookup(word)
+	if !ok {
+		return nil, false
+	}
+	vec := make([]float64, w.Config.Dimension)
+	copy(vec, w.vector[id*w.Config.Dimension:(id+1)*w.Config.Dimension])
+	return vec, true
+}
+
+// Matrix returns words' vector as a flat matrix in row-major order of word ids without copying, e.g. to feed into
+// BLAS, whose row i of cols elements is the vector of the word of id i. The matrix is shared with the model:
+// writes to it change the vectors trained, queried and saved from then on, and it must not be accessed during
+// Train, which updates it in place. It is the matrix of the storage given by Config or UseStorage if any.
+func (w *Word2vec) Matrix() (data []float64, rows int, cols int) {
+	return w.vector, w.Word2vecCorpus.Size(), w.Config.Dimension
+}
+
+// LoadPretrained overwrites words' vector with pretrained vectors for words in vocabulary,
+// and returns the number of words overwritten. The other words keep random initial vectors.
+func (w *Word2vec) LoadPretrained(vectors *vectorio.Vectors) (int, error) {
+	if dim := vectors.Dimension(); dim != w.Config.Dimension {
+		return 0, errors.Errorf("Dimension of pretrained vectors %d is not equal to %d", dim, w.Config.Dimension)
+	}
+	var loaded int
+	for _, word := range vectors.Words {
+		id, ok := w.Lookup(word)
+		if !ok {
+			continue
+		}
+		copy(w.vector[id*w.Config.Dimension:(id+1)*w.Config.Dimension], vectors.Vector[word])
+		loaded++
+	}
+	return loaded, nil
+}
+
+// UseStorage moves words' vector into s, e.g. model.MmapStorage for the vectors of large vocabulary,
+// and trains, queries and saves them on it from then on. s must hold the vectors of all words in vocabulary,
+// and isn't closed by Word2vec, so close it after saving. The vectors are allocated in memory until they are moved,
+// unlike the storage given by NewStorage of model.Config. The context vectors held by optimizer stay in memory.
+func (w *Word2vec) UseStorage(s model.Storage) error {
+	if err := w.validateStorage(s); err != nil {
+		return err
+	}
+	for id := 0; id < w.Word2vecCorpus.Size(); id++ {
+		s.Set(id, w.vector[id*w.Config.Dimension:(id+1)*w.Config.Dimension])
+	}
+	w.vector = s.Matrix()
+	return nil
+}
+
+// Close closes the storage of words' vector created by NewStorage of model.Config, e.g. after saving them.
+// It does nothing if there is no such storage.
+func (w *Word2vec) Close() error {
+	if w.storage == nil {
+		return nil
+	}
+	return w.storage.Close()
+}
+
+// TrainOnly trains only one side of vectors and freezes the other. One of: input|context
+// With context, words' vector is kept as it is, e.g. given by LoadPretrained.
+// With input, the context vectors held by optimizer are initialized at random and kept.
+func (w *Word2vec) TrainOnly(target string) error {
+	switch target {
+	case "input", "context":
+	default:
+		return errors.Errorf("Invalid trainOnly: %s not in input|context", target)
+	}
+	if w.trainOnly != "" {
+		return errors.Errorf("trainOnly is already set to %s", w.trainOnly)
+	}
+	w.trainOnly = target
+	w.freeze(w.mod, w.opt, 0)
+	for i, o := range w.others {
+		w.freeze(o.mod, o.opt, i+1)
+	}
+	return nil
+}
+
+// freeze freezes either side of vectors of the index-th pair of model and optimizer, i.e. 0 for the main one and
+// the others from 1, whose context vectors are initialized at random derived from the master seed and index.
+func (w *Word2vec) freeze(mod Model, opt Optimizer, index int) {
+	switch w.trainOnly {
+	case "input":
+		opt.freezeContext(model.NewRandom(model.DeriveSeed(w.seed, freezeSeedKey, uint64(index))))
+	case "context":
+		mod.freezeInput()
+	}
+}
+
+// Mask keeps words' vector of words on training, e.g. curated embeddings given by LoadPretrained,
+// and returns the words not in vocabulary. Unlike TrainOnly, the other words are trained as usual,
+// and the masked words still serve as context for them.
+func (w *Word2vec) Mask(words []string) []string {
+	ids, missing := w.WordIDs(words)
+	if w.masked == nil {
+		w.masked = make([]bool, w.Word2vecCorpus.Size())
+	}
+	for _, id := range ids {
+		w.masked[id] = true
+	}
+	w.mod.maskInput(w.masked)
+	for _, o := range w.others {
+		o.mod.maskInput(w.masked)
+	}
+	return missing
+}
+
+// MaxTotalTokens sets limit of tokens to train on across all iterations of Train, e.g. for benchmarks independent
+// of the size of corpus, no limit if n is not positive. Training stops cleanly once it is reached, skipping the rest
+// of the iteration and the remaining iterations. MaxTokens of Config still limits each iteration.
+func (w *Word2vec) MaxTotalTokens(n int64) {
+	w.maxTotalTokens = n
+}
+
+// reachedMaxTotalTokens returns whether the tokens trained on have reached MaxTotalTokens.
+func (w *Word2vec) reachedMaxTotalTokens() bool {
+	return w.maxTotalTokens > 0 && atomic.LoadInt64(&w.totalTokens) >= w.maxTotalTokens
+}
+
+// reserveTokens reserves up to n tokens for a thread to train on under MaxTokens and MaxTotalTokens,
+// and returns the number of tokens reserved, which is 0 once either limit has been reached.
+func (w *Word2vec) reserveTokens(n int64) int64 {
+	if w.Config.MaxTokens > 0 {
+		n = reserve(&w.iterationTokens, w.Config.MaxTokens, n)
+	}
+	if w.maxTotalTokens > 0 {
+		reserved := reserve(&w.totalTokens, w.maxTotalTokens, n)
+		if w.Config.MaxTokens > 0 {
+			atomic.AddInt64(&w.iterationTokens, reserved-n)
+		}
+		n = reserved
+	}
+	return n
+}
+
+// releaseTokens gives back n tokens reserved by reserveTokens but not trained on.
+func (w *Word2vec) releaseTokens(n int64) {
+	if n == 0 {
+		return
+	}
+	if w.Config.MaxTokens > 0 {
+		atomic.AddInt64(&w.iterationTokens, -n)
+	}
+	if w.maxTotalTokens > 0 {
+		atomic.AddInt64(&w.totalTokens, -n)
+	}
+}
+
+// reserve adds up to n to counter without exceeding limit, and returns the amount added.
+func reserve(counter *int64, limit, n int64) int64 {
+	for {
+		current := atomic.LoadInt64(counter)
+		if current >= limit {
+			return 0
+		}
+		if n > limit-current {
+			n = limit - current
+		}
+		if atomic.CompareAndSwapInt64(counter, current, current+n) {
+			return n
+		}
+	}
+}
+
+// OnProgress sets fn to be called with progress of training, i.e. positions of words processed in each iteration
+// and learning rate. Cost is not reported.
+func (w *Word2vec) OnProgress(fn model.ProgressFunc) {
+	w.onProgress = fn
+}
+
+// reportProgress starts to report progress of the iteration with total positions if OnProgress is set,
+// and returns the function to stop it.
+func (w *Word2vec) reportProgress(iteration, total int) func() {
+	if w.onProgress == nil {
+		return func() {}
+	}
+	atomic.StoreInt64(&w.processed, 0)
+	return model.ReportProgress(w.onProgress, func(final bool) model.Progress {
+		p := model.Progress{
+			Iteration:    iteration,
+			Done:         int(atomic.LoadInt64(&w.processed)),
+			Total:        total,
+			LearningRate: w.learningRate(atomic.LoadInt64(&w.trainedWordCount)),
+			Valid:        model.ProgressLearningRate,
+		}
+		if final {
+			dim := w.Config.Dimension
+			p.Norms = model.EstimateNormPercentiles(w.Word2vecCorpus.Size(), func(id int) []float64 {
+				return w.vector[id*dim : (id+1)*dim]
+			})
+			p.Valid |= model.ProgressNorms
+			if w.heldout != nil {
+				p.Perplexity = w.heldoutPerplexity
+				p.Valid |= model.ProgressPerplexity
+			}
+		}
+		return p
+	})
+}
+
+// Throughput returns tokens trained per second averaged over the time spent in Train and TrainChunks so far,
+// e.g. to compare hardware and settings. It is safe to call during training, when threads add their tokens
+// per batch, and is 0 before training.
+func (w *Word2vec) Throughput() float64 {
+	return w.throughput.Rate()
+}
+
+// TrackWords appends the vectors of words into JSONL file on path after each iteration of Train,
+// and returns the words not in vocabulary, which are not tracked.
+func (w *Word2vec) TrackWords(words []string, path string) ([]string, error) {
+	ids, missing := w.WordIDs(words)
+	tracked := make([]string, len(ids))
+	for i, id := range ids {
+		tracked[i], _ = w.Word(id)
+	}
+	tracker, err := model.NewTracker(ids, tracked, path)
+	if err != nil {
+		return nil, err
+	}
+	w.tracker = tracker
+	return missing, nil
 }
 
 // Train trains words' vector on corpus.
 func (w *Word2vec) Train() error {
+	if err := w.guard.Begin(); err != nil {
+		return err
+	}
+	defer w.guard.End()
+
 	document := w.Word2vecCorpus.Document()
 	documentSize := len(document)
 	if documentSize <= 0 {
 		return errors.New("No words for training")
 	}
 
-	w.indexPerThread = model.IndexPerThread(w.Config.ThreadSize, documentSize)
+	w.threadSize = w.Config.ThreadSize
+	auto := w.threadSize <= 0
+	if !auto {
+		w.indexPerThread = model.IndexPerThread(w.threadSize, documentSize)
+	}
+
+	atomic.StoreInt64(&w.totalTokens, 0)
+	w.throughput.Start()
+	defer w.throughput.Stop()
 
-	for i := 1; i <= w.Config.Iteration; i++ {
+	for i := 1; i <= w.Config.Iteration && !w.reachedMaxTotalTokens(); i++ {
+		w.RunBefore(i)
 		if w.Config.Verbose {
 			fmt.Printf("%d-th:\n", i)
 			w.progress = pb.New(documentSize).SetWidth(80)
 			w.progress.Start()
 		}
-		go w.observeLearningRate()
-
-		semaphore := make(chan struct{}, w.Config.ThreadSize)
-		waitGroup := &sync.WaitGroup{}
-
-		for j := 0; j < w.Config.ThreadSize; j++ {
-			waitGroup.Add(1)
-			go w.trainPerThread(document[w.indexPerThread[j]:w.indexPerThread[j+1]], w.mod.trainOne,
-				semaphore, waitGroup)
+		atomic.StoreInt64(&w.iterationTokens, 0)
+		stopProgress := w.reportProgress(i, documentSize)
+		iterationDocument, iterationWeights, iterationSentences := w.iterationSentences(i, document, w.weights)
+
+		if auto {
+			w.threadSize = w.trainAuto(iterationDocument, iterationWeights, iterationSentences)
+			w.indexPerThread = model.IndexPerThread(w.threadSize, documentSize)
+		} else {
+			semaphore := make(chan struct{}, w.threadSize)
+			waitGroup := &sync.WaitGroup{}
+
+			for j := 0; j < w.threadSize; j++ {
+				waitGroup.Add(1)
+				from, to := w.indexPerThread[j], w.indexPerThread[j+1]
+				go w.trainPerThread(w.workerRandom(j),
+					once(iterationDocument[from:to], weightsOf(iterationWeights, from, to),
+						sentencesOf(iterationSentences, from, to)), semaphore, waitGroup)
+			}
+			waitGroup.Wait()
+		}
+		if w.heldout != nil {
+			// the held-out corpus is validated by Heldout, and reading it again never fails.
+			w.heldoutPerplexity, _ = w.Perplexity(bytes.NewReader(w.heldout))
 		}
-		waitGroup.Wait()
+		stopProgress()
 		if w.Config.Verbose {
 			w.progress.Finish()
+			if w.heldout != nil {
+				fmt.Printf("Held-out perplexity: %v\n", w.heldoutPerplexity)
+			}
+		}
+		if auto && w.Config.Verbose {
+			fmt.Printf("Auto thread size: %d\n", w.threadSize)
+		}
+		auto = false
+		if w.tracker != nil {
+			if err := w.tracker.Track(i, func(id int) []float64 {
+				return w.vector[id*w.Config.Dimension : (id+1)*w.Config.Dimension]
+			}); err != nil {
+				return err
+			}
 		}
+		w.RunAfter(i, w)
+	}
+	if w.tracker != nil {
+		return w.tracker.Close()
 	}
 	return nil
 }
 
-func (w *Word2vec) trainPerThread(document []int,
-	trainOne func(wordIDs []int, wordIndex int, wordVector []float64, lr float64, optimizer Optimizer),
+// part is the part of document for a thread to train on. The words in [from, to) are the targets,
+// and the others only give context to them. weights scale learning rate for each word, or are nil.
+// sentences are the offsets in document where sentences begin, or nil if the part counts as a sentence.
+type part struct {
+	document  []int
+	weights   []float64
+	sentences []int
+	from, to  int
+}
+
+// weightsOf returns weights[from:to], or nil if weights is nil.
+func weightsOf(weights []float64, from, to int) []float64 {
+	if weights == nil {
+		return nil
+	}
+	return weights[from:to]
+}
+
+// sentencesOf returns the offsets of sentences beginning in [from, to) relative to from,
+// or nil if sentences is nil.
+func sentencesOf(sentences []int, from, to int) []int {
+	if sentences == nil {
+		return nil
+	}
+	begin, end := sort.SearchInts(sentences, from), sort.SearchInts(sentences, to)
+	offsets := make([]int, end-begin)
+	for i, s := range sentences[begin:end] {
+		offsets[i] = s - from
+	}
+	return offsets
+}
+
+// workerRandom returns the random generator of the worker, whose seed is derived from the master seed.
+// It must be called before the worker starts, not concurrently.
+func (w *Word2vec) workerRandom(worker int) *model.Random {
+	for len(w.randoms) <= worker {
+		w.randoms = append(w.randoms, model.NewRandom(model.WorkerSeed(w.seed, len(w.randoms))))
+	}
+	return w.randoms[worker]
+}
+
+// Summary returns the master seed of the run and the seeds of the workers derived from it,
+// e.g. to repeat the run with Config.Seed under a single thread for debugging, and the number of threads
+// of the last Train. The workers may outnumber the threads if the thread size is chosen automatically.
+func (w *Word2vec) Summary() model.Summary {
+	s := model.NewSummary(w.seed, len(w.randoms))
+	s.ThreadSize = w.threadSize
+	return s
+}
+
+// trainPerThread trains on the parts of document returned by next until it returns nil,
+// drawing randomness from rnd of the worker.
+func (w *Word2vec) trainPerThread(rnd *model.Random, next func() *part,
 	semaphore chan struct{}, waitGroup *sync.WaitGroup) {
 
 	defer func() {
@@ -151,35 +683,116 @@ func (w *Word2vec) trainPerThread(document []int,
 	}()
 
 	semaphore <- struct{}{}
-	for idx, wordID := range document {
-		if w.Config.Verbose {
-			w.progress.Increment()
+	vector, opt := newMatrix(w.vector, w.Config.Dimension), w.opt
+	var rep *replica
+	if w.syncMode == "periodic" {
+		rep = w.newReplica()
+		vector, opt = rep.vector, rep.opt
+	}
+	others := make([]*matrix, len(w.others))
+	for i, o := range w.others {
+		others[i] = newMatrix(o.vector, w.Config.Dimension)
+	}
+	// words trained by this thread are added to the shared count per batch, and so are tokens reserved
+	// under the limits of tokens, if any.
+	var pending, reserved int64
+	limited := w.Config.MaxTokens > 0 || w.maxTotalTokens > 0
+	lr := w.learningRate(atomic.LoadInt64(&w.trainedWordCount))
+	defer func() {
+		atomic.AddInt64(&w.trainedWordCount, pending)
+		w.throughput.Add(pending)
+		w.releaseTokens(reserved)
+	}()
+train:
+	for p := next(); p != nil; p = next() {
+		document := p.document
+		if rep != nil && p.sentences == nil {
+			w.beginSentence(rep)
 		}
+		sentence := sort.SearchInts(p.sentences, p.from)
+		for idx := p.from; idx < p.to; idx++ {
+			if rep != nil && sentence < len(p.sentences) && p.sentences[sentence] == idx {
+				w.beginSentence(rep)
+				sentence++
+			}
+			wordID := document[idx]
+			if w.Config.Verbose {
+				w.progress.Increment()
+			}
+			if w.onProgress != nil {
+				atomic.AddInt64(&w.processed, 1)
+			}
 
-		bernoulliTrial := rand.Float64()
-		p := w.subSamples[wordID]
-		if p < bernoulliTrial {
-			continue
+			if w.subSamples != nil && w.subSamples[wordID] < rnd.Float64() {
+				continue
+			}
+			if limited {
+				if reserved == 0 {
+					// no more than the rest of the part is reserved, which the other threads may need.
+					n := int64(w.batchSize)
+					if rest := int64(p.to - idx); rest < n {
+						n = rest
+					}
+					if reserved = w.reserveTokens(n); reserved == 0 {
+						break train
+					}
+				}
+				reserved--
+			}
+			wordlr := lr
+			if p.weights != nil {
+				wordlr *= p.weights[idx]
+			}
+			w.mod.trainOne(document, idx, vector, wordlr, opt, rnd)
+			for i, o := range w.others {
+				o.mod.trainOne(document, idx, others[i], wordlr, o.opt, rnd)
+			}
+			if pending++; pending == int64(w.batchSize) {
+				lr = w.learningRate(atomic.AddInt64(&w.trainedWordCount, pending))
+				w.throughput.Add(pending)
+				pending = 0
+			}
 		}
-		trainOne(document, idx, w.vector, w.currentlr, w.opt)
-		w.trained <- struct{}{}
+	}
+	if rep != nil {
+		w.merge(rep)
 	}
 }
 
-func (w *Word2vec) observeLearningRate() {
-	for range w.trained {
-		w.trainedWordCount++
-		if w.trainedWordCount%w.batchSize == 0 {
-			w.currentlr = w.Config.Initlr * (1.0 - float64(w.trainedWordCount)/float64(w.TotalFreq()))
-			if w.currentlr < w.Config.Initlr*w.theta {
-				w.currentlr = w.Config.Initlr * w.theta
-			}
-		}
+// learningRate returns the learning rate decayed by the given number of words trained by all threads.
+// The number is rounded down to a multiple of batchSize, so that the learning rate follows the same
+// trajectory regardless of the number of threads.
+func (w *Word2vec) learningRate(trained int64) float64 {
+	return decayLearningRate(w.Config.Initlr, w.theta, trained, int64(w.TotalFreq()), w.batchSize)
+}
+
+// decayLearningRate returns initlr decayed linearly by trained of total, which is bounded by initlr * theta.
+// trained is rounded down to a multiple of batchSize.
+func decayLearningRate(initlr, theta float64, trained, total int64, batchSize int) float64 {
+	trained -= trained % int64(batchSize)
+	lr := initlr * (1.0 - float64(trained)/float64(total))
+	if lr < initlr*theta {
+		lr = initlr * theta
 	}
+	return lr
 }
 
 // Save saves the word vector to outputFile.
 func (w *Word2vec) Save(outputPath string) error {
+	return w.save(outputPath, w.vector)
+}
+
+// SaveAs saves the word vector of the model attached by AlsoTrain with the name to outputFile.
+func (w *Word2vec) SaveAs(name, outputPath string) error {
+	for _, o := range w.others {
+		if o.name == name {
+			return w.save(outputPath, o.vector)
+		}
+	}
+	return errors.Errorf("%s is not attached", name)
+}
+
+func (w *Word2vec) save(outputPath string, vector []float64) error {
 	extractDir := func(path string) string {
 		e := strings.Split(path, "/")
 		return strings.Join(e[:len(e)-1], "/")
@@ -203,17 +816,41 @@ func (w *Word2vec) Save(outputPath string) error {
 		file.Close()
 	}()
 
-	var buf bytes.Buffer
-	for i := 0; i < w.Size(); i++ {
-		word, _ := w.Word(i)
-		fmt.Fprintf(&buf, "%v ", word)
-		for j := 0; j < w.Config.Dimension; j++ {
-			fmt.Fprintf(&buf, "%f ", w.vector[i*w.Config.Dimension+j])
-		}
-		fmt.Fprintln(&buf)
+	return w.writeVector(wr, vector, nil)
+}
+
+// SaveSubset writes the word vector only for words in vocabulary, and returns the words not in vocabulary.
+func (w *Word2vec) SaveSubset(wr io.Writer, words []string) ([]string, error) {
+	ids, missing := w.WordIDs(words)
+	return missing, w.writeVector(wr, w.vector, ids)
+}
+
+// SaveMatching writes the word vector only for words in vocabulary matching the regular expression pattern.
+func (w *Word2vec) SaveMatching(wr io.Writer, pattern string) error {
+	ids, err := w.MatchingIDs(pattern)
+	if err != nil {
+		return err
 	}
+	return w.writeVector(wr, w.vector, ids)
+}
 
-	wr.WriteString(fmt.Sprintf("%v", buf.String()))
+// writeVector writes the vector for ids, or for all words in vocabulary if ids is nil.
+func (w *Word2vec) writeVector(wr io.Writer, vector []float64, ids []int) error {
+	if ids == nil {
+		ids = make([]int, w.Size())
+		for i := range ids {
+			ids[i] = i
+		}
+	}
 
-	return nil
+	vectors := &vectorio.Vectors{
+		Words:  make([]string, len(ids)),
+		Vector: make(map[string][]float64, len(ids)),
+	}
+	for k, i := range ids {
+		word, _ := w.Word(i)
+		vectors.Words[k] = word
+		vectors.Vector[word] = vector[i*w.Config.Dimension : (i+1)*w.Config.Dimension]
+	}
+	return vectorio.WriteFloat(wr, w.Config.OutputFormat, w.Config.FloatFormat(), vectors)
 }
@@ -0,0 +1,1034 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ynqa/wego/corpus"
+	"github.com/ynqa/wego/model"
+	"github.com/ynqa/wego/vectorio"
+)
+
+func newTestWord2vec(t *testing.T, mod Model, opt Optimizer) *Word2vec {
+	f := ioutil.NopCloser(strings.NewReader("a b b c c c c"))
+	cnf := model.NewConfig(5, 3, 0, 1, 2, 0.025, false, false)
+	w2v, err := NewWord2vec(f, cnf, mod, opt, 10000, 1.0, 1.0e-4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w2v
+}
+
+func TestSummary(t *testing.T) {
+	train := func(seed uint64) *Word2vec {
+		f := ioutil.NopCloser(strings.NewReader("a b b c c c c"))
+		cnf := model.NewConfig(5, 3, 0, 1, 2, 0.025, false, false)
+		cnf.Seed = seed
+		w2v, err := NewWord2vec(f, cnf, NewSkipGram(5, 2, 1), NewNegativeSampling(2), 10000, 1.0, 1.0e-4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w2v.Train(); err != nil {
+			t.Fatal(err)
+		}
+		return w2v
+	}
+
+	w1, w2 := train(7), train(7)
+	if !reflect.DeepEqual(w1.Summary(), model.NewSummary(7, 1)) {
+		t.Errorf("Expected summary of seed=7 and the seed of a worker: %v", w1.Summary())
+	}
+	if !reflect.DeepEqual(w1.vector, w2.vector) {
+		t.Errorf("Expected the same vectors with the same seed under a single thread: %v, %v", w1.vector, w2.vector)
+	}
+	if generated := train(0).Summary(); generated.Seed == 0 {
+		t.Errorf("Expected seed to be generated: %v", generated)
+	}
+}
+
+func TestAlsoTrain(t *testing.T) {
+	w2v := newTestWord2vec(t, NewCbow(5, 2, 1), NewHierarchicalSoftmax(0))
+
+	if err := w2v.AlsoTrain("skip-gram-ns", NewSkipGram(5, 2, 1), NewNegativeSampling(2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w2v.AlsoTrain("skip-gram-ns", NewSkipGram(5, 2, 1), NewNegativeSampling(2)); err == nil {
+		t.Error("Expected to fail attaching the same name twice")
+	}
+
+	before := make([]float64, len(w2v.others[0].vector))
+	copy(before, w2v.others[0].vector)
+
+	if err := w2v.Train(); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := false
+	for i := range before {
+		if before[i] != w2v.others[0].vector[i] {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		t.Error("Expected the attached vector is trained on the same pass")
+	}
+
+	var buf bytes.Buffer
+	if err := w2v.writeVector(&buf, w2v.others[0].vector, nil); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != w2v.Size() {
+		t.Errorf("Expected %d lines of the attached vector: %d", w2v.Size(), lines)
+	}
+
+	if err := w2v.SaveAs("cbow-ns", "fake.txt"); err == nil {
+		t.Error("Expected to fail saving the model not attached")
+	}
+}
+
+func TestSaveSubset(t *testing.T) {
+	w2v := newTestWord2vec(t, NewCbow(5, 2, 1), NewHierarchicalSoftmax(0))
+
+	var buf bytes.Buffer
+	missing, err := w2v.SaveSubset(&buf, []string{"c", "a", "z", "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(missing) != 1 || missing[0] != "z" {
+		t.Errorf("Expected missing=[z]: %v", missing)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines to be written: %v", lines)
+	}
+	for i, expected := range []string{"c", "a"} {
+		fields := strings.Fields(lines[i])
+		if fields[0] != expected || len(fields) != 6 {
+			t.Errorf("Expected line %d for %v with 5 values: %v", i, expected, lines[i])
+		}
+	}
+}
+
+func TestSaveMatching(t *testing.T) {
+	f := ioutil.NopCloser(strings.NewReader("a b b c c c c dd"))
+	cnf := model.NewConfig(5, 3, 0, 1, 2, 0.025, false, false)
+	cnf.OutputFormat = vectorio.FormatFastTextVec
+	w2v, err := NewWord2vec(f, cnf, NewCbow(5, 2, 1), NewHierarchicalSoftmax(0), 10000, 1.0, 1.0e-4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := w2v.SaveMatching(&buf, "^[a-c]$"); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || lines[0] != "3 5" {
+		t.Fatalf("Expected header of 3 words and their lines: %v", lines)
+	}
+	for _, line := range lines[1:] {
+		if word := strings.Fields(line)[0]; word == "dd" {
+			t.Errorf("Expected dd not to match: %v", line)
+		}
+	}
+
+	if err := w2v.SaveMatching(&buf, "[a-"); err == nil || !strings.Contains(err.Error(), "Invalid pattern") {
+		t.Errorf("Expected invalid pattern to fail: %v", err)
+	}
+}
+
+func TestSaveVocab(t *testing.T) {
+	f := ioutil.NopCloser(strings.NewReader("a b b c c c c d d d"))
+	cnf := model.NewConfig(5, 3, 0, 1, 2, 0.025, false, false)
+	w2v, err := NewWord2vec(f, cnf, NewCbow(5, 2, 1), NewHierarchicalSoftmax(0), 10000, 1.0, 1.0e-4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var vocab, vectors bytes.Buffer
+	if err := w2v.SaveVocab(&vocab); err != nil {
+		t.Fatal(err)
+	}
+	if err := w2v.writeVector(&vectors, w2v.vector, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(vocab.String()), "\n")
+	if !reflect.DeepEqual(lines, w2v.Vocabulary()) || !reflect.DeepEqual(lines, []string{"c", "d", "b", "a"}) {
+		t.Errorf("Expected lines of Vocabulary() in order of word ids: %v, %v", lines, w2v.Vocabulary())
+	}
+	for i, line := range strings.Split(strings.TrimSpace(vectors.String()), "\n") {
+		if word := strings.Fields(line)[0]; word != lines[i] {
+			t.Errorf("Expected line %d of vocabulary to be zipped with vectors: %v, %v", i, lines[i], word)
+		}
+	}
+}
+
+func TestOutputFormat(t *testing.T) {
+	w2v := newTestWord2vec(t, NewCbow(5, 2, 1), NewHierarchicalSoftmax(0))
+
+	for _, format := range vectorio.Formats {
+		w2v.Config.OutputFormat = format
+		var buf bytes.Buffer
+		if err := w2v.writeVector(&buf, w2v.vector, nil); err != nil {
+			t.Fatalf("Unable to write in %v: %v", format, err)
+		}
+
+		var (
+			rows int
+			err  error
+		)
+		if format == vectorio.FormatNpy {
+			_, rows, _, err = vectorio.ReadNpy(&buf)
+		} else {
+			var v *vectorio.Vectors
+			if v, err = vectorio.Read(&buf, format); err == nil {
+				rows = len(v.Words)
+			}
+		}
+		if err != nil {
+			t.Fatalf("Unable to read %v: %v", format, err)
+		}
+		if rows != w2v.Size() {
+			t.Errorf("Expected %d words in %v: %d", w2v.Size(), format, rows)
+		}
+	}
+}
+
+func TestTrainOnlyContext(t *testing.T) {
+	opt := NewNegativeSampling(2)
+	w2v := newTestWord2vec(t, NewSkipGram(5, 2, 1), opt)
+
+	if err := w2v.TrainOnly("context"); err != nil {
+		t.Fatal(err)
+	}
+	before := make([]float64, len(w2v.vector))
+	copy(before, w2v.vector)
+	contextBefore := make([]float64, len(opt.contextVector))
+	copy(contextBefore, opt.contextVector)
+
+	if err := w2v.Train(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range before {
+		if math.Float64bits(before[i]) != math.Float64bits(w2v.vector[i]) {
+			t.Fatalf("Expected words' vector is frozen at %d: %v -> %v", i, before[i], w2v.vector[i])
+		}
+	}
+	if equalFloat64s(contextBefore, opt.contextVector) {
+		t.Error("Expected context vector is trained")
+	}
+}
+
+func TestTrainOnlyInput(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mod      Model
+		opt      Optimizer
+		snapshot func(Optimizer) []float64
+	}{
+		{
+			name: "ns",
+			mod:  NewSkipGram(5, 2, 1),
+			opt:  NewNegativeSampling(2),
+			snapshot: func(opt Optimizer) []float64 {
+				return append([]float64(nil), opt.(*NegativeSampling).contextVector...)
+			},
+		},
+		{
+			name: "hs",
+			mod:  NewCbow(5, 2, 1),
+			opt:  NewHierarchicalSoftmax(0),
+			snapshot: func(opt Optimizer) []float64 {
+				hs := opt.(*HierarchicalSoftmax)
+				var vec []float64
+				for id := 0; id < hs.vocabulary; id++ {
+					path := hs.nodeMap[id].GetPath()
+					for _, relayPoint := range path[:len(path)-1] {
+						vec = append(vec, relayPoint.Vector...)
+					}
+				}
+				return vec
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		w2v := newTestWord2vec(t, testCase.mod, testCase.opt)
+		if err := w2v.TrainOnly("input"); err != nil {
+			t.Fatal(err)
+		}
+		before := append([]float64(nil), w2v.vector...)
+		contextBefore := testCase.snapshot(testCase.opt)
+
+		if err := w2v.Train(); err != nil {
+			t.Fatal(err)
+		}
+
+		contextAfter := testCase.snapshot(testCase.opt)
+		for i := range contextBefore {
+			if math.Float64bits(contextBefore[i]) != math.Float64bits(contextAfter[i]) {
+				t.Fatalf("Expected context vector of %v is frozen at %d: %v -> %v",
+					testCase.name, i, contextBefore[i], contextAfter[i])
+			}
+		}
+		if equalFloat64s(before, w2v.vector) {
+			t.Errorf("Expected words' vector is trained against frozen context of %v", testCase.name)
+		}
+	}
+}
+
+func TestTrainOnlyInputSeed(t *testing.T) {
+	freeze := func(seed uint64) []float64 {
+		f := ioutil.NopCloser(strings.NewReader("a b b c c c c"))
+		cnf := model.NewConfig(5, 3, 0, 1, 2, 0.025, false, false)
+		cnf.Seed = seed
+		opt := NewNegativeSampling(2)
+		w2v, err := NewWord2vec(f, cnf, NewSkipGram(5, 2, 1), opt, 10000, 1.0, 1.0e-4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w2v.TrainOnly("input"); err != nil {
+			t.Fatal(err)
+		}
+		return append([]float64(nil), opt.context()...)
+	}
+
+	if !equalFloat64s(freeze(3), freeze(3)) {
+		t.Error("Expected the same context vectors frozen by the same seed")
+	}
+	if equalFloat64s(freeze(3), freeze(4)) {
+		t.Error("Expected different context vectors frozen by different seeds")
+	}
+}
+
+func TestInvalidTrainOnly(t *testing.T) {
+	w2v := newTestWord2vec(t, NewCbow(5, 2, 1), NewHierarchicalSoftmax(0))
+
+	if err := w2v.TrainOnly("output"); err == nil {
+		t.Error("Expected to fail freezing except for input|context")
+	}
+	if err := w2v.TrainOnly("input"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w2v.TrainOnly("context"); err == nil {
+		t.Error("Expected to fail freezing both of vectors")
+	}
+}
+
+func TestLoadPretrained(t *testing.T) {
+	w2v := newTestWord2vec(t, NewCbow(5, 2, 1), NewHierarchicalSoftmax(0))
+
+	vectors, err := vectorio.ReadText(strings.NewReader("c 1 2 3 4 5\nz 1 1 1 1 1\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := w2v.LoadPretrained(vectors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded != 1 {
+		t.Errorf("Expected 1 word is loaded: %d", loaded)
+	}
+	id, _ := w2v.Id("c")
+	if !equalFloat64s(w2v.vector[id*5:(id+1)*5], []float64{1, 2, 3, 4, 5}) {
+		t.Errorf("Expected pretrained vector of c: %v", w2v.vector[id*5:(id+1)*5])
+	}
+
+	short, _ := vectorio.ReadText(strings.NewReader("c 1 2\n"))
+	if _, err := w2v.LoadPretrained(short); err == nil {
+		t.Error("Expected to fail loading pretrained vectors with different dimension")
+	}
+}
+
+func equalFloat64s(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSanitizeUTF8(t *testing.T) {
+	f := ioutil.NopCloser(strings.NewReader("a b\xff b\nc c\xfe c c\n"))
+	cnf := model.NewConfig(5, 1, 0, 1, 2, 0.025, false, false)
+	cnf.SanitizeUTF8 = corpus.SanitizeReplace
+	w2v, err := NewWord2vec(f, cnf, NewCbow(5, 2, 1), NewHierarchicalSoftmax(0), 10000, 1.0, 1.0e-4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w2v.Train(); err != nil {
+		t.Fatalf("Expected to train on sanitized corpus: %v", err)
+	}
+	if w2v.InvalidUTF8Lines() != 2 {
+		t.Errorf("Expected 2 lines are sanitized: %d", w2v.InvalidUTF8Lines())
+	}
+}
+
+func TestTrackWords(t *testing.T) {
+	w2v := newTestWord2vec(t, NewCbow(5, 2, 1), NewHierarchicalSoftmax(0))
+
+	f, err := ioutil.TempFile("", "track")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	missing, err := w2v.TrackWords([]string{"c", "z", "a"}, f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 1 || missing[0] != "z" {
+		t.Errorf("Expected z is not tracked: %v", missing)
+	}
+	if err := w2v.Train(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2*w2v.Config.Iteration {
+		t.Fatalf("Expected %d lines for 2 words by %d iterations: %d",
+			2*w2v.Config.Iteration, w2v.Config.Iteration, len(lines))
+	}
+	var last struct {
+		Iteration int       `json:"iteration"`
+		Word      string    `json:"word"`
+		Vector    []float64 `json:"vector"`
+	}
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &last); err != nil {
+		t.Fatal(err)
+	}
+	id, _ := w2v.Id("a")
+	if last.Iteration != w2v.Config.Iteration || last.Word != "a" ||
+		!equalFloat64s(last.Vector, w2v.vector[id*5:(id+1)*5]) {
+		t.Errorf("Expected the last line is the final vector of a: %v", lines[len(lines)-1])
+	}
+}
+
+type countingModel struct {
+	count int64
+}
+
+func (c *countingModel) trainOne(document []int, wordIndex int, wordVector *matrix, lr float64, optimizer Optimizer,
+	rnd *model.Random) {
+	atomic.AddInt64(&c.count, 1)
+}
+
+func (c *countingModel) freezeInput() {}
+
+func (c *countingModel) loss([]int, int, []float64, Optimizer) (float64, int) {
+	return 0, 0
+}
+
+func (c *countingModel) maskInput([]bool) {}
+
+func TestMaxTokens(t *testing.T) {
+	f := ioutil.NopCloser(strings.NewReader("a b b c c c c a b b c c c c"))
+	cnf := model.NewConfig(5, 3, 0, 2, 2, 0.025, false, false)
+	cnf.MaxTokens = 5
+	mod := &countingModel{}
+	w2v, err := NewWord2vec(f, cnf, mod, NewHierarchicalSoftmax(0), 10000, 1.0, 1.0e-4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w2v.Train(); err != nil {
+		t.Fatal(err)
+	}
+	if mod.count != 5*3 {
+		t.Errorf("Expected 5 tokens are trained on per iteration by 2 threads: %d in 3 iterations", mod.count)
+	}
+}
+
+func TestSpecialTokens(t *testing.T) {
+	f := ioutil.NopCloser(strings.NewReader("a b b c c c c </s>"))
+	cnf := model.NewConfig(5, 1, 0, 1, 2, 0.025, false, false)
+	cnf.SpecialTokens = []string{"<pad>", "</s>"}
+	w2v, err := NewWord2vec(f, cnf, NewCbow(5, 2, 1), NewHierarchicalSoftmax(0), 10000, 1.0, 1.0e-4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w2v.Train(); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := w2v.writeVector(&buf, w2v.vector, nil); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[0], "<pad> ") || !strings.HasPrefix(lines[1], "</s> ") {
+		t.Errorf("Expected special tokens at the reserved ids in export: %v", lines)
+	}
+}
+
+func TestMaxTotalTokens(t *testing.T) {
+	f := ioutil.NopCloser(strings.NewReader("a b b c c c c a b b c c c c"))
+	cnf := model.NewConfig(5, 3, 0, 2, 2, 0.025, false, false)
+	cnf.MaxTokens = 5
+	mod := &countingModel{}
+	w2v, err := NewWord2vec(f, cnf, mod, NewHierarchicalSoftmax(0), 10000, 1.0, 1.0e-4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w2v.MaxTotalTokens(7)
+	iterations := 0
+	w2v.BeforeIteration(func(int) { iterations++ })
+	if err := w2v.Train(); err != nil {
+		t.Fatal(err)
+	}
+	if mod.count != 7 || iterations != 2 {
+		t.Errorf("Expected 7 tokens are trained on in 2 iterations of 5 tokens at most: %d in %d iterations",
+			mod.count, iterations)
+	}
+
+	w2v = newTestWord2vec(t, NewCbow(5, 2, 1), NewHierarchicalSoftmax(0))
+	w2v.MaxTotalTokens(3)
+	if err := w2v.Train(); err != nil {
+		t.Fatal(err)
+	}
+	if norm, ok := w2v.Norm("b"); !ok || math.IsNaN(norm) {
+		t.Errorf("Expected usable vector of b after training on 3 tokens: %v, %v", norm, ok)
+	}
+}
+
+func TestReserveTokens(t *testing.T) {
+	w2v := newTestWord2vec(t, NewCbow(5, 2, 1), NewHierarchicalSoftmax(0))
+	if err := w2v.Train(); err != nil {
+		t.Fatal(err)
+	}
+	if w2v.iterationTokens != 0 || w2v.totalTokens != 0 {
+		t.Errorf("Expected no tokens to be counted without limits: %d, %d", w2v.iterationTokens, w2v.totalTokens)
+	}
+
+	w2v.Config.MaxTokens = 5
+	w2v.MaxTotalTokens(7)
+	if n := w2v.reserveTokens(10); n != 5 {
+		t.Errorf("Expected 5 tokens to be reserved up to MaxTokens: %d", n)
+	}
+	w2v.releaseTokens(2)
+	if n := w2v.reserveTokens(10); n != 2 {
+		t.Errorf("Expected the released 2 tokens to be reserved again: %d", n)
+	}
+	w2v.iterationTokens = 0
+	if n := w2v.reserveTokens(10); n != 2 || w2v.iterationTokens != 2 {
+		t.Errorf("Expected 2 tokens to be reserved up to MaxTotalTokens in the next iteration: %d, %d",
+			n, w2v.iterationTokens)
+	}
+	if n := w2v.reserveTokens(10); n != 0 {
+		t.Errorf("Expected no tokens to be reserved after MaxTotalTokens: %d", n)
+	}
+}
+
+func TestThroughput(t *testing.T) {
+	w2v := newTestWord2vec(t, NewSkipGram(5, 2, 1), NewNegativeSampling(2))
+	if rate := w2v.Throughput(); rate != 0 {
+		t.Errorf("Expected no throughput before training: %v", rate)
+	}
+	if err := w2v.Train(); err != nil {
+		t.Fatal(err)
+	}
+	rate := w2v.Throughput()
+	if rate <= 0 || math.IsInf(rate, 0) {
+		t.Errorf("Expected positive throughput after training: %v", rate)
+	}
+	if after := w2v.Throughput(); after != rate {
+		t.Errorf("Expected throughput not to change without training: %v -> %v", rate, after)
+	}
+}
+
+func TestNorm(t *testing.T) {
+	w2v := newTestWord2vec(t, NewCbow(5, 2, 1), NewHierarchicalSoftmax(0))
+
+	id, _ := w2v.Id("b")
+	var sum float64
+	for _, v := range w2v.vector[id*5 : (id+1)*5] {
+		sum += v * v
+	}
+	norm, ok := w2v.Norm("b")
+	if !ok || math.Abs(norm-math.Sqrt(sum)) > 1e-12 {
+		t.Errorf("Expected norm of b=%v: %v, %v", math.Sqrt(sum), norm, ok)
+	}
+	if _, ok := w2v.Norm("z"); ok {
+		t.Error("Expected no norm for the word not in vocabulary")
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	w2v := newTestWord2vec(t, NewCbow(5, 2, 1), NewHierarchicalSoftmax(0))
+
+	data, rows, cols := w2v.Matrix()
+	if rows != w2v.Word2vecCorpus.Size() || cols != 5 || len(data) != rows*cols {
+		t.Fatalf("Expected %vx5 matrix of %v elements: %vx%v, %v", w2v.Word2vecCorpus.Size(),
+			w2v.Word2vecCorpus.Size()*5, rows, cols, len(data))
+	}
+	for i := 0; i < rows; i++ {
+		word, _ := w2v.Word(i)
+		vec, ok := w2v.Vector(word)
+		if !ok || !reflect.DeepEqual(data[i*cols:(i+1)*cols], vec) {
+			t.Errorf("Expected row %v to be the vector of %v=%v: %v", i, word, vec, data[i*cols:(i+1)*cols])
+		}
+	}
+
+	id, _ := w2v.Id("b")
+	for i := range data[id*cols : (id+1)*cols] {
+		data[id*cols+i] = 0
+	}
+	data[id*cols] = 3
+	if norm, _ := w2v.Norm("b"); norm != 3 {
+		t.Errorf("Expected writes to matrix to change the vector of b: norm=%v", norm)
+	}
+	if _, ok := w2v.Vector("z"); ok {
+		t.Error("Expected no vector for the word not in vocabulary")
+	}
+}
+
+func TestSyncMode(t *testing.T) {
+	testCases := []struct {
+		name    string
+		newPair func() (Model, Optimizer)
+	}{
+		{"cbow-hs", func() (Model, Optimizer) { return NewCbow(5, 2, 1), NewHierarchicalSoftmax(0) }},
+		{"skip-gram-ns", func() (Model, Optimizer) { return NewSkipGram(5, 2, 1), NewNegativeSampling(2) }},
+	}
+	// the corpus of sentences to merge the updates several times in an iteration.
+	newWord2vec := func(mod Model, opt Optimizer) *Word2vec {
+		f := ioutil.NopCloser(strings.NewReader("a b\nb c\nc c\nc"))
+		cnf := model.NewConfig(5, 3, 0, 1, 2, 0.025, false, false)
+		w2v, err := NewWord2vec(f, cnf, mod, opt, 10000, 1.0, 1.0e-4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return w2v
+	}
+	for _, testCase := range testCases {
+		mod, opt := testCase.newPair()
+		hogwild := newWord2vec(mod, opt)
+		mod, opt = testCase.newPair()
+		periodic := newWord2vec(mod, opt)
+		copy(periodic.vector, hogwild.vector)
+		if err := periodic.SyncMode("periodic"); err != nil {
+			t.Fatal(err)
+		}
+		if err := periodic.SyncInterval(1); err != nil {
+			t.Fatal(err)
+		}
+
+		hogwild.seed, periodic.seed = 1, 1
+		if err := hogwild.Train(); err != nil {
+			t.Fatal(err)
+		}
+		if err := periodic.Train(); err != nil {
+			t.Fatal(err)
+		}
+		for i := range hogwild.vector {
+			if hogwild.vector[i] != periodic.vector[i] {
+				t.Errorf("Expected periodic sync mode to be equal to hogwild with a single thread in %v: %v, %v",
+					testCase.name, hogwild.vector, periodic.vector)
+				break
+			}
+		}
+		for i := range hogwild.opt.context() {
+			if hogwild.opt.context()[i] != periodic.opt.context()[i] {
+				t.Errorf("Expected context vectors of periodic sync mode to be equal to hogwild in %v",
+					testCase.name)
+				break
+			}
+		}
+	}
+
+	w2v := newTestWord2vec(t, NewSkipGram(5, 2, 2), NewNegativeSampling(2))
+	w2v.Config.ThreadSize = 2
+	if err := w2v.SyncMode("periodic"); err != nil {
+		t.Fatal(err)
+	}
+	before := append([]float64(nil), w2v.vector...)
+	if err := w2v.Train(); err != nil {
+		t.Fatal(err)
+	}
+	changed := false
+	for i := range before {
+		if before[i] != w2v.vector[i] {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		t.Error("Expected the updates of threads to be merged in periodic sync mode")
+	}
+
+	if err := w2v.AlsoTrain("cbow-hs", NewCbow(5, 2, 2), NewHierarchicalSoftmax(0)); err == nil {
+		t.Error("Expected to fail attaching another model in periodic sync mode")
+	}
+	if err := w2v.SyncInterval(0); err == nil {
+		t.Error("Expected to fail setting sync interval not positive")
+	}
+	if err := w2v.SyncMode("locked"); err == nil {
+		t.Error("Expected to fail setting unknown sync mode")
+	}
+}
+
+func TestSyncInterval(t *testing.T) {
+	w2v := newTestWord2vec(t, NewSkipGram(5, 2, 1), NewNegativeSampling(2))
+	if err := w2v.SyncMode("periodic"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w2v.SyncInterval(2); err != nil {
+		t.Fatal(err)
+	}
+	rep := w2v.newReplica()
+	before := append([]float64(nil), w2v.vector...)
+
+	w2v.beginSentence(rep)
+	rep.vector.row(1)[0] += 1
+	w2v.beginSentence(rep)
+	if len(rep.vector.local.ids) != 1 || len(rep.context.local.ids) != 0 {
+		t.Errorf("Expected the replica to keep only the touched row: %v, %v",
+			rep.vector.local.ids, rep.context.local.ids)
+	}
+	if w2v.vector[5] != before[5] {
+		t.Error("Expected the updates not to be merged before 2 sentences are trained")
+	}
+	w2v.beginSentence(rep)
+	if w2v.vector[5] != before[5]+1 {
+		t.Errorf("Expected the updates to be merged after 2 sentences: %v, %v", before[5], w2v.vector[5])
+	}
+	for i := range before {
+		if i != 5 && w2v.vector[i] != before[i] {
+			t.Errorf("Expected the untouched rows to be kept: %v", i)
+			break
+		}
+	}
+	if len(rep.vector.local.ids) != 0 {
+		t.Errorf("Expected the merged rows to be released: %v", rep.vector.local.ids)
+	}
+}
+
+func TestNewWord2vecFromIDs(t *testing.T) {
+	cnf := model.NewConfig(5, 1, 0, 1, 2, 0.025, false, false)
+	sentences := corpus.NewSentenceSlice([][]uint32{{0, 1, 1}, {2, 2, 2, 2}})
+	w2v, err := NewWord2vecFromIDs([]string{"a", "b", "c"}, sentences, cnf,
+		NewSkipGram(5, 2, 1), NewNegativeSampling(2), 10000, 1.0, 1.0e-4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w2v.Train(); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := w2v.SaveSubset(&buf, []string{"a", "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 2 {
+		t.Errorf("Expected vectors of 2 words in dictionary: %v", buf.String())
+	}
+}
+
+func TestAutoThreadSize(t *testing.T) {
+	f := ioutil.NopCloser(strings.NewReader("a b b c c c c"))
+	cnf := model.NewConfig(5, 2, 0, 0, 2, 0.025, false, false)
+	w2v, err := NewWord2vec(f, cnf, NewCbow(5, 2, 0), NewNegativeSampling(2), 10000, 1.0, 1.0e-4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// each Train chooses the thread size again, since Config.ThreadSize is kept.
+	for i := 0; i < 2; i++ {
+		if err := w2v.Train(); err != nil {
+			t.Fatal(err)
+		}
+		if w2v.threadSize < 1 || w2v.threadSize > model.MaxThreadSize(0) {
+			t.Errorf("Expected thread size chosen in [1, %d]: %d", model.MaxThreadSize(0), w2v.threadSize)
+		}
+		if w2v.Config.ThreadSize != 0 {
+			t.Errorf("Expected Config.ThreadSize to be kept 0: %d", w2v.Config.ThreadSize)
+		}
+		if threads := w2v.Summary().ThreadSize; threads != w2v.threadSize {
+			t.Errorf("Expected the chosen thread size %d in summary: %d", w2v.threadSize, threads)
+		}
+	}
+}
+
+func TestChunks(t *testing.T) {
+	document := []int{0, 1, 2, 3, 4}
+	next, exhausted := chunks(document, nil, nil, 2)
+	var got []int
+	for chunk := next(); chunk != nil; chunk = next() {
+		got = append(got, chunk.document[chunk.from:chunk.to]...)
+	}
+	if !reflect.DeepEqual(got, document) || !exhausted() {
+		t.Errorf("Expected chunks to cover document once: %v", got)
+	}
+}
+
+func TestMask(t *testing.T) {
+	for _, mod := range []Model{NewSkipGram(5, 2, 1), NewCbow(5, 2, 1)} {
+		w2v := newTestWord2vec(t, mod, NewNegativeSampling(2))
+		missing := w2v.Mask([]string{"a", "unknown"})
+		if len(missing) != 1 || missing[0] != "unknown" {
+			t.Errorf("Expected unknown is not masked: %v", missing)
+		}
+		before := append([]float64(nil), w2v.vector...)
+
+		if err := w2v.Train(); err != nil {
+			t.Fatal(err)
+		}
+
+		a, _ := w2v.Lookup("a")
+		for i := a * 5; i < (a+1)*5; i++ {
+			if math.Float64bits(before[i]) != math.Float64bits(w2v.vector[i]) {
+				t.Fatalf("Expected the vector of masked a is kept at %d: %v -> %v", i, before[i], w2v.vector[i])
+			}
+		}
+		for _, word := range []string{"b", "c"} {
+			id, _ := w2v.Lookup(word)
+			if equalFloat64s(before[id*5:(id+1)*5], w2v.vector[id*5:(id+1)*5]) {
+				t.Errorf("Expected the vector of %v next to masked a is trained", word)
+			}
+		}
+	}
+}
+
+func TestOnProgress(t *testing.T) {
+	w2v := newTestWord2vec(t, NewSkipGram(5, 2, 2), NewNegativeSampling(2))
+	var reports []model.Progress
+	w2v.OnProgress(func(p model.Progress) {
+		reports = append(reports, p)
+	})
+	if err := w2v.Train(); err != nil {
+		t.Fatal(err)
+	}
+
+	var last model.Progress
+	for _, p := range reports {
+		if p.Iteration < last.Iteration || p.Iteration == last.Iteration && p.Done < last.Done {
+			t.Fatalf("Expected progress to be monotonic: %+v -> %+v", last, p)
+		}
+		if p.Total != 7 || !p.Has(model.ProgressLearningRate) || p.Has(model.ProgressCost) || p.Cost != 0 {
+			t.Fatalf("Expected progress over 7 words with learning rate but no cost: %+v", p)
+		}
+		last = p
+	}
+	if last.Iteration != 3 || last.Done != last.Total {
+		t.Errorf("Expected the last progress is the end of the last iteration: %+v", last)
+	}
+	if n := last.Norms; !last.Has(model.ProgressNorms) || n.Min <= 0 || n.P50 < n.Min || n.P99 > n.Max {
+		t.Errorf("Expected percentiles of norms at the end of the iteration: %+v", last)
+	}
+}
+
+func TestSentenceWeights(t *testing.T) {
+	f := ioutil.NopCloser(strings.NewReader("4 a b a b a b a b\n0.25 c d c d c d c d\n"))
+	cnf := model.NewConfig(5, 3, 0, 1, 1, 0.025, false, false)
+	cnf.SentenceWeights = true
+	w2v, err := NewWord2vec(f, cnf, NewSkipGram(5, 1, 1), NewHierarchicalSoftmax(0), 10000, 1.0, 1.0e-4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := append([]float64(nil), w2v.vector...)
+
+	if err := w2v.Train(); err != nil {
+		t.Fatal(err)
+	}
+
+	moved := func(words ...string) float64 {
+		var sum float64
+		for _, word := range words {
+			id, _ := w2v.Lookup(word)
+			for i := id * 5; i < (id+1)*5; i++ {
+				sum += math.Abs(w2v.vector[i] - before[i])
+			}
+		}
+		return sum
+	}
+	if heavy, light := moved("a", "b"), moved("c", "d"); heavy <= light {
+		t.Errorf("Expected words of the heavier sentence to move more: %v <= %v", heavy, light)
+	}
+}
+
+func TestIterationHooks(t *testing.T) {
+	w2v := newTestWord2vec(t, NewCbow(5, 2, 2), NewNegativeSampling(2))
+	var before, after []int
+	w2v.BeforeIteration(func(iteration int) {
+		if len(before) != len(after) {
+			t.Errorf("Expected before hook of %d to follow after hook of the previous", iteration)
+		}
+		before = append(before, iteration)
+	})
+	w2v.AfterIteration(func(iteration int, m model.Model) {
+		if m != model.Model(w2v) {
+			t.Errorf("Expected after hook to be called with the model itself: %v", m)
+		}
+		after = append(after, iteration)
+	})
+	if err := w2v.Train(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []int{1, 2, 3}
+	if !reflect.DeepEqual(before, expected) || !reflect.DeepEqual(after, expected) {
+		t.Errorf("Expected hooks to be called on iterations %v: before=%v, after=%v", expected, before, after)
+	}
+}
+
+func TestConcurrentTrain(t *testing.T) {
+	w2v := newTestWord2vec(t, NewSkipGram(5, 2, 1), NewNegativeSampling(2))
+	started, released := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	w2v.BeforeIteration(func(int) {
+		// block the first iteration of the first Train until the second Train returns.
+		once.Do(func() {
+			close(started)
+			<-released
+		})
+	})
+
+	errs := make(chan error, 2)
+	go func() {
+		errs <- w2v.Train()
+	}()
+	go func() {
+		<-started
+		errs <- w2v.Train()
+		close(released)
+	}()
+	if err := <-errs; err != model.ErrTraining {
+		t.Errorf("Expected the second Train to fail while training: %v", err)
+	}
+	if err := <-errs; err != nil {
+		t.Errorf("Expected the first Train to succeed: %v", err)
+	}
+	if err := w2v.Train(); err != nil {
+		t.Errorf("Expected Train to succeed after training: %v", err)
+	}
+}
+
+type lrModel struct {
+	countingModel
+	mu  sync.Mutex
+	lrs map[float64]bool
+}
+
+func (l *lrModel) trainOne(document []int, wordIndex int, wordVector *matrix, lr float64, optimizer Optimizer,
+	rnd *model.Random) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lrs[lr] = true
+}
+
+func TestLearningRateThreads(t *testing.T) {
+	text := strings.Repeat("a b b c c c c d d e ", 20)
+	trainLearningRates := func(threads int) (*Word2vec, map[float64]bool) {
+		f := ioutil.NopCloser(strings.NewReader(text))
+		cnf := model.NewConfig(5, 1, 0, threads, 1, 0.025, false, false)
+		mod := &lrModel{lrs: make(map[float64]bool)}
+		w2v, err := NewWord2vec(f, cnf, mod, NewHierarchicalSoftmax(0), 10, 1.0, 1.0e-4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w2v.Train(); err != nil {
+			t.Fatal(err)
+		}
+		return w2v, mod.lrs
+	}
+
+	single, singleLrs := trainLearningRates(1)
+	if single.trainedWordCount != 200 {
+		t.Fatalf("Expected 200 words are counted for learning rate: %d", single.trainedWordCount)
+	}
+	for count := int64(0); count < 200; count += 10 {
+		lr := single.learningRate(count)
+		if !singleLrs[lr] {
+			t.Errorf("Expected learning rate at %d words to be used by 1 thread: %v", count, lr)
+		}
+	}
+	if len(singleLrs) != 20 {
+		t.Errorf("Expected 20 learning rates to be used by 1 thread: %v", singleLrs)
+	}
+
+	multi, multiLrs := trainLearningRates(4)
+	if multi.trainedWordCount != 200 {
+		t.Fatalf("Expected 200 words are counted for learning rate by 4 threads: %d", multi.trainedWordCount)
+	}
+	for lr := range multiLrs {
+		if !singleLrs[lr] {
+			t.Errorf("Expected learning rate used by 4 threads to be on the trajectory of 1 thread: %v", lr)
+		}
+	}
+}
+
+func TestNoSubsample(t *testing.T) {
+	text := strings.Repeat("the the the the the the the the fox ", 10)
+	f := ioutil.NopCloser(strings.NewReader(text))
+	cnf := model.NewConfig(5, 2, 0, 2, 2, 0.025, false, false)
+	mod := &countingModel{}
+	w2v, err := NewWord2vec(f, cnf, mod, NewHierarchicalSoftmax(0), 10000, 0, 1.0e-4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w2v.Train(); err != nil {
+		t.Fatal(err)
+	}
+	if mod.count != 90*2 {
+		t.Errorf("Expected all 90 tokens are trained on in 2 iterations without subsampling: %d", mod.count)
+	}
+	if freq, total := w2v.WordFreq("the"), w2v.TotalFreq(); freq != 80 || total != 90 {
+		t.Errorf("Expected frequencies of raw corpus without subsampling: the=%d, total=%d", freq, total)
+	}
+}
+
+func TestLearningRateLargeBatch(t *testing.T) {
+	f := ioutil.NopCloser(strings.NewReader("a b b c c c c"))
+	cnf := model.NewConfig(5, 3, 0, 1, 1, 0.025, false, false)
+	mod := &lrModel{lrs: make(map[float64]bool)}
+	w2v, err := NewWord2vec(f, cnf, mod, NewHierarchicalSoftmax(0), 10000, 1.0, 1.0e-4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w2v.batchSize != 7 {
+		t.Errorf("Expected batch size to be capped to 7 tokens of corpus: %d", w2v.batchSize)
+	}
+	if err := w2v.Train(); err != nil {
+		t.Fatal(err)
+	}
+	decayed := false
+	for lr := range mod.lrs {
+		decayed = decayed || lr < 0.025
+	}
+	if !decayed {
+		t.Errorf("Expected learning rate to decay across iterations with batch size larger than corpus: %v", mod.lrs)
+	}
+}
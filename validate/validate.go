@@ -15,7 +15,11 @@
 package validate
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
 )
 
 // FileExists validates whether the file path exists or not.
@@ -23,3 +27,21 @@ func FileExists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
 }
+
+// DirWritable validates whether the directory of the file path exists and a file can be created in it.
+func DirWritable(path string) error {
+	dir := filepath.Dir(path)
+	info, err := os.Stat(dir)
+	if err != nil {
+		return errors.Errorf("Unable to write %s: directory %s does not exist", path, dir)
+	}
+	if !info.IsDir() {
+		return errors.Errorf("Unable to write %s: %s is not a directory", path, dir)
+	}
+	f, err := ioutil.TempFile(dir, ".wego-")
+	if err != nil {
+		return errors.Wrapf(err, "Unable to write %s", path)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
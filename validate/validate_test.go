@@ -15,6 +15,9 @@
 package validate
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -23,3 +26,21 @@ func TestFileExists(t *testing.T) {
 		t.Error("fake.go is not existed")
 	}
 }
+
+func TestDirWritable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wego")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := DirWritable(filepath.Join(dir, "vectors.txt")); err != nil {
+		t.Errorf("Expected %s to be writable: %v", dir, err)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expected no files left after validation: %v", files)
+	}
+	if err := DirWritable(filepath.Join(dir, "fake", "vectors.txt")); err == nil {
+		t.Error("Expected to fail with non-existent directory")
+	}
+}
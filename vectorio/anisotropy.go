@@ -0,0 +1,70 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorio
+
+// anisotropyPairs is the number of random pairs of words to estimate Anisotropy,
+// which is computed on all pairs if there are fewer.
+const anisotropyPairs = 10000
+
+// Anisotropy returns the mean cosine similarity between pairs of different words, e.g. to diagnose the vectors
+// clustering in a narrow cone, which is close to 0 for isotropic vectors and to 1 for anisotropic ones.
+// It is estimated on anisotropyPairs random pairs for large vocabulary, which are fixed for the same words
+// so that the values before and after post-processing are comparable. Zero vectors are ignored.
+func (v *Vectors) Anisotropy() float64 {
+	vecs := make([][]float64, 0, len(v.Words))
+	norms := make([]float64, 0, len(v.Words))
+	for _, word := range v.Words {
+		vec := v.Vector[word]
+		if norm := Norm(vec); norm > 0 {
+			vecs = append(vecs, vec)
+			norms = append(norms, norm)
+		}
+	}
+	n := len(vecs)
+	if n < 2 {
+		return 0
+	}
+	cosine := func(i, j int) float64 {
+		var dot float64
+		for d := range vecs[i] {
+			dot += vecs[i][d] * vecs[j][d]
+		}
+		return dot / (norms[i] * norms[j])
+	}
+
+	var sum float64
+	if n*(n-1)/2 <= anisotropyPairs {
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				sum += cosine(i, j)
+			}
+		}
+		return sum / float64(n*(n-1)/2)
+	}
+	// linear congruential generator with a fixed seed to draw the same pairs every time.
+	next := uint64(1)
+	random := func(value int) int {
+		next = next*uint64(25214903917) + 11
+		return int((next >> 16) % uint64(value))
+	}
+	for p := 0; p < anisotropyPairs; p++ {
+		i, j := random(n), random(n-1)
+		if j >= i {
+			j++
+		}
+		sum += cosine(i, j)
+	}
+	return sum / anisotropyPairs
+}
@@ -0,0 +1,169 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// BinaryReader reads word vectors in the binary format of the original word2vec:
+// the header "<words> <dimension>\n" followed by "<word> " with little-endian float32 values and "\n" per word.
+type BinaryReader struct {
+	r *bufio.Reader
+
+	header    bool
+	size, dim int
+	read      int
+	row       []float32
+}
+
+// NewBinaryReader creates *BinaryReader.
+func NewBinaryReader(r io.Reader) *BinaryReader {
+	return &BinaryReader{
+		r: bufio.NewReader(r),
+	}
+}
+
+// Read reads the next word vector.
+func (b *BinaryReader) Read() (string, []float64, error) {
+	if !b.header {
+		if _, err := fmt.Fscanf(b.r, "%d %d\n", &b.size, &b.dim); err != nil {
+			return "", nil, errors.Wrap(err, "Unable to read header")
+		}
+		if err := validateHeader(b.size, b.dim); err != nil {
+			return "", nil, err
+		}
+		b.row = make([]float32, b.dim)
+		b.header = true
+	}
+	if b.read >= b.size {
+		return "", nil, io.EOF
+	}
+
+	word, err := b.r.ReadString(' ')
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "Unable to read word %d", b.read)
+	}
+	word = word[:len(word)-1]
+	if err := binary.Read(b.r, binary.LittleEndian, b.row); err != nil {
+		return "", nil, errors.Wrapf(err, "Unable to read vector of %v", word)
+	}
+	if c, err := b.r.ReadByte(); err != nil || c != '\n' {
+		return "", nil, errors.Errorf("Expected newline after vector of %v", word)
+	}
+	vec := make([]float64, b.dim)
+	for k, v := range b.row {
+		vec[k] = float64(v)
+	}
+	b.read++
+	return word, vec, nil
+}
+
+// BinaryWriter writes word vectors in the binary format of the original word2vec.
+type BinaryWriter struct {
+	dst io.Writer
+	w   *bufio.Writer
+
+	header    bool
+	size, dim int
+	written   int
+	row       []float32
+}
+
+// NewBinaryWriter creates *BinaryWriter to write size vectors of dimension.
+func NewBinaryWriter(w io.Writer, size, dimension int) *BinaryWriter {
+	return &BinaryWriter{
+		dst:  w,
+		w:    bufio.NewWriter(w),
+		size: size,
+		dim:  dimension,
+		row:  make([]float32, dimension),
+	}
+}
+
+// Write writes the word vector. It fails for the word invalid by ValidateWord.
+func (b *BinaryWriter) Write(word string, vector []float64) error {
+	if err := ValidateWord(word); err != nil {
+		return err
+	}
+	if b.written >= b.size {
+		return errors.Errorf("Unable to write %v: %d vectors are already written", word, b.size)
+	}
+	if len(vector) != b.dim {
+		return errors.Errorf("Dimension of %v is %d, but expected %d", word, len(vector), b.dim)
+	}
+	if err := b.writeHeader(); err != nil {
+		return err
+	}
+	fmt.Fprintf(b.w, "%v ", word)
+	for i, v := range vector {
+		b.row[i] = float32(v)
+	}
+	if err := binary.Write(b.w, binary.LittleEndian, b.row); err != nil {
+		return err
+	}
+	b.written++
+	return b.w.WriteByte('\n')
+}
+
+// Flush flushes the header and the buffered vectors. The header has the size given in advance,
+// which UpdateHeader corrects to Written for the prefix of vectors.
+func (b *BinaryWriter) Flush() error {
+	if err := b.writeHeader(); err != nil {
+		return err
+	}
+	return b.w.Flush()
+}
+
+// Close flushes the buffered vectors, and syncs the underlying writer.
+// It fails if fewer vectors than the size are written.
+func (b *BinaryWriter) Close() error {
+	if err := b.Flush(); err != nil {
+		return err
+	}
+	if b.written < b.size {
+		return errors.Errorf("Expected %d vectors, but %d are written", b.size, b.written)
+	}
+	return syncWriter(b.dst)
+}
+
+// Written returns the number of vectors written so far.
+func (b *BinaryWriter) Written() int {
+	return b.written
+}
+
+func (b *BinaryWriter) writeHeader() error {
+	if b.header {
+		return nil
+	}
+	b.header = true
+	_, err := b.w.WriteString(formatHeader(b.size, b.dim))
+	return err
+}
+
+// ReadBinary reads all word vectors in binary format.
+func ReadBinary(r io.Reader) (*Vectors, error) {
+	return ReadAll(NewBinaryReader(r))
+}
+
+// WriteBinary writes the word vectors in binary format with the order of words.
+func WriteBinary(w io.Writer, vectors *Vectors) error {
+	return WriteAll(NewBinaryWriter(w, len(vectors.Words), vectors.Dimension()), vectors)
+}
@@ -0,0 +1,45 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorio
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Convert reads all word vectors of r in the format from, and writes them into w in the format to,
+// e.g. text to binary. It returns the number of words and dimension of the vectors.
+// It fails without writing anything if the vectors have different dimensions.
+// npy is not supported for from since it has no words.
+func Convert(r io.Reader, from string, w io.Writer, to string) (int, int, error) {
+	if err := ValidateFormat(to); err != nil {
+		return 0, 0, err
+	}
+	vectors, err := Read(r, from)
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "Unable to read vectors in %s", from)
+	}
+	dim := vectors.Dimension()
+	for _, word := range vectors.Words {
+		if len(vectors.Vector[word]) != dim {
+			return 0, 0, errors.Errorf("Dimension of %v is %d, but expected %d", word, len(vectors.Vector[word]), dim)
+		}
+	}
+	if err := Write(w, to, vectors); err != nil {
+		return 0, 0, errors.Wrapf(err, "Unable to write vectors in %s", to)
+	}
+	return len(vectors.Words), dim, nil
+}
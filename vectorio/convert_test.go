@@ -0,0 +1,65 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorio
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	text := "a 0.5 -1\nb 2 0.25\nc -0.125 4\n"
+	vectors, err := ReadText(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var binary bytes.Buffer
+	size, dim, err := Convert(strings.NewReader(text), FormatText, &binary, FormatBinary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 3 || dim != 2 {
+		t.Errorf("Expected 3 words of dimension 2: %d words of dimension %d", size, dim)
+	}
+
+	var back bytes.Buffer
+	if _, _, err := Convert(&binary, FormatBinary, &back, FormatText); err != nil {
+		t.Fatal(err)
+	}
+	actual, err := ReadText(&back)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualVectors(t, "text -> binary -> text", vectors, actual)
+}
+
+func TestConvertInvalid(t *testing.T) {
+	var buf bytes.Buffer
+	if _, _, err := Convert(strings.NewReader("a 1 2\n"), FormatText, &buf, "csv"); err == nil {
+		t.Error("Expected to fail converting into unknown format")
+	}
+	if _, _, err := Convert(strings.NewReader(""), FormatNpy, &buf, FormatText); err == nil {
+		t.Error("Expected to fail converting from npy without words")
+	}
+	json := `[{"word": "a", "vector": [1, 2]}, {"word": "b", "vector": [1]}]`
+	if _, _, err := Convert(strings.NewReader(json), FormatJSON, &buf, FormatText); err == nil {
+		t.Error("Expected to fail converting vectors of different dimensions")
+	}
+	if buf.Len() > 0 {
+		t.Errorf("Expected nothing is written on failure: %q", buf.String())
+	}
+}
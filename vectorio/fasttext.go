@@ -0,0 +1,174 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// FastTextReader reads word vectors in the .vec format of fastText:
+// the header "<words> <dimension>\n" followed by the lines of text format.
+type FastTextReader struct {
+	*TextReader
+
+	header    bool
+	size, dim int
+	read      int
+}
+
+// NewFastTextReader creates *FastTextReader.
+func NewFastTextReader(r io.Reader) *FastTextReader {
+	return &FastTextReader{
+		TextReader: NewTextReader(r),
+	}
+}
+
+// Read reads the next word vector.
+func (f *FastTextReader) Read() (string, []float64, error) {
+	if !f.header {
+		if err := f.readHeader(); err != nil {
+			return "", nil, err
+		}
+	}
+	word, vec, err := f.TextReader.Read()
+	if err == io.EOF {
+		if f.read != f.size {
+			return "", nil, errors.Errorf("Expected %d vectors in header, but %d are read", f.size, f.read)
+		}
+		return "", nil, io.EOF
+	} else if err != nil {
+		return "", nil, err
+	}
+	if len(vec) != f.dim {
+		return "", nil, errors.Errorf("Dimension of %v is %d, but expected %d in header", word, len(vec), f.dim)
+	}
+	f.read++
+	return word, vec, nil
+}
+
+func (f *FastTextReader) readHeader() error {
+	f.header = true
+	if !f.scanner.Scan() {
+		if err := f.scanner.Err(); err != nil {
+			return errors.Wrap(err, "Unable to read header")
+		}
+		return errors.New("Unable to read header: empty input")
+	}
+	f.lineNum++
+	if _, err := fmt.Sscanf(TrimLine(f.scanner.Text()), "%d %d", &f.size, &f.dim); err != nil {
+		return errors.Wrapf(err, "Unable to parse header %q", f.scanner.Text())
+	}
+	return validateHeader(f.size, f.dim)
+}
+
+// FastTextWriter writes word vectors in the .vec format of fastText.
+// Same as fastText, values are float32 with 5 significant digits and each of them is followed by space.
+type FastTextWriter struct {
+	dst io.Writer
+	w   *bufio.Writer
+
+	header    bool
+	size, dim int
+	written   int
+	buf       []byte
+}
+
+// NewFastTextWriter creates *FastTextWriter to write size vectors of dimension.
+func NewFastTextWriter(w io.Writer, size, dimension int) *FastTextWriter {
+	return &FastTextWriter{
+		dst:  w,
+		w:    bufio.NewWriter(w),
+		size: size,
+		dim:  dimension,
+	}
+}
+
+// Write writes the word vector. It fails for the word invalid by ValidateWord.
+func (f *FastTextWriter) Write(word string, vector []float64) error {
+	if err := ValidateWord(word); err != nil {
+		return err
+	}
+	if f.written >= f.size {
+		return errors.Errorf("Unable to write %v: %d vectors are already written", word, f.size)
+	}
+	if len(vector) != f.dim {
+		return errors.Errorf("Dimension of %v is %d, but expected %d", word, len(vector), f.dim)
+	}
+	if err := f.writeHeader(); err != nil {
+		return err
+	}
+	f.buf = append(f.buf[:0], word...)
+	f.buf = append(f.buf, ' ')
+	for _, v := range vector {
+		f.buf = strconv.AppendFloat(f.buf, float64(float32(v)), 'g', 5, 32)
+		f.buf = append(f.buf, ' ')
+	}
+	f.buf = append(f.buf, '\n')
+	if _, err := f.w.Write(f.buf); err != nil {
+		return err
+	}
+	f.written++
+	return nil
+}
+
+// Flush flushes the header and the buffered vectors. The header has the size given in advance,
+// which UpdateHeader corrects to Written for the prefix of vectors.
+func (f *FastTextWriter) Flush() error {
+	if err := f.writeHeader(); err != nil {
+		return err
+	}
+	return f.w.Flush()
+}
+
+// Close flushes the buffered vectors, and syncs the underlying writer.
+// It fails if fewer vectors than the size are written.
+func (f *FastTextWriter) Close() error {
+	if err := f.Flush(); err != nil {
+		return err
+	}
+	if f.written < f.size {
+		return errors.Errorf("Expected %d vectors, but %d are written", f.size, f.written)
+	}
+	return syncWriter(f.dst)
+}
+
+// Written returns the number of vectors written so far.
+func (f *FastTextWriter) Written() int {
+	return f.written
+}
+
+func (f *FastTextWriter) writeHeader() error {
+	if f.header {
+		return nil
+	}
+	f.header = true
+	_, err := f.w.WriteString(formatHeader(f.size, f.dim))
+	return err
+}
+
+// ReadFastTextVec reads all word vectors in .vec format of fastText.
+func ReadFastTextVec(r io.Reader) (*Vectors, error) {
+	return ReadAll(NewFastTextReader(r))
+}
+
+// WriteFastTextVec writes the word vectors in .vec format of fastText with the order of words.
+func WriteFastTextVec(w io.Writer, vectors *Vectors) error {
+	return WriteAll(NewFastTextWriter(w, len(vectors.Words), vectors.Dimension()), vectors)
+}
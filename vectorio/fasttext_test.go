@@ -0,0 +1,74 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorio
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// fastTextVec follows the layout written by saveVectors of fastText:
+// header "<words> <dimension>", and values with 5 significant digits each followed by space.
+const fastTextVec = "4 3\n" +
+	"</s> 0.0012385 -0.0038439 0.0042963 \n" +
+	"the -0.12346 0.5 1.2346e-05 \n" +
+	"of 3 -2.2e+07 0 \n" +
+	"and -0.0001 12346 -0.33333 \n"
+
+func TestFastTextVec(t *testing.T) {
+	vectors := &Vectors{
+		Words: []string{"</s>", "the", "of", "and"},
+		Vector: map[string][]float64{
+			"</s>": {0.00123853, -0.00384392, 0.00429631},
+			"the":  {-0.123456, 0.5, 0.0000123456},
+			"of":   {3, -22000000, 0},
+			"and":  {-0.0001, 12345.6, -1.0 / 3},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteFastTextVec(&buf, vectors); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != fastTextVec {
+		t.Errorf("Expected the same bytes as fastText:\n%q\nbut got:\n%q", fastTextVec, buf.String())
+	}
+
+	actual, err := ReadFastTextVec(strings.NewReader(fastTextVec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual.Words) != 4 || actual.Words[1] != "the" || actual.Vector["the"][2] != 1.2346e-05 {
+		t.Errorf("Expected to read vectors of fastText: %v", actual.Vector)
+	}
+}
+
+func TestFastTextVecHeader(t *testing.T) {
+	if _, err := ReadFastTextVec(strings.NewReader("3 2\na 1 2 \n")); err == nil {
+		t.Error("Expected to fail reading fewer vectors than the header")
+	}
+	if _, err := ReadFastTextVec(strings.NewReader("1 3\na 1 2 \n")); err == nil {
+		t.Error("Expected to fail reading vector whose dimension is different from the header")
+	}
+	if _, err := ReadFastTextVec(strings.NewReader("a 1 2\n")); err == nil {
+		t.Error("Expected to fail reading without header")
+	}
+	for _, header := range []string{"-1 2\n", "1 0\n", "1 -2\n", "1 2000000000\n"} {
+		if _, err := ReadFastTextVec(strings.NewReader(header + "a 1 2 \n")); err == nil {
+			t.Errorf("Expected to fail reading invalid header %q", header)
+		}
+	}
+}
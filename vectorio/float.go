@@ -0,0 +1,70 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorio
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// The list of styles to format values in text format.
+const (
+	// FloatFixed is the style without exponent, e.g. 0.0123
+	FloatFixed = "fixed"
+	// FloatScientific is the style with exponent, e.g. 1.23e-02
+	FloatScientific = "scientific"
+	// FloatShortest is the style of fixed or scientific, whichever is shorter, e.g. 0.0123, 1.23e-05
+	FloatShortest = "shortest"
+)
+
+// FloatFormat is the style and precision to format values in text format.
+// Precision is the number of digits after the decimal point for fixed and scientific,
+// or the number of significant digits for shortest. Negative precision means the minimal digits to
+// represent values exactly.
+type FloatFormat struct {
+	Style     string
+	Precision int
+}
+
+// DefaultFloatFormat formats values in fixed style exactly.
+var DefaultFloatFormat = FloatFormat{
+	Style:     FloatFixed,
+	Precision: -1,
+}
+
+// Validate validates the style.
+func (f FloatFormat) Validate() error {
+	switch f.Style {
+	case FloatFixed, FloatScientific, FloatShortest:
+		return nil
+	default:
+		return errors.Errorf("Invalid float style: %s not in %s|%s|%s",
+			f.Style, FloatFixed, FloatScientific, FloatShortest)
+	}
+}
+
+func (f FloatFormat) append(buf []byte, v float64) []byte {
+	var verb byte
+	switch f.Style {
+	case FloatScientific:
+		verb = 'e'
+	case FloatShortest:
+		verb = 'g'
+	default:
+		verb = 'f'
+	}
+	return strconv.AppendFloat(buf, v, verb, f.Precision, 64)
+}
@@ -0,0 +1,121 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorio
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func cosine(v1, v2 []float64) float64 {
+	var inner float64
+	for i := range v1 {
+		inner += v1[i] * v2[i]
+	}
+	return inner / (Norm(v1) * Norm(v2))
+}
+
+func TestFloatFormat(t *testing.T) {
+	testCases := []struct {
+		float    FloatFormat
+		expected string
+	}{
+		{float: DefaultFloatFormat, expected: "a 0.000012345 -1.5 \n"},
+		{float: FloatFormat{Style: FloatFixed, Precision: 4}, expected: "a 0.0000 -1.5000 \n"},
+		{float: FloatFormat{Style: FloatScientific, Precision: 2}, expected: "a 1.23e-05 -1.50e+00 \n"},
+		{float: FloatFormat{Style: FloatShortest, Precision: 3}, expected: "a 1.23e-05 -1.5 \n"},
+		{float: FloatFormat{Style: FloatShortest, Precision: -1}, expected: "a 1.2345e-05 -1.5 \n"},
+	}
+
+	for _, testCase := range testCases {
+		var buf bytes.Buffer
+		vectors := &Vectors{
+			Words:  []string{"a"},
+			Vector: map[string][]float64{"a": {0.000012345, -1.5}},
+		}
+		if err := WriteFloat(&buf, FormatText, testCase.float, vectors); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != testCase.expected {
+			t.Errorf("Expected %q by %v: %q", testCase.expected, testCase.float, buf.String())
+		}
+	}
+}
+
+func TestFloatFormatRoundTrip(t *testing.T) {
+	dim := 50
+	vectors := &Vectors{
+		Words:  []string{"a", "b", "c"},
+		Vector: make(map[string][]float64),
+	}
+	for _, word := range vectors.Words {
+		vec := make([]float64, dim)
+		for i := range vec {
+			vec[i] = rand.Float64()*2 - 1
+		}
+		vectors.Vector[word] = vec
+	}
+
+	// the bounds of cosine changes documented in README.
+	precision := 4
+	minNorm := math.Inf(1)
+	for _, vec := range vectors.Vector {
+		minNorm = math.Min(minNorm, Norm(vec))
+	}
+	fixedBound := math.Sqrt(float64(dim)) * math.Pow(10, -float64(precision)) / minNorm
+	significantBound := math.Pow(10, 1-float64(precision))
+
+	testCases := []struct {
+		float FloatFormat
+		bound float64
+	}{
+		{float: DefaultFloatFormat, bound: 0},
+		{float: FloatFormat{Style: FloatScientific, Precision: -1}, bound: 0},
+		{float: FloatFormat{Style: FloatShortest, Precision: -1}, bound: 0},
+		{float: FloatFormat{Style: FloatFixed, Precision: precision}, bound: fixedBound},
+		{float: FloatFormat{Style: FloatScientific, Precision: precision - 1}, bound: significantBound},
+		{float: FloatFormat{Style: FloatShortest, Precision: precision}, bound: significantBound},
+	}
+
+	for _, testCase := range testCases {
+		var buf bytes.Buffer
+		if err := WriteFloat(&buf, FormatText, testCase.float, vectors); err != nil {
+			t.Fatal(err)
+		}
+		actual, err := ReadText(&buf)
+		if err != nil {
+			t.Fatalf("Unable to parse values by %v: %v", testCase.float, err)
+		}
+		for _, pair := range [][2]string{{"a", "b"}, {"b", "c"}, {"a", "c"}} {
+			expected := cosine(vectors.Vector[pair[0]], vectors.Vector[pair[1]])
+			diff := math.Abs(cosine(actual.Vector[pair[0]], actual.Vector[pair[1]]) - expected)
+			if diff > testCase.bound+1e-12 {
+				t.Errorf("Expected cosine of %v changes less than %v by %v: %v", pair, testCase.bound, testCase.float, diff)
+			}
+		}
+	}
+}
+
+func TestInvalidFloatFormat(t *testing.T) {
+	vectors := &Vectors{
+		Words:  []string{"a"},
+		Vector: map[string][]float64{"a": {1}},
+	}
+	if err := WriteFloat(&bytes.Buffer{}, FormatText, FloatFormat{Style: "fake"}, vectors); err == nil {
+		t.Error("Expected to fail writing with invalid float style")
+	}
+}
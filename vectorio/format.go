@@ -0,0 +1,91 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorio
+
+import (
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The list of formats to save word vectors.
+const (
+	FormatText   = "text"
+	FormatBinary = "binary"
+	FormatJSON   = "json"
+	FormatNpy    = "npy"
+
+	FormatFastTextVec = "fasttext-vec"
+)
+
+// Formats is the list of supported formats.
+var Formats = []string{FormatText, FormatBinary, FormatJSON, FormatNpy, FormatFastTextVec}
+
+// ValidateFormat validates whether the format is supported or not.
+func ValidateFormat(format string) error {
+	for _, f := range Formats {
+		if f == format {
+			return nil
+		}
+	}
+	return errors.Errorf("Invalid format: %s not in %s", format, strings.Join(Formats, "|"))
+}
+
+// Write writes the word vectors in the format, or in text format if it is empty.
+func Write(w io.Writer, format string, vectors *Vectors) error {
+	return WriteFloat(w, format, DefaultFloatFormat, vectors)
+}
+
+// WriteFloat writes the word vectors in the format like Write, whose values are formatted by float in text format.
+// The other formats have their own representation of values.
+func WriteFloat(w io.Writer, format string, float FloatFormat, vectors *Vectors) error {
+	switch format {
+	case FormatText, "":
+		if err := float.Validate(); err != nil {
+			return err
+		}
+		return WriteAll(NewFloatTextWriter(w, float), vectors)
+	case FormatBinary:
+		return WriteBinary(w, vectors)
+	case FormatJSON:
+		return WriteJSON(w, vectors)
+	case FormatNpy:
+		return WriteNpy(w, vectors)
+	case FormatFastTextVec:
+		return WriteFastTextVec(w, vectors)
+	default:
+		return ValidateFormat(format)
+	}
+}
+
+// Read reads all word vectors in the format, or in text format if it is empty.
+// npy format is not supported since it has no words.
+func Read(r io.Reader, format string) (*Vectors, error) {
+	switch format {
+	case FormatText, "":
+		return ReadText(r)
+	case FormatBinary:
+		return ReadBinary(r)
+	case FormatJSON:
+		return ReadJSON(r)
+	case FormatFastTextVec:
+		return ReadFastTextVec(r)
+	case FormatNpy:
+		return nil, errors.New("Unable to read words from npy, use ReadNpy to read the matrix")
+	default:
+		return nil, ValidateFormat(format)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorio
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFormats(t *testing.T) {
+	vectors, err := ReadText(strings.NewReader("a 0.5 -1\nb 2 0.25\nc -0.125 4\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range Formats {
+		var buf bytes.Buffer
+		if err := Write(&buf, format, vectors); err != nil {
+			t.Fatalf("Unable to write in %v: %v", format, err)
+		}
+
+		if format == FormatNpy {
+			data, rows, cols, err := ReadNpy(&buf)
+			if err != nil {
+				t.Fatalf("Unable to read npy: %v", err)
+			}
+			if rows != 3 || cols != 2 {
+				t.Errorf("Expected npy shape=(3, 2): (%d, %d)", rows, cols)
+			}
+			for i, word := range vectors.Words {
+				for j, v := range vectors.Vector[word] {
+					if float64(data[i*cols+j]) != v {
+						t.Errorf("Expected npy row %d=%v: %v", i, vectors.Vector[word], data[i*cols:(i+1)*cols])
+					}
+				}
+			}
+			continue
+		}
+
+		actual, err := Read(&buf, format)
+		if err != nil {
+			t.Fatalf("Unable to read %v: %v", format, err)
+		}
+		assertEqualVectors(t, format, vectors, actual)
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	if err := ValidateFormat("txt"); err == nil {
+		t.Error("Expected to fail validating typo of format")
+	}
+	if err := ValidateFormat(FormatBinary); err != nil {
+		t.Errorf("Expected binary to be supported: %v", err)
+	}
+}
+
+func assertEqualVectors(t *testing.T, name string, expected, actual *Vectors) {
+	if len(actual.Words) != len(expected.Words) {
+		t.Fatalf("Expected %v words=%v: %v", name, expected.Words, actual.Words)
+	}
+	for i, word := range expected.Words {
+		if actual.Words[i] != word {
+			t.Errorf("Expected %v words=%v: %v", name, expected.Words, actual.Words)
+			return
+		}
+		for j, v := range expected.Vector[word] {
+			if actual.Vector[word][j] != v {
+				t.Errorf("Expected %v vector of %v=%v: %v", name, word, expected.Vector[word], actual.Vector[word])
+				break
+			}
+		}
+	}
+}
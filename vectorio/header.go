@@ -0,0 +1,78 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorio
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// formatHeader returns the header "<words> <dimension>\n" of binary and fasttext-vec formats.
+func formatHeader(size, dimension int) string {
+	return fmt.Sprintf("%d %d\n", size, dimension)
+}
+
+// ParseHeader parses the line as the header "<words> <dimension>" of binary and fasttext-vec formats,
+// which text vector files converted from them often begin with, and returns whether it is the header.
+func ParseHeader(line string) (int, int, bool) {
+	sep := strings.Fields(TrimLine(line))
+	if len(sep) != 2 {
+		return 0, 0, false
+	}
+	size, err := strconv.Atoi(sep[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	dimension, err := strconv.Atoi(sep[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return size, dimension, true
+}
+
+// maxDimension bounds the dimension in the header to read, so a corrupt header fails instead of allocating
+// a huge vector.
+const maxDimension = 1 << 20
+
+// validateHeader validates the size and the dimension parsed from the header of binary or fasttext-vec format.
+// The dimension is 0 only for no vectors, as written for empty Vectors.
+func validateHeader(size, dimension int) error {
+	if size < 0 {
+		return errors.Errorf("Invalid size in header: %d must not be negative", size)
+	}
+	if dimension < 0 || dimension == 0 && size > 0 || dimension > maxDimension {
+		return errors.Errorf("Invalid dimension in header: %d not in [1, %d]", dimension, maxDimension)
+	}
+	return nil
+}
+
+// UpdateHeader rewrites the header of binary or fasttext-vec format at the beginning of w,
+// which was written for size vectors, to the number of vectors actually written, e.g. Written of the writer
+// after streaming is interrupted. The header keeps its length by padding spaces after the number,
+// so the flushed vectors remain a valid file.
+func UpdateHeader(w io.WriterAt, size, dimension, written int) error {
+	if written < 0 || written > size {
+		return errors.Errorf("Invalid written: %d not in [0, %d]", written, size)
+	}
+	prefix := fmt.Sprintf("%d", written)
+	suffix := fmt.Sprintf(" %d\n", dimension)
+	padding := len(formatHeader(size, dimension)) - len(prefix) - len(suffix)
+	_, err := w.WriteAt([]byte(prefix+strings.Repeat(" ", padding)+suffix), 0)
+	return err
+}
@@ -0,0 +1,155 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorio
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// syncBuffer counts calls of Sync.
+type syncBuffer struct {
+	bytes.Buffer
+	synced int
+}
+
+func (s *syncBuffer) Sync() error {
+	s.synced++
+	return nil
+}
+
+func TestFlushMidStream(t *testing.T) {
+	words := []string{"a", "b", "c"}
+	vecs := [][]float64{{0.5, -1}, {2, 0.25}, {-0.125, 4}}
+
+	for _, format := range []string{FormatText, FormatBinary, FormatFastTextVec} {
+		f, err := ioutil.TempFile("", "vectors")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(f.Name())
+
+		// declare 10 vectors for 2-digit header, and flush after 2 of them.
+		w, err := NewWriter(f, format, 10, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, word := range words[:2] {
+			if err := w.Write(word, vecs[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatalf("Unable to flush mid-stream in %v: %v", format, err)
+		}
+		if format != FormatText {
+			if err := UpdateHeader(f, 10, 2, 2); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		prefix, err := os.Open(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, _ := NewReader(prefix, format)
+		vectors, err := ReadAll(r)
+		prefix.Close()
+		if err != nil {
+			t.Fatalf("Expected flushed prefix is parseable in %v: %v", format, err)
+		}
+		if len(vectors.Words) != 2 || vectors.Words[0] != "a" || vectors.Words[1] != "b" {
+			t.Errorf("Expected flushed a, b in %v: %v", format, vectors.Words)
+		}
+		if vectors.Vector["b"][1] != 0.25 {
+			t.Errorf("Expected vector of b is complete in %v: %v", format, vectors.Vector["b"])
+		}
+		f.Close()
+	}
+}
+
+func TestCloseSyncs(t *testing.T) {
+	for _, format := range []string{FormatText, FormatBinary, FormatFastTextVec} {
+		buf := &syncBuffer{}
+		w, err := NewWriter(buf, format, 1, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Write("a", []float64{1, 2}); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if buf.synced != 0 {
+			t.Errorf("Expected Flush does not sync in %v", format)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if buf.synced != 1 {
+			t.Errorf("Expected Close syncs once in %v: %d", format, buf.synced)
+		}
+	}
+}
+
+func TestUpdateHeader(t *testing.T) {
+	f, err := ioutil.TempFile("", "header")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if _, err := f.WriteString(formatHeader(100, 5)); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateHeader(f, 100, 5, 7); err != nil {
+		t.Fatal(err)
+	}
+	actual, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(actual) != "7   5\n" {
+		t.Errorf("Expected the header keeps its length: %q", actual)
+	}
+	if err := UpdateHeader(f, 100, 5, 101); err == nil {
+		t.Error("Expected to fail updating the header to more vectors than written for")
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	testCases := []struct {
+		line      string
+		size, dim int
+		ok        bool
+	}{
+		{"3000 300\n", 3000, 300, true},
+		{"\ufeff3 2\r\n", 3, 2, true},
+		{"3 0.5\n", 0, 0, false},
+		{"3 2 1\n", 0, 0, false},
+		{"apple 1\n", 0, 0, false},
+	}
+	for _, testCase := range testCases {
+		size, dim, ok := ParseHeader(testCase.line)
+		if size != testCase.size || dim != testCase.dim || ok != testCase.ok {
+			t.Errorf("Expected %q to be parsed as (%d, %d, %v): (%d, %d, %v)",
+				testCase.line, testCase.size, testCase.dim, testCase.ok, size, dim, ok)
+		}
+	}
+}
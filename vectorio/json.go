@@ -0,0 +1,58 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorio
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+type jsonVector struct {
+	Word   string    `json:"word"`
+	Vector []float64 `json:"vector"`
+}
+
+// WriteJSON writes the word vectors as JSON array of {"word": word, "vector": [values]}.
+func WriteJSON(w io.Writer, vectors *Vectors) error {
+	js := make([]jsonVector, len(vectors.Words))
+	for i, word := range vectors.Words {
+		js[i] = jsonVector{
+			Word:   word,
+			Vector: vectors.Vector[word],
+		}
+	}
+	return json.NewEncoder(w).Encode(js)
+}
+
+// ReadJSON reads the word vectors saved by WriteJSON.
+func ReadJSON(r io.Reader) (*Vectors, error) {
+	var js []jsonVector
+	if err := json.NewDecoder(r).Decode(&js); err != nil {
+		return nil, errors.Wrap(err, "Unable to decode JSON")
+	}
+	vectors := &Vectors{
+		Words:  make([]string, 0, len(js)),
+		Vector: make(map[string][]float64, len(js)),
+	}
+	for _, v := range js {
+		if _, ok := vectors.Vector[v.Word]; !ok {
+			vectors.Words = append(vectors.Words, v.Word)
+		}
+		vectors.Vector[v.Word] = v.Vector
+	}
+	return vectors, nil
+}
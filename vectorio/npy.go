@@ -0,0 +1,89 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// npy has only the matrix of values, whose row i is the vector for the i-th word in the order of words.
+// Since words are not stored, the vocabulary has to be kept by the other format.
+
+var (
+	npyMagic = []byte("\x93NUMPY")
+	npyShape = regexp.MustCompile(`'shape':\s*\((\d+),\s*(\d+)\)`)
+)
+
+// WriteNpy writes the word vectors as float32 matrix in npy format (version 1.0).
+func WriteNpy(w io.Writer, vectors *Vectors) error {
+	header := fmt.Sprintf("{'descr': '<f4', 'fortran_order': False, 'shape': (%d, %d), }",
+		len(vectors.Words), vectors.Dimension())
+	// magic, version, header length and header with padding ends with newline, aligned by 64 bytes.
+	pad := 64 - (len(npyMagic)+4+len(header)+1)%64
+	header += string(bytes.Repeat([]byte(" "), pad%64)) + "\n"
+
+	wr := bufio.NewWriter(w)
+	wr.Write(npyMagic)
+	wr.Write([]byte{1, 0})
+	binary.Write(wr, binary.LittleEndian, uint16(len(header)))
+	wr.WriteString(header)
+	row := make([]float32, vectors.Dimension())
+	for _, word := range vectors.Words {
+		for i, v := range vectors.Vector[word] {
+			row[i] = float32(v)
+		}
+		if err := binary.Write(wr, binary.LittleEndian, row); err != nil {
+			return err
+		}
+	}
+	return wr.Flush()
+}
+
+// ReadNpy reads the float32 matrix saved by WriteNpy, and returns it as row-major values.
+func ReadNpy(r io.Reader) ([]float32, int, int, error) {
+	prefix := make([]byte, len(npyMagic)+4)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, 0, 0, errors.Wrap(err, "Unable to read npy header")
+	}
+	if !bytes.Equal(prefix[:len(npyMagic)], npyMagic) || prefix[len(npyMagic)] != 1 {
+		return nil, 0, 0, errors.New("Not npy format of version 1.0")
+	}
+	header := make([]byte, binary.LittleEndian.Uint16(prefix[len(npyMagic)+2:]))
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, 0, 0, errors.Wrap(err, "Unable to read npy header")
+	}
+	if !bytes.Contains(header, []byte("'<f4'")) {
+		return nil, 0, 0, errors.Errorf("Unsupported npy header: %s", header)
+	}
+	shape := npyShape.FindSubmatch(header)
+	if shape == nil {
+		return nil, 0, 0, errors.Errorf("Unsupported npy shape: %s", header)
+	}
+	rows, _ := strconv.Atoi(string(shape[1]))
+	cols, _ := strconv.Atoi(string(shape[2]))
+	data := make([]float32, rows*cols)
+	if err := binary.Read(r, binary.LittleEndian, data); err != nil {
+		return nil, 0, 0, errors.Wrap(err, "Unable to read npy data")
+	}
+	return data, rows, cols, nil
+}
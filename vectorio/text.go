@@ -0,0 +1,161 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorio
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ByteOrderMark is UTF-8 BOM, which editors on Windows often put at the beginning of text files.
+const ByteOrderMark = "\ufeff"
+
+// TrimLine trims UTF-8 BOM at the beginning of line and the line ending, i.e. "\n" or "\r\n", at the end of it,
+// so that files written on Windows are read in the same way.
+func TrimLine(line string) string {
+	line = strings.TrimPrefix(line, ByteOrderMark)
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r")
+}
+
+// TextReader reads word vectors in text format, "<word> <value> <value> ..." per line.
+// Blank lines and lines beginning with space are skipped.
+type TextReader struct {
+	scanner *bufio.Scanner
+	lineNum int
+	dim     int
+}
+
+// NewTextReader creates *TextReader.
+func NewTextReader(r io.Reader) *TextReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024*1024)
+	return &TextReader{
+		scanner: scanner,
+		dim:     -1,
+	}
+}
+
+// Read reads the next word vector.
+func (t *TextReader) Read() (string, []float64, error) {
+	for t.scanner.Scan() {
+		t.lineNum++
+		line := TrimLine(t.scanner.Text())
+		if strings.HasPrefix(line, " ") {
+			continue
+		}
+		word, vec, err := ParseLine(line)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "Unable to parse line %d", t.lineNum)
+		}
+		if word == "" {
+			continue
+		}
+		if t.dim < 0 {
+			t.dim = len(vec)
+		} else if len(vec) != t.dim {
+			return "", nil, errors.Errorf("Dimension of %v at line %d is %d, but expected %d",
+				word, t.lineNum, len(vec), t.dim)
+		}
+		return word, vec, nil
+	}
+	if err := t.scanner.Err(); err != nil && err != io.EOF {
+		return "", nil, errors.Wrap(err, "Unable to complete scanning")
+	}
+	return "", nil, io.EOF
+}
+
+// ParseLine parses the line of text format into word and vector, the word is empty for blank lines.
+func ParseLine(line string) (string, []float64, error) {
+	sep := strings.Fields(TrimLine(line))
+	if len(sep) == 0 {
+		return "", nil, nil
+	}
+	word, v := sep[0], sep[1:]
+	vec := make([]float64, len(v))
+	for k, elem := range v {
+		val, err := strconv.ParseFloat(elem, 64)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "Unable to parse vector of %v", word)
+		}
+		vec[k] = val
+	}
+	return word, vec, nil
+}
+
+// TextWriter writes word vectors in text format, "<word> <value> <value> ... " per line.
+type TextWriter struct {
+	dst   io.Writer
+	w     *bufio.Writer
+	buf   []byte
+	float FloatFormat
+}
+
+// NewTextWriter creates *TextWriter, which formats values by DefaultFloatFormat.
+func NewTextWriter(w io.Writer) *TextWriter {
+	return NewFloatTextWriter(w, DefaultFloatFormat)
+}
+
+// NewFloatTextWriter creates *TextWriter which formats values by float.
+func NewFloatTextWriter(w io.Writer, float FloatFormat) *TextWriter {
+	return &TextWriter{
+		dst:   w,
+		w:     bufio.NewWriter(w),
+		float: float,
+	}
+}
+
+// Write writes the word vector. It fails for the word invalid by ValidateWord.
+func (t *TextWriter) Write(word string, vector []float64) error {
+	if err := ValidateWord(word); err != nil {
+		return err
+	}
+	t.buf = append(t.buf[:0], word...)
+	t.buf = append(t.buf, ' ')
+	for _, v := range vector {
+		t.buf = t.float.append(t.buf, v)
+		t.buf = append(t.buf, ' ')
+	}
+	t.buf = append(t.buf, '\n')
+	_, err := t.w.Write(t.buf)
+	return err
+}
+
+// Flush flushes the buffered vectors.
+func (t *TextWriter) Flush() error {
+	return t.w.Flush()
+}
+
+// Close flushes the buffered vectors, and syncs the underlying writer.
+func (t *TextWriter) Close() error {
+	if err := t.Flush(); err != nil {
+		return err
+	}
+	return syncWriter(t.dst)
+}
+
+// ReadText reads all word vectors in text format.
+func ReadText(r io.Reader) (*Vectors, error) {
+	return ReadAll(NewTextReader(r))
+}
+
+// WriteText writes the word vectors in text format with the order of words.
+func WriteText(w io.Writer, vectors *Vectors) error {
+	return WriteAll(NewTextWriter(w), vectors)
+}
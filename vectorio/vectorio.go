@@ -0,0 +1,178 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vectorio reads and writes word vectors independent of training.
+package vectorio
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// Vectors stores the word vectors with the order of words.
+type Vectors struct {
+	Words  []string
+	Vector map[string][]float64
+}
+
+// Dimension returns dimension of word vector, or 0 if there are no vectors.
+func (v *Vectors) Dimension() int {
+	if len(v.Words) == 0 {
+		return 0
+	}
+	return len(v.Vector[v.Words[0]])
+}
+
+// Norm returns L2 norm of the vector for word, and whether the word exists or not.
+func (v *Vectors) Norm(word string) (float64, bool) {
+	vec, ok := v.Vector[word]
+	if !ok {
+		return 0, false
+	}
+	return Norm(vec), true
+}
+
+// Norm returns L2 norm of the vector.
+func Norm(vec []float64) float64 {
+	var sum float64
+	for _, v := range vec {
+		sum += v * v
+	}
+	return math.Sqrt(sum)
+}
+
+// ValidateWord validates that word is not empty and has no whitespace, which separates the word from values
+// in text, binary and fasttext-vec formats.
+func ValidateWord(word string) error {
+	if word == "" {
+		return errors.New("Invalid word: empty")
+	}
+	if strings.IndexFunc(word, unicode.IsSpace) >= 0 {
+		return errors.Errorf("Invalid word: %q contains whitespace", word)
+	}
+	return nil
+}
+
+// ValidateWords validates all words by ValidateWord, and lists all the invalid ones in the error.
+func ValidateWords(words []string) error {
+	invalid := make([]string, 0)
+	for _, word := range words {
+		if ValidateWord(word) != nil {
+			invalid = append(invalid, fmt.Sprintf("%q", word))
+		}
+	}
+	if len(invalid) > 0 {
+		return errors.Errorf("Invalid words: %d words are empty or contain whitespace: %s",
+			len(invalid), strings.Join(invalid, ", "))
+	}
+	return nil
+}
+
+// Reader reads word vectors one by one, and returns io.EOF after the last one.
+type Reader interface {
+	Read() (string, []float64, error)
+}
+
+// Writer writes word vectors one by one, e.g. to stream them into network.
+// Flush writes the buffered vectors into the underlying writer at any time, so that the written bytes are
+// a valid prefix of whole vectors. Close has to be called after the last one, which flushes, checks the number of
+// vectors for the formats with header, and syncs the underlying writer if it has Sync, e.g. *os.File.
+// Close does not close the underlying writer.
+type Writer interface {
+	Write(word string, vector []float64) error
+	Flush() error
+	Close() error
+}
+
+// syncer is implemented by the writers to commit the written data to stable storage, e.g. *os.File.
+type syncer interface {
+	Sync() error
+}
+
+func syncWriter(w io.Writer) error {
+	if s, ok := w.(syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// NewReader creates Reader for the format. One of: text|binary|fasttext-vec
+func NewReader(r io.Reader, format string) (Reader, error) {
+	switch format {
+	case FormatText:
+		return NewTextReader(r), nil
+	case FormatBinary:
+		return NewBinaryReader(r), nil
+	case FormatFastTextVec:
+		return NewFastTextReader(r), nil
+	default:
+		return nil, errors.Errorf("Invalid format to read one by one: %s not in %s|%s|%s",
+			format, FormatText, FormatBinary, FormatFastTextVec)
+	}
+}
+
+// NewWriter creates Writer for the format. One of: text|binary|fasttext-vec
+// size and dimension are the number of vectors to write and their dimension, which the header needs in advance.
+func NewWriter(w io.Writer, format string, size, dimension int) (Writer, error) {
+	switch format {
+	case FormatText:
+		return NewTextWriter(w), nil
+	case FormatBinary:
+		return NewBinaryWriter(w, size, dimension), nil
+	case FormatFastTextVec:
+		return NewFastTextWriter(w, size, dimension), nil
+	default:
+		return nil, errors.Errorf("Invalid format to write one by one: %s not in %s|%s|%s",
+			format, FormatText, FormatBinary, FormatFastTextVec)
+	}
+}
+
+// ReadAll reads all word vectors from r. The later vector wins for the duplicated word.
+func ReadAll(r Reader) (*Vectors, error) {
+	vectors := &Vectors{
+		Words:  make([]string, 0),
+		Vector: make(map[string][]float64),
+	}
+	for {
+		word, vec, err := r.Read()
+		if err == io.EOF {
+			return vectors, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if _, ok := vectors.Vector[word]; !ok {
+			vectors.Words = append(vectors.Words, word)
+		}
+		vectors.Vector[word] = vec
+	}
+}
+
+// WriteAll writes all word vectors with the order of words, and closes w.
+// Nothing is written if any word is invalid by ValidateWord.
+func WriteAll(w Writer, vectors *Vectors) error {
+	if err := ValidateWords(vectors.Words); err != nil {
+		return err
+	}
+	for _, word := range vectors.Words {
+		if err := w.Write(word, vectors.Vector[word]); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
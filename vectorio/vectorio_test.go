@@ -0,0 +1,196 @@
+// Copyright © 2017 Makoto Ito
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vectorio
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReaderWriter(t *testing.T) {
+	words := []string{"a", "b", "c"}
+	vecs := [][]float64{{0.5, -1}, {2, 0.25}, {-0.125, 4}}
+
+	for _, format := range []string{FormatText, FormatBinary} {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, format, len(words), 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, word := range words {
+			if err := w.Write(word, vecs[i]); err != nil {
+				t.Fatalf("Unable to write %v in %v: %v", word, format, err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := NewReader(&buf, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, word := range words {
+			actualWord, actualVec, err := r.Read()
+			if err != nil {
+				t.Fatalf("Unable to read %v in %v: %v", word, format, err)
+			}
+			if actualWord != word || actualVec[0] != vecs[i][0] || actualVec[1] != vecs[i][1] {
+				t.Errorf("Expected %v %v in %v: %v %v", word, vecs[i], format, actualWord, actualVec)
+			}
+		}
+		if _, _, err := r.Read(); err != io.EOF {
+			t.Errorf("Expected io.EOF after the last vector in %v: %v", format, err)
+		}
+	}
+}
+
+func TestInvalidReaderWriter(t *testing.T) {
+	if _, err := NewReader(strings.NewReader(""), FormatJSON); err == nil {
+		t.Error("Expected to fail creating Reader except for text|binary")
+	}
+	if _, err := NewWriter(&bytes.Buffer{}, FormatNpy, 0, 0); err == nil {
+		t.Error("Expected to fail creating Writer except for text|binary")
+	}
+}
+
+func TestBinaryWriterSize(t *testing.T) {
+	w := NewBinaryWriter(&bytes.Buffer{}, 1, 2)
+	if err := w.Write("a", []float64{1}); err == nil {
+		t.Error("Expected to fail writing vector with different dimension")
+	}
+	if err := w.Flush(); err != nil {
+		t.Errorf("Expected to flush fewer vectors than the header mid-stream: %v", err)
+	}
+	if err := w.Close(); err == nil {
+		t.Error("Expected to fail closing fewer vectors than the header")
+	}
+	if err := w.Write("a", []float64{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write("b", []float64{1, 2}); err == nil {
+		t.Error("Expected to fail writing more vectors than the header")
+	}
+}
+
+func TestBinaryReaderHeader(t *testing.T) {
+	for _, header := range []string{"-1 2\n", "1 0\n", "1 -2\n", "1 2000000000\n"} {
+		if _, err := ReadBinary(strings.NewReader(header)); err == nil {
+			t.Errorf("Expected to fail reading invalid header %q", header)
+		}
+	}
+	vectors, err := ReadBinary(strings.NewReader("0 0\n"))
+	if err != nil || len(vectors.Words) != 0 {
+		t.Errorf("Expected to read no vectors with the header of empty vectors: %v", err)
+	}
+}
+
+func TestTextReaderDimension(t *testing.T) {
+	if _, err := ReadText(strings.NewReader("a 1 2\nb 1\n")); err == nil {
+		t.Error("Expected to fail reading vectors with different dimension")
+	}
+}
+
+func TestReadCRLF(t *testing.T) {
+	expected := &Vectors{
+		Words:  []string{"a", "b"},
+		Vector: map[string][]float64{"a": {1, 2}, "b": {3, 4}},
+	}
+	testCases := []struct {
+		format string
+		input  string
+	}{
+		{format: "text", input: "\ufeffa 1 2\r\nb 3 4\r\n"},
+		{format: "fasttext-vec", input: "\ufeff2 2\r\na 1 2 \r\nb 3 4 \r\n"},
+	}
+	for _, testCase := range testCases {
+		actual, err := Read(strings.NewReader(testCase.input), testCase.format)
+		if err != nil {
+			t.Fatalf("Expected to read %s with BOM and CRLF: %v", testCase.format, err)
+		}
+		assertEqualVectors(t, testCase.format, expected, actual)
+
+		var buf bytes.Buffer
+		if err := Write(&buf, testCase.format, actual); err != nil {
+			t.Fatal(err)
+		}
+		if strings.ContainsAny(buf.String(), "\r\ufeff") {
+			t.Errorf("Expected %s written with LF and without BOM: %q", testCase.format, buf.String())
+		}
+	}
+}
+
+func TestWriteInvalidWords(t *testing.T) {
+	vectors := &Vectors{
+		Words: []string{"a", "new york", "b\tc", "d\ne", ""},
+		Vector: map[string][]float64{
+			"a": {1}, "new york": {2}, "b\tc": {3}, "d\ne": {4}, "": {5},
+		},
+	}
+	for _, format := range []string{FormatText, FormatBinary, FormatFastTextVec} {
+		var buf bytes.Buffer
+		err := Write(&buf, format, vectors)
+		if err == nil {
+			t.Errorf("Expected to fail writing words with whitespace in %v", format)
+			continue
+		}
+		for _, word := range []string{`"new york"`, `"b\tc"`, `"d\ne"`, `""`} {
+			if !strings.Contains(err.Error(), word) {
+				t.Errorf("Expected %v to be listed in %v: %v", word, format, err)
+			}
+		}
+		if strings.Contains(err.Error(), `"a"`) {
+			t.Errorf("Expected valid word not to be listed in %v: %v", format, err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("Expected nothing to be written in %v: %q", format, buf.String())
+		}
+	}
+
+	if err := NewTextWriter(&bytes.Buffer{}).Write("new york", []float64{1}); err == nil {
+		t.Error("Expected to fail writing a word with space one by one")
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatJSON, vectors); err != nil {
+		t.Fatalf("Expected json to keep words with whitespace: %v", err)
+	}
+	actual, err := Read(&buf, FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual.Vector["new york"][0] != 2 || actual.Vector["b\tc"][0] != 3 {
+		t.Errorf("Expected words with whitespace to round-trip in json: %v", actual.Vector)
+	}
+}
+
+func TestAnisotropy(t *testing.T) {
+	cone := &Vectors{
+		Words:  []string{"a", "b", "c"},
+		Vector: map[string][]float64{"a": {1, 0.1}, "b": {1, -0.1}, "c": {1, 0}},
+	}
+	if a := cone.Anisotropy(); a < 0.95 {
+		t.Errorf("Expected anisotropy close to 1 for vectors in a narrow cone: %v", a)
+	}
+	opposite := &Vectors{
+		Words:  []string{"a", "b", "zero"},
+		Vector: map[string][]float64{"a": {1, 0}, "b": {-1, 0}, "zero": {0, 0}},
+	}
+	if a := opposite.Anisotropy(); a != -1 {
+		t.Errorf("Expected anisotropy=-1 for opposite vectors ignoring zero vector: %v", a)
+	}
+}